@@ -14,6 +14,7 @@ import (
 	"html"
 	"net/http"
 	"sort"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/StackVista/stackstate-agent/cmd/agent/gui"
 	"github.com/StackVista/stackstate-agent/pkg/autodiscovery"
 	"github.com/StackVista/stackstate-agent/pkg/autodiscovery/integration"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	"github.com/StackVista/stackstate-agent/pkg/config"
 	"github.com/StackVista/stackstate-agent/pkg/flare"
 	"github.com/StackVista/stackstate-agent/pkg/secrets"
@@ -57,6 +59,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/config/{setting}", setRuntimeConfig).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
+	r.HandleFunc("/topology", getTopology).Methods("GET") // [sts]
 
 	return r
 }
@@ -391,6 +394,32 @@ func secretInfo(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonInfo)
 }
 
+// [sts] getTopology returns the last topology and health data flushed by the batcher per check. The optional `since`
+// parameter (RFC3339) limits the result to the checks that flushed after that time
+func getTopology(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	since := time.Time{}
+	if param := r.URL.Query().Get("since"); param != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, param)
+		if err != nil {
+			body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid since parameter: %s", err)})
+			http.Error(w, string(body), 400)
+			return
+		}
+		since = parsed
+	}
+
+	jsonTopology, err := json.Marshal(batcher.GetFlushInspector().Statuses(since))
+	if err != nil {
+		log.Errorf("Unable to marshal topology response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(jsonTopology)
+}
+
 // max returns the maximum value between a and b.
 func max(a, b int) int {
 	if a > b {
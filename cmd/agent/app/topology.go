@@ -0,0 +1,220 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/StackVista/stackstate-agent/cmd/agent/common"
+	"github.com/StackVista/stackstate-agent/pkg/api/util"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+)
+
+var (
+	topologyCheckID        string
+	topologyFollow         bool
+	topologyFollowInterval int
+)
+
+func init() {
+	AgentCmd.AddCommand(topologyCmd)
+	topologyCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
+	topologyCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
+	topologyCmd.Flags().StringVarP(&topologyCheckID, "check", "c", "", "only show the data of the given check id")
+	topologyCmd.Flags().BoolVarP(&topologyFollow, "follow", "f", false, "keep printing new flushes as JSON lines")
+	topologyCmd.Flags().IntVarP(&topologyFollowInterval, "interval", "i", 1, "interval in seconds between polls when following")
+}
+
+var topologyCmd = &cobra.Command{
+	Use:          "topology",
+	Short:        "Print the last topology and health data sent by the batcher of a running agent",
+	Long:         ``,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		// Set session token
+		err = util.SetAuthToken()
+		if err != nil {
+			return err
+		}
+
+		c := util.GetClient(false) // FIX: get certificates right then make this true
+		if topologyFollow {
+			return followTopology(c)
+		}
+		return requestTopology(c)
+	},
+}
+
+func getTopologyStatuses(c *http.Client, since time.Time) ([]batcher.CheckFlushStatus, error) {
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return nil, err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/topology", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	if !since.IsZero() {
+		urlstr = fmt.Sprintf("%s?since=%s", urlstr, url.QueryEscape(since.Format(time.RFC3339Nano)))
+	}
+
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			err = fmt.Errorf(e)
+		}
+		return nil, fmt.Errorf("could not reach agent: %v\nMake sure the agent is running before requesting the topology", err)
+	}
+
+	statuses := make([]batcher.CheckFlushStatus, 0)
+	if err := json.Unmarshal(r, &statuses); err != nil {
+		return nil, err
+	}
+
+	if topologyCheckID == "" {
+		return statuses, nil
+	}
+	filtered := make([]batcher.CheckFlushStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.CheckID == check.ID(topologyCheckID) {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered, nil
+}
+
+func requestTopology(c *http.Client) error {
+	statuses, err := getTopologyStatuses(c, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	// The rendering is done in the client so that the agent has less work to do
+	if prettyPrintJSON || jsonStatus {
+		r, err := json.Marshal(statuses)
+		if err != nil {
+			return err
+		}
+		if prettyPrintJSON {
+			var prettyJSON bytes.Buffer
+			json.Indent(&prettyJSON, r, "", "  ") //nolint:errcheck
+			r = prettyJSON.Bytes()
+		}
+		fmt.Println(string(r))
+		return nil
+	}
+
+	if len(statuses) == 0 {
+		fmt.Fprintln(color.Output, "No topology or health data was flushed yet")
+		return nil
+	}
+	for _, status := range statuses {
+		printTopologyStatus(status)
+	}
+	return nil
+}
+
+// followTopology polls the agent and prints every new flush as a single JSON line
+func followTopology(c *http.Client) error {
+	since := time.Time{}
+	for {
+		statuses, err := getTopologyStatuses(c, since)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			r, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(r))
+			if status.LastFlush.After(since) {
+				since = status.LastFlush
+			}
+		}
+		time.Sleep(time.Duration(topologyFollowInterval) * time.Second)
+	}
+}
+
+func printTopologyStatus(status batcher.CheckFlushStatus) {
+	fmt.Fprintln(color.Output, fmt.Sprintf("\n=== Check %s ===", color.GreenString(string(status.CheckID))))
+	fmt.Fprintln(color.Output, fmt.Sprintf("Last flush: %s (%d flushes)", status.LastFlush.Format(time.RFC3339), status.FlushCount))
+	if status.LastFlushSuccess {
+		fmt.Fprintln(color.Output, fmt.Sprintf("Last flush result: %s", color.GreenString("OK")))
+	} else {
+		fmt.Fprintln(color.Output, fmt.Sprintf("Last flush result: %s", color.RedString("ERROR")))
+	}
+	if status.LastError != "" && status.LastErrorTime != nil {
+		fmt.Fprintln(color.Output, fmt.Sprintf("Last error: %s at %s", color.RedString(status.LastError), status.LastErrorTime.Format(time.RFC3339)))
+	}
+
+	if status.Topology != nil {
+		fmt.Fprintln(color.Output, fmt.Sprintf("Topology instance: %s %s, start snapshot: %t, stop snapshot: %t",
+			color.BlueString(status.Topology.Instance.Type), color.CyanString(status.Topology.Instance.URL),
+			status.Topology.StartSnapshot, status.Topology.StopSnapshot))
+		fmt.Fprintln(color.Output, fmt.Sprintf("Components: %d, relations: %d, deletes: %d",
+			status.ComponentCount, status.RelationCount, status.DeleteCount))
+	}
+
+	for _, healthBatch := range status.Health {
+		stream := healthBatch.Stream.Urn
+		if healthBatch.Stream.SubStream != "" {
+			stream = fmt.Sprintf("%s/%s", stream, healthBatch.Stream.SubStream)
+		}
+		fmt.Fprintln(color.Output, fmt.Sprintf("Health stream %s: %d check states, start snapshot: %t, stop snapshot: %t",
+			color.BlueString(stream), len(healthBatch.CheckStates), healthBatch.StartSnapshot != nil, healthBatch.StopSnapshot != nil))
+	}
+
+	for _, rollup := range status.HealthRollup {
+		stream := rollup.Urn
+		if rollup.SubStream != "" {
+			stream = fmt.Sprintf("%s/%s", stream, rollup.SubStream)
+		}
+		fmt.Fprintln(color.Output, fmt.Sprintf("Health of %s: %s (%d check states)", color.BlueString(stream),
+			healthStateString(rollup.Health), rollup.StateCount))
+	}
+	fmt.Fprintln(color.Output, "===")
+}
+
+func healthStateString(state health.State) string {
+	switch state {
+	case health.Clear:
+		return color.GreenString(string(state))
+	case health.Deviating:
+		return color.YellowString(string(state))
+	case health.Critical:
+		return color.RedString(string(state))
+	default:
+		return string(state)
+	}
+}
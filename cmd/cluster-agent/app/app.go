@@ -184,7 +184,7 @@ func start(cmd *cobra.Command, args []string) error {
 	aggregatorInstance.AddAgentStartupTelemetry(fmt.Sprintf("%s - Datadog Cluster Agent", version.AgentVersion))
 
 	// [sts] init the batcher for topology production
-	batcher.InitBatcher(s, hostname, "agent", config.GetMaxCapacity())
+	batcher.InitBatcher(s, hostname, "cluster-agent", config.GetMaxCapacity())
 
 	log.Infof("Datadog Cluster Agent is now running.")
 
@@ -422,7 +422,7 @@ func readResponseStatuses(checkName string, responses <-chan forwarder.Response)
 }
 
 func encodePayload(m model.MessageBody) ([]byte, error) {
-	msgType, err := model.DetectMessageType(m)
+	msgType, err := detectMessageType(m)
 	if err != nil {
 		return nil, fmt.Errorf("unable to detect message type: %s", err)
 	}
@@ -435,6 +435,16 @@ func encodePayload(m model.MessageBody) ([]byte, error) {
 		}, Body: m})
 }
 
+// detectMessageType detects the message type of the message body, the connections with extended stats are sent as
+// collector connections
+func detectMessageType(m model.MessageBody) (model.MessageType, error) {
+	// sts
+	if _, ok := m.(*checks.CollectorConnections); ok {
+		return model.TypeCollectorConnections, nil
+	}
+	return model.DetectMessageType(m)
+}
+
 func keysPerDomains(endpoints []api.Endpoint) map[string][]string {
 	keysPerDomains := make(map[string][]string)
 
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	model "github.com/DataDog/agent-payload/process"
+	"github.com/StackVista/stackstate-agent/pkg/network/encoding"
+	"github.com/StackVista/stackstate-agent/pkg/process/checks"
 	"github.com/StackVista/stackstate-agent/pkg/process/config"
 	"github.com/StackVista/stackstate-agent/pkg/process/util/api"
 	"github.com/stretchr/testify/assert"
@@ -97,6 +99,30 @@ func TestHasContainers(t *testing.T) {
 	assert.Equal(1, getContainerCount(&collectorContainerRealTime))
 }
 
+func TestEncodePayloadWithConnectionsExtension(t *testing.T) {
+	body := &checks.CollectorConnections{
+		CollectorConnections: &model.CollectorConnections{
+			HostName:    "host",
+			Connections: []*model.Connection{{Pid: 1}},
+		},
+		Extension: &encoding.ConnectionsExtension{
+			Conns: []*encoding.ConnectionExtension{{ConnectionsOpened: 2}},
+		},
+	}
+
+	payload, err := encodePayload(body)
+	require.NoError(t, err)
+
+	msg, err := model.DecodeMessage(payload)
+	require.NoError(t, err)
+	assert.Equal(t, model.MessageType(model.TypeCollectorConnections), msg.Header.Type)
+	decoded, ok := msg.Body.(*model.CollectorConnections)
+	require.True(t, ok)
+	assert.Equal(t, "host", decoded.HostName)
+	require.Len(t, decoded.Connections, 1)
+	assert.Equal(t, int32(1), decoded.Connections[0].Pid)
+}
+
 func TestRemovePathIfPresent(t *testing.T) {
 	for _, tt := range []struct {
 		input    string
@@ -9,12 +9,15 @@ import (
 	"os"
 	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	ddconfig "github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/forwarder"
 	"github.com/StackVista/stackstate-agent/pkg/pidfile"
 	"github.com/StackVista/stackstate-agent/pkg/process/checks"
 	"github.com/StackVista/stackstate-agent/pkg/process/config"
 	"github.com/StackVista/stackstate-agent/pkg/process/statsd"
 	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/serializer"
 	"github.com/StackVista/stackstate-agent/pkg/tagger"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 )
@@ -168,6 +171,16 @@ func runAgent(exit chan struct{}) {
 		http.ListenAndServe(fmt.Sprintf("localhost:%d", cfg.ProcessExpVarPort), nil) //nolint:errcheck
 	}()
 
+	// [sts] init the batcher before the checks, the connections check produces topology from its first run
+	if cfg.EnableConnectionsTopology {
+		topologyForwarder, err := initBatcher(cfg)
+		if err != nil {
+			log.Errorf("Error initializing the batcher, no process topology will be reported: %s", err)
+		} else {
+			defer topologyForwarder.Stop()
+		}
+	}
+
 	cl, err := NewCollector(cfg)
 	if err != nil {
 		log.Criticalf("Error creating collector: %s", err)
@@ -184,6 +197,21 @@ func runAgent(exit chan struct{}) {
 	}
 }
 
+// initBatcher starts the forwarder and the batcher that submit the process topology to the v1 intake, the forwarder is
+// returned to be stopped when the agent exits
+func initBatcher(cfg *config.AgentConfig) (forwarder.Forwarder, error) {
+	keysPerDomain, err := ddconfig.GetMultipleEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("misconfiguration of agent endpoints: %s", err)
+	}
+	topologyForwarder := forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	if err := topologyForwarder.Start(); err != nil {
+		return nil, fmt.Errorf("error starting topology forwarder: %s", err)
+	}
+	batcher.InitBatcher(serializer.NewSerializer(topologyForwarder), cfg.HostName, "process-agent", ddconfig.GetMaxCapacity())
+	return topologyForwarder, nil
+}
+
 func debugCheckResults(cfg *config.AgentConfig, check string) error {
 	sysInfo, err := checks.CollectSystemInfo(cfg)
 	if err != nil {
@@ -6,7 +6,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/network"
 	"github.com/StackVista/stackstate-agent/pkg/process/config"
 
 	_ "net/http/pprof"
@@ -21,6 +24,11 @@ func main() {
 	opts.checkCmd = flag.NewFlagSet("check", flag.ExitOnError)
 	flag.StringVar(&opts.checkType, "type", "", "The type of check to run. Choose from: connections, network_maps, network_state, stats")
 	flag.StringVar(&opts.checkClient, "client", "", "The client ID that the check will use to run")
+
+	// sts
+	opts.replayCmd = flag.NewFlagSet("replay", flag.ExitOnError)
+	opts.replayCmd.StringVar(&opts.replayPcap, "pcap", "", "The pcap or pcapng capture of ethernet frames to replay")
+	opts.replayCmd.DurationVar(&opts.replayInterval, "interval", 30*time.Second, "The capture time between two snapshots of the connections")
 	flag.Parse()
 
 	runAgent()
@@ -47,3 +55,67 @@ func runCheck(cfg *config.AgentConfig) {
 		cleanupAndExit(0)
 	}
 }
+
+// sts
+// run replay command if the flag is specified, it prints the connections and DNS stats the system probe would have
+// reported for the traffic in a capture
+func runReplay(cfg *config.AgentConfig) {
+	if flag.NArg() >= 1 && flag.Arg(0) == "replay" {
+		err := opts.replayCmd.Parse(flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			cleanupAndExit(1)
+		}
+		if opts.replayPcap == "" {
+			opts.replayCmd.PrintDefaults()
+			cleanupAndExit(1)
+		}
+		err = replayCapture(cfg, opts.replayPcap, opts.replayInterval)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			cleanupAndExit(1)
+		}
+
+		cleanupAndExit(0)
+	}
+}
+
+func replayCapture(cfg *config.AgentConfig, path string, interval time.Duration) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tracerCfg := config.SysProbeConfigFromConfig(cfg)
+	replayCfg := network.DefaultReplayConfig()
+	replayCfg.Interval = interval
+	replayCfg.UDPConnTimeout = tracerCfg.UDPConnTimeout
+	replayCfg.CollectLocalDNS = tracerCfg.CollectLocalDNS
+	replayCfg.DNSTimeout = tracerCfg.DNSTimeout
+	replayCfg.MaxDNSDomainsTracked = tracerCfg.MaxDNSDomainsTracked
+	replayCfg.MaxClosedConnectionsBuffered = tracerCfg.MaxClosedConnectionsBuffered
+	replayCfg.MaxConnectionsStateBuffered = tracerCfg.MaxConnectionsStateBuffered
+	replayCfg.MaxDNSStatsBuffered = tracerCfg.MaxDNSStatsBufferred
+	replayCfg.AggregateConnections = tracerCfg.AggregateConnections
+
+	stats, err := network.Replay(f, replayCfg, func(snapshot network.ReplaySnapshot) {
+		network.FormatReplaySnapshot(os.Stdout, snapshot)
+	})
+	if stats != nil {
+		fmt.Println("telemetry:")
+		for _, name := range sortedKeys(stats) {
+			fmt.Printf("  %s: %d\n", name, stats[name])
+		}
+	}
+	return err
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
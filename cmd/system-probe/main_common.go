@@ -29,6 +29,11 @@ var opts struct {
 	checkCmd    *flag.FlagSet
 	checkType   string
 	checkClient string
+
+	// sts
+	replayCmd      *flag.FlagSet
+	replayPcap     string
+	replayInterval time.Duration
 }
 
 // Version info sourced from build flags
@@ -70,6 +75,9 @@ func runAgent() {
 		cleanupAndExit(1)
 	}
 
+	// sts - replaying a capture does not need a running system probe
+	runReplay(cfg)
+
 	// Exit if system probe is disabled
 	if !cfg.EnableSystemProbe {
 		log.Info("system probe not enabled. exiting.")
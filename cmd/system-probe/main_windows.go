@@ -19,3 +19,7 @@ func main() {
 func runCheck(cfg *config.AgentConfig) {
 	return
 }
+
+func runReplay(cfg *config.AgentConfig) {
+	return
+}
@@ -65,6 +65,9 @@ type CommonInstanceConfig struct {
 	Service               string   `yaml:"service"`
 	Name                  string   `yaml:"name"`
 	Namespace             string   `yaml:"namespace"`
+	// [sts] batcher overrides
+	BatcherMaxCapacity   int `yaml:"batcher_max_capacity"`
+	BatcherFlushInterval int `yaml:"batcher_flush_interval"`
 }
 
 // CommonGlobalConfig holds the reserved fields for the yaml init_config data
@@ -1,6 +1,9 @@
 package batcher
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
@@ -15,6 +18,14 @@ type CheckInstanceBatchState struct {
 // CheckInstanceBatchStates is the type representing batched data for all check instances
 type CheckInstanceBatchStates map[check.ID]CheckInstanceBatchState
 
+// CheckBatchConfig holds the batching settings of a single check, overriding the batcher wide defaults
+type CheckBatchConfig struct {
+	// MaxCapacity is the amount of elements of this check after which the batch is flushed, 0 means no override
+	MaxCapacity int
+	// FlushInterval is the maximum time data of this check is held before flushing, 0 means no override
+	FlushInterval time.Duration
+}
+
 // BatchBuilder is a helper class to build Topology based on submitted data, this data structure is not thread safe
 type BatchBuilder struct {
 	states CheckInstanceBatchStates
@@ -22,17 +33,42 @@ type BatchBuilder struct {
 	elementCount int
 	// Amount of elements when we flush
 	maxCapacity int
+	// Approximate serialized size in bytes of the elements we gathered
+	payloadSize int
+	// Serialized size in bytes above which we flush, 0 means no limit
+	maxPayloadSize int
+	// Maximum time data is held before flushing, 0 means no limit
+	flushInterval time.Duration
+	// Per check overrides and bookkeeping
+	checkConfigs      map[check.ID]CheckBatchConfig
+	checkElementCount map[check.ID]int
+	checkDataSince    map[check.ID]time.Time
+	now               func() time.Time
 }
 
 // NewBatchBuilder constructs a BatchBuilder
 func NewBatchBuilder(maxCapacity int) BatchBuilder {
 	return BatchBuilder{
-		states:       make(map[check.ID]CheckInstanceBatchState),
-		elementCount: 0,
-		maxCapacity:  maxCapacity,
+		states:            make(map[check.ID]CheckInstanceBatchState),
+		elementCount:      0,
+		maxCapacity:       maxCapacity,
+		checkConfigs:      make(map[check.ID]CheckBatchConfig),
+		checkElementCount: make(map[check.ID]int),
+		checkDataSince:    make(map[check.ID]time.Time),
+		now:               time.Now,
 	}
 }
 
+// SetCheckBatchConfig overrides the batching settings for a check
+func (builder *BatchBuilder) SetCheckBatchConfig(checkID check.ID, config CheckBatchConfig) {
+	builder.checkConfigs[checkID] = config
+}
+
+// RemoveCheck forgets the batching settings of a check that is no longer scheduled
+func (builder *BatchBuilder) RemoveCheck(checkID check.ID) {
+	delete(builder.checkConfigs, checkID)
+}
+
 func (builder *BatchBuilder) getOrCreateState(checkID check.ID) CheckInstanceBatchState {
 	if value, ok := builder.states[checkID]; ok {
 		return value
@@ -85,16 +121,20 @@ func (builder *BatchBuilder) getOrCreateHealth(checkID check.ID, stream health.S
 
 // AddComponent adds a component
 func (builder *BatchBuilder) AddComponent(checkID check.ID, instance topology.Instance, component topology.Component) CheckInstanceBatchStates {
+	size := builder.elementSize(component)
+	flushed := builder.flushIfPayloadTooLarge(size)
 	topologyData := builder.getOrCreateTopology(checkID, instance)
 	topologyData.Components = append(topologyData.Components, component)
-	return builder.incrementAndTryFlush()
+	return builder.incrementAndTryFlush(checkID, size, flushed)
 }
 
 // AddRelation adds a relation
 func (builder *BatchBuilder) AddRelation(checkID check.ID, instance topology.Instance, relation topology.Relation) CheckInstanceBatchStates {
+	size := builder.elementSize(relation)
+	flushed := builder.flushIfPayloadTooLarge(size)
 	topologyData := builder.getOrCreateTopology(checkID, instance)
 	topologyData.Relations = append(topologyData.Relations, relation)
-	return builder.incrementAndTryFlush()
+	return builder.incrementAndTryFlush(checkID, size, flushed)
 }
 
 // TopologyStartSnapshot starts a snapshot
@@ -112,12 +152,27 @@ func (builder *BatchBuilder) TopologyStopSnapshot(checkID check.ID, instance top
 	return builder.Flush()
 }
 
+// TopologyDeltaStopSnapshot ends a snapshot that is sent as a delta. The elements that disappeared since the previous
+// snapshot are sent as deletes, together with any changed elements
+func (builder *BatchBuilder) TopologyDeltaStopSnapshot(checkID check.ID, instance topology.Instance, deleteIDs []string) CheckInstanceBatchStates {
+	if len(deleteIDs) == 0 {
+		return builder.FlushIfDataProduced(checkID)
+	}
+
+	topologyData := builder.getOrCreateTopology(checkID, instance)
+	topologyData.DeleteIDs = append(topologyData.DeleteIDs, deleteIDs...)
+	// Like TopologyStopSnapshot we always flush to limit latency
+	return builder.Flush()
+}
+
 // AddHealthCheckData adds a component
 func (builder *BatchBuilder) AddHealthCheckData(checkID check.ID, stream health.Stream, data health.CheckData) CheckInstanceBatchStates {
+	size := builder.elementSize(data)
+	flushed := builder.flushIfPayloadTooLarge(size)
 	healthData := builder.getOrCreateHealth(checkID, stream)
 	healthData.CheckStates = append(healthData.CheckStates, data)
 	builder.states[checkID].Health[stream.GoString()] = healthData
-	return builder.incrementAndTryFlush()
+	return builder.incrementAndTryFlush(checkID, size, flushed)
 }
 
 // HealthStartSnapshot starts a Health snapshot
@@ -145,16 +200,55 @@ func (builder *BatchBuilder) Flush() CheckInstanceBatchStates {
 	data := builder.states
 	builder.states = make(map[check.ID]CheckInstanceBatchState)
 	builder.elementCount = 0
+	builder.payloadSize = 0
+	builder.checkElementCount = make(map[check.ID]int)
+	builder.checkDataSince = make(map[check.ID]time.Time)
 	return data
 }
 
-func (builder *BatchBuilder) incrementAndTryFlush() CheckInstanceBatchStates {
+// elementSize returns the serialized size of an element, only computed when the payload size is limited
+func (builder *BatchBuilder) elementSize(element interface{}) int {
+	if builder.maxPayloadSize <= 0 {
+		return 0
+	}
+	b, err := json.Marshal(element)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// flushIfPayloadTooLarge flushes the collected data when adding an element of the given size would exceed the
+// maximum payload size. The element then starts the next batch
+func (builder *BatchBuilder) flushIfPayloadTooLarge(size int) CheckInstanceBatchStates {
+	if builder.maxPayloadSize > 0 && builder.elementCount > 0 && builder.payloadSize+size > builder.maxPayloadSize {
+		return builder.Flush()
+	}
+	return nil
+}
+
+// incrementAndTryFlush accounts for an added element and flushes when a capacity is reached. When the element
+// already caused a size based flush, that data is returned and the element starts the next batch
+func (builder *BatchBuilder) incrementAndTryFlush(checkID check.ID, size int, flushed CheckInstanceBatchStates) CheckInstanceBatchStates {
 	builder.elementCount = builder.elementCount + 1
+	builder.payloadSize = builder.payloadSize + size
+	builder.checkElementCount[checkID] = builder.checkElementCount[checkID] + 1
+	if _, ok := builder.checkDataSince[checkID]; !ok {
+		builder.checkDataSince[checkID] = builder.now()
+	}
+
+	if flushed != nil {
+		return flushed
+	}
 
 	if builder.elementCount >= builder.maxCapacity {
 		return builder.Flush()
 	}
 
+	if checkCapacity := builder.checkConfigs[checkID].MaxCapacity; checkCapacity > 0 && builder.checkElementCount[checkID] >= checkCapacity {
+		return builder.Flush()
+	}
+
 	return nil
 }
 
@@ -166,3 +260,19 @@ func (builder *BatchBuilder) FlushIfDataProduced(checkID check.ID) CheckInstance
 
 	return nil
 }
+
+// FlushIfExpired flushes the collected data when any check has held data for longer than its flush interval
+func (builder *BatchBuilder) FlushIfExpired() CheckInstanceBatchStates {
+	now := builder.now()
+	for checkID, since := range builder.checkDataSince {
+		interval := builder.flushInterval
+		if checkInterval := builder.checkConfigs[checkID].FlushInterval; checkInterval > 0 {
+			interval = checkInterval
+		}
+		if interval > 0 && now.Sub(since) >= interval {
+			return builder.Flush()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package batcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderSplitsOnPayloadSize(t *testing.T) {
+	builder := NewBatchBuilder(100)
+	// testComponent serializes to 56 bytes, so two components fit
+	builder.maxPayloadSize = 120
+
+	assert.Nil(t, builder.TopologyStartSnapshot(testID, testInstance))
+	assert.Nil(t, builder.AddComponent(testID, testInstance, testComponent))
+	assert.Nil(t, builder.AddComponent(testID, testInstance, testComponent))
+
+	flushed := builder.AddComponent(testID, testInstance, testComponent2)
+	assert.Equal(t, CheckInstanceBatchStates(map[check.ID]CheckInstanceBatchState{
+		testID: {
+			Health: make(map[string]health.Health),
+			Topology: &topology.Topology{
+				StartSnapshot: true,
+				StopSnapshot:  false,
+				Instance:      testInstance,
+				Components:    []topology.Component{testComponent, testComponent},
+				Relations:     []topology.Relation{},
+			},
+		},
+	}), flushed)
+
+	// The element that triggered the flush starts the next batch
+	assert.Equal(t, CheckInstanceBatchStates(map[check.ID]CheckInstanceBatchState{
+		testID: {
+			Health: make(map[string]health.Health),
+			Topology: &topology.Topology{
+				StartSnapshot: false,
+				StopSnapshot:  true,
+				Instance:      testInstance,
+				Components:    []topology.Component{testComponent2},
+				Relations:     []topology.Relation{},
+			},
+		},
+	}), builder.TopologyStopSnapshot(testID, testInstance))
+}
+
+func TestBuilderOversizedElementIsSentAlone(t *testing.T) {
+	builder := NewBatchBuilder(100)
+	builder.maxPayloadSize = 10
+
+	assert.Nil(t, builder.AddComponent(testID, testInstance, testComponent))
+	flushed := builder.AddComponent(testID, testInstance, testComponent2)
+	assert.Equal(t, []topology.Component{testComponent}, flushed[testID].Topology.Components)
+}
+
+func TestBuilderPerCheckCapacity(t *testing.T) {
+	builder := NewBatchBuilder(100)
+	builder.SetCheckBatchConfig(testID2, CheckBatchConfig{MaxCapacity: 2})
+
+	assert.Nil(t, builder.AddComponent(testID, testInstance, testComponent))
+	assert.Nil(t, builder.AddComponent(testID, testInstance, testComponent))
+	assert.Nil(t, builder.AddHealthCheckData(testID2, testStream, testCheckData))
+
+	flushed := builder.AddHealthCheckData(testID2, testStream, testCheckData)
+	assert.Len(t, flushed, 2)
+	assert.Len(t, flushed[testID2].Health[testStream.GoString()].CheckStates, 2)
+}
+
+func TestBuilderRemoveCheck(t *testing.T) {
+	builder := NewBatchBuilder(100)
+	builder.SetCheckBatchConfig(testID, CheckBatchConfig{MaxCapacity: 2})
+	builder.SetCheckBatchConfig(testID2, CheckBatchConfig{MaxCapacity: 2})
+
+	builder.RemoveCheck(testID)
+	assert.Equal(t, map[check.ID]CheckBatchConfig{testID2: {MaxCapacity: 2}}, builder.checkConfigs)
+}
+
+func TestBuilderFlushIfExpired(t *testing.T) {
+	now := time.Now()
+	builder := NewBatchBuilder(100)
+	builder.now = func() time.Time { return now }
+	builder.flushInterval = time.Minute
+	builder.SetCheckBatchConfig(testID2, CheckBatchConfig{FlushInterval: 10 * time.Second})
+
+	assert.Nil(t, builder.FlushIfExpired())
+
+	assert.Nil(t, builder.AddComponent(testID, testInstance, testComponent))
+	now = now.Add(30 * time.Second)
+	assert.Nil(t, builder.FlushIfExpired())
+
+	// The check override expires before the global interval
+	assert.Nil(t, builder.AddComponent(testID2, testInstance2, testComponent))
+	now = now.Add(10 * time.Second)
+	flushed := builder.FlushIfExpired()
+	assert.Len(t, flushed, 2)
+
+	assert.Nil(t, builder.FlushIfExpired())
+}
@@ -1,14 +1,19 @@
 package batcher
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/forwarder"
 	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/serializer"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"sort"
 	"sync"
+	"time"
 )
 
 var (
@@ -16,6 +21,12 @@ var (
 	batcherInit     sync.Once
 )
 
+const flushExpiryCheckInterval = time.Second
+
+// errPayloadSpooled is returned for the payloads that are spooled without being sent, as older payloads are still
+// waiting in the spool
+var errPayloadSpooled = errors.New("the intake is unavailable, the payload is spooled")
+
 // Batcher interface can receive data for sending to the intake and will accumulate the data in batches. This does
 // not work on a fixed schedule like the aggregator but flushes either when data exceeds a threshold, when
 // data is complete.
@@ -32,7 +43,9 @@ type Batcher interface {
 	SubmitHealthStopSnapshot(checkID check.ID, stream health.Stream)
 
 	// lifecycle
+	SubmitCheckBatchConfig(checkID check.ID, config CheckBatchConfig)
 	SubmitComplete(checkID check.ID)
+	SubmitCheckStopped(checkID check.ID)
 	Shutdown()
 }
 
@@ -44,17 +57,49 @@ func InitBatcher(serializer serializer.AgentV1Serializer, hostname, agentName st
 }
 
 func newAsynchronousBatcher(serializer serializer.AgentV1Serializer, hostname, agentName string, maxCapacity int) AsynchronousBatcher {
+	builder := NewBatchBuilder(maxCapacity)
+	builder.maxPayloadSize = config.Datadog.GetInt("batcher_max_payload_size")
+	builder.flushInterval = time.Duration(config.Datadog.GetInt64("batcher_flush_interval")) * time.Second
+
 	batcher := AsynchronousBatcher{
-		builder:    NewBatchBuilder(maxCapacity),
+		builder:    builder,
 		hostname:   hostname,
 		agentName:  agentName,
 		input:      make(chan interface{}),
 		serializer: serializer,
+		deltas:     newTopologyDeltaTrackerFromConfig(),
+		validator:  newTopologyValidatorFromConfig(),
+		health:     newHealthStateTrackerFromConfig(),
+	}
+	batcher.delivery, batcher.spool = newDeliveryFromConfig(serializer, agentName, batcher.deltas != nil)
+	if batcher.deltas != nil && batcher.delivery == nil {
+		// Without knowing whether a delta reached the intake, the receiver can not be resynced after a lost delta
+		log.Warnf("The serializer of the batcher can not confirm the delivery of payloads, topology deltas are disabled")
+		batcher.deltas = nil
 	}
 	go batcher.run()
 	return batcher
 }
 
+// newDeliveryFromConfig returns the serializer that confirms the delivery of the payloads and the spool, when the
+// spool or the topology deltas are enabled. Both rely on knowing whether a payload reached the intake.
+func newDeliveryFromConfig(s serializer.AgentV1Serializer, agentName string, deltas bool) (serializer.AgentV1DeliverySerializer, *Spool) {
+	spoolEnabled := config.Datadog.GetBool("batcher_spool.enabled")
+	if !spoolEnabled && !deltas {
+		return nil, nil
+	}
+
+	delivery, ok := s.(serializer.AgentV1DeliverySerializer)
+	if !ok {
+		log.Debugf("The serializer of the batcher can not confirm the delivery of payloads, the spool is disabled")
+		return nil, nil
+	}
+	if !spoolEnabled {
+		return delivery, nil
+	}
+	return delivery, newSpoolFromConfig(agentName)
+}
+
 // GetBatcher returns a handle on the global batcher instance
 func GetBatcher() Batcher {
 	return batcherInstance
@@ -73,6 +118,17 @@ type AsynchronousBatcher struct {
 	hostname, agentName string
 	input               chan interface{}
 	serializer          serializer.AgentV1Serializer
+	// delivery sends the payloads and waits until they reached the intake, nil when no feature needs to know whether
+	// the payloads were delivered
+	delivery serializer.AgentV1DeliverySerializer
+	// spool keeps the payloads that could not be delivered, nil when spooling is disabled
+	spool *Spool
+	// deltas tracks the previous topology snapshots, nil when topology deltas are disabled
+	deltas *TopologyDeltaTracker
+	// validator checks the submitted topology, nil when validation is disabled
+	validator *TopologyValidator
+	// health deduplicates health check states and aggregates the health per stream
+	health *HealthStateTracker
 }
 
 type submitComponent struct {
@@ -115,28 +171,50 @@ type submitHealthStopSnapshot struct {
 	stream  health.Stream
 }
 
+type submitCheckBatchConfig struct {
+	checkID check.ID
+	config  CheckBatchConfig
+}
+
 type submitComplete struct {
 	checkID check.ID
 }
 
+type submitCheckStopped struct {
+	checkID check.ID
+}
+
 type submitShutdown struct{}
 
 func (batcher *AsynchronousBatcher) sendState(states CheckInstanceBatchStates) {
 	if states != nil {
 
+		// Iterate the checks in a stable order, so payloads are reproducible
+		checkIDs := make([]string, 0, len(states))
+		for checkID := range states {
+			checkIDs = append(checkIDs, string(checkID))
+		}
+		sort.Strings(checkIDs)
+
 		// Create the topologies
 		topologies := make([]topology.Topology, 0)
-		for _, state := range states {
-			if state.Topology != nil {
+		for _, checkID := range checkIDs {
+			if state := states[check.ID(checkID)]; state.Topology != nil {
 				topologies = append(topologies, *state.Topology)
 			}
 		}
 
 		// Create the healthData payload
 		healthData := make([]health.Health, 0)
-		for _, state := range states {
-			for _, healthRecord := range state.Health {
-				healthData = append(healthData, healthRecord)
+		for _, checkID := range checkIDs {
+			state := states[check.ID(checkID)]
+			streams := make([]string, 0, len(state.Health))
+			for stream := range state.Health {
+				streams = append(streams, stream)
+			}
+			sort.Strings(streams)
+			for _, stream := range streams {
+				healthData = append(healthData, state.Health[stream])
 			}
 		}
 
@@ -159,42 +237,139 @@ func (batcher *AsynchronousBatcher) sendState(states CheckInstanceBatchStates) {
 			}
 		}
 
-		// For debug purposes print out all topologies payload
-		if config.Datadog.GetBool("log_payloads") {
-			log.Debug("Flushing the following topologies:")
-			for _, topo := range topologies {
-				log.Debugf("%v", topo)
+		err := batcher.sendPayload(payload)
+		flushInspector.record(states, time.Now(), err)
+		if err != nil && batcher.deltas != nil {
+			for _, checkID := range checkIDs {
+				if state := states[check.ID(checkID)]; state.Topology != nil {
+					batcher.deltas.SendFailed(check.ID(checkID), state.Topology.Instance)
+				}
 			}
 		}
+	}
+}
 
-		if err := batcher.serializer.SendJSONToV1Intake(payload); err != nil {
+// sendPayload sends the payload to the intake. When the delivery of the payloads is confirmed, the batcher waits
+// until the payload reached the intake. With the spool enabled, payloads that were not delivered are persisted and new
+// payloads queue up behind any spooled ones to preserve ordering. Returns the error of sending the payload
+func (batcher *AsynchronousBatcher) sendPayload(payload map[string]interface{}) error {
+	if batcher.delivery == nil {
+		err := batcher.serializer.SendJSONToV1Intake(payload)
+		if err != nil {
 			_ = log.Errorf("error in SendJSONToV1Intake: %s", err)
 		}
+		return err
+	}
+
+	if batcher.spool != nil && batcher.spool.Len() > 0 {
+		// The spooled payloads are replayed every retry interval, until then new payloads wait in the spool too
+		if err := batcher.spool.Push(payload); err != nil {
+			_ = log.Errorf("error spooling payload, dropping it: %s", err)
+			return err
+		}
+		return errPayloadSpooled
+	}
+
+	err := batcher.delivery.SendJSONToV1IntakeAndWait(payload)
+	if err != nil {
+		_ = log.Errorf("error delivering the payload to the intake: %s", err)
+		// A rejected payload would be rejected again when it is replayed
+		if batcher.spool != nil && !errors.Is(err, forwarder.ErrTransactionRejected) {
+			if err := batcher.spool.Push(payload); err != nil {
+				_ = log.Errorf("error spooling payload, dropping it: %s", err)
+			}
+		}
+	}
+	return err
+}
+
+// replaySpool sends the spooled payloads in order until the spool is empty or the intake fails again
+func (batcher *AsynchronousBatcher) replaySpool() error {
+	if batcher.spool == nil || batcher.spool.Len() == 0 {
+		return nil
+	}
+
+	sent, err := batcher.spool.Replay(func(payload json.RawMessage) error {
+		err := batcher.delivery.SendJSONToV1IntakeAndWait(payload)
+		if errors.Is(err, forwarder.ErrTransactionRejected) {
+			return fmt.Errorf("%w: %s", errSpooledPayloadRejected, err)
+		}
+		return err
+	})
+	if sent > 0 {
+		log.Infof("Replayed %d spooled payloads, %d remaining", sent, batcher.spool.Len())
 	}
+	if err != nil {
+		log.Debugf("Intake still unavailable, %d payloads remain spooled: %s", batcher.spool.Len(), err)
+	}
+	return err
 }
 
 func (batcher *AsynchronousBatcher) run() {
+	var retry <-chan time.Time
+	if batcher.spool != nil {
+		ticker := time.NewTicker(spoolRetryIntervalFromConfig())
+		defer ticker.Stop()
+		retry = ticker.C
+		_ = batcher.replaySpool()
+	}
+
+	// Checks may configure a flush interval at any time, so expired data is looked for periodically
+	expiry := time.NewTicker(flushExpiryCheckInterval)
+	defer expiry.Stop()
+
 	for {
-		s := <-batcher.input
+		var s interface{}
+		select {
+		case s = <-batcher.input:
+		case <-retry:
+			_ = batcher.replaySpool()
+			continue
+		case <-expiry.C:
+			batcher.sendState(batcher.builder.FlushIfExpired())
+			batcher.health.RemoveExpired()
+			if batcher.validator != nil {
+				batcher.validator.RemoveExpired()
+			}
+			continue
+		}
+
 		switch submission := s.(type) {
 		case submitComponent:
-			batcher.sendState(batcher.builder.AddComponent(submission.checkID, submission.instance, submission.component))
+			batcher.addComponent(submission.checkID, submission.instance, submission.component)
 		case submitRelation:
-			batcher.sendState(batcher.builder.AddRelation(submission.checkID, submission.instance, submission.relation))
+			if batcher.validator == nil || batcher.validator.ValidateRelation(submission.checkID, submission.instance, submission.relation) {
+				batcher.addRelation(submission.checkID, submission.instance, submission.relation)
+			}
 		case submitStartSnapshot:
-			batcher.sendState(batcher.builder.TopologyStartSnapshot(submission.checkID, submission.instance))
+			batcher.startSnapshot(submission.checkID, submission.instance)
 		case submitStopSnapshot:
-			batcher.sendState(batcher.builder.TopologyStopSnapshot(submission.checkID, submission.instance))
+			batcher.stopSnapshot(submission.checkID, submission.instance)
 
 		case submitHealthCheckData:
-			batcher.sendState(batcher.builder.AddHealthCheckData(submission.checkID, submission.stream, submission.data))
+			if batcher.health.CheckData(submission.checkID, submission.stream, submission.data) {
+				batcher.sendState(batcher.builder.AddHealthCheckData(submission.checkID, submission.stream, submission.data))
+			}
 		case submitHealthStartSnapshot:
+			batcher.health.StartSnapshot(submission.checkID, submission.stream)
 			batcher.sendState(batcher.builder.HealthStartSnapshot(submission.checkID, submission.stream, submission.intervalSeconds, submission.expirySeconds))
 		case submitHealthStopSnapshot:
+			batcher.health.StopSnapshot(submission.checkID, submission.stream)
+			batcher.recordHealthRollup(submission.checkID)
 			batcher.sendState(batcher.builder.HealthStopSnapshot(submission.checkID, submission.stream))
 
+		case submitCheckBatchConfig:
+			batcher.builder.SetCheckBatchConfig(submission.checkID, submission.config)
 		case submitComplete:
+			batcher.recordHealthRollup(submission.checkID)
+			batcher.sendState(batcher.builder.FlushIfDataProduced(submission.checkID))
+		case submitCheckStopped:
 			batcher.sendState(batcher.builder.FlushIfDataProduced(submission.checkID))
+			batcher.builder.RemoveCheck(submission.checkID)
+			flushInspector.remove(submission.checkID)
+			if batcher.validator != nil {
+				batcher.validator.RemoveCheck(submission.checkID)
+			}
 		case submitShutdown:
 			return
 		default:
@@ -203,6 +378,55 @@ func (batcher *AsynchronousBatcher) run() {
 	}
 }
 
+func (batcher *AsynchronousBatcher) recordHealthRollup(checkID check.ID) {
+	if rollup := batcher.health.Rollup(checkID); len(rollup) > 0 {
+		flushInspector.recordHealthRollup(checkID, rollup)
+	}
+}
+
+func (batcher *AsynchronousBatcher) addComponent(checkID check.ID, instance topology.Instance, component topology.Component) {
+	if batcher.validator != nil && !batcher.validator.ValidateComponent(checkID, instance, component) {
+		return
+	}
+	if batcher.deltas == nil || batcher.deltas.Component(checkID, instance, component) {
+		batcher.sendState(batcher.builder.AddComponent(checkID, instance, component))
+	}
+}
+
+// addRelation adds an already validated relation
+func (batcher *AsynchronousBatcher) addRelation(checkID check.ID, instance topology.Instance, relation topology.Relation) {
+	if batcher.deltas == nil || batcher.deltas.Relation(checkID, instance, relation) {
+		batcher.sendState(batcher.builder.AddRelation(checkID, instance, relation))
+	}
+}
+
+func (batcher *AsynchronousBatcher) startSnapshot(checkID check.ID, instance topology.Instance) {
+	if batcher.validator != nil {
+		batcher.validator.StartSnapshot(checkID, instance)
+	}
+	// A snapshot sent as delta does not start a snapshot downstream, otherwise the receiver would remove all
+	// unchanged elements
+	if batcher.deltas == nil || batcher.deltas.StartSnapshot(checkID, instance) {
+		batcher.sendState(batcher.builder.TopologyStartSnapshot(checkID, instance))
+	}
+}
+
+func (batcher *AsynchronousBatcher) stopSnapshot(checkID check.ID, instance topology.Instance) {
+	if batcher.validator != nil {
+		// Relations held back by the validator are only released at the end of the snapshot
+		for _, relation := range batcher.validator.StopSnapshot(checkID, instance) {
+			batcher.addRelation(checkID, instance, relation)
+		}
+	}
+	if batcher.deltas != nil {
+		if full, deleteIDs := batcher.deltas.StopSnapshot(checkID, instance); !full {
+			batcher.sendState(batcher.builder.TopologyDeltaStopSnapshot(checkID, instance, deleteIDs))
+			return
+		}
+	}
+	batcher.sendState(batcher.builder.TopologyStopSnapshot(checkID, instance))
+}
+
 // SubmitComponent submits a component to the batch
 func (batcher AsynchronousBatcher) SubmitComponent(checkID check.ID, instance topology.Instance, component topology.Component) {
 	batcher.input <- submitComponent{
@@ -265,6 +489,14 @@ func (batcher AsynchronousBatcher) SubmitHealthStopSnapshot(checkID check.ID, st
 	}
 }
 
+// SubmitCheckBatchConfig overrides the batching settings for a check
+func (batcher AsynchronousBatcher) SubmitCheckBatchConfig(checkID check.ID, config CheckBatchConfig) {
+	batcher.input <- submitCheckBatchConfig{
+		checkID: checkID,
+		config:  config,
+	}
+}
+
 // SubmitComplete signals completion of a check. May trigger a flush only if the check produced data
 func (batcher AsynchronousBatcher) SubmitComplete(checkID check.ID) {
 	log.Debugf("Submitting complete for check [%s]", checkID)
@@ -273,6 +505,14 @@ func (batcher AsynchronousBatcher) SubmitComplete(checkID check.ID) {
 	}
 }
 
+// SubmitCheckStopped signals that a check is unscheduled. Flushes the data of the check and forgets about the check
+func (batcher AsynchronousBatcher) SubmitCheckStopped(checkID check.ID) {
+	log.Debugf("Submitting stopped for check [%s]", checkID)
+	batcher.input <- submitCheckStopped{
+		checkID: checkID,
+	}
+}
+
 // Shutdown shuts down the batcher
 func (batcher AsynchronousBatcher) Shutdown() {
 	batcher.input <- submitShutdown{}
@@ -315,6 +315,34 @@ func TestBatcherStartSnapshot(t *testing.T) {
 	batcher.Shutdown()
 }
 
+func TestBatcherCheckStopped(t *testing.T) {
+	serializer := serializer2.NewAgentV1MockSerializer()
+	batcher := newAsynchronousBatcher(serializer, testHost, testAgent, 100)
+
+	batcher.SubmitCheckBatchConfig(testID, CheckBatchConfig{MaxCapacity: 10})
+	batcher.SubmitStartSnapshot(testID, testInstance)
+	batcher.SubmitCheckStopped(testID)
+
+	// The data of the stopped check is flushed
+	message := serializer.GetJSONToV1IntakeMessage()
+	assert.Equal(t, message,
+		map[string]interface{}{
+			"internalHostname": "myhost",
+			"topologies": []topology.Topology{
+				{
+					StartSnapshot: true,
+					StopSnapshot:  false,
+					Instance:      testInstance,
+					Components:    []topology.Component{},
+					Relations:     []topology.Relation{},
+				},
+			},
+			"health": []health.Health{},
+		})
+
+	batcher.Shutdown()
+}
+
 func TestBatcherRelation(t *testing.T) {
 	serializer := serializer2.NewAgentV1MockSerializer()
 	batcher := newAsynchronousBatcher(serializer, testHost, testAgent, 100)
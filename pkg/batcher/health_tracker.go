@@ -0,0 +1,236 @@
+package batcher
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/telemetry"
+)
+
+var tlmHealthDeduplicated = telemetry.NewCounter("batcher", "health_deduplicated_states",
+	nil, "Number of unchanged health check states that were not resent")
+
+// StreamHealth is the aggregated health of a health stream or sub stream
+type StreamHealth struct {
+	Urn        string       `json:"urn"`
+	SubStream  string       `json:"sub_stream,omitempty"`
+	Health     health.State `json:"health"`
+	StateCount int          `json:"state_count"`
+}
+
+// healthExpiryCheckInterval is how often the tracker looks for expired check states
+const healthExpiryCheckInterval = time.Minute
+
+type sentCheckState struct {
+	hash uint64
+	sent time.Time
+}
+
+type trackedHealth struct {
+	state health.State
+	seen  time.Time
+}
+
+// healthStreamState holds what the tracker knows of one health stream of a check
+type healthStreamState struct {
+	stream health.Stream
+	// inSnapshot is true between a start and a stop snapshot
+	inSnapshot bool
+	// sent holds the check states sent in the current snapshot, or within the deduplication window outside snapshots
+	sent map[string]sentCheckState
+	// health holds the health per check state id of the last complete snapshot, or of all states outside snapshots
+	health map[string]trackedHealth
+	// pending holds the health per check state id of the snapshot in progress
+	pending map[string]trackedHealth
+}
+
+// HealthStateTracker understands the standard fields of health check data. It deduplicates check states that are
+// resubmitted unchanged within a snapshot, or within a time window for streams without snapshots, and keeps the
+// aggregated health per stream and sub stream. Check states that are not resubmitted within the state expiry are
+// forgotten. This data structure is not thread safe
+type HealthStateTracker struct {
+	deduplicate bool
+	window      time.Duration
+	stateExpiry time.Duration
+	streams     map[check.ID]map[string]*healthStreamState
+	lastExpiry  time.Time
+	now         func() time.Time
+}
+
+// NewHealthStateTracker constructs a HealthStateTracker. The window only applies to streams without snapshots, 0
+// disables deduplication outside snapshots
+func NewHealthStateTracker(deduplicate bool, window, stateExpiry time.Duration) *HealthStateTracker {
+	return &HealthStateTracker{
+		deduplicate: deduplicate,
+		window:      window,
+		stateExpiry: stateExpiry,
+		streams:     make(map[check.ID]map[string]*healthStreamState),
+		now:         time.Now,
+	}
+}
+
+func newHealthStateTrackerFromConfig() *HealthStateTracker {
+	return NewHealthStateTracker(config.Datadog.GetBool("batcher_health.deduplicate"),
+		time.Duration(config.Datadog.GetInt64("batcher_health.deduplicate_window"))*time.Second,
+		time.Duration(config.Datadog.GetInt64("batcher_health.state_expiry"))*time.Second)
+}
+
+func (tracker *HealthStateTracker) getOrCreateStream(checkID check.ID, stream health.Stream) *healthStreamState {
+	streams, ok := tracker.streams[checkID]
+	if !ok {
+		streams = make(map[string]*healthStreamState)
+		tracker.streams[checkID] = streams
+	}
+
+	state, ok := streams[stream.GoString()]
+	if !ok {
+		state = &healthStreamState{
+			stream:  stream,
+			sent:    make(map[string]sentCheckState),
+			health:  make(map[string]trackedHealth),
+			pending: make(map[string]trackedHealth),
+		}
+		streams[stream.GoString()] = state
+	}
+	return state
+}
+
+// StartSnapshot registers the start of a health snapshot
+func (tracker *HealthStateTracker) StartSnapshot(checkID check.ID, stream health.Stream) {
+	state := tracker.getOrCreateStream(checkID, stream)
+	state.inSnapshot = true
+	state.sent = make(map[string]sentCheckState)
+	state.pending = make(map[string]trackedHealth)
+}
+
+// CheckData registers health check data. Returns whether the data has to be sent
+func (tracker *HealthStateTracker) CheckData(checkID check.ID, stream health.Stream, data health.CheckData) bool {
+	state := tracker.getOrCreateStream(checkID, stream)
+	checkState := data.CheckState()
+
+	if checkState.CheckStateID == "" {
+		// Without an id states can't be told apart, so they are always sent
+		return true
+	}
+
+	now := tracker.now()
+	tracked := trackedHealth{state: checkState.Health, seen: now}
+	if state.inSnapshot {
+		state.pending[checkState.CheckStateID] = tracked
+	} else {
+		state.health[checkState.CheckStateID] = tracked
+	}
+
+	if !tracker.deduplicate {
+		return true
+	}
+
+	hash := hashElement(data)
+	if previous, ok := state.sent[checkState.CheckStateID]; ok && previous.hash == hash && hash != 0 {
+		if state.inSnapshot || now.Sub(previous.sent) < tracker.window {
+			tlmHealthDeduplicated.Inc()
+			return false
+		}
+	}
+	if state.inSnapshot || tracker.window > 0 {
+		state.sent[checkState.CheckStateID] = sentCheckState{hash: hash, sent: now}
+	}
+	return true
+}
+
+// StopSnapshot registers the end of a health snapshot, the snapshot then determines the health of the stream
+func (tracker *HealthStateTracker) StopSnapshot(checkID check.ID, stream health.Stream) {
+	state := tracker.getOrCreateStream(checkID, stream)
+	if !state.inSnapshot {
+		return
+	}
+	state.inSnapshot = false
+	state.health = state.pending
+	state.pending = make(map[string]trackedHealth)
+	state.sent = make(map[string]sentCheckState)
+}
+
+// Rollup returns the aggregated health of the streams of a check. For every urn there is an entry without sub stream
+// aggregating all its sub streams, followed by an entry per sub stream. The result is sorted by urn and sub stream
+func (tracker *HealthStateTracker) Rollup(checkID check.ID) []StreamHealth {
+	substreams := make(map[string]StreamHealth)
+	streams := make(map[string]StreamHealth)
+
+	for _, state := range tracker.streams[checkID] {
+		worst := health.Unknown
+		for _, stateHealth := range state.health {
+			worst = health.Worst(worst, stateHealth.state)
+		}
+
+		if state.stream.SubStream != "" {
+			substreams[fmt.Sprintf("%s/%s", state.stream.Urn, state.stream.SubStream)] = StreamHealth{
+				Urn:        state.stream.Urn,
+				SubStream:  state.stream.SubStream,
+				Health:     worst,
+				StateCount: len(state.health),
+			}
+		}
+
+		aggregate, ok := streams[state.stream.Urn]
+		if !ok {
+			aggregate = StreamHealth{Urn: state.stream.Urn, Health: health.Unknown}
+		}
+		aggregate.Health = health.Worst(aggregate.Health, worst)
+		aggregate.StateCount += len(state.health)
+		streams[state.stream.Urn] = aggregate
+	}
+
+	rollup := make([]StreamHealth, 0, len(streams)+len(substreams))
+	for _, aggregate := range streams {
+		rollup = append(rollup, aggregate)
+	}
+	for _, aggregate := range substreams {
+		rollup = append(rollup, aggregate)
+	}
+	sort.Slice(rollup, func(i, j int) bool {
+		if rollup[i].Urn != rollup[j].Urn {
+			return rollup[i].Urn < rollup[j].Urn
+		}
+		return rollup[i].SubStream < rollup[j].SubStream
+	})
+	return rollup
+}
+
+// RemoveExpired forgets the check states that were not resubmitted within the state expiry, and the deduplication
+// state that is outside the deduplication window. Streams without any state left are removed. The tracker only looks
+// for expired states once per healthExpiryCheckInterval.
+func (tracker *HealthStateTracker) RemoveExpired() {
+	now := tracker.now()
+	if now.Sub(tracker.lastExpiry) < healthExpiryCheckInterval {
+		return
+	}
+	tracker.lastExpiry = now
+
+	for checkID, streams := range tracker.streams {
+		for key, state := range streams {
+			if state.inSnapshot {
+				continue
+			}
+			for id, tracked := range state.health {
+				if tracker.stateExpiry > 0 && now.Sub(tracked.seen) >= tracker.stateExpiry {
+					delete(state.health, id)
+				}
+			}
+			for id, sent := range state.sent {
+				if now.Sub(sent.sent) >= tracker.window {
+					delete(state.sent, id)
+				}
+			}
+			if len(state.health) == 0 && len(state.sent) == 0 {
+				delete(streams, key)
+			}
+		}
+		if len(streams) == 0 {
+			delete(tracker.streams, checkID)
+		}
+	}
+}
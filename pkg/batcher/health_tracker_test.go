@@ -0,0 +1,154 @@
+package batcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	serializer2 "github.com/StackVista/stackstate-agent/pkg/serializer"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkState(id string, state health.State) health.CheckData {
+	return health.CheckState{
+		CheckStateID:              id,
+		Name:                      id,
+		Health:                    state,
+		TopologyElementIdentifier: "urn:" + id,
+	}.CheckData()
+}
+
+func TestHealthTrackerDeduplicatesWithinSnapshot(t *testing.T) {
+	tracker := NewHealthStateTracker(true, 0, time.Hour)
+
+	tracker.StartSnapshot(testID, testStream)
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+	assert.False(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Critical)))
+	// Other streams are tracked separately
+	assert.True(t, tracker.CheckData(testID, testStream2, checkState("a", health.Clear)))
+	tracker.StopSnapshot(testID, testStream)
+
+	// Every snapshot has to repeat its states
+	tracker.StartSnapshot(testID, testStream)
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Critical)))
+
+	// States without id are always sent
+	assert.True(t, tracker.CheckData(testID, testStream, testCheckData))
+	assert.True(t, tracker.CheckData(testID, testStream, testCheckData))
+}
+
+func TestHealthTrackerDeduplicatesWithinWindow(t *testing.T) {
+	now := time.Now()
+	tracker := NewHealthStateTracker(true, time.Minute, time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+	now = now.Add(15 * time.Second)
+	assert.False(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+	now = now.Add(time.Minute)
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+
+	// Without a window states outside snapshots are always sent
+	tracker = NewHealthStateTracker(true, 0, time.Hour)
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+}
+
+func TestHealthTrackerDeduplicatesByDefault(t *testing.T) {
+	config.Mock()
+	tracker := newHealthStateTrackerFromConfig()
+
+	assert.True(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+	assert.False(t, tracker.CheckData(testID, testStream, checkState("a", health.Clear)))
+}
+
+func TestHealthTrackerRollup(t *testing.T) {
+	tracker := NewHealthStateTracker(false, 0, time.Hour)
+	substream := health.Stream{Urn: "urn", SubStream: "other"}
+
+	tracker.StartSnapshot(testID, testStream)
+	tracker.CheckData(testID, testStream, checkState("a", health.Clear))
+	tracker.CheckData(testID, testStream, checkState("b", health.Deviating))
+	// The rollup only reflects complete snapshots
+	assert.Equal(t, []StreamHealth{
+		{Urn: "urn", Health: health.Unknown},
+		{Urn: "urn", SubStream: "bla", Health: health.Unknown},
+	}, tracker.Rollup(testID))
+	tracker.StopSnapshot(testID, testStream)
+
+	tracker.CheckData(testID, substream, checkState("c", health.Critical))
+	tracker.CheckData(testID, substream, checkState("c", health.Clear))
+
+	assert.Equal(t, []StreamHealth{
+		{Urn: "urn", Health: health.Deviating, StateCount: 3},
+		{Urn: "urn", SubStream: "bla", Health: health.Deviating, StateCount: 2},
+		{Urn: "urn", SubStream: "other", Health: health.Clear, StateCount: 1},
+	}, tracker.Rollup(testID))
+	assert.Empty(t, tracker.Rollup(testID2))
+}
+
+func TestHealthTrackerRemovesExpiredStates(t *testing.T) {
+	now := time.Now()
+	tracker := NewHealthStateTracker(true, time.Minute, time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	tracker.CheckData(testID, testStream, checkState("a", health.Clear))
+	tracker.CheckData(testID, testStream, checkState("b", health.Critical))
+	tracker.StartSnapshot(testID2, testStream)
+	tracker.CheckData(testID2, testStream, checkState("c", health.Clear))
+
+	now = now.Add(30 * time.Minute)
+	tracker.CheckData(testID, testStream, checkState("b", health.Critical))
+	tracker.RemoveExpired()
+	assert.Len(t, tracker.streams[testID][testStream.GoString()].health, 2)
+	// the deduplication state is only kept within the window
+	assert.Len(t, tracker.streams[testID][testStream.GoString()].sent, 1)
+
+	now = now.Add(45 * time.Minute)
+	tracker.RemoveExpired()
+	assert.Equal(t, []StreamHealth{
+		{Urn: "urn", Health: health.Critical, StateCount: 1},
+		{Urn: "urn", SubStream: "bla", Health: health.Critical, StateCount: 1},
+	}, tracker.Rollup(testID))
+	// streams in a snapshot are not expired
+	assert.Len(t, tracker.streams[testID2], 1)
+
+	now = now.Add(time.Hour)
+	tracker.RemoveExpired()
+	assert.Empty(t, tracker.streams[testID])
+}
+
+func TestBatcherDeduplicatesHealth(t *testing.T) {
+	serializer := serializer2.NewAgentV1MockSerializer()
+	batcher := newAsynchronousBatcher(serializer, testHost, testAgent, 100)
+	start := time.Now()
+
+	batcher.SubmitHealthStartSnapshot(testID, testStream, 1, 0)
+	batcher.SubmitHealthCheckData(testID, testStream, checkState("a", health.Critical))
+	batcher.SubmitHealthCheckData(testID, testStream, checkState("a", health.Critical))
+	batcher.SubmitHealthStopSnapshot(testID, testStream)
+
+	assert.Equal(t, map[string]interface{}{
+		"internalHostname": "myhost",
+		"topologies":       []topology.Topology{},
+		"health": []health.Health{
+			{
+				StartSnapshot: &testStartSnapshot,
+				StopSnapshot:  &testStopSnapshot,
+				Stream:        testStream,
+				CheckStates:   []health.CheckData{checkState("a", health.Critical)},
+			},
+		},
+	}, serializer.GetJSONToV1IntakeMessage())
+	batcher.Shutdown()
+
+	statuses := GetFlushInspector().Statuses(start)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, []StreamHealth{
+		{Urn: "urn", Health: health.Critical, StateCount: 1},
+		{Urn: "urn", SubStream: "bla", Health: health.Critical, StateCount: 1},
+	}, statuses[0].HealthRollup)
+}
@@ -0,0 +1,125 @@
+package batcher
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+)
+
+var flushInspector = newFlushInspector()
+
+// CheckFlushStatus describes the last data flushed by the batcher for a check
+type CheckFlushStatus struct {
+	CheckID          check.ID           `json:"check_id"`
+	LastFlush        time.Time          `json:"last_flush"`
+	FlushCount       int                `json:"flush_count"`
+	ComponentCount   int                `json:"component_count"`
+	RelationCount    int                `json:"relation_count"`
+	DeleteCount      int                `json:"delete_count"`
+	CheckStateCount  int                `json:"check_state_count"`
+	Topology         *topology.Topology `json:"topology,omitempty"`
+	Health           []health.Health    `json:"health"`
+	HealthRollup     []StreamHealth     `json:"health_rollup,omitempty"`
+	LastError        string             `json:"last_error,omitempty"`
+	LastErrorTime    *time.Time         `json:"last_error_time,omitempty"`
+	LastFlushSuccess bool               `json:"last_flush_success"`
+}
+
+// FlushInspector keeps the last flushed batch per check, so it can be inspected through the agent API. This data
+// structure is thread safe
+type FlushInspector struct {
+	sync.RWMutex
+	checks map[check.ID]CheckFlushStatus
+}
+
+func newFlushInspector() *FlushInspector {
+	return &FlushInspector{
+		checks: make(map[check.ID]CheckFlushStatus),
+	}
+}
+
+// GetFlushInspector returns the inspector of the global batcher
+func GetFlushInspector() *FlushInspector {
+	return flushInspector
+}
+
+// record registers the states flushed at the given time, together with the outcome of sending them
+func (inspector *FlushInspector) record(states CheckInstanceBatchStates, flushTime time.Time, err error) {
+	inspector.Lock()
+	defer inspector.Unlock()
+
+	for checkID, state := range states {
+		status := inspector.checks[checkID]
+		status.CheckID = checkID
+		status.LastFlush = flushTime
+		status.FlushCount++
+		status.Topology = state.Topology
+		status.ComponentCount, status.RelationCount, status.DeleteCount = 0, 0, 0
+		if state.Topology != nil {
+			status.ComponentCount = len(state.Topology.Components)
+			status.RelationCount = len(state.Topology.Relations)
+			status.DeleteCount = len(state.Topology.DeleteIDs)
+		}
+
+		streams := make([]string, 0, len(state.Health))
+		for stream := range state.Health {
+			streams = append(streams, stream)
+		}
+		sort.Strings(streams)
+		status.Health = make([]health.Health, 0, len(streams))
+		status.CheckStateCount = 0
+		for _, stream := range streams {
+			status.Health = append(status.Health, state.Health[stream])
+			status.CheckStateCount += len(state.Health[stream].CheckStates)
+		}
+
+		status.LastFlushSuccess = err == nil
+		if err != nil {
+			errorTime := flushTime
+			status.LastError = err.Error()
+			status.LastErrorTime = &errorTime
+		}
+		inspector.checks[checkID] = status
+	}
+}
+
+// recordHealthRollup registers the aggregated health of the streams of a check
+func (inspector *FlushInspector) recordHealthRollup(checkID check.ID, rollup []StreamHealth) {
+	inspector.Lock()
+	defer inspector.Unlock()
+
+	status := inspector.checks[checkID]
+	status.CheckID = checkID
+	status.HealthRollup = rollup
+	inspector.checks[checkID] = status
+}
+
+// remove forgets the flush status of a check that is no longer scheduled
+func (inspector *FlushInspector) remove(checkID check.ID) {
+	inspector.Lock()
+	defer inspector.Unlock()
+
+	delete(inspector.checks, checkID)
+}
+
+// Statuses returns the flush status of every check that flushed after the given time, sorted by check id. Pass the
+// zero time to get all checks
+func (inspector *FlushInspector) Statuses(since time.Time) []CheckFlushStatus {
+	inspector.RLock()
+	defer inspector.RUnlock()
+
+	statuses := make([]CheckFlushStatus, 0, len(inspector.checks))
+	for _, status := range inspector.checks {
+		if status.LastFlush.After(since) {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CheckID < statuses[j].CheckID
+	})
+	return statuses
+}
@@ -0,0 +1,88 @@
+package batcher
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	serializer2 "github.com/StackVista/stackstate-agent/pkg/serializer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlushInspectorRecordsLastFlush(t *testing.T) {
+	inspector := newFlushInspector()
+	builder := NewBatchBuilder(100)
+	start := time.Now()
+
+	builder.AddComponent(testID, testInstance, testComponent)
+	builder.AddRelation(testID, testInstance, testRelation)
+	builder.AddHealthCheckData(testID2, testStream, testCheckData)
+	builder.AddHealthCheckData(testID2, testStream2, testCheckData)
+	inspector.record(builder.Flush(), start, nil)
+
+	statuses := inspector.Statuses(time.Time{})
+	assert.Len(t, statuses, 2)
+
+	assert.Equal(t, testID, statuses[0].CheckID)
+	assert.Equal(t, 1, statuses[0].FlushCount)
+	assert.Equal(t, 1, statuses[0].ComponentCount)
+	assert.Equal(t, 1, statuses[0].RelationCount)
+	assert.Equal(t, 0, statuses[0].CheckStateCount)
+	assert.True(t, statuses[0].LastFlushSuccess)
+	assert.Nil(t, statuses[0].LastErrorTime)
+
+	assert.Equal(t, testID2, statuses[1].CheckID)
+	assert.Nil(t, statuses[1].Topology)
+	assert.Equal(t, 2, statuses[1].CheckStateCount)
+	assert.Equal(t, []health.Health{
+		{Stream: testStream, CheckStates: []health.CheckData{testCheckData}},
+		{Stream: testStream2, CheckStates: []health.CheckData{testCheckData}},
+	}, statuses[1].Health)
+
+	// A failed flush keeps the error, even after a later successful flush
+	failure := start.Add(time.Second)
+	builder.AddComponent(testID, testInstance, testComponent)
+	inspector.record(builder.Flush(), failure, errors.New("intake unavailable"))
+	builder.AddComponent(testID, testInstance, testComponent)
+	inspector.record(builder.Flush(), failure.Add(time.Second), nil)
+
+	statuses = inspector.Statuses(start)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, 3, statuses[0].FlushCount)
+	assert.True(t, statuses[0].LastFlushSuccess)
+	assert.Equal(t, "intake unavailable", statuses[0].LastError)
+	assert.Equal(t, failure, *statuses[0].LastErrorTime)
+}
+
+func TestBatcherRecordsFlushes(t *testing.T) {
+	serializer := serializer2.NewAgentV1MockSerializer()
+	batcher := newAsynchronousBatcher(serializer, testHost, testAgent, 100)
+	start := time.Now()
+
+	batcher.SubmitComponent(testID, testInstance, testComponent)
+	batcher.SubmitStopSnapshot(testID, testInstance)
+	serializer.GetJSONToV1IntakeMessage()
+	batcher.Shutdown()
+
+	statuses := GetFlushInspector().Statuses(start)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, testID, statuses[0].CheckID)
+	assert.Equal(t, 1, statuses[0].ComponentCount)
+	assert.True(t, statuses[0].Topology.StopSnapshot)
+}
+
+func TestBatcherForgetsFlushesOfStoppedChecks(t *testing.T) {
+	serializer := serializer2.NewAgentV1MockSerializer()
+	batcher := newAsynchronousBatcher(serializer, testHost, testAgent, 100)
+	start := time.Now()
+
+	batcher.SubmitComponent(testID, testInstance, testComponent)
+	batcher.SubmitStopSnapshot(testID, testInstance)
+	serializer.GetJSONToV1IntakeMessage()
+	assert.Len(t, GetFlushInspector().Statuses(start), 1)
+
+	batcher.SubmitCheckStopped(testID)
+	batcher.Shutdown()
+	assert.Empty(t, GetFlushInspector().Statuses(start))
+}
@@ -52,10 +52,20 @@ func (batcher MockBatcher) SubmitHealthStopSnapshot(checkID check.ID, stream hea
 	batcher.CollectedTopology.HealthStopSnapshot(checkID, stream)
 }
 
+// SubmitCheckBatchConfig mock
+func (batcher MockBatcher) SubmitCheckBatchConfig(checkID check.ID, config CheckBatchConfig) {
+	batcher.CollectedTopology.SetCheckBatchConfig(checkID, config)
+}
+
 // SubmitComplete mock
 func (batcher MockBatcher) SubmitComplete(checkID check.ID) {
 
 }
 
+// SubmitCheckStopped mock
+func (batcher MockBatcher) SubmitCheckStopped(checkID check.ID) {
+	batcher.CollectedTopology.RemoveCheck(checkID)
+}
+
 // Shutdown mock
 func (batcher MockBatcher) Shutdown() {}
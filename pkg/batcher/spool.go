@@ -0,0 +1,251 @@
+package batcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/telemetry"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+)
+
+const (
+	spoolDirName    = "batcher_spool"
+	spoolFileSuffix = ".json"
+
+	dropReasonOverflow = "overflow"
+	dropReasonExpired  = "expired"
+	dropReasonCorrupt  = "corrupt"
+	dropReasonRejected = "rejected"
+
+	defaultSpoolRetryInterval = 15 * time.Second
+)
+
+// errSpooledPayloadRejected is wrapped by the errors of sending a spooled payload that the intake will never accept,
+// the payload is dropped instead of blocking the spool
+var errSpooledPayloadRejected = errors.New("the spooled payload was rejected")
+
+var (
+	tlmSpoolDepth = telemetry.NewGauge("batcher", "spool_depth",
+		nil, "Number of payloads waiting in the batcher spool")
+	tlmSpoolBytes = telemetry.NewGauge("batcher", "spool_bytes",
+		nil, "Amount of bytes waiting in the batcher spool")
+	tlmSpoolDrops = telemetry.NewCounter("batcher", "spool_drops",
+		[]string{"reason"}, "Number of payloads dropped from the batcher spool")
+	tlmSpoolReplays = telemetry.NewCounter("batcher", "spool_replays",
+		nil, "Number of spooled payloads successfully sent to the intake")
+)
+
+type spoolEntry struct {
+	sequence uint64
+	path     string
+	size     int64
+	created  time.Time
+}
+
+// Spool persists payloads that could not be sent to the intake, so they can be replayed in order once the intake
+// is reachable again. The spool is bounded in both size and age, the oldest payloads are dropped first. This data
+// structure is not thread safe
+type Spool struct {
+	dir          string
+	maxSizeBytes int64
+	maxAge       time.Duration
+	entries      []spoolEntry
+	totalBytes   int64
+	nextSequence uint64
+}
+
+// NewSpool constructs a Spool writing to dir, loading any payloads left behind by a previous run
+func NewSpool(dir string, maxSizeBytes int64, maxAge time.Duration) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create batcher spool directory %s: %s", dir, err)
+	}
+
+	spool := &Spool{
+		dir:          dir,
+		maxSizeBytes: maxSizeBytes,
+		maxAge:       maxAge,
+		entries:      make([]spoolEntry, 0),
+	}
+
+	if err := spool.load(); err != nil {
+		return nil, err
+	}
+	spool.evict(time.Now())
+	return spool, nil
+}
+
+// newSpoolFromConfig creates the spool configured under `batcher_spool`, returns nil if spooling is disabled. Every
+// agent process has its own spool directory, named after the agent
+func newSpoolFromConfig(agentName string) *Spool {
+	if !config.Datadog.GetBool("batcher_spool.enabled") {
+		return nil
+	}
+
+	dir := filepath.Join(config.Datadog.GetString("run_path"), spoolDirName, agentName)
+	maxSize := config.Datadog.GetInt64("batcher_spool.max_size_mb") * 1024 * 1024
+	maxAge := time.Duration(config.Datadog.GetInt64("batcher_spool.max_age")) * time.Second
+
+	spool, err := NewSpool(dir, maxSize, maxAge)
+	if err != nil {
+		_ = log.Errorf("Unable to create the batcher spool, unsent payloads will be dropped: %s", err)
+		return nil
+	}
+	log.Infof("Batcher spool enabled in %s (max %d bytes, max age %s), %d payloads pending", dir, maxSize, maxAge, spool.Len())
+	return spool
+}
+
+// spoolRetryIntervalFromConfig returns the interval configured under `batcher_spool.retry_interval`, invalid intervals
+// fall back to the default
+func spoolRetryIntervalFromConfig() time.Duration {
+	interval := time.Duration(config.Datadog.GetInt64("batcher_spool.retry_interval")) * time.Second
+	if interval <= 0 {
+		log.Warnf("Invalid batcher_spool.retry_interval %s, using the default of %s", interval, defaultSpoolRetryInterval)
+		return defaultSpoolRetryInterval
+	}
+	return interval
+}
+
+func (spool *Spool) load() error {
+	files, err := ioutil.ReadDir(spool.dir)
+	if err != nil {
+		return fmt.Errorf("could not read batcher spool directory %s: %s", spool.dir, err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), spoolFileSuffix) {
+			continue
+		}
+		sequence, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), spoolFileSuffix), 10, 64)
+		if err != nil {
+			log.Warnf("Ignoring unknown file %s in the batcher spool", file.Name())
+			continue
+		}
+		spool.entries = append(spool.entries, spoolEntry{
+			sequence: sequence,
+			path:     filepath.Join(spool.dir, file.Name()),
+			size:     file.Size(),
+			created:  file.ModTime(),
+		})
+		spool.totalBytes += file.Size()
+		if sequence >= spool.nextSequence {
+			spool.nextSequence = sequence + 1
+		}
+	}
+
+	sort.Slice(spool.entries, func(i, j int) bool {
+		return spool.entries[i].sequence < spool.entries[j].sequence
+	})
+	spool.updateTelemetry()
+	return nil
+}
+
+// Len returns the amount of payloads in the spool
+func (spool *Spool) Len() int {
+	return len(spool.entries)
+}
+
+// Push persists a payload at the end of the spool
+func (spool *Spool) Push(payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not serialize payload for the batcher spool: %s", err)
+	}
+
+	now := time.Now()
+	entry := spoolEntry{
+		sequence: spool.nextSequence,
+		path:     filepath.Join(spool.dir, fmt.Sprintf("%020d%s", spool.nextSequence, spoolFileSuffix)),
+		size:     int64(len(data)),
+		created:  now,
+	}
+	if err := ioutil.WriteFile(entry.path, data, 0600); err != nil {
+		return fmt.Errorf("could not write payload to the batcher spool: %s", err)
+	}
+
+	spool.nextSequence++
+	spool.entries = append(spool.entries, entry)
+	spool.totalBytes += entry.size
+	spool.evict(now)
+	spool.updateTelemetry()
+	return nil
+}
+
+// Replay sends the spooled payloads oldest first, stopping at the first failure. Payloads that were sent are
+// removed from the spool, as are the payloads whose send error wraps errSpooledPayloadRejected. Returns the amount of
+// payloads sent
+func (spool *Spool) Replay(send func(payload json.RawMessage) error) (int, error) {
+	spool.evict(time.Now())
+
+	sent := 0
+	for len(spool.entries) > 0 {
+		entry := spool.entries[0]
+		data, err := ioutil.ReadFile(entry.path)
+		if err != nil {
+			_ = log.Errorf("Could not read spooled payload %s, dropping it: %s", entry.path, err)
+			spool.removeOldest(dropReasonCorrupt)
+			continue
+		}
+
+		if err := send(json.RawMessage(data)); errors.Is(err, errSpooledPayloadRejected) {
+			_ = log.Errorf("Dropping spooled payload %s: %s", entry.path, err)
+			spool.removeOldest(dropReasonRejected)
+			continue
+		} else if err != nil {
+			spool.updateTelemetry()
+			return sent, err
+		}
+
+		spool.removeOldest("")
+		tlmSpoolReplays.Inc()
+		sent++
+	}
+
+	spool.updateTelemetry()
+	return sent, nil
+}
+
+// evict drops the oldest payloads until the spool is within its age and size bounds
+func (spool *Spool) evict(now time.Time) {
+	for len(spool.entries) > 0 {
+		oldest := spool.entries[0]
+		if spool.maxAge > 0 && now.Sub(oldest.created) > spool.maxAge {
+			log.Warnf("Dropping spooled payload %s, it is older than %s", oldest.path, spool.maxAge)
+			spool.removeOldest(dropReasonExpired)
+			continue
+		}
+		if spool.maxSizeBytes > 0 && spool.totalBytes > spool.maxSizeBytes {
+			log.Warnf("Dropping spooled payload %s, the spool exceeds %d bytes", oldest.path, spool.maxSizeBytes)
+			spool.removeOldest(dropReasonOverflow)
+			continue
+		}
+		break
+	}
+}
+
+// removeOldest removes the oldest payload, counting it as a drop if a reason is given
+func (spool *Spool) removeOldest(dropReason string) {
+	entry := spool.entries[0]
+	if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not remove spooled payload %s: %s", entry.path, err)
+	}
+	spool.entries = spool.entries[1:]
+	spool.totalBytes -= entry.size
+
+	if dropReason != "" {
+		tlmSpoolDrops.Inc(dropReason)
+	}
+}
+
+func (spool *Spool) updateTelemetry() {
+	tlmSpoolDepth.Set(float64(len(spool.entries)))
+	tlmSpoolBytes.Set(float64(spool.totalBytes))
+}
@@ -0,0 +1,310 @@
+package batcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/forwarder"
+	"github.com/StackVista/stackstate-agent/pkg/serializer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSerializer confirms the delivery of payloads to an intake that can be unavailable or reject the payloads
+type failingSerializer struct {
+	failing bool
+	// rejecting is the amount of payloads that the intake rejects next
+	rejecting int
+	received  []json.RawMessage
+}
+
+// SendJSONToV1Intake does not wait for the delivery, like the forwarder it does not report delivery failures
+func (s *failingSerializer) SendJSONToV1Intake(data interface{}) error {
+	_ = s.SendJSONToV1IntakeAndWait(data)
+	return nil
+}
+
+func (s *failingSerializer) SendJSONToV1IntakeAndWait(data interface{}) error {
+	if s.failing {
+		return errors.New("intake unavailable")
+	}
+	if s.rejecting > 0 {
+		s.rejecting--
+		return fmt.Errorf("%w: intake responded with status code 400", forwarder.ErrTransactionRejected)
+	}
+	payload, _ := json.Marshal(data)
+	s.received = append(s.received, payload)
+	return nil
+}
+
+// fireAndForgetSerializer can not confirm the delivery of payloads
+type fireAndForgetSerializer struct{}
+
+func (fireAndForgetSerializer) SendJSONToV1Intake(data interface{}) error {
+	return nil
+}
+
+func newTestSpool(t *testing.T, maxSize int64, maxAge time.Duration) (*Spool, string) {
+	dir, err := ioutil.TempDir("", "batcher-spool-")
+	require.NoError(t, err)
+	spool, err := NewSpool(dir, maxSize, maxAge)
+	require.NoError(t, err)
+	return spool, dir
+}
+
+func replayAll(t *testing.T, spool *Spool) []string {
+	result := make([]string, 0)
+	_, err := spool.Replay(func(payload json.RawMessage) error {
+		var value string
+		require.NoError(t, json.Unmarshal(payload, &value))
+		result = append(result, value)
+		return nil
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestSpoolReplayInOrder(t *testing.T) {
+	spool, dir := newTestSpool(t, 0, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, spool.Push("first"))
+	require.NoError(t, spool.Push("second"))
+	require.NoError(t, spool.Push("third"))
+	assert.Equal(t, 3, spool.Len())
+
+	// A failing intake stops the replay and keeps the payloads
+	sent, err := spool.Replay(func(payload json.RawMessage) error {
+		return errors.New("intake unavailable")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Equal(t, 3, spool.Len())
+
+	assert.Equal(t, []string{"first", "second", "third"}, replayAll(t, spool))
+	assert.Equal(t, 0, spool.Len())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpoolSurvivesRestart(t *testing.T) {
+	spool, dir := newTestSpool(t, 0, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, spool.Push("first"))
+	require.NoError(t, spool.Push("second"))
+
+	reloaded, err := NewSpool(dir, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reloaded.Len())
+	require.NoError(t, reloaded.Push("third"))
+
+	assert.Equal(t, []string{"first", "second", "third"}, replayAll(t, reloaded))
+}
+
+func TestSpoolDropsOldestWhenFull(t *testing.T) {
+	// every payload is 7 bytes: a quoted 5 character string
+	spool, dir := newTestSpool(t, 15, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, spool.Push("aaaaa"))
+	require.NoError(t, spool.Push("bbbbb"))
+	require.NoError(t, spool.Push("ccccc"))
+
+	assert.Equal(t, 2, spool.Len())
+	assert.Equal(t, []string{"bbbbb", "ccccc"}, replayAll(t, spool))
+}
+
+func TestSpoolDropsExpiredPayloads(t *testing.T) {
+	spool, dir := newTestSpool(t, 0, time.Minute)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, spool.Push("old"))
+	spool.entries[0].created = time.Now().Add(-2 * time.Minute)
+	require.NoError(t, spool.Push("new"))
+
+	assert.Equal(t, []string{"new"}, replayAll(t, spool))
+}
+
+func TestBatcherSpoolsUnsentPayloads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batcher-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", dir)
+	mockConfig.Set("batcher_spool.enabled", true)
+	defer mockConfig.Set("batcher_spool.enabled", false)
+
+	serializer := &failingSerializer{failing: true}
+	batcher := AsynchronousBatcher{
+		builder:    NewBatchBuilder(100),
+		hostname:   testHost,
+		agentName:  testAgent,
+		serializer: serializer,
+	}
+	batcher.delivery, batcher.spool = newDeliveryFromConfig(serializer, testAgent, false)
+	require.NotNil(t, batcher.delivery)
+	require.NotNil(t, batcher.spool)
+	assert.Equal(t, filepath.Join(dir, spoolDirName, testAgent), batcher.spool.dir)
+
+	batcher.sendState(batcher.builder.TopologyStopSnapshot(testID, testInstance))
+	batcher.sendState(batcher.builder.HealthStopSnapshot(testID, testStream))
+	assert.Equal(t, 2, batcher.spool.Len())
+	assert.Empty(t, serializer.received)
+
+	serializer.failing = false
+	assert.NoError(t, batcher.replaySpool())
+	assert.Equal(t, 0, batcher.spool.Len())
+	require.Len(t, serializer.received, 2)
+
+	var first, second map[string]interface{}
+	require.NoError(t, json.Unmarshal(serializer.received[0], &first))
+	require.NoError(t, json.Unmarshal(serializer.received[1], &second))
+	assert.Len(t, first["topologies"], 1)
+	assert.Len(t, first["health"], 0)
+	assert.Len(t, second["topologies"], 0)
+	assert.Len(t, second["health"], 1)
+}
+
+func TestSpoolRetryIntervalFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("batcher_spool.retry_interval", 15)
+
+	mockConfig.Set("batcher_spool.retry_interval", 30)
+	assert.Equal(t, 30*time.Second, spoolRetryIntervalFromConfig())
+
+	for _, invalid := range []int{0, -1} {
+		mockConfig.Set("batcher_spool.retry_interval", invalid)
+		assert.Equal(t, defaultSpoolRetryInterval, spoolRetryIntervalFromConfig())
+	}
+}
+
+func TestBatcherDropsRejectedPayloads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batcher-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", dir)
+	mockConfig.Set("batcher_spool.enabled", true)
+	defer mockConfig.Set("batcher_spool.enabled", false)
+
+	serializer := &failingSerializer{rejecting: 1}
+	batcher := AsynchronousBatcher{
+		builder:    NewBatchBuilder(100),
+		hostname:   testHost,
+		agentName:  testAgent,
+		serializer: serializer,
+	}
+	batcher.delivery, batcher.spool = newDeliveryFromConfig(serializer, testAgent, false)
+
+	// The intake would reject the payload again, so it is not spooled
+	batcher.sendState(batcher.builder.TopologyStopSnapshot(testID, testInstance))
+	assert.Equal(t, 0, batcher.spool.Len())
+
+	// A spooled payload that is rejected on replay does not block the payloads behind it
+	serializer.failing = true
+	batcher.sendState(batcher.builder.TopologyStopSnapshot(testID, testInstance))
+	batcher.sendState(batcher.builder.HealthStopSnapshot(testID, testStream))
+	assert.Equal(t, 2, batcher.spool.Len())
+
+	serializer.failing = false
+	serializer.rejecting = 1
+	assert.NoError(t, batcher.replaySpool())
+	assert.Equal(t, 0, batcher.spool.Len())
+	require.Len(t, serializer.received, 1)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(serializer.received[0], &payload))
+	assert.Len(t, payload["health"], 1)
+}
+
+func TestNewDeliveryFromConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("batcher_spool.enabled", true)
+	defer mockConfig.Set("batcher_spool.enabled", false)
+	dir, err := ioutil.TempDir("", "batcher-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mockConfig.Set("run_path", dir)
+
+	// A serializer that can not confirm the delivery disables the spool
+	delivery, spool := newDeliveryFromConfig(fireAndForgetSerializer{}, testAgent, true)
+	assert.Nil(t, delivery)
+	assert.Nil(t, spool)
+
+	// Every agent process has its own spool
+	_, agentSpool := newDeliveryFromConfig(&failingSerializer{}, "agent", false)
+	_, processAgentSpool := newDeliveryFromConfig(&failingSerializer{}, "process-agent", false)
+	require.NotNil(t, agentSpool)
+	require.NotNil(t, processAgentSpool)
+	assert.NotEqual(t, agentSpool.dir, processAgentSpool.dir)
+
+	// The topology deltas need the delivery without the spool
+	mockConfig.Set("batcher_spool.enabled", false)
+	delivery, spool = newDeliveryFromConfig(&failingSerializer{}, testAgent, true)
+	assert.NotNil(t, delivery)
+	assert.Nil(t, spool)
+
+	delivery, spool = newDeliveryFromConfig(&failingSerializer{}, testAgent, false)
+	assert.Nil(t, delivery)
+	assert.Nil(t, spool)
+}
+
+// TestBatcherSpoolsPayloadsTheIntakeDidNotReceive sends the payloads through the forwarder to an intake that fails,
+// the forwarder does not report the failure to the caller of SendJSONToV1Intake
+func TestBatcherSpoolsPayloadsTheIntakeDidNotReceive(t *testing.T) {
+	var status, requests int32 = http.StatusServiceUnavailable, 0
+	intake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer intake.Close()
+
+	dir, err := ioutil.TempDir("", "batcher-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", dir)
+	mockConfig.Set("batcher_spool.enabled", true)
+	defer mockConfig.Set("batcher_spool.enabled", false)
+
+	options := forwarder.NewOptions(map[string][]string{intake.URL: {"api_key"}})
+	options.DisableAPIKeyChecking = true
+	fwd := forwarder.NewDefaultForwarder(options)
+	require.NoError(t, fwd.Start())
+	defer fwd.Stop()
+	s := serializer.NewSerializer(fwd)
+
+	batcher := AsynchronousBatcher{
+		builder:    NewBatchBuilder(100),
+		hostname:   testHost,
+		agentName:  testAgent,
+		serializer: s,
+	}
+	batcher.delivery, batcher.spool = newDeliveryFromConfig(s, testAgent, false)
+	require.NotNil(t, batcher.delivery)
+
+	batcher.sendState(batcher.builder.TopologyStopSnapshot(testID, testInstance))
+	assert.Equal(t, 1, batcher.spool.Len())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	assert.NoError(t, batcher.replaySpool())
+	assert.Equal(t, 0, batcher.spool.Len())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
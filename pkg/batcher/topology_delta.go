@@ -0,0 +1,210 @@
+package batcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/telemetry"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+)
+
+var (
+	tlmDeltaSkipped = telemetry.NewCounter("batcher", "delta_skipped_elements",
+		[]string{"kind"}, "Number of unchanged topology elements not resent thanks to topology deltas")
+	tlmDeltaDeleted = telemetry.NewCounter("batcher", "delta_deleted_elements",
+		nil, "Number of topology elements deleted through topology deltas")
+	tlmDeltaSnapshots = telemetry.NewCounter("batcher", "delta_snapshots",
+		[]string{"mode"}, "Number of topology snapshots sent, either full or as delta")
+)
+
+// topologySnapshotState holds the hashes of the elements seen in a topology snapshot
+type topologySnapshotState struct {
+	components map[string]uint64
+	relations  map[string]uint64
+	// full is true when the snapshot is sent as a full snapshot instead of a delta
+	full             bool
+	lastFullSnapshot time.Time
+	// failed is set when a payload of the snapshot could not be sent, the receiver is then resynced with a full
+	// snapshot
+	failed bool
+}
+
+func newTopologySnapshotState(full bool, lastFullSnapshot time.Time) *topologySnapshotState {
+	return &topologySnapshotState{
+		components:       make(map[string]uint64),
+		relations:        make(map[string]uint64),
+		full:             full,
+		lastFullSnapshot: lastFullSnapshot,
+	}
+}
+
+// TopologyDeltaTracker remembers the last topology snapshot per check instance, so subsequent snapshots can be sent
+// as a delta containing only the added, changed and deleted elements. A full snapshot is still sent periodically to
+// resync the receiver, and after a payload of the check instance failed to send. This data structure is not thread
+// safe
+type TopologyDeltaTracker struct {
+	fullSnapshotInterval time.Duration
+	previous             map[string]*topologySnapshotState
+	current              map[string]*topologySnapshotState
+	now                  func() time.Time
+}
+
+// NewTopologyDeltaTracker constructs a TopologyDeltaTracker
+func NewTopologyDeltaTracker(fullSnapshotInterval time.Duration) *TopologyDeltaTracker {
+	return &TopologyDeltaTracker{
+		fullSnapshotInterval: fullSnapshotInterval,
+		previous:             make(map[string]*topologySnapshotState),
+		current:              make(map[string]*topologySnapshotState),
+		now:                  time.Now,
+	}
+}
+
+// newTopologyDeltaTrackerFromConfig creates the tracker configured under `batcher_topology_deltas`, returns nil if
+// topology deltas are disabled
+func newTopologyDeltaTrackerFromConfig() *TopologyDeltaTracker {
+	if !config.Datadog.GetBool("batcher_topology_deltas.enabled") {
+		return nil
+	}
+
+	interval := time.Duration(config.Datadog.GetInt64("batcher_topology_deltas.full_snapshot_interval")) * time.Second
+	log.Infof("Batcher topology deltas enabled, full snapshot every %s", interval)
+	return NewTopologyDeltaTracker(interval)
+}
+
+func deltaKey(checkID check.ID, instance topology.Instance) string {
+	return fmt.Sprintf("%s_%s", checkID, instance.GoString())
+}
+
+func hashElement(element interface{}) uint64 {
+	// encoding/json sorts map keys, so equal elements produce equal hashes
+	b, err := json.Marshal(element)
+	if err != nil {
+		// Unhashable elements are always considered changed
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
+
+// StartSnapshot registers the start of a snapshot. Returns whether the snapshot has to be sent as a full snapshot
+func (tracker *TopologyDeltaTracker) StartSnapshot(checkID check.ID, instance topology.Instance) bool {
+	key := deltaKey(checkID, instance)
+	now := tracker.now()
+	tracker.removeExpired(now)
+
+	previous, ok := tracker.previous[key]
+	full := !ok || now.Sub(previous.lastFullSnapshot) >= tracker.fullSnapshotInterval
+
+	lastFullSnapshot := now
+	if !full {
+		lastFullSnapshot = previous.lastFullSnapshot
+	}
+	tracker.current[key] = newTopologySnapshotState(full, lastFullSnapshot)
+	return full
+}
+
+// Component registers a component. Returns whether the component has to be sent
+func (tracker *TopologyDeltaTracker) Component(checkID check.ID, instance topology.Instance, component topology.Component) bool {
+	key := deltaKey(checkID, instance)
+	return tracker.track(key, component.ExternalID, hashElement(component), "component",
+		func(state *topologySnapshotState) map[string]uint64 { return state.components })
+}
+
+// Relation registers a relation. Returns whether the relation has to be sent
+func (tracker *TopologyDeltaTracker) Relation(checkID check.ID, instance topology.Instance, relation topology.Relation) bool {
+	key := deltaKey(checkID, instance)
+	return tracker.track(key, relation.ExternalID, hashElement(relation), "relation",
+		func(state *topologySnapshotState) map[string]uint64 { return state.relations })
+}
+
+func (tracker *TopologyDeltaTracker) track(key, externalID string, hash uint64, kind string,
+	elements func(state *topologySnapshotState) map[string]uint64) bool {
+	current, ok := tracker.current[key]
+	if !ok {
+		// Not part of a snapshot, the element is always sent
+		return true
+	}
+	elements(current)[externalID] = hash
+
+	if current.full {
+		return true
+	}
+
+	previousHash, seen := elements(tracker.previous[key])[externalID]
+	if seen && previousHash == hash && hash != 0 {
+		tlmDeltaSkipped.Inc(kind)
+		return false
+	}
+	return true
+}
+
+// StopSnapshot registers the end of a snapshot. Returns whether the snapshot was sent as a full snapshot and, for
+// deltas, the external ids of the elements that disappeared since the previous snapshot
+func (tracker *TopologyDeltaTracker) StopSnapshot(checkID check.ID, instance topology.Instance) (bool, []string) {
+	key := deltaKey(checkID, instance)
+	current, ok := tracker.current[key]
+	if !ok {
+		// A stop without a start is passed on as is
+		return true, nil
+	}
+	delete(tracker.current, key)
+
+	previous := tracker.previous[key]
+	if current.failed {
+		// The receiver may have missed part of the snapshot, it can't be used as the base of the next delta
+		delete(tracker.previous, key)
+	} else {
+		tracker.previous[key] = current
+	}
+
+	if current.full {
+		tlmDeltaSnapshots.Inc("full")
+		return true, nil
+	}
+
+	deleteIDs := make([]string, 0)
+	deleteIDs = appendDeleted(deleteIDs, previous.components, current.components)
+	deleteIDs = appendDeleted(deleteIDs, previous.relations, current.relations)
+	sort.Strings(deleteIDs)
+
+	tlmDeltaSnapshots.Inc("delta")
+	tlmDeltaDeleted.Add(float64(len(deleteIDs)))
+	return false, deleteIDs
+}
+
+func appendDeleted(deleteIDs []string, previous, current map[string]uint64) []string {
+	for externalID := range previous {
+		if _, ok := current[externalID]; !ok {
+			deleteIDs = append(deleteIDs, externalID)
+		}
+	}
+	return deleteIDs
+}
+
+// SendFailed registers that a payload with the topology of the check instance could not be sent, the next snapshot
+// of the check instance is sent as a full snapshot
+func (tracker *TopologyDeltaTracker) SendFailed(checkID check.ID, instance topology.Instance) {
+	key := deltaKey(checkID, instance)
+	if current, ok := tracker.current[key]; ok {
+		// The snapshot in progress still needs the previous snapshot to compute its deleted elements
+		current.failed = true
+		return
+	}
+	delete(tracker.previous, key)
+}
+
+// removeExpired forgets the snapshots that are due for a full snapshot anyway, e.g. of unscheduled checks
+func (tracker *TopologyDeltaTracker) removeExpired(now time.Time) {
+	for key, previous := range tracker.previous {
+		if now.Sub(previous.lastFullSnapshot) >= tracker.fullSnapshotInterval {
+			delete(tracker.previous, key)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package batcher
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/forwarder"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	serializer2 "github.com/StackVista/stackstate-agent/pkg/serializer"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testChangedComponent = topology.Component{
+		ExternalID: "id",
+		Type:       topology.Type{Name: "typename"},
+		Data:       map[string]interface{}{"changed": true},
+	}
+)
+
+func TestDeltaTrackerSendsOnlyChanges(t *testing.T) {
+	tracker := NewTopologyDeltaTracker(time.Hour)
+
+	// The first snapshot is always full
+	assert.True(t, tracker.StartSnapshot(testID, testInstance))
+	assert.True(t, tracker.Component(testID, testInstance, testComponent))
+	assert.True(t, tracker.Component(testID, testInstance, testComponent2))
+	assert.True(t, tracker.Relation(testID, testInstance, testRelation))
+	full, deleteIDs := tracker.StopSnapshot(testID, testInstance)
+	assert.True(t, full)
+	assert.Nil(t, deleteIDs)
+
+	// The second snapshot only sends the changed component and deletes what is gone
+	assert.False(t, tracker.StartSnapshot(testID, testInstance))
+	assert.True(t, tracker.Component(testID, testInstance, testChangedComponent))
+	assert.False(t, tracker.Relation(testID, testInstance, testRelation))
+	full, deleteIDs = tracker.StopSnapshot(testID, testInstance)
+	assert.False(t, full)
+	assert.Equal(t, []string{"id2"}, deleteIDs)
+
+	// Other instances are tracked separately
+	assert.True(t, tracker.StartSnapshot(testID, testInstance2))
+}
+
+func TestDeltaTrackerPeriodicFullSnapshot(t *testing.T) {
+	now := time.Now()
+	tracker := NewTopologyDeltaTracker(time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	assert.True(t, tracker.StartSnapshot(testID, testInstance))
+	tracker.StopSnapshot(testID, testInstance)
+
+	now = now.Add(30 * time.Minute)
+	assert.False(t, tracker.StartSnapshot(testID, testInstance))
+	tracker.StopSnapshot(testID, testInstance)
+
+	now = now.Add(30 * time.Minute)
+	assert.True(t, tracker.StartSnapshot(testID, testInstance))
+	assert.True(t, tracker.Component(testID, testInstance, testComponent))
+}
+
+func TestDeltaTrackerResyncsAfterFailedSend(t *testing.T) {
+	tracker := NewTopologyDeltaTracker(time.Hour)
+
+	assert.True(t, tracker.StartSnapshot(testID, testInstance))
+	tracker.Component(testID, testInstance, testComponent)
+	tracker.StopSnapshot(testID, testInstance)
+
+	// A payload of the delta snapshot is lost, the delta is still finished against the previous snapshot
+	assert.False(t, tracker.StartSnapshot(testID, testInstance))
+	assert.True(t, tracker.Component(testID, testInstance, testChangedComponent))
+	tracker.SendFailed(testID, testInstance)
+	full, _ := tracker.StopSnapshot(testID, testInstance)
+	assert.False(t, full)
+
+	// but the next snapshot is full
+	assert.True(t, tracker.StartSnapshot(testID, testInstance))
+	assert.True(t, tracker.Component(testID, testInstance, testChangedComponent))
+	tracker.StopSnapshot(testID, testInstance)
+
+	// The stop of the snapshot is lost
+	tracker.SendFailed(testID, testInstance)
+	assert.True(t, tracker.StartSnapshot(testID, testInstance))
+}
+
+func TestDeltaTrackerForgetsExpiredSnapshots(t *testing.T) {
+	now := time.Now()
+	tracker := NewTopologyDeltaTracker(time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	tracker.StartSnapshot(testID, testInstance)
+	tracker.StopSnapshot(testID, testInstance)
+	assert.Len(t, tracker.previous, 1)
+
+	// The check is no longer scheduled, its snapshot is forgotten once another check snapshots after the interval
+	now = now.Add(time.Hour)
+	tracker.StartSnapshot(testID2, testInstance)
+	assert.Len(t, tracker.previous, 0)
+}
+
+func TestDeltaTrackerIgnoresElementsOutsideSnapshot(t *testing.T) {
+	tracker := NewTopologyDeltaTracker(time.Hour)
+
+	assert.True(t, tracker.Component(testID, testInstance, testComponent))
+	assert.True(t, tracker.Component(testID, testInstance, testComponent))
+	full, deleteIDs := tracker.StopSnapshot(testID, testInstance)
+	assert.True(t, full)
+	assert.Nil(t, deleteIDs)
+}
+
+func TestBatcherSendsTopologyDelta(t *testing.T) {
+	serializer := serializer2.NewAgentV1MockSerializer()
+	batcher := AsynchronousBatcher{
+		builder:    NewBatchBuilder(100),
+		hostname:   testHost,
+		agentName:  testAgent,
+		input:      make(chan interface{}),
+		serializer: serializer,
+		deltas:     NewTopologyDeltaTracker(time.Hour),
+	}
+	go batcher.run()
+
+	batcher.SubmitStartSnapshot(testID, testInstance)
+	batcher.SubmitComponent(testID, testInstance, testComponent)
+	batcher.SubmitComponent(testID, testInstance, testComponent2)
+	batcher.SubmitStopSnapshot(testID, testInstance)
+
+	assert.Equal(t, map[string]interface{}{
+		"internalHostname": "myhost",
+		"topologies": []topology.Topology{
+			{
+				StartSnapshot: true,
+				StopSnapshot:  true,
+				Instance:      testInstance,
+				Components:    []topology.Component{testComponent, testComponent2},
+				Relations:     []topology.Relation{},
+			},
+		},
+		"health": []health.Health{},
+	}, serializer.GetJSONToV1IntakeMessage())
+
+	batcher.SubmitStartSnapshot(testID, testInstance)
+	batcher.SubmitComponent(testID, testInstance, testComponent)
+	batcher.SubmitStopSnapshot(testID, testInstance)
+
+	assert.Equal(t, map[string]interface{}{
+		"internalHostname": "myhost",
+		"topologies": []topology.Topology{
+			{
+				StartSnapshot: false,
+				StopSnapshot:  false,
+				Instance:      testInstance,
+				Components:    []topology.Component{},
+				Relations:     []topology.Relation{},
+				DeleteIDs:     []string{"id2"},
+			},
+		},
+		"health": []health.Health{},
+	}, serializer.GetJSONToV1IntakeMessage())
+
+	batcher.Shutdown()
+}
+
+func TestBatcherResyncsTopologyAfterFailedSend(t *testing.T) {
+	serializer := &failingSerializer{}
+	batcher := AsynchronousBatcher{
+		builder:    NewBatchBuilder(100),
+		hostname:   testHost,
+		agentName:  testAgent,
+		serializer: serializer,
+		delivery:   serializer,
+		deltas:     NewTopologyDeltaTracker(time.Hour),
+	}
+
+	batcher.startSnapshot(testID, testInstance)
+	batcher.addComponent(testID, testInstance, testComponent)
+	serializer.failing = true
+	batcher.stopSnapshot(testID, testInstance)
+
+	// The receiver did not get the snapshot, so the next one is not sent as a delta
+	serializer.failing = false
+	batcher.startSnapshot(testID, testInstance)
+	batcher.addComponent(testID, testInstance, testComponent)
+	batcher.stopSnapshot(testID, testInstance)
+
+	require.Len(t, serializer.received, 1)
+	var payload struct {
+		Topologies []topology.Topology `json:"topologies"`
+	}
+	require.NoError(t, json.Unmarshal(serializer.received[0], &payload))
+	require.Len(t, payload.Topologies, 1)
+	assert.True(t, payload.Topologies[0].StartSnapshot)
+	assert.Len(t, payload.Topologies[0].Components, 1)
+}
+
+// TestBatcherResyncsTopologyAfterUndeliveredPayload sends the snapshots through the forwarder, which does not report
+// delivery failures to the caller of SendJSONToV1Intake
+func TestBatcherResyncsTopologyAfterUndeliveredPayload(t *testing.T) {
+	var lock sync.Mutex
+	status := http.StatusServiceUnavailable
+	received := make([][]byte, 0)
+	intake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if status == http.StatusOK {
+			body, _ := ioutil.ReadAll(r.Body)
+			received = append(received, body)
+		}
+		w.WriteHeader(status)
+	}))
+	defer intake.Close()
+
+	options := forwarder.NewOptions(map[string][]string{intake.URL: {"api_key"}})
+	options.DisableAPIKeyChecking = true
+	fwd := forwarder.NewDefaultForwarder(options)
+	require.NoError(t, fwd.Start())
+	defer fwd.Stop()
+	s := serializer2.NewSerializer(fwd)
+
+	batcher := AsynchronousBatcher{
+		builder:    NewBatchBuilder(100),
+		hostname:   testHost,
+		agentName:  testAgent,
+		serializer: s,
+		deltas:     NewTopologyDeltaTracker(time.Hour),
+	}
+	batcher.delivery, batcher.spool = newDeliveryFromConfig(s, testAgent, true)
+	require.NotNil(t, batcher.delivery)
+
+	batcher.startSnapshot(testID, testInstance)
+	batcher.addComponent(testID, testInstance, testComponent)
+	batcher.stopSnapshot(testID, testInstance)
+
+	// The intake did not get the snapshot, so the next one is not sent as a delta
+	lock.Lock()
+	status = http.StatusOK
+	lock.Unlock()
+	batcher.startSnapshot(testID, testInstance)
+	batcher.addComponent(testID, testInstance, testComponent)
+	batcher.stopSnapshot(testID, testInstance)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, received, 1)
+	var payload struct {
+		Topologies []topology.Topology `json:"topologies"`
+	}
+	require.NoError(t, json.Unmarshal(received[0], &payload))
+	require.Len(t, payload.Topologies, 1)
+	assert.True(t, payload.Topologies[0].StartSnapshot)
+	assert.Len(t, payload.Topologies[0].Components, 1)
+}
+
+func TestBatcherDisablesTopologyDeltasWithoutDelivery(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("batcher_topology_deltas.enabled", true)
+	defer mockConfig.Set("batcher_topology_deltas.enabled", false)
+
+	batcher := newAsynchronousBatcher(fireAndForgetSerializer{}, testHost, testAgent, 100)
+	defer batcher.Shutdown()
+	assert.Nil(t, batcher.deltas)
+}
@@ -0,0 +1,271 @@
+package batcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/telemetry"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+)
+
+// Violations of the topology invariants found by the TopologyValidator
+const (
+	ViolationEmptyExternalID     = "empty_external_id"
+	ViolationEmptyTypeName       = "empty_type_name"
+	ViolationEmptyRelationSource = "empty_relation_source"
+	ViolationEmptyRelationTarget = "empty_relation_target"
+	ViolationUnserializableData  = "unserializable_data"
+	ViolationDanglingRelation    = "dangling_relation"
+)
+
+var (
+	tlmValidationViolations = telemetry.NewCounter("batcher", "topology_violations",
+		[]string{"check", "violation"}, "Number of topology elements violating the topology invariants")
+	tlmValidationDropped = telemetry.NewCounter("batcher", "topology_dropped_elements",
+		[]string{"check", "violation"}, "Number of topology elements dropped because of a violation")
+)
+
+// snapshotValidation holds what the validator saw of a snapshot
+type snapshotValidation struct {
+	checkID check.ID
+	started time.Time
+	// componentIDs contains the external ids and identifiers of the components in the snapshot
+	componentIDs map[string]struct{}
+	// relations contains the relations of the snapshot, which are held back when dangling relations are dropped
+	relations  []topology.Relation
+	violations map[string]int
+}
+
+// TopologyValidator checks the components and relations submitted by a check against the invariants the receiver
+// expects. Violations are counted and logged per check. Elements with data that cannot be serialized are dropped, as
+// they would make the whole payload fail. Relations pointing to components that the check instance no longer reports
+// can optionally be dropped, in which case the relations of a snapshot are held back until the snapshot stops.
+// Relations to components of other checks are always kept. Snapshots that are not stopped within the snapshot timeout
+// are forgotten. This data structure is not thread safe
+type TopologyValidator struct {
+	dropDanglingRelations bool
+	snapshotTimeout       time.Duration
+	snapshots             map[string]*snapshotValidation
+	// previous holds the last stopped snapshot per check instance, its components are owned by the check instance
+	previous map[string]*snapshotValidation
+	now      func() time.Time
+}
+
+// NewTopologyValidator constructs a TopologyValidator
+func NewTopologyValidator(dropDanglingRelations bool, snapshotTimeout time.Duration) *TopologyValidator {
+	return &TopologyValidator{
+		dropDanglingRelations: dropDanglingRelations,
+		snapshotTimeout:       snapshotTimeout,
+		snapshots:             make(map[string]*snapshotValidation),
+		previous:              make(map[string]*snapshotValidation),
+		now:                   time.Now,
+	}
+}
+
+// newTopologyValidatorFromConfig creates the validator configured under `batcher_validation`, returns nil if
+// validation is disabled
+func newTopologyValidatorFromConfig() *TopologyValidator {
+	if !config.Datadog.GetBool("batcher_validation.enabled") {
+		return nil
+	}
+	return NewTopologyValidator(config.Datadog.GetBool("batcher_validation.drop_dangling_relations"),
+		time.Duration(config.Datadog.GetInt64("batcher_validation.snapshot_timeout"))*time.Second)
+}
+
+// StartSnapshot registers the start of a snapshot
+func (validator *TopologyValidator) StartSnapshot(checkID check.ID, instance topology.Instance) {
+	validator.snapshots[deltaKey(checkID, instance)] = &snapshotValidation{
+		checkID:      checkID,
+		started:      validator.now(),
+		componentIDs: make(map[string]struct{}),
+		relations:    make([]topology.Relation, 0),
+		violations:   make(map[string]int),
+	}
+}
+
+// ValidateComponent validates a component. Returns whether the component has to be sent
+func (validator *TopologyValidator) ValidateComponent(checkID check.ID, instance topology.Instance, component topology.Component) bool {
+	snapshot := validator.snapshots[deltaKey(checkID, instance)]
+	element := fmt.Sprintf("component %s", component.ExternalID)
+
+	if component.ExternalID == "" {
+		validator.violation(checkID, snapshot, ViolationEmptyExternalID, element)
+	}
+	if component.Type.Name == "" {
+		validator.violation(checkID, snapshot, ViolationEmptyTypeName, element)
+	}
+	if _, err := json.Marshal(component.Data); err != nil {
+		validator.violation(checkID, snapshot, ViolationUnserializableData, fmt.Sprintf("%s: %s", element, err))
+		tlmValidationDropped.Inc(string(checkID), ViolationUnserializableData)
+		return false
+	}
+
+	if snapshot != nil {
+		snapshot.componentIDs[component.ExternalID] = struct{}{}
+		for _, identifier := range componentIdentifiers(component) {
+			snapshot.componentIDs[identifier] = struct{}{}
+		}
+	}
+	return true
+}
+
+// ValidateRelation validates a relation. Returns whether the relation has to be sent now, relations that are held
+// back are returned by StopSnapshot
+func (validator *TopologyValidator) ValidateRelation(checkID check.ID, instance topology.Instance, relation topology.Relation) bool {
+	snapshot := validator.snapshots[deltaKey(checkID, instance)]
+	element := fmt.Sprintf("relation %s", relation.ExternalID)
+
+	if relation.ExternalID == "" {
+		validator.violation(checkID, snapshot, ViolationEmptyExternalID, element)
+	}
+	if relation.Type.Name == "" {
+		validator.violation(checkID, snapshot, ViolationEmptyTypeName, element)
+	}
+	if relation.SourceID == "" {
+		validator.violation(checkID, snapshot, ViolationEmptyRelationSource, element)
+	}
+	if relation.TargetID == "" {
+		validator.violation(checkID, snapshot, ViolationEmptyRelationTarget, element)
+	}
+	if _, err := json.Marshal(relation.Data); err != nil {
+		validator.violation(checkID, snapshot, ViolationUnserializableData, fmt.Sprintf("%s: %s", element, err))
+		tlmValidationDropped.Inc(string(checkID), ViolationUnserializableData)
+		return false
+	}
+
+	if snapshot == nil {
+		// Dangling relations can only be detected within a snapshot
+		return true
+	}
+	snapshot.relations = append(snapshot.relations, relation)
+	return !validator.dropDanglingRelations
+}
+
+// StopSnapshot registers the end of a snapshot, checks the relations for dangling endpoints and logs the violations
+// of the snapshot. Returns the held back relations that have to be sent
+func (validator *TopologyValidator) StopSnapshot(checkID check.ID, instance topology.Instance) []topology.Relation {
+	key := deltaKey(checkID, instance)
+	snapshot, ok := validator.snapshots[key]
+	if !ok {
+		return nil
+	}
+	delete(validator.snapshots, key)
+	previous := validator.previous[key]
+	validator.previous[key] = &snapshotValidation{checkID: checkID, componentIDs: snapshot.componentIDs}
+
+	// A component is owned by the check instance when the check instance reports it now or reported it in its
+	// previous snapshot, relations to other components may point to components of other checks
+	owned := func(id string) bool {
+		if _, ok := snapshot.componentIDs[id]; ok {
+			return true
+		}
+		if previous != nil {
+			_, ok := previous.componentIDs[id]
+			return ok
+		}
+		return false
+	}
+
+	release := make([]topology.Relation, 0)
+	for _, relation := range snapshot.relations {
+		_, sourceFound := snapshot.componentIDs[relation.SourceID]
+		_, targetFound := snapshot.componentIDs[relation.TargetID]
+		dangling := !sourceFound || !targetFound
+		if dangling {
+			validator.violation(checkID, snapshot, ViolationDanglingRelation,
+				fmt.Sprintf("relation %s, source found: %t, target found: %t", relation.ExternalID, sourceFound, targetFound))
+		}
+
+		if !validator.dropDanglingRelations {
+			continue
+		}
+		if dangling && owned(relation.SourceID) && owned(relation.TargetID) {
+			tlmValidationDropped.Inc(string(checkID), ViolationDanglingRelation)
+			continue
+		}
+		release = append(release, relation)
+	}
+
+	// Relations to components of other checks, e.g. to the host, are expected to dangle, so they are only logged at
+	// debug level
+	if dangling, ok := snapshot.violations[ViolationDanglingRelation]; ok {
+		delete(snapshot.violations, ViolationDanglingRelation)
+		log.Debugf("Topology snapshot of check %s for instance %s has %d relations to components outside the snapshot",
+			checkID, instance.GoString(), dangling)
+	}
+
+	if len(snapshot.violations) > 0 {
+		summary := make([]string, 0, len(snapshot.violations))
+		for violation, count := range snapshot.violations {
+			summary = append(summary, fmt.Sprintf("%s=%d", violation, count))
+		}
+		sort.Strings(summary)
+		log.Warnf("Topology snapshot of check %s for instance %s has invalid elements: %s", checkID,
+			instance.GoString(), strings.Join(summary, ", "))
+	}
+	return release
+}
+
+// RemoveExpired forgets the snapshots that were not stopped within the snapshot timeout, the relations they held back
+// are dropped
+func (validator *TopologyValidator) RemoveExpired() {
+	if validator.snapshotTimeout <= 0 {
+		return
+	}
+
+	now := validator.now()
+	for key, snapshot := range validator.snapshots {
+		if now.Sub(snapshot.started) < validator.snapshotTimeout {
+			continue
+		}
+		delete(validator.snapshots, key)
+		if validator.dropDanglingRelations && len(snapshot.relations) > 0 {
+			log.Warnf("Topology snapshot of check %s was not stopped within %s, dropping %d held back relations",
+				snapshot.checkID, validator.snapshotTimeout, len(snapshot.relations))
+		}
+	}
+}
+
+// RemoveCheck forgets the snapshots of a check that is no longer scheduled
+func (validator *TopologyValidator) RemoveCheck(checkID check.ID) {
+	for _, snapshots := range []map[string]*snapshotValidation{validator.snapshots, validator.previous} {
+		for key, snapshot := range snapshots {
+			if snapshot.checkID == checkID {
+				delete(snapshots, key)
+			}
+		}
+	}
+}
+
+func (validator *TopologyValidator) violation(checkID check.ID, snapshot *snapshotValidation, violation, element string) {
+	tlmValidationViolations.Inc(string(checkID), violation)
+	if snapshot != nil {
+		// Logged as a summary when the snapshot stops
+		snapshot.violations[violation]++
+		log.Debugf("Topology violation %s in check %s: %s", violation, checkID, element)
+		return
+	}
+	log.Warnf("Topology violation %s in check %s: %s", violation, checkID, element)
+}
+
+// componentIdentifiers returns the identifiers a component can be referred to by, besides its external id
+func componentIdentifiers(component topology.Component) []string {
+	identifiers := make([]string, 0)
+	switch values := component.Data["identifiers"].(type) {
+	case []string:
+		identifiers = append(identifiers, values...)
+	case []interface{}:
+		for _, value := range values {
+			if identifier, ok := value.(string); ok {
+				identifiers = append(identifiers, identifier)
+			}
+		}
+	}
+	return identifiers
+}
@@ -0,0 +1,182 @@
+package batcher
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	serializer2 "github.com/StackVista/stackstate-agent/pkg/serializer"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testSourceComponent = topology.Component{
+		ExternalID: "source",
+		Type:       topology.Type{Name: "typename"},
+		Data:       map[string]interface{}{"identifiers": []interface{}{"urn:source"}},
+	}
+	testTargetComponent = topology.Component{
+		ExternalID: "target",
+		Type:       topology.Type{Name: "typename"},
+		Data:       map[string]interface{}{},
+	}
+	testIdentifierRelation = topology.Relation{
+		ExternalID: "urn:source-uses-target",
+		Type:       topology.Type{Name: "uses"},
+		SourceID:   "urn:source",
+		TargetID:   "target",
+		Data:       map[string]interface{}{},
+	}
+	testDanglingRelation = topology.Relation{
+		ExternalID: "source-uses-typo",
+		Type:       topology.Type{Name: "uses"},
+		SourceID:   "source",
+		TargetID:   "typo",
+		Data:       map[string]interface{}{},
+	}
+	testTypoComponent = topology.Component{
+		ExternalID: "typo",
+		Type:       topology.Type{Name: "typename"},
+		Data:       map[string]interface{}{},
+	}
+	testCrossCheckRelation = topology.Relation{
+		ExternalID: "source-runs-on-host",
+		Type:       topology.Type{Name: "runs_on"},
+		SourceID:   "source",
+		TargetID:   "urn:host:/myhost",
+		Data:       map[string]interface{}{},
+	}
+)
+
+func TestValidatorDropsUnserializableData(t *testing.T) {
+	validator := NewTopologyValidator(false, time.Hour)
+
+	assert.False(t, validator.ValidateComponent(testID, testInstance, topology.Component{
+		ExternalID: "id",
+		Type:       topology.Type{Name: "typename"},
+		Data:       map[string]interface{}{"value": math.NaN()},
+	}))
+	assert.False(t, validator.ValidateRelation(testID, testInstance, topology.Relation{
+		ExternalID: "id",
+		Type:       topology.Type{Name: "typename"},
+		SourceID:   "source",
+		TargetID:   "target",
+		Data:       map[string]interface{}{"value": make(chan int)},
+	}))
+
+	// Other violations are reported, but the element is still sent
+	assert.True(t, validator.ValidateComponent(testID, testInstance, topology.Component{Data: map[string]interface{}{}}))
+	assert.True(t, validator.ValidateRelation(testID, testInstance, topology.Relation{Data: map[string]interface{}{}}))
+}
+
+func TestValidatorReportsDanglingRelations(t *testing.T) {
+	validator := NewTopologyValidator(false, time.Hour)
+
+	validator.StartSnapshot(testID, testInstance)
+	assert.True(t, validator.ValidateRelation(testID, testInstance, testIdentifierRelation))
+	assert.True(t, validator.ValidateRelation(testID, testInstance, testDanglingRelation))
+	assert.True(t, validator.ValidateComponent(testID, testInstance, testSourceComponent))
+	assert.True(t, validator.ValidateComponent(testID, testInstance, testTargetComponent))
+
+	assert.Empty(t, validator.StopSnapshot(testID, testInstance))
+	assert.Nil(t, validator.StopSnapshot(testID, testInstance))
+}
+
+func TestValidatorDropsDanglingRelations(t *testing.T) {
+	validator := NewTopologyValidator(true, time.Hour)
+
+	// Outside of a snapshot relations are never held back
+	assert.True(t, validator.ValidateRelation(testID, testInstance, testDanglingRelation))
+
+	validator.StartSnapshot(testID, testInstance)
+	assert.True(t, validator.ValidateComponent(testID, testInstance, testTypoComponent))
+	assert.Empty(t, validator.StopSnapshot(testID, testInstance))
+
+	// The check instance no longer reports the target of the dangling relation, relations to components it never
+	// reported are kept as they can point to components of other checks
+	validator.StartSnapshot(testID, testInstance)
+	assert.False(t, validator.ValidateRelation(testID, testInstance, testIdentifierRelation))
+	assert.False(t, validator.ValidateRelation(testID, testInstance, testDanglingRelation))
+	assert.False(t, validator.ValidateRelation(testID, testInstance, testCrossCheckRelation))
+	assert.True(t, validator.ValidateComponent(testID, testInstance, testSourceComponent))
+	assert.True(t, validator.ValidateComponent(testID, testInstance, testTargetComponent))
+
+	assert.Equal(t, []topology.Relation{testIdentifierRelation, testCrossCheckRelation},
+		validator.StopSnapshot(testID, testInstance))
+}
+
+func TestValidatorRemovesExpiredSnapshots(t *testing.T) {
+	now := time.Now()
+	validator := NewTopologyValidator(true, time.Hour)
+	validator.now = func() time.Time { return now }
+
+	validator.StartSnapshot(testID, testInstance)
+	assert.False(t, validator.ValidateRelation(testID, testInstance, testIdentifierRelation))
+	validator.StartSnapshot(testID2, testInstance)
+
+	now = now.Add(30 * time.Minute)
+	validator.RemoveExpired()
+	assert.Len(t, validator.snapshots, 2)
+
+	validator.StartSnapshot(testID2, testInstance)
+	now = now.Add(30 * time.Minute)
+	validator.RemoveExpired()
+	assert.Len(t, validator.snapshots, 1)
+	assert.Nil(t, validator.StopSnapshot(testID, testInstance))
+	assert.Empty(t, validator.StopSnapshot(testID2, testInstance))
+}
+
+func TestValidatorRemovesStoppedChecks(t *testing.T) {
+	validator := NewTopologyValidator(true, time.Hour)
+
+	validator.StartSnapshot(testID, testInstance)
+	assert.True(t, validator.ValidateComponent(testID, testInstance, testSourceComponent))
+	validator.StopSnapshot(testID, testInstance)
+	validator.StartSnapshot(testID, testInstance2)
+	validator.StartSnapshot(testID2, testInstance)
+
+	validator.RemoveCheck(testID)
+	assert.Len(t, validator.snapshots, 1)
+	assert.Empty(t, validator.previous)
+}
+
+func TestBatcherDropsDanglingRelations(t *testing.T) {
+	serializer := serializer2.NewAgentV1MockSerializer()
+	mockConfig := config.Mock()
+	mockConfig.Set("batcher_validation.enabled", true)
+	defer mockConfig.Set("batcher_validation.enabled", false)
+	mockConfig.Set("batcher_validation.drop_dangling_relations", true)
+	defer mockConfig.Set("batcher_validation.drop_dangling_relations", false)
+	batcher := newAsynchronousBatcher(serializer, testHost, testAgent, 100)
+
+	batcher.SubmitStartSnapshot(testID, testInstance)
+	batcher.SubmitComponent(testID, testInstance, testTypoComponent)
+	batcher.SubmitStopSnapshot(testID, testInstance)
+	serializer.GetJSONToV1IntakeMessage()
+
+	batcher.SubmitStartSnapshot(testID, testInstance)
+	batcher.SubmitRelation(testID, testInstance, testIdentifierRelation)
+	batcher.SubmitRelation(testID, testInstance, testDanglingRelation)
+	batcher.SubmitComponent(testID, testInstance, testSourceComponent)
+	batcher.SubmitComponent(testID, testInstance, testTargetComponent)
+	batcher.SubmitStopSnapshot(testID, testInstance)
+
+	assert.Equal(t, map[string]interface{}{
+		"internalHostname": "myhost",
+		"topologies": []topology.Topology{
+			{
+				StartSnapshot: true,
+				StopSnapshot:  true,
+				Instance:      testInstance,
+				Components:    []topology.Component{testSourceComponent, testTargetComponent},
+				Relations:     []topology.Relation{testIdentifierRelation},
+			},
+		},
+		"health": []health.Health{},
+	}, serializer.GetJSONToV1IntakeMessage())
+
+	batcher.Shutdown()
+}
@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 
 	"github.com/StackVista/stackstate-agent/pkg/autodiscovery/integration"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	"github.com/StackVista/stackstate-agent/pkg/collector/runner"
 	"github.com/StackVista/stackstate-agent/pkg/collector/scheduler"
@@ -187,6 +188,11 @@ func (c *Collector) StopCheck(id check.ID) error {
 	// remove the check from the stats map
 	runner.RemoveCheckStats(id)
 
+	// [sts] let the batcher forget about the check
+	if b := batcher.GetBatcher(); b != nil {
+		b.SubmitCheckStopped(id)
+	}
+
 	// vaporize the check
 	c.delete(id)
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/StackVista/stackstate-agent/pkg/aggregator"
 	"github.com/StackVista/stackstate-agent/pkg/autodiscovery/integration"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check/defaults"
 	"github.com/StackVista/stackstate-agent/pkg/telemetry"
@@ -148,6 +149,16 @@ func (c *CheckBase) CommonConfigure(instance integration.Data, source string) er
 		s.SetCheckService(commonOptions.Service)
 	}
 
+	// [sts] Override the batcher settings for this check
+	if commonOptions.BatcherMaxCapacity > 0 || commonOptions.BatcherFlushInterval > 0 {
+		if b := batcher.GetBatcher(); b != nil {
+			b.SubmitCheckBatchConfig(c.checkID, batcher.CheckBatchConfig{
+				MaxCapacity:   commonOptions.BatcherMaxCapacity,
+				FlushInterval: time.Duration(commonOptions.BatcherFlushInterval) * time.Second,
+			})
+		}
+	}
+
 	c.source = source
 	return nil
 }
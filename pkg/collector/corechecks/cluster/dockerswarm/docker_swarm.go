@@ -26,10 +26,11 @@ const (
 	SwarmServiceCheck = "swarm.service"
 )
 
-// SwarmConfig have boolean flag to collect topology
+// SwarmConfig have boolean flags to collect topology and health
 type SwarmConfig struct {
 	// sts
 	CollectSwarmTopology bool `yaml:"collect_swarm_topology"`
+	CollectSwarmHealth   bool `yaml:"collect_swarm_health"`
 }
 
 // SwarmCheck grabs Swarm topology and replica metrics
@@ -57,7 +58,7 @@ func (s *SwarmCheck) Run() error {
 		}
 
 		log.Infof("Swarm check is enabled and running it")
-		err = s.topologyCollector.BuildSwarmTopology(hostname, sender)
+		err = s.topologyCollector.BuildSwarmTopology(hostname, sender, s.instance.CollectSwarmHealth, int(s.Interval().Seconds()))
 		if err != nil {
 			sender.ServiceCheck(SwarmServiceCheck, metrics.ServiceCheckCritical, "", nil, err.Error())
 			log.Errorf("Could not collect swarm topology: %s", err)
@@ -74,10 +75,17 @@ func (s *SwarmCheck) Run() error {
 
 // Parse the config
 func (c *SwarmConfig) Parse(data []byte) error {
+	// the health of the swarm services is collected together with the topology by default
+	c.CollectSwarmHealth = true
+
 	// use STS_COLLECT_SWARM_TOPOLOGY to set the config
 	if config.Datadog.IsSet("collect_swarm_topology") {
 		c.CollectSwarmTopology = config.Datadog.GetBool("collect_swarm_topology")
 	}
+	// use STS_COLLECT_SWARM_HEALTH to set the config
+	if config.Datadog.IsSet("collect_swarm_health") {
+		c.CollectSwarmHealth = config.Datadog.GetBool("collect_swarm_health")
+	}
 
 	return yaml.Unmarshal(data, c)
 }
@@ -49,7 +49,14 @@ func TestDockerSwarmCheck_True(t *testing.T) {
 	producedTopology := mockBatcher.CollectedTopology.Flush()
 	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
 		"swarm_topology": {
-			Health: make(map[string]health.Health),
+			Health: map[string]health.Health{
+				serviceHealthStream.GoString(): {
+					StartSnapshot: &health.StartSnapshotMetadata{RepeatIntervalS: 15},
+					StopSnapshot:  &health.StopSnapshotMetadata{},
+					Stream:        serviceHealthStream,
+					CheckStates:   serviceCheckData(),
+				},
+			},
 			Topology: &topology.Topology{
 				StartSnapshot: false,
 				StopSnapshot:  false,
@@ -97,7 +104,14 @@ func TestDockerSwarmCheck_FromEnv(t *testing.T) {
 	producedTopology := mockBatcher.CollectedTopology.Flush()
 	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
 		"swarm_topology": {
-			Health: make(map[string]health.Health),
+			Health: map[string]health.Health{
+				serviceHealthStream.GoString(): {
+					StartSnapshot: &health.StartSnapshotMetadata{RepeatIntervalS: 15},
+					StopSnapshot:  &health.StopSnapshotMetadata{},
+					Stream:        serviceHealthStream,
+					CheckStates:   serviceCheckData(),
+				},
+			},
 			Topology: &topology.Topology{
 				StartSnapshot: false,
 				StopSnapshot:  false,
@@ -0,0 +1,116 @@
+// +build docker
+
+package dockerswarm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// The names of the health checks derived from the state of the swarm services
+const (
+	ReplicasRunningHealthCheck  = "Replicas Running"
+	FailedTasksHealthCheck      = "Failed Tasks"
+	NodeAvailabilityHealthCheck = "Node Availability"
+)
+
+// failedTasksWindow is how long a failed task keeps its service deviating. Swarm keeps the history of the tasks it
+// replaced, so without it a service that recovered long ago would stay deviating.
+const failedTasksWindow = 5 * time.Minute
+
+// serviceCheckStates creates the health check states of a swarm service
+func serviceCheckStates(externalID string, s *containers.SwarmService, now time.Time) []*health.CheckState {
+	return []*health.CheckState{
+		replicasCheckState(externalID, s),
+		failedTasksCheckState(externalID, s, now),
+		nodeAvailabilityCheckState(externalID, s),
+	}
+}
+
+// replicasCheckState is critical when none of the desired tasks of the service run, and deviating when only part of
+// them do
+func replicasCheckState(externalID string, s *containers.SwarmService) *health.CheckState {
+	if s.RunningTasks >= s.DesiredTasks {
+		return createCheckState(externalID, ReplicasRunningHealthCheck, health.Clear, "")
+	}
+
+	message := fmt.Sprintf("%d of %d desired replicas are not running", s.DesiredTasks-s.RunningTasks, s.DesiredTasks)
+	if s.RunningTasks == 0 {
+		return createCheckState(externalID, ReplicasRunningHealthCheck, health.Critical, message)
+	}
+	return createCheckState(externalID, ReplicasRunningHealthCheck, health.Deviating, message)
+}
+
+// failedTasksCheckState is deviating when tasks of the service failed or were rejected within the failedTasksWindow,
+// the message contains the error of the most recent one
+func failedTasksCheckState(externalID string, s *containers.SwarmService, now time.Time) *health.CheckState {
+	failed := 0
+	var latest *containers.SwarmTask
+	for _, task := range s.TaskContainers {
+		if task.DesiredState != swarm.TaskStateFailed && task.DesiredState != swarm.TaskStateRejected {
+			continue
+		}
+		if now.Sub(task.Timestamp) > failedTasksWindow {
+			continue
+		}
+		failed++
+		if latest == nil || task.Timestamp.After(latest.Timestamp) {
+			latest = task
+		}
+	}
+
+	if failed == 0 {
+		return createCheckState(externalID, FailedTasksHealthCheck, health.Clear, "")
+	}
+
+	message := fmt.Sprintf("%d tasks failed in the last %s, task %s is %s", failed, failedTasksWindow, latest.Name, latest.DesiredState)
+	if latest.Error != "" {
+		message = fmt.Sprintf("%s: %s", message, latest.Error)
+	}
+	return createCheckState(externalID, FailedTasksHealthCheck, health.Deviating, message)
+}
+
+// nodeAvailabilityCheckState is deviating when running tasks of the service are scheduled on nodes that are not ready
+// or are drained or paused, these tasks are lost or moved soon
+func nodeAvailabilityCheckState(externalID string, s *containers.SwarmService) *health.CheckState {
+	nodes := make(map[string]string)
+	for _, task := range s.TaskContainers {
+		if task.DesiredState != swarm.TaskStateRunning || task.NodeID == "" {
+			continue
+		}
+		if task.NodeState != swarm.NodeStateReady {
+			nodes[task.NodeID] = string(task.NodeState)
+		} else if task.NodeAvailability == swarm.NodeAvailabilityDrain || task.NodeAvailability == swarm.NodeAvailabilityPause {
+			nodes[task.NodeID] = string(task.NodeAvailability)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return createCheckState(externalID, NodeAvailabilityHealthCheck, health.Clear, "")
+	}
+
+	unavailable := make([]string, 0, len(nodes))
+	for nodeID, state := range nodes {
+		unavailable = append(unavailable, fmt.Sprintf("%s (%s)", nodeID, state))
+	}
+	sort.Strings(unavailable)
+	message := fmt.Sprintf("Tasks run on unavailable nodes: %s", strings.Join(unavailable, ", "))
+	return createCheckState(externalID, NodeAvailabilityHealthCheck, health.Deviating, message)
+}
+
+// createCheckState creates a health check state called checkName for the component with the given externalID
+func createCheckState(externalID, checkName string, state health.State, message string) *health.CheckState {
+	return &health.CheckState{
+		CheckStateID:              fmt.Sprintf("%s:%s", externalID, strings.ToLower(strings.ReplaceAll(checkName, " ", "-"))),
+		Name:                      checkName,
+		Health:                    state,
+		TopologyElementIdentifier: externalID,
+		Message:                   message,
+	}
+}
@@ -0,0 +1,88 @@
+// +build docker
+
+package dockerswarm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceCheckStates(t *testing.T) {
+	now := time.Date(2021, time.March, 11, 12, 0, 0, 0, time.UTC)
+	externalID := "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e"
+
+	for _, tc := range []struct {
+		testCase string
+		service  containers.SwarmService
+		expected map[string]health.State
+		messages map[string]string
+	}{
+		{
+			testCase: "A service without desired replicas is healthy",
+			service:  containers.SwarmService{},
+			expected: map[string]health.State{
+				ReplicasRunningHealthCheck:  health.Clear,
+				FailedTasksHealthCheck:      health.Clear,
+				NodeAvailabilityHealthCheck: health.Clear,
+			},
+		},
+		{
+			testCase: "A service with part of the replicas running is deviating, old failures and shut down tasks are ignored",
+			service: containers.SwarmService{
+				DesiredTasks: 3,
+				RunningTasks: 2,
+				TaskContainers: []*containers.SwarmTask{
+					{Name: "web.1", DesiredState: swarm.TaskStateFailed, Timestamp: now.Add(-time.Hour), Error: "task: non-zero exit (1)"},
+					{Name: "web.2", DesiredState: swarm.TaskStateShutdown, Timestamp: now, NodeID: "node-1", NodeState: swarm.NodeStateDown},
+				},
+			},
+			expected: map[string]health.State{
+				ReplicasRunningHealthCheck:  health.Deviating,
+				FailedTasksHealthCheck:      health.Clear,
+				NodeAvailabilityHealthCheck: health.Clear,
+			},
+			messages: map[string]string{
+				ReplicasRunningHealthCheck: "1 of 3 desired replicas are not running",
+			},
+		},
+		{
+			testCase: "A service without running replicas, recent failures and tasks on unavailable nodes is unhealthy",
+			service: containers.SwarmService{
+				DesiredTasks: 2,
+				RunningTasks: 0,
+				TaskContainers: []*containers.SwarmTask{
+					{Name: "web.1", DesiredState: swarm.TaskStateFailed, Timestamp: now.Add(-2 * time.Minute), Error: "task: non-zero exit (1)"},
+					{Name: "web.1", DesiredState: swarm.TaskStateRejected, Timestamp: now.Add(-time.Minute), Error: "no suitable node"},
+					{Name: "web.2", DesiredState: swarm.TaskStateRunning, NodeID: "node-2", NodeState: swarm.NodeStateReady, NodeAvailability: swarm.NodeAvailabilityDrain},
+					{Name: "web.3", DesiredState: swarm.TaskStateRunning, NodeID: "node-1", NodeState: swarm.NodeStateDown, NodeAvailability: swarm.NodeAvailabilityActive},
+					{Name: "web.4", DesiredState: swarm.TaskStateRunning, NodeID: "node-3", NodeState: swarm.NodeStateReady, NodeAvailability: swarm.NodeAvailabilityActive},
+				},
+			},
+			expected: map[string]health.State{
+				ReplicasRunningHealthCheck:  health.Critical,
+				FailedTasksHealthCheck:      health.Deviating,
+				NodeAvailabilityHealthCheck: health.Deviating,
+			},
+			messages: map[string]string{
+				ReplicasRunningHealthCheck:  "2 of 2 desired replicas are not running",
+				FailedTasksHealthCheck:      "2 tasks failed in the last 5m0s, task web.1 is rejected: no suitable node",
+				NodeAvailabilityHealthCheck: "Tasks run on unavailable nodes: node-1 (down), node-2 (drain)",
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			checkStates := serviceCheckStates(externalID, &tc.service, now)
+			assert.Len(t, checkStates, len(tc.expected))
+			for _, checkState := range checkStates {
+				assert.Equal(t, externalID, checkState.TopologyElementIdentifier)
+				assert.Equal(t, tc.expected[checkState.Name], checkState.Health, checkState.Name)
+				assert.Equal(t, tc.messages[checkState.Name], checkState.Message, checkState.Name)
+			}
+		})
+	}
+}
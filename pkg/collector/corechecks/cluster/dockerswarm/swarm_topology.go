@@ -10,9 +10,12 @@ package dockerswarm
 import (
 	"errors"
 	"fmt"
+	"time"
+
 	"github.com/StackVista/stackstate-agent/pkg/aggregator"
 	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/docker"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/clustername"
@@ -25,10 +28,11 @@ const (
 	swarmServiceType       = "swarm-service"
 )
 
-// SwarmTopologyCollector contains the checkID and topology instance for the swarm topology check
+// SwarmTopologyCollector contains the checkID, topology instance and health stream for the swarm topology check
 type SwarmTopologyCollector struct {
 	corechecks.CheckTopologyCollector
-	swarmClient SwarmClient
+	swarmClient  SwarmClient
+	HealthStream health.Stream
 }
 
 // MakeSwarmTopologyCollector returns a new instance of SwarmTopologyCollector
@@ -43,24 +47,27 @@ func MakeSwarmTopologyCollector() *SwarmTopologyCollector {
 }
 
 func makeSwarmTopologyCollector(client SwarmClient) *SwarmTopologyCollector {
+	instance := topology.Instance{
+		Type: "docker-swarm",
+		URL:  "agents",
+	}
 	return &SwarmTopologyCollector{
-		corechecks.MakeCheckTopologyCollector(SwarmTopologyCheckName, topology.Instance{
-			Type: "docker-swarm",
-			URL:  "agents",
-		}),
-		client,
+		CheckTopologyCollector: corechecks.MakeCheckTopologyCollector(SwarmTopologyCheckName, instance),
+		swarmClient:            client,
+		HealthStream:           health.Stream{Urn: fmt.Sprintf("urn:health:%s:%s", instance.Type, instance.URL)},
 	}
 }
 
-// BuildSwarmTopology collects and produces all docker swarm topology
-func (dt *SwarmTopologyCollector) BuildSwarmTopology(hostname string, metrics aggregator.Sender) error {
+// BuildSwarmTopology collects and produces all docker swarm topology, when collectHealth is set the health of the swarm
+// services is submitted as a snapshot that is repeated every intervalSeconds
+func (dt *SwarmTopologyCollector) BuildSwarmTopology(hostname string, metrics aggregator.Sender, collectHealth bool, intervalSeconds int) error {
 	sender := batcher.GetBatcher()
 	if sender == nil {
 		return errors.New("no batcher instance available, skipping BuildSwarmTopology")
 	}
 
 	// collect all swarm services as topology components
-	swarmComponents, swarmRelations, err := dt.collectSwarmServices(hostname, metrics)
+	swarmComponents, swarmRelations, swarmCheckStates, err := dt.collectSwarmServices(hostname, metrics)
 	if err != nil {
 		return err
 	}
@@ -73,24 +80,40 @@ func (dt *SwarmTopologyCollector) BuildSwarmTopology(hostname string, metrics ag
 	for _, relation := range swarmRelations {
 		sender.SubmitRelation(dt.CheckID, dt.TopologyInstance, *relation)
 	}
+	// submit the health of all swarm services in a snapshot, the services that are gone lose their health
+	if collectHealth {
+		// the snapshot is sent per cluster, or per host when the cluster name is not set
+		stream := dt.HealthStream
+		stream.SubStream = clustername.GetClusterName()
+		if stream.SubStream == "" {
+			stream.SubStream = hostname
+		}
+		sender.SubmitHealthStartSnapshot(dt.CheckID, stream, intervalSeconds, 0)
+		for _, checkState := range swarmCheckStates {
+			sender.SubmitHealthCheckData(dt.CheckID, stream, checkState.CheckData())
+		}
+		sender.SubmitHealthStopSnapshot(dt.CheckID, stream)
+	}
 
 	sender.SubmitComplete(dt.CheckID)
 
 	return nil
 }
 
-// collectSwarmServices collects swarm services from the docker util and produces topology.Component
-func (dt *SwarmTopologyCollector) collectSwarmServices(hostname string, sender aggregator.Sender) ([]*topology.Component, []*topology.Relation, error) {
+// collectSwarmServices collects swarm services from the docker util and produces topology.Component and health.CheckState
+func (dt *SwarmTopologyCollector) collectSwarmServices(hostname string, sender aggregator.Sender) ([]*topology.Component, []*topology.Relation, []*health.CheckState, error) {
 
 	sList, err := dt.swarmClient.ListSwarmServices()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	clusterName := clustername.GetClusterName()
 	taskContainerComponents := make([]*topology.Component, 0)
 	swarmServiceComponents := make([]*topology.Component, 0)
 	swarmServiceRelations := make([]*topology.Relation, 0)
+	swarmServiceCheckStates := make([]*health.CheckState, 0)
+	now := time.Now()
 	for _, s := range sList {
 		tags := make([]string, 0)
 		// ------------ Create a component structure for Swarm Service
@@ -122,6 +145,7 @@ func (dt *SwarmTopologyCollector) collectSwarmServices(hostname string, sender a
 		}
 
 		swarmServiceComponents = append(swarmServiceComponents, swarmServiceComponent)
+		swarmServiceCheckStates = append(swarmServiceCheckStates, serviceCheckStates(sourceExternalID, s, now)...)
 
 		for _, taskContainer := range s.TaskContainers {
 			// ------------ Create a component structure for Swarm Task Container
@@ -164,5 +188,5 @@ func (dt *SwarmTopologyCollector) collectSwarmServices(hostname string, sender a
 	// Append TaskContainer components to same Service Component list
 	swarmServiceComponents = append(swarmServiceComponents, taskContainerComponents...)
 
-	return swarmServiceComponents, swarmServiceRelations, nil
+	return swarmServiceComponents, swarmServiceRelations, swarmServiceCheckStates, nil
 }
@@ -50,6 +50,27 @@ var (
 			"identifiers": []string{"urn:container:/mock-host:a95f48f7f58b9154afa074d541d1bff142611e3a800f78d6be423e82f8178406"},
 		},
 	}
+	serviceHealthStream = health.Stream{Urn: "urn:health:docker-swarm:agents", SubStream: "agent-swarm"}
+	expectedCheckStates = []*health.CheckState{
+		{
+			CheckStateID:              "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e:replicas-running",
+			Name:                      "Replicas Running",
+			Health:                    health.Clear,
+			TopologyElementIdentifier: "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e",
+		},
+		{
+			CheckStateID:              "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e:failed-tasks",
+			Name:                      "Failed Tasks",
+			Health:                    health.Clear,
+			TopologyElementIdentifier: "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e",
+		},
+		{
+			CheckStateID:              "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e:node-availability",
+			Name:                      "Node Availability",
+			Health:                    health.Clear,
+			TopologyElementIdentifier: "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e",
+		},
+	}
 	serviceRelation = &topology.Relation{
 		ExternalID: "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e->urn:container:/a95f48f7f58b9154afa074d541d1bff142611e3a800f78d6be423e82f8178406",
 		SourceID:   "urn:swarm-service:/klbo61rrhksdmc9ho3pq97t6e",
@@ -59,6 +80,14 @@ var (
 	}
 )
 
+func serviceCheckData() []health.CheckData {
+	checkData := make([]health.CheckData, 0, len(expectedCheckStates))
+	for _, checkState := range expectedCheckStates {
+		checkData = append(checkData, checkState.CheckData())
+	}
+	return checkData
+}
+
 func TestMakeSwarmTopologyCollector(t *testing.T) {
 	st := makeSwarmTopologyCollector(&MockSwarmClient{})
 	assert.Equal(t, check.ID("swarm_topology"), st.CheckID)
@@ -67,6 +96,7 @@ func TestMakeSwarmTopologyCollector(t *testing.T) {
 		URL:  "agents",
 	}
 	assert.Equal(t, expectedInstance, st.TopologyInstance)
+	assert.Equal(t, health.Stream{Urn: "urn:health:docker-swarm:agents"}, st.HealthStream)
 }
 
 func TestSwarmTopologyCollector_CollectSwarmServices(t *testing.T) {
@@ -83,7 +113,7 @@ func TestSwarmTopologyCollector_CollectSwarmServices(t *testing.T) {
 	// check for produced metrics
 	sender.On("Gauge", "swarm.service.running_replicas", 2.0, "", expectedTags).Return().Times(1)
 	sender.On("Gauge", "swarm.service.desired_replicas", 2.0, "", expectedTags).Return().Times(1)
-	comps, relations, err := st.collectSwarmServices(testHostname, sender)
+	comps, relations, checkStates, err := st.collectSwarmServices(testHostname, sender)
 
 	// list of swamr service components
 	serviceComponents := []*topology.Component{
@@ -105,6 +135,8 @@ func TestSwarmTopologyCollector_CollectSwarmServices(t *testing.T) {
 	assert.EqualValues(t, comps, serviceComponents)
 	// relations should be serviceRelations
 	assert.EqualValues(t, relations, serviceRelations)
+	// check states should be the healthy states of the service
+	assert.EqualValues(t, expectedCheckStates, checkStates)
 	// metrics assertion
 	sender.AssertExpectations(t)
 	sender.AssertNumberOfCalls(t, "Gauge", 2)
@@ -127,13 +159,20 @@ func TestSwarmTopologyCollector_BuildSwarmTopology(t *testing.T) {
 	sender.On("Gauge", "swarm.service.running_replicas", 2.0, "", expectedTags).Return().Times(1)
 	sender.On("Gauge", "swarm.service.desired_replicas", 2.0, "", expectedTags).Return().Times(1)
 
-	err := st.BuildSwarmTopology(testHostname, sender)
+	err := st.BuildSwarmTopology(testHostname, sender, true, 30)
 	assert.NoError(t, err)
 
 	producedTopology := mockBatcher.CollectedTopology.Flush()
 	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
 		"swarm_topology": {
-			Health: make(map[string]health.Health),
+			Health: map[string]health.Health{
+				serviceHealthStream.GoString(): {
+					StartSnapshot: &health.StartSnapshotMetadata{RepeatIntervalS: 30},
+					StopSnapshot:  &health.StopSnapshotMetadata{},
+					Stream:        serviceHealthStream,
+					CheckStates:   serviceCheckData(),
+				},
+			},
 			Topology: &topology.Topology{
 				StartSnapshot: false,
 				StopSnapshot:  false,
@@ -14,9 +14,12 @@ import (
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	core "github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
 	collectors "github.com/StackVista/stackstate-agent/pkg/collector/corechecks/cluster/topologycollectors"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
@@ -28,6 +31,15 @@ type TopologyCheck struct {
 	CommonCheck
 	instance  *TopologyConfig
 	submitter TopologySubmitter
+	// informerClient serves the collectors from the informer cache, it is created on the first run
+	informerClient  *apiserver.InformerCollectorClient
+	informersSynced bool
+	// changes is notified by the informers when a resource changes
+	changes  chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	// lastCollect is the time the last topology snapshot was collected
+	lastCollect time.Time
 }
 
 // Configure parses the check configuration and init the check.
@@ -85,6 +97,115 @@ func (t *TopologyCheck) Run() error {
 		return err
 	}
 
+	collectorClient, err := t.getCollectorClient()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !t.shouldCollect(now) {
+		return nil
+	}
+	t.lastCollect = now
+
+	return t.collectTopology(collectorClient)
+}
+
+// Interval returns the interval the check is scheduled at. When resource changes trigger topology snapshots the check
+// is scheduled every change interval, the runner then collects the snapshots so they never overlap
+func (t *TopologyCheck) Interval() time.Duration {
+	interval := t.CommonCheck.Interval()
+	if changeInterval := t.changeInterval(); changeInterval > 0 && interval > changeInterval {
+		return changeInterval
+	}
+	return interval
+}
+
+// changeInterval returns the interval in which resource changes are collected in a single snapshot, 0 when changes do
+// not trigger snapshots
+func (t *TopologyCheck) changeInterval() time.Duration {
+	if !t.instance.UseInformers || t.instance.ChangeInterval <= 0 {
+		return 0
+	}
+	return time.Duration(t.instance.ChangeInterval) * time.Second
+}
+
+// shouldCollect returns whether a run collects a topology snapshot. With change triggered snapshots a run only
+// collects when resources changed or the check interval passed since the last snapshot
+func (t *TopologyCheck) shouldCollect(now time.Time) bool {
+	changed := t.drainChanges()
+	if t.changeInterval() == 0 || t.lastCollect.IsZero() || changed {
+		return true
+	}
+	return now.Sub(t.lastCollect) >= t.CommonCheck.Interval()
+}
+
+// Stop stops the informers and the collection of topology snapshots on resource changes
+func (t *TopologyCheck) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+	})
+}
+
+// getCollectorClient returns the client that the collectors list the resources with. With informers the resources are
+// read from the informer cache, which is started and synced on the first run
+func (t *TopologyCheck) getCollectorClient() (apiserver.APICollectorClient, error) {
+	if !t.instance.UseInformers {
+		return t.ac, nil
+	}
+
+	if t.informerClient == nil {
+		t.informerClient = apiserver.NewInformerCollectorClient(t.ac.InformerFactory, t.ac.DynamicInformerFactory, t.ac.Cl.Discovery())
+		if t.instance.ChangeInterval > 0 {
+			t.informerClient.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { t.notifyChange() },
+				UpdateFunc: t.notifyUpdate,
+				DeleteFunc: func(obj interface{}) { t.notifyChange() },
+			})
+		}
+	}
+
+	if !t.informersSynced {
+		if err := t.informerClient.Start(t.stopCh); err != nil {
+			_ = t.Warnf("Could not sync the Kubernetes informers: %s", err)
+			return nil, err
+		}
+		t.informersSynced = true
+	}
+
+	return t.informerClient, nil
+}
+
+// notifyUpdate notifies a change unless the update is a periodic resync of an unchanged resource
+func (t *TopologyCheck) notifyUpdate(oldObj, newObj interface{}) {
+	oldMeta, oldErr := meta.Accessor(oldObj)
+	newMeta, newErr := meta.Accessor(newObj)
+	if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+		return
+	}
+	t.notifyChange()
+}
+
+// notifyChange marks that a resource changed, it does not block when a change is pending already
+func (t *TopologyCheck) notifyChange() {
+	select {
+	case t.changes <- struct{}{}:
+	default:
+	}
+}
+
+// drainChanges discards the pending change, returns whether a change was pending
+func (t *TopologyCheck) drainChanges() bool {
+	select {
+	case <-t.changes:
+		return true
+	default:
+		return false
+	}
+}
+
+// collectTopology runs the cluster collectors with the client and publishes their topology as a snapshot
+func (t *TopologyCheck) collectTopology(collectorClient apiserver.APICollectorClient) error {
 	// set the check "instance id" for snapshots
 	t.instance.CheckID = kubernetesAPITopologyCheckName
 
@@ -102,6 +223,9 @@ func (t *TopologyCheck) Run() error {
 
 	// start the topology snapshot with the batch-er
 	t.submitter.SubmitStartSnapshot()
+	if t.instance.CollectHealth {
+		t.submitter.SubmitHealthStartSnapshot(int(t.CommonCheck.Interval().Seconds()))
+	}
 
 	// create a wait group for all the collectors
 	var waitGroup sync.WaitGroup
@@ -114,10 +238,11 @@ func (t *TopologyCheck) Run() error {
 	// make a channel that is responsible for publishing components and relations
 	componentChannel := make(chan *topology.Component)
 	relationChannel := make(chan *topology.Relation)
+	healthChannel := make(chan *health.CheckState)
 	errChannel := make(chan error)
 	waitGroupChannel := make(chan bool)
 
-	clusterTopologyCommon := collectors.NewClusterTopologyCommon(t.instance.Instance, t.ac)
+	clusterTopologyCommon := collectors.NewClusterTopologyCommon(t.instance.Instance, collectorClient)
 	commonClusterCollector := collectors.NewClusterTopologyCollector(clusterTopologyCommon)
 	clusterCollectors := []collectors.ClusterTopologyCollector{
 		// Register Cluster Component Collector
@@ -129,6 +254,7 @@ func (t *TopologyCheck) Run() error {
 		collectors.NewNodeCollector(
 			componentChannel,
 			relationChannel,
+			healthChannel,
 			nodeIdentifierCorrelationChannel,
 			commonClusterCollector,
 		),
@@ -151,12 +277,14 @@ func (t *TopologyCheck) Run() error {
 		collectors.NewDaemonSetCollector(
 			componentChannel,
 			relationChannel,
+			healthChannel,
 			commonClusterCollector,
 		),
 		// Register Deployment Component Collector
 		collectors.NewDeploymentCollector(
 			componentChannel,
 			relationChannel,
+			healthChannel,
 			commonClusterCollector,
 		),
 		// Register ReplicaSet Component Collector
@@ -169,10 +297,18 @@ func (t *TopologyCheck) Run() error {
 		collectors.NewStatefulSetCollector(
 			componentChannel,
 			relationChannel,
+			healthChannel,
 			commonClusterCollector,
 		),
 		// Register Persistent Volume Component Collector
 		collectors.NewPersistentVolumeCollector(
+			componentChannel,
+			relationChannel,
+			healthChannel,
+			commonClusterCollector,
+		),
+		// Register Persistent Volume Claim Component Collector
+		collectors.NewPersistentVolumeClaimCollector(
 			componentChannel,
 			relationChannel,
 			commonClusterCollector,
@@ -181,6 +317,7 @@ func (t *TopologyCheck) Run() error {
 		collectors.NewPodCollector(
 			componentChannel,
 			relationChannel,
+			healthChannel,
 			containerCorrelationChannel,
 			volumeCorrelationChannel,
 			commonClusterCollector,
@@ -189,6 +326,7 @@ func (t *TopologyCheck) Run() error {
 		collectors.NewServiceCollector(
 			componentChannel,
 			relationChannel,
+			t.instance.ClusterDomain,
 			commonClusterCollector,
 		),
 		// Register Ingress Component Collector
@@ -209,6 +347,37 @@ func (t *TopologyCheck) Run() error {
 			relationChannel,
 			commonClusterCollector,
 		),
+		// Register HorizontalPodAutoscaler Component Collector
+		collectors.NewHorizontalPodAutoscalerCollector(
+			componentChannel,
+			relationChannel,
+			commonClusterCollector,
+		),
+		// Register NetworkPolicy Component Collector
+		collectors.NewNetworkPolicyCollector(
+			componentChannel,
+			relationChannel,
+			commonClusterCollector,
+		),
+		// Register PodDisruptionBudget Component Collector
+		collectors.NewPodDisruptionBudgetCollector(
+			componentChannel,
+			relationChannel,
+			commonClusterCollector,
+		),
+		// Register StorageClass Component Collector
+		collectors.NewStorageClassCollector(
+			componentChannel,
+			relationChannel,
+			commonClusterCollector,
+		),
+		// Register Custom Resource Component Collector
+		collectors.NewCustomResourceCollector(
+			componentChannel,
+			relationChannel,
+			t.instance.CustomResources,
+			commonClusterCollector,
+		),
 	}
 
 	commonClusterCorrelator := collectors.NewClusterTopologyCorrelator(clusterTopologyCommon)
@@ -224,6 +393,7 @@ func (t *TopologyCheck) Run() error {
 		collectors.NewVolumeCorrelator(
 			componentChannel,
 			relationChannel,
+			healthChannel,
 			volumeCorrelationChannel,
 			commonClusterCorrelator,
 		),
@@ -233,15 +403,19 @@ func (t *TopologyCheck) Run() error {
 	t.RunClusterCollectors(clusterCollectors, clusterCorrelators, &waitGroup, errChannel)
 
 	// receive all the components, will return once the wait group notifies
-	t.WaitForTopology(componentChannel, relationChannel, errChannel, &waitGroup, waitGroupChannel)
+	t.WaitForTopology(componentChannel, relationChannel, healthChannel, errChannel, &waitGroup, waitGroupChannel)
 
 	t.submitter.SubmitStopSnapshot()
+	if t.instance.CollectHealth {
+		t.submitter.SubmitHealthStopSnapshot()
+	}
 	t.submitter.SubmitComplete()
 
 	log.Infof("Topology Check for cluster: %s completed successfully", t.instance.ClusterName)
 	// close all the created channels
 	close(componentChannel)
 	close(relationChannel)
+	close(healthChannel)
 	close(errChannel)
 	close(waitGroupChannel)
 
@@ -250,7 +424,7 @@ func (t *TopologyCheck) Run() error {
 
 // sets up the receiver that handles the component and relation channel and publishes it to StackState, returns when all the collectors have finished or the timeout was reached.
 func (t *TopologyCheck) WaitForTopology(componentChannel <-chan *topology.Component, relationChannel <-chan *topology.Relation,
-	errorChannel <-chan error, waitGroup *sync.WaitGroup, waitGroupChannel chan bool) {
+	healthChannel <-chan *health.CheckState, errorChannel <-chan error, waitGroup *sync.WaitGroup, waitGroupChannel chan bool) {
 	log.Debugf("Waiting for Cluster Collectors to Finish")
 	go func() {
 	loop:
@@ -260,6 +434,10 @@ func (t *TopologyCheck) WaitForTopology(componentChannel <-chan *topology.Compon
 				t.submitter.SubmitComponent(component)
 			case relation := <-relationChannel:
 				t.submitter.SubmitRelation(relation)
+			case checkState := <-healthChannel:
+				if t.instance.CollectHealth {
+					t.submitter.SubmitHealthCheckData(checkState)
+				}
 			case err := <-errorChannel:
 				t.submitter.HandleError(err)
 			case timedOut := <-waitGroupChannel:
@@ -344,6 +522,8 @@ func KubernetesAPITopologyFactory() check.Check {
 			CheckBase: core.NewCheckBase(kubernetesAPITopologyCheckName),
 		},
 		instance: &TopologyConfig{},
+		changes:  make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
 	}
 }
 
@@ -3,9 +3,13 @@
 package kubeapi
 
 import (
+	"fmt"
+
 	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	collectors "github.com/StackVista/stackstate-agent/pkg/collector/corechecks/cluster/topologycollectors"
 	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"gopkg.in/yaml.v2"
@@ -25,7 +29,13 @@ const (
 type TopologyConfig struct {
 	ClusterName     string `yaml:"cluster_name"`
 	CollectTopology bool   `yaml:"collect_topology"`
+	CollectHealth   bool   `yaml:"collect_health"`
 	CollectTimeout  int    `yaml:"collect_timeout"`
+	UseInformers    bool   `yaml:"use_informers"`
+	ChangeInterval  int    `yaml:"change_interval"`
+	ClusterDomain   string `yaml:"cluster_domain"`
+	// CustomResources are the custom resources that are collected as components
+	CustomResources []collectors.CustomResourceMapping `yaml:"custom_resources"`
 	CheckID         check.ID
 	Instance        topology.Instance
 }
@@ -34,7 +44,11 @@ func (c *TopologyConfig) parse(data []byte) error {
 	// default values
 	c.ClusterName = config.Datadog.GetString("cluster_name")
 	c.CollectTopology = config.Datadog.GetBool("collect_kubernetes_topology")
+	c.CollectHealth = config.Datadog.GetBool("collect_kubernetes_health")
 	c.CollectTimeout = config.Datadog.GetInt("collect_kubernetes_timeout")
+	c.UseInformers = config.Datadog.GetBool("kubernetes_topology_use_informers")
+	c.ChangeInterval = config.Datadog.GetInt("kubernetes_topology_change_interval")
+	c.ClusterDomain = config.Datadog.GetString("kubernetes_cluster_domain")
 
 	return yaml.Unmarshal(data, c)
 }
@@ -46,21 +60,26 @@ type TopologySubmitter interface {
 	SubmitComplete()
 	SubmitComponent(component *topology.Component)
 	SubmitRelation(relation *topology.Relation)
+	SubmitHealthStartSnapshot(intervalSeconds int)
+	SubmitHealthStopSnapshot()
+	SubmitHealthCheckData(checkState *health.CheckState)
 	HandleError(err error)
 }
 
 // NewBatchTopologySubmitter creates a new instance of BatchTopologySubmitter
 func NewBatchTopologySubmitter(checkID check.ID, instance topology.Instance) TopologySubmitter {
 	return &BatchTopologySubmitter{
-		CheckID:  checkID,
-		Instance: instance,
+		CheckID:      checkID,
+		Instance:     instance,
+		HealthStream: health.Stream{Urn: fmt.Sprintf("urn:health:%s:%s", instance.Type, instance.URL)},
 	}
 }
 
 // BatchTopologySubmitter provides functionality to submit topology data with the Batcher.
 type BatchTopologySubmitter struct {
-	CheckID  check.ID
-	Instance topology.Instance
+	CheckID      check.ID
+	Instance     topology.Instance
+	HealthStream health.Stream
 }
 
 // SubmitStartSnapshot submits the start for this Check ID and instance
@@ -90,6 +109,22 @@ func (b *BatchTopologySubmitter) SubmitRelation(relation *topology.Relation) {
 	batcher.GetBatcher().SubmitRelation(b.CheckID, b.Instance, *relation)
 }
 
+// SubmitHealthStartSnapshot submits the start of a health snapshot for this Check ID
+func (b *BatchTopologySubmitter) SubmitHealthStartSnapshot(intervalSeconds int) {
+	batcher.GetBatcher().SubmitHealthStartSnapshot(b.CheckID, b.HealthStream, intervalSeconds, 0)
+}
+
+// SubmitHealthStopSnapshot submits the stop of a health snapshot for this Check ID
+func (b *BatchTopologySubmitter) SubmitHealthStopSnapshot() {
+	batcher.GetBatcher().SubmitHealthStopSnapshot(b.CheckID, b.HealthStream)
+}
+
+// SubmitHealthCheckData takes a health check state and submits it with the Batcher
+func (b *BatchTopologySubmitter) SubmitHealthCheckData(checkState *health.CheckState) {
+	log.Debugf("Publishing StackState %s health state for %s: %s", checkState.Health, checkState.TopologyElementIdentifier, checkState.Message)
+	batcher.GetBatcher().SubmitHealthCheckData(b.CheckID, b.HealthStream, checkState.CheckData())
+}
+
 // HandleError handles any errors during topology gathering
 func (b *BatchTopologySubmitter) HandleError(err error) {
 	_ = log.Errorf("Error occurred in during topology collection: %s", err.Error())
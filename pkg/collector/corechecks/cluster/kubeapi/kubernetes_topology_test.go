@@ -10,12 +10,16 @@ import (
 	"fmt"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	collectors "github.com/StackVista/stackstate-agent/pkg/collector/corechecks/cluster/topologycollectors"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 )
 
 var componentID int
@@ -35,6 +39,7 @@ func TestRunClusterCollectors(t *testing.T) {
 	var waitGroup sync.WaitGroup
 	componentChannel := make(chan *topology.Component)
 	relationChannel := make(chan *topology.Relation)
+	healthChannel := make(chan *health.CheckState)
 	errChannel := make(chan error)
 	waitGroupChannel := make(chan bool)
 
@@ -51,14 +56,58 @@ func TestRunClusterCollectors(t *testing.T) {
 	kubernetesTopologyCheck.RunClusterCollectors(clusterCollectors, clusterCorrelators, &waitGroup, errChannel)
 
 	// receive all the components, will return once the wait group notifies
-	kubernetesTopologyCheck.WaitForTopology(componentChannel, relationChannel, errChannel, &waitGroup, waitGroupChannel)
+	kubernetesTopologyCheck.WaitForTopology(componentChannel, relationChannel, healthChannel, errChannel, &waitGroup, waitGroupChannel)
 
 	close(componentChannel)
 	close(relationChannel)
+	close(healthChannel)
 	close(errChannel)
 	close(waitGroupChannel)
 }
 
+func TestTopologyCheckNotifyChanges(t *testing.T) {
+	kubernetesTopologyCheck := KubernetesAPITopologyFactory().(*TopologyCheck)
+	pod := func(resourceVersion string) *coreV1.Pod {
+		return &coreV1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: "pod", ResourceVersion: resourceVersion}}
+	}
+
+	// a resync of an unchanged resource is not a change
+	kubernetesTopologyCheck.notifyUpdate(pod("1"), pod("1"))
+	assert.Len(t, kubernetesTopologyCheck.changes, 0)
+
+	// multiple changes result in a single pending change
+	kubernetesTopologyCheck.notifyUpdate(pod("1"), pod("2"))
+	kubernetesTopologyCheck.notifyChange()
+	assert.Len(t, kubernetesTopologyCheck.changes, 1)
+
+	kubernetesTopologyCheck.drainChanges()
+	assert.Len(t, kubernetesTopologyCheck.changes, 0)
+}
+
+func TestTopologyCheckChangeTriggeredSnapshots(t *testing.T) {
+	kubernetesTopologyCheck := KubernetesAPITopologyFactory().(*TopologyCheck)
+	assert.NoError(t, kubernetesTopologyCheck.CommonConfigure([]byte("min_collection_interval: 60"), "test"))
+	now := time.Now()
+
+	// without informers every run collects the topology
+	kubernetesTopologyCheck.instance.ChangeInterval = 5
+	assert.Equal(t, 60*time.Second, kubernetesTopologyCheck.Interval())
+	kubernetesTopologyCheck.lastCollect = now
+	assert.True(t, kubernetesTopologyCheck.shouldCollect(now))
+
+	// with change triggered snapshots the check runs every change interval, but only collects on changes
+	kubernetesTopologyCheck.instance.UseInformers = true
+	assert.Equal(t, 5*time.Second, kubernetesTopologyCheck.Interval())
+	assert.False(t, kubernetesTopologyCheck.shouldCollect(now.Add(5*time.Second)))
+
+	kubernetesTopologyCheck.notifyChange()
+	assert.True(t, kubernetesTopologyCheck.shouldCollect(now.Add(10*time.Second)))
+	assert.False(t, kubernetesTopologyCheck.shouldCollect(now.Add(15*time.Second)))
+
+	// and on the check interval
+	assert.True(t, kubernetesTopologyCheck.shouldCollect(now.Add(60*time.Second)))
+}
+
 // NewTestTopologySubmitter creates a new instance of TestTopologySubmitter
 func NewTestTopologySubmitter(t *testing.T, checkID check.ID, instance topology.Instance) TopologySubmitter {
 	return &TestTopologySubmitter{
@@ -79,6 +128,10 @@ func (b *TestTopologySubmitter) SubmitStartSnapshot() {}
 func (b *TestTopologySubmitter) SubmitStopSnapshot()  {}
 func (b *TestTopologySubmitter) SubmitComplete()      {}
 
+func (b *TestTopologySubmitter) SubmitHealthStartSnapshot(intervalSeconds int)       {}
+func (b *TestTopologySubmitter) SubmitHealthStopSnapshot()                           {}
+func (b *TestTopologySubmitter) SubmitHealthCheckData(checkState *health.CheckState) {}
+
 // SubmitRelation takes a component and submits it with the Batcher
 func (b *TestTopologySubmitter) SubmitComponent(component *topology.Component) {
 	// match the component with the count number that represents the ExternalID
@@ -7,6 +7,7 @@ import (
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1B1 "k8s.io/api/batch/v1beta1"
 	coreV1 "k8s.io/api/core/v1"
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
 	extensionsV1B1 "k8s.io/api/extensions/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -606,6 +607,10 @@ func (m MockBenchmarkAPICollectorClient) GetServices() ([]coreV1.Service, error)
 	return services, nil
 }
 
+func (m MockBenchmarkAPICollectorClient) GetEndpointSlices() ([]discoveryV1A1.EndpointSlice, error) {
+	return nil, apiserver.ErrEndpointSlicesNotServed
+}
+
 func (m MockBenchmarkAPICollectorClient) GetEndpoints() ([]coreV1.Endpoints, error) {
 	endpoints := make([]coreV1.Endpoints, 0)
 	// endpoints for test case 1
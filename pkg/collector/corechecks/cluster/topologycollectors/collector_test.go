@@ -8,9 +8,11 @@ package topologycollectors
 
 import (
 	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
 )
 
 func TestCollectorInterface(t *testing.T) {
@@ -81,6 +83,10 @@ func TestCollectorInterface(t *testing.T) {
 	actualPersistentVolumeExternalID := testCollector.buildPersistentVolumeExternalID(persistentVolumeName)
 	assert.Equal(t, "urn:kubernetes:/Test-Cluster-Name:persistent-volume/test-persistent-volume", actualPersistentVolumeExternalID)
 
+	persistentVolumeClaimName := "test-persistent-volume-claim"
+	actualPersistentVolumeClaimExternalID := testCollector.buildPersistentVolumeClaimExternalID(testNameSpace, persistentVolumeClaimName)
+	assert.Equal(t, "urn:kubernetes:/Test-Cluster-Name:test-namespace:persistent-volume-claim/test-persistent-volume-claim", actualPersistentVolumeClaimExternalID)
+
 	endpointName := "test-url"
 	actualEndpointExternalID := testCollector.buildEndpointExternalID(endpointName)
 	assert.Equal(t, "urn:endpoint:/Test-Cluster-Name:test-url", actualEndpointExternalID)
@@ -101,6 +107,33 @@ func TestCollectorInterface(t *testing.T) {
 	assert.Equal(t, instance.Type, actualCollectorInstanceType)
 }
 
+func TestCollectorsShareThePods(t *testing.T) {
+	client := &countingPodsAPICollectorClient{}
+	commonClusterCollector := NewTestCommonClusterCollector(client)
+
+	for _, collector := range []ClusterTopologyCollector{
+		NewPodCollector(nil, nil, nil, nil, nil, commonClusterCollector),
+		NewNetworkPolicyCollector(nil, nil, commonClusterCollector),
+		NewPodDisruptionBudgetCollector(nil, nil, commonClusterCollector),
+	} {
+		pods, err := collector.getPods()
+		assert.NoError(t, err)
+		assert.Len(t, pods, 1)
+	}
+
+	assert.Equal(t, 1, client.podListings)
+}
+
+type countingPodsAPICollectorClient struct {
+	apiserver.APICollectorClient
+	podListings int
+}
+
+func (c *countingPodsAPICollectorClient) GetPods() ([]coreV1.Pod, error) {
+	c.podListings++
+	return []coreV1.Pod{{}}, nil
+}
+
 // TestCollector implements the ClusterTopologyCollector interface.
 type TestCollector struct {
 	ClusterTopologyCollector
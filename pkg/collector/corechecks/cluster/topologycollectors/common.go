@@ -4,10 +4,14 @@ package topologycollectors
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks/cluster/urn"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -17,8 +21,10 @@ type ClusterTopologyCommon interface {
 	GetInstance() topology.Instance
 	GetName() string
 	GetURNBuilder() urn.Builder
+	getPods() ([]v1.Pod, error)
 	CreateRelation(sourceExternalID, targetExternalID, typeName string) *topology.Relation
 	CreateRelationData(sourceExternalID, targetExternalID, typeName string, data map[string]interface{}) *topology.Relation
+	CreateCheckState(externalID, checkName string, state health.State, message string) *health.CheckState
 	initTags(meta metav1.ObjectMeta) map[string]string
 	buildClusterExternalID() string
 	buildConfigMapExternalID(namespace, configMapName string) string
@@ -37,13 +43,23 @@ type ClusterTopologyCommon interface {
 	buildIngressExternalID(namespace, ingressName string) string
 	buildVolumeExternalID(namespace, volumeName string) string
 	buildPersistentVolumeExternalID(persistentVolumeName string) string
+	buildPersistentVolumeClaimExternalID(namespace, persistentVolumeClaimName string) string
 	buildEndpointExternalID(endpointID string) string
+	buildHorizontalPodAutoscalerExternalID(namespace, horizontalPodAutoscalerName string) string
+	buildNetworkPolicyExternalID(namespace, networkPolicyName string) string
+	buildPodDisruptionBudgetExternalID(namespace, podDisruptionBudgetName string) string
+	buildStorageClassExternalID(storageClassName string) string
+	buildCustomResourceExternalID(group, kind, namespace, customResourceName string) string
 }
 
 type clusterTopologyCommon struct {
 	Instance           topology.Instance
 	APICollectorClient apiserver.APICollectorClient
 	urn                urn.Builder
+	// the pods are listed once per collection, the collectors that relate to pods share the list
+	podsOnce sync.Once
+	pods     []v1.Pod
+	podsErr  error
 }
 
 // NewClusterTopologyCommon creates a clusterTopologyCommon
@@ -75,6 +91,14 @@ func (c *clusterTopologyCommon) GetURNBuilder() urn.Builder {
 	return c.urn
 }
 
+// getPods lists the pods of the cluster on the first call, later calls return the same list
+func (c *clusterTopologyCommon) getPods() ([]v1.Pod, error) {
+	c.podsOnce.Do(func() {
+		c.pods, c.podsErr = c.APICollectorClient.GetPods()
+	})
+	return c.pods, c.podsErr
+}
+
 // CreateRelationData creates a StackState relation called typeName for the given sourceExternalID and targetExternalID
 func (c *clusterTopologyCommon) CreateRelationData(sourceExternalID, targetExternalID, typeName string, data map[string]interface{}) *topology.Relation {
 	var _data map[string]interface{}
@@ -105,6 +129,17 @@ func (c *clusterTopologyCommon) CreateRelation(sourceExternalID, targetExternalI
 	}
 }
 
+// CreateCheckState creates a health check state called checkName for the component with the given externalID
+func (c *clusterTopologyCommon) CreateCheckState(externalID, checkName string, state health.State, message string) *health.CheckState {
+	return &health.CheckState{
+		CheckStateID:              fmt.Sprintf("%s:%s", externalID, strings.ToLower(strings.ReplaceAll(checkName, " ", "-"))),
+		Name:                      checkName,
+		Health:                    state,
+		TopologyElementIdentifier: externalID,
+		Message:                   message,
+	}
+}
+
 // buildClusterExternalID
 func (c *clusterTopologyCommon) buildClusterExternalID() string {
 	return c.urn.BuildClusterExternalID()
@@ -190,12 +225,42 @@ func (c *clusterTopologyCommon) buildPersistentVolumeExternalID(persistentVolume
 	return c.urn.BuildPersistentVolumeExternalID(persistentVolumeName)
 }
 
+// buildPersistentVolumeClaimExternalID creates the urn external identifier for a cluster persistent volume claim
+func (c *clusterTopologyCommon) buildPersistentVolumeClaimExternalID(namespace, persistentVolumeClaimName string) string {
+	return c.urn.BuildPersistentVolumeClaimExternalID(namespace, persistentVolumeClaimName)
+}
+
 // buildEndpointExternalID
 // endpointID
 func (c *clusterTopologyCommon) buildEndpointExternalID(endpointID string) string {
 	return c.urn.BuildEndpointExternalID(endpointID)
 }
 
+// buildHorizontalPodAutoscalerExternalID creates the urn external identifier for a cluster horizontal pod autoscaler
+func (c *clusterTopologyCommon) buildHorizontalPodAutoscalerExternalID(namespace, horizontalPodAutoscalerName string) string {
+	return c.urn.BuildHorizontalPodAutoscalerExternalID(namespace, horizontalPodAutoscalerName)
+}
+
+// buildNetworkPolicyExternalID creates the urn external identifier for a cluster network policy
+func (c *clusterTopologyCommon) buildNetworkPolicyExternalID(namespace, networkPolicyName string) string {
+	return c.urn.BuildNetworkPolicyExternalID(namespace, networkPolicyName)
+}
+
+// buildPodDisruptionBudgetExternalID creates the urn external identifier for a cluster pod disruption budget
+func (c *clusterTopologyCommon) buildPodDisruptionBudgetExternalID(namespace, podDisruptionBudgetName string) string {
+	return c.urn.BuildPodDisruptionBudgetExternalID(namespace, podDisruptionBudgetName)
+}
+
+// buildStorageClassExternalID creates the urn external identifier for a cluster storage class
+func (c *clusterTopologyCommon) buildStorageClassExternalID(storageClassName string) string {
+	return c.urn.BuildStorageClassExternalID(storageClassName)
+}
+
+// buildCustomResourceExternalID creates the urn external identifier for a cluster custom resource
+func (c *clusterTopologyCommon) buildCustomResourceExternalID(group, kind, namespace, customResourceName string) string {
+	return c.urn.BuildCustomResourceExternalID(group, kind, namespace, customResourceName)
+}
+
 func (c *clusterTopologyCommon) initTags(meta metav1.ObjectMeta) map[string]string {
 	tags := make(map[string]string, 0)
 	if meta.Labels != nil {
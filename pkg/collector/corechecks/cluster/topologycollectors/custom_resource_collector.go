@@ -0,0 +1,307 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CustomResourceMapping declares how the custom resources of a GroupVersionResource are mapped to StackState
+// components. The component type, name, labels and relation targets are templates in which `${field.path}` is replaced
+// by the value of the field of the custom resource, e.g. `${spec.secretName}` or `${metadata.labels[app.kubernetes.io/name]}`
+// for keys that contain dots.
+type CustomResourceMapping struct {
+	Group         string                          `yaml:"group"`
+	Version       string                          `yaml:"version"`
+	Resource      string                          `yaml:"resource"`
+	ComponentType string                          `yaml:"component_type"`
+	Name          string                          `yaml:"name"`
+	Labels        map[string]string               `yaml:"labels"`
+	Relations     []CustomResourceRelationMapping `yaml:"relations"`
+}
+
+// CustomResourceRelationMapping declares a relation of a custom resource. With owner_references the owners of the custom
+// resource are related to it, otherwise the custom resource is related to the resource of target_kind that is named by
+// the target_name template. The target is in the namespace of the custom resource, unless the target_namespace template
+// is set or the target is cluster scoped.
+type CustomResourceRelationMapping struct {
+	Type                string `yaml:"type"`
+	OwnerReferences     bool   `yaml:"owner_references"`
+	TargetAPIVersion    string `yaml:"target_api_version"`
+	TargetKind          string `yaml:"target_kind"`
+	TargetName          string `yaml:"target_name"`
+	TargetNamespace     string `yaml:"target_namespace"`
+	TargetClusterScoped bool   `yaml:"target_cluster_scoped"`
+}
+
+// GroupVersionResource returns the GroupVersionResource of the custom resources of the mapping
+func (m CustomResourceMapping) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: m.Group, Version: m.Version, Resource: m.Resource}
+}
+
+// CustomResourceCollector implements the ClusterTopologyCollector interface.
+type CustomResourceCollector struct {
+	ComponentChan chan<- *topology.Component
+	RelationChan  chan<- *topology.Relation
+	Mappings      []CustomResourceMapping
+	ClusterTopologyCollector
+}
+
+// NewCustomResourceCollector
+func NewCustomResourceCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, mappings []CustomResourceMapping, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	return &CustomResourceCollector{
+		ComponentChan:            componentChannel,
+		RelationChan:             relationChannel,
+		Mappings:                 mappings,
+		ClusterTopologyCollector: clusterTopologyCollector,
+	}
+}
+
+// GetName returns the name of the Collector
+func (*CustomResourceCollector) GetName() string {
+	return "CustomResource Collector"
+}
+
+// Collects and Published the Custom Resource Components of the mappings. A custom resource that can not be listed, e.g.
+// because its CustomResourceDefinition is not installed in the cluster, does not stop the collection of the others.
+func (crc *CustomResourceCollector) CollectorFunction() error {
+	for _, mapping := range crc.Mappings {
+		gvr := mapping.GroupVersionResource()
+		if gvr.Version == "" || gvr.Resource == "" {
+			_ = log.Warnf("Skipping custom resource mapping '%s', the version and resource are required", gvr.String())
+			continue
+		}
+
+		customResources, err := crc.GetAPIClient().GetCustomResources(gvr)
+		if err != nil {
+			_ = log.Warnf("Could not collect the custom resources '%s': %s", gvr.String(), err)
+			continue
+		}
+
+		for _, cr := range customResources {
+			component, ok := crc.customResourceToStackStateComponent(mapping, cr)
+			if !ok {
+				continue
+			}
+			crc.ComponentChan <- component
+
+			if cr.GetNamespace() != "" {
+				crc.RelationChan <- crc.namespaceToCustomResourceStackStateRelation(crc.buildNamespaceExternalID(cr.GetNamespace()), component.ExternalID)
+			}
+
+			for _, relation := range mapping.Relations {
+				for _, r := range crc.customResourceStackStateRelations(relation, cr, component.ExternalID) {
+					crc.RelationChan <- r
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Creates a StackState component from a Kubernetes / OpenShift Cluster custom resource, the custom resource is skipped
+// when its name template can not be expanded
+func (crc *CustomResourceCollector) customResourceToStackStateComponent(mapping CustomResourceMapping, cr unstructured.Unstructured) (*topology.Component, bool) {
+	log.Tracef("Mapping custom resource to StackState component: %s/%s", cr.GetKind(), cr.GetName())
+
+	nameTemplate := mapping.Name
+	if nameTemplate == "" {
+		nameTemplate = "${metadata.name}"
+	}
+	name, ok := expandFieldTemplate(nameTemplate, cr.Object)
+	if !ok || name == "" {
+		_ = log.Warnf("Skipping custom resource %s/%s, its name template '%s' can not be expanded", cr.GetKind(), cr.GetName(), nameTemplate)
+		return nil, false
+	}
+
+	componentType := strings.ToLower(cr.GetKind())
+	if mapping.ComponentType != "" {
+		if componentType, ok = expandFieldTemplate(mapping.ComponentType, cr.Object); !ok || componentType == "" {
+			_ = log.Warnf("Skipping custom resource %s/%s, its component type template '%s' can not be expanded", cr.GetKind(), cr.GetName(), mapping.ComponentType)
+			return nil, false
+		}
+	}
+
+	tags := crc.initTags(metav1.ObjectMeta{Namespace: cr.GetNamespace(), Labels: cr.GetLabels()})
+	for label, template := range mapping.Labels {
+		if value, ok := expandFieldTemplate(template, cr.Object); ok {
+			tags[label] = value
+		}
+	}
+
+	customResourceExternalID := crc.buildCustomResourceExternalID(cr.GroupVersionKind().Group, cr.GetKind(), cr.GetNamespace(), cr.GetName())
+	component := &topology.Component{
+		ExternalID: customResourceExternalID,
+		Type:       topology.Type{Name: componentType},
+		Data: map[string]interface{}{
+			"name":              name,
+			"creationTimestamp": cr.GetCreationTimestamp(),
+			"tags":              tags,
+			"uid":               cr.GetUID(),
+			"kind":              cr.GetKind(),
+			"apiVersion":        cr.GetAPIVersion(),
+		},
+	}
+
+	component.Data.PutNonEmpty("generateName", cr.GetGenerateName())
+
+	log.Tracef("Created StackState custom resource component %s: %v", customResourceExternalID, component.JSONString())
+
+	return component, true
+}
+
+// Creates the StackState relations of a relation mapping of a Kubernetes / OpenShift custom resource. Relations whose
+// target can not be expanded are skipped
+func (crc *CustomResourceCollector) customResourceStackStateRelations(mapping CustomResourceRelationMapping, cr unstructured.Unstructured, customResourceExternalID string) []*topology.Relation {
+	relations := make([]*topology.Relation, 0)
+
+	if mapping.OwnerReferences {
+		relationType := mapping.Type
+		if relationType == "" {
+			relationType = "controls"
+		}
+		for _, owner := range cr.GetOwnerReferences() {
+			ownerExternalID := crc.resourceExternalID(owner.APIVersion, owner.Kind, cr.GetNamespace(), owner.Name)
+			relations = append(relations, crc.customResourceStackStateRelation(ownerExternalID, customResourceExternalID, relationType))
+		}
+		return relations
+	}
+
+	if mapping.TargetKind == "" || mapping.TargetName == "" || mapping.Type == "" {
+		_ = log.Warnf("Skipping relation of custom resource %s/%s, the type, target_kind and target_name are required", cr.GetKind(), cr.GetName())
+		return relations
+	}
+
+	targetName, ok := expandFieldTemplate(mapping.TargetName, cr.Object)
+	if !ok || targetName == "" {
+		log.Debugf("Skipping %s relation of custom resource %s/%s, its target name '%s' can not be expanded", mapping.Type, cr.GetKind(), cr.GetName(), mapping.TargetName)
+		return relations
+	}
+	targetNamespace := cr.GetNamespace()
+	if mapping.TargetClusterScoped {
+		targetNamespace = ""
+	} else if mapping.TargetNamespace != "" {
+		if targetNamespace, ok = expandFieldTemplate(mapping.TargetNamespace, cr.Object); !ok {
+			log.Debugf("Skipping %s relation of custom resource %s/%s, its target namespace '%s' can not be expanded", mapping.Type, cr.GetKind(), cr.GetName(), mapping.TargetNamespace)
+			return relations
+		}
+	}
+
+	targetExternalID := crc.resourceExternalID(mapping.TargetAPIVersion, mapping.TargetKind, targetNamespace, targetName)
+	return append(relations, crc.customResourceStackStateRelation(customResourceExternalID, targetExternalID, mapping.Type))
+}
+
+// resourceExternalID returns the external identifier of a built-in resource, or else of a custom resource
+func (crc *CustomResourceCollector) resourceExternalID(apiVersion, kind, namespace, name string) string {
+	group := ""
+	if gv, err := schema.ParseGroupVersion(apiVersion); err == nil {
+		group = gv.Group
+	}
+
+	// the built-in api groups are either not qualified, e.g. apps or batch, or belong to k8s.io
+	if !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io") {
+		if externalID, err := crc.GetURNBuilder().BuildExternalID(kind, namespace, name); err == nil {
+			return externalID
+		}
+	}
+
+	return crc.buildCustomResourceExternalID(group, kind, namespace, name)
+}
+
+// Creates a StackState relation from or to a Kubernetes / OpenShift custom resource
+func (crc *CustomResourceCollector) customResourceStackStateRelation(sourceExternalID, targetExternalID, relationType string) *topology.Relation {
+	log.Tracef("Mapping kubernetes custom resource relation: %s -> %s", sourceExternalID, targetExternalID)
+
+	relation := crc.CreateRelation(sourceExternalID, targetExternalID, relationType)
+
+	log.Tracef("Created StackState custom resource relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift Namespace to a custom resource
+func (crc *CustomResourceCollector) namespaceToCustomResourceStackStateRelation(namespaceExternalID, customResourceExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes namespace to custom resource relation: %s -> %s", namespaceExternalID, customResourceExternalID)
+
+	relation := crc.CreateRelation(namespaceExternalID, customResourceExternalID, "encloses")
+
+	log.Tracef("Created StackState namespace -> custom resource relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
+
+// expandFieldTemplate replaces the `${field.path}` references of the template with the values of the fields of the
+// object, it is not expanded when a field is missing or is not a scalar value
+func expandFieldTemplate(template string, object map[string]interface{}) (string, bool) {
+	var expanded strings.Builder
+	for {
+		start := strings.Index(template, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(template[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+
+		value, found, err := unstructured.NestedFieldNoCopy(object, fieldPath(template[start+2:end])...)
+		if err != nil || !found {
+			return "", false
+		}
+		switch value.(type) {
+		case string, bool, int64, float64:
+		default:
+			return "", false
+		}
+
+		expanded.WriteString(template[:start])
+		expanded.WriteString(fmt.Sprint(value))
+		template = template[end+1:]
+	}
+	expanded.WriteString(template)
+
+	return expanded.String(), true
+}
+
+// fieldPath splits a field path on dots, a `[key]` segment is taken as is so map keys can contain dots
+func fieldPath(path string) []string {
+	fields := make([]string, 0)
+	var field strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '.':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				field.WriteString(path[i:])
+				i = len(path)
+				continue
+			}
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			fields = append(fields, path[i+1:i+end])
+			i += end
+		default:
+			field.WriteByte(path[i])
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
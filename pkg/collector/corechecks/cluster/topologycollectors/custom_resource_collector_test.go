@@ -0,0 +1,193 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCustomResourceCollector(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour).Truncate(time.Second)}
+
+	mappings := []CustomResourceMapping{
+		// the version and resource are required
+		{Group: "cert-manager.io", Resource: "certificates"},
+		// custom resources that can not be listed are skipped
+		{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"},
+		{
+			Group:    "cert-manager.io",
+			Version:  "v1",
+			Resource: "certificates",
+			Name:     "${spec.commonName}",
+			Labels:   map[string]string{"issuer": "${spec.issuerRef.name}", "app": "${metadata.labels[app.kubernetes.io/name]}", "missing": "${spec.missing}"},
+			Relations: []CustomResourceRelationMapping{
+				{OwnerReferences: true},
+				{Type: "uses", TargetKind: "Secret", TargetName: "${spec.secretName}"},
+				{Type: "issued_by", TargetAPIVersion: "cert-manager.io/v1", TargetKind: "ClusterIssuer", TargetName: "${spec.issuerRef.name}", TargetClusterScoped: true},
+				// relations without a target name are skipped
+				{Type: "uses", TargetKind: "ConfigMap", TargetName: "${spec.missing}"},
+			},
+		},
+		{
+			Group:         "cert-manager.io",
+			Version:       "v1",
+			Resource:      "clusterissuers",
+			ComponentType: "certificate-issuer",
+		},
+	}
+
+	crc := NewCustomResourceCollector(componentChannel, relationChannel, mappings, NewTestCommonClusterCollector(MockCustomResourceAPICollectorClient{}))
+	expectedCollectorName := "CustomResource Collector"
+	RunCollectorTest(t, crc, expectedCollectorName)
+
+	certificateExternalID := "urn:kubernetes:/test-cluster-name:test-namespace:certificate.cert-manager.io/test-certificate"
+	component := <-componentChannel
+	assert.EqualValues(t, &topology.Component{
+		ExternalID: certificateExternalID,
+		Type:       topology.Type{Name: "certificate"},
+		Data: topology.Data{
+			"name":              "shop.example.com",
+			"creationTimestamp": creationTime,
+			"tags": map[string]string{
+				"app.kubernetes.io/name": "shop",
+				"app":                    "shop",
+				"issuer":                 "letsencrypt",
+				"cluster-name":           "test-cluster-name",
+				"namespace":              "test-namespace",
+			},
+			"uid":        types.UID("test-certificate"),
+			"kind":       "Certificate",
+			"apiVersion": "cert-manager.io/v1",
+		},
+	}, component)
+
+	for _, expectedRelation := range []struct {
+		source, target, relationType string
+	}{
+		{"urn:kubernetes:/test-cluster-name:namespace/test-namespace", certificateExternalID, "encloses"},
+		{"urn:kubernetes:/test-cluster-name:test-namespace:ingress/test-ingress", certificateExternalID, "controls"},
+		{certificateExternalID, "urn:kubernetes:/test-cluster-name:test-namespace:secret/shop-tls", "uses"},
+		{certificateExternalID, "urn:kubernetes:/test-cluster-name:clusterissuer.cert-manager.io/letsencrypt", "issued_by"},
+	} {
+		relation := <-relationChannel
+		assert.EqualValues(t, &topology.Relation{
+			ExternalID: fmt.Sprintf("%s->%s", expectedRelation.source, expectedRelation.target),
+			Type:       topology.Type{Name: expectedRelation.relationType},
+			SourceID:   expectedRelation.source,
+			TargetID:   expectedRelation.target,
+			Data:       map[string]interface{}{},
+		}, relation)
+	}
+
+	// the certificate without a common name is skipped, cluster scoped custom resources are not enclosed by a namespace
+	component = <-componentChannel
+	assert.EqualValues(t, &topology.Component{
+		ExternalID: "urn:kubernetes:/test-cluster-name:clusterissuer.cert-manager.io/letsencrypt",
+		Type:       topology.Type{Name: "certificate-issuer"},
+		Data: topology.Data{
+			"name":              "letsencrypt",
+			"creationTimestamp": creationTime,
+			"tags":              map[string]string{"cluster-name": "test-cluster-name"},
+			"uid":               types.UID("letsencrypt"),
+			"kind":              "ClusterIssuer",
+			"apiVersion":        "cert-manager.io/v1",
+		},
+	}, component)
+}
+
+func TestExpandFieldTemplate(t *testing.T) {
+	object := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "test-certificate",
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "shop"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"enabled":  true,
+			"dnsNames": []interface{}{"shop.example.com"},
+		},
+	}
+
+	for _, tc := range []struct {
+		template string
+		expected string
+		ok       bool
+	}{
+		{template: "plain", expected: "plain", ok: true},
+		{template: "${metadata.name}", expected: "test-certificate", ok: true},
+		{template: "${metadata.labels[app.kubernetes.io/name]}-${spec.replicas}", expected: "shop-3", ok: true},
+		{template: "enabled: ${spec.enabled}", expected: "enabled: true", ok: true},
+		{template: "unclosed ${metadata.name", expected: "unclosed ${metadata.name", ok: true},
+		{template: "${spec.missing}", ok: false},
+		{template: "${spec.dnsNames}", ok: false},
+		{template: "${spec}", ok: false},
+	} {
+		t.Run(tc.template, func(t *testing.T) {
+			actual, ok := expandFieldTemplate(tc.template, object)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+type MockCustomResourceAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockCustomResourceAPICollectorClient) GetCustomResources(gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	switch gvr.Resource {
+	case "certificates":
+		certificate := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"spec": map[string]interface{}{
+				"commonName": "shop.example.com",
+				"secretName": "shop-tls",
+				"issuerRef":  map[string]interface{}{"name": "letsencrypt", "kind": "ClusterIssuer"},
+			},
+		}}
+		certificate.SetName("test-certificate")
+		certificate.SetNamespace("test-namespace")
+		certificate.SetUID(types.UID("test-certificate"))
+		certificate.SetCreationTimestamp(creationTime)
+		certificate.SetLabels(map[string]string{"app.kubernetes.io/name": "shop"})
+		certificate.SetOwnerReferences([]v1.OwnerReference{{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "test-ingress"}})
+
+		unnamed := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+		}}
+		unnamed.SetName("test-certificate-without-common-name")
+		unnamed.SetNamespace("test-namespace")
+
+		return []unstructured.Unstructured{certificate, unnamed}, nil
+	case "clusterissuers":
+		issuer := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "ClusterIssuer",
+		}}
+		issuer.SetName("letsencrypt")
+		issuer.SetUID(types.UID("letsencrypt"))
+		issuer.SetCreationTimestamp(creationTime)
+
+		return []unstructured.Unstructured{issuer}, nil
+	}
+
+	return nil, fmt.Errorf("the server could not find the requested resource %s", gvr.String())
+}
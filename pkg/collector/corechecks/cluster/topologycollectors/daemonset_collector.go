@@ -3,6 +3,7 @@
 package topologycollectors
 
 import (
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"k8s.io/api/apps/v1"
@@ -12,14 +13,16 @@ import (
 type DaemonSetCollector struct {
 	ComponentChan chan<- *topology.Component
 	RelationChan  chan<- *topology.Relation
+	HealthChan    chan<- *health.CheckState
 	ClusterTopologyCollector
 }
 
 // NewDaemonSetCollector
-func NewDaemonSetCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+func NewDaemonSetCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, healthChannel chan<- *health.CheckState, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 	return &DaemonSetCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
+		HealthChan:               healthChannel,
 		ClusterTopologyCollector: clusterTopologyCollector,
 	}
 }
@@ -39,6 +42,7 @@ func (dsc *DaemonSetCollector) CollectorFunction() error {
 	for _, ds := range daemonSets {
 		component := dsc.daemonSetToStackStateComponent(ds)
 		dsc.ComponentChan <- component
+		dsc.HealthChan <- dsc.daemonSetToReplicasCheckState(ds)
 		dsc.RelationChan <- dsc.namespaceToDaemonSetStackStateRelation(dsc.buildNamespaceExternalID(ds.Namespace), component.ExternalID)
 	}
 
@@ -82,3 +86,9 @@ func (dsc *DaemonSetCollector) namespaceToDaemonSetStackStateRelation(namespaceE
 
 	return relation
 }
+
+// Creates the available replicas health check state of a Kubernetes / OpenShift DaemonSet
+func (dsc *DaemonSetCollector) daemonSetToReplicasCheckState(daemonSet v1.DaemonSet) *health.CheckState {
+	state, message := replicasHealthState(daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.NumberAvailable)
+	return dsc.CreateCheckState(dsc.buildDaemonSetExternalID(daemonSet.Namespace, daemonSet.Name), ReplicasAvailableHealthCheck, state, message)
+}
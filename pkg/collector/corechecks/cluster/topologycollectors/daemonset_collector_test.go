@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
@@ -25,16 +26,19 @@ func TestDaemonSetCollector(t *testing.T) {
 	defer close(componentChannel)
 	relationChannel := make(chan *topology.Relation)
 	defer close(relationChannel)
+	healthChannel := make(chan *health.CheckState)
+	defer close(healthChannel)
 
 	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
 
-	cmc := NewDaemonSetCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockDaemonSetAPICollectorClient{}))
+	cmc := NewDaemonSetCollector(componentChannel, relationChannel, healthChannel, NewTestCommonClusterCollector(MockDaemonSetAPICollectorClient{}))
 	expectedCollectorName := "DaemonSet Collector"
 	RunCollectorTest(t, cmc, expectedCollectorName)
 
 	for _, tc := range []struct {
-		testCase string
-		expected *topology.Component
+		testCase       string
+		expected       *topology.Component
+		expectedHealth *health.CheckState
 	}{
 		{
 			testCase: "Test DaemonSet 1",
@@ -49,6 +53,12 @@ func TestDaemonSetCollector(t *testing.T) {
 					"updateStrategy":    appsV1.RollingUpdateDaemonSetStrategyType,
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:daemonset/test-daemonset-1:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Clear,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:daemonset/test-daemonset-1",
+			},
 		},
 		{
 			testCase: "Test DaemonSet 2",
@@ -63,6 +73,13 @@ func TestDaemonSetCollector(t *testing.T) {
 					"updateStrategy":    appsV1.RollingUpdateDaemonSetStrategyType,
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:daemonset/test-daemonset-2:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Deviating,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:daemonset/test-daemonset-2",
+				Message:                   "2 of 3 desired replicas are unavailable",
+			},
 		},
 		{
 			testCase: "Test DaemonSet 3 - Kind + Generate Name",
@@ -79,12 +96,22 @@ func TestDaemonSetCollector(t *testing.T) {
 					"generateName":      "some-specified-generation",
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:daemonset/test-daemonset-3:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Critical,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:daemonset/test-daemonset-3",
+				Message:                   "3 of 3 desired replicas are unavailable",
+			},
 		},
 	} {
 		t.Run(tc.testCase, func(t *testing.T) {
 			component := <-componentChannel
 			assert.EqualValues(t, tc.expected, component)
 
+			checkState := <-healthChannel
+			assert.EqualValues(t, tc.expectedHealth, checkState)
+
 			actualRelation := <-relationChannel
 			expectedRelation := &topology.Relation{
 				ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->" + component.ExternalID,
@@ -125,9 +152,18 @@ func (m MockDaemonSetAPICollectorClient) GetDaemonSets() ([]appsV1.DaemonSet, er
 					Type: appsV1.RollingUpdateDaemonSetStrategyType,
 				},
 			},
+			Status: appsV1.DaemonSetStatus{
+				DesiredNumberScheduled: 3,
+				NumberAvailable:        3,
+			},
+		}
+
+		if i == 2 {
+			daemonSet.Status.NumberAvailable = 1
 		}
 
 		if i == 3 {
+			daemonSet.Status.NumberAvailable = 0
 			daemonSet.TypeMeta.Kind = "some-specified-kind"
 			daemonSet.ObjectMeta.GenerateName = "some-specified-generation"
 		}
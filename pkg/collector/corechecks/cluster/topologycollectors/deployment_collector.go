@@ -3,6 +3,7 @@
 package topologycollectors
 
 import (
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"k8s.io/api/apps/v1"
@@ -12,14 +13,16 @@ import (
 type DeploymentCollector struct {
 	ComponentChan chan<- *topology.Component
 	RelationChan  chan<- *topology.Relation
+	HealthChan    chan<- *health.CheckState
 	ClusterTopologyCollector
 }
 
 // NewDeploymentCollector
-func NewDeploymentCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+func NewDeploymentCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, healthChannel chan<- *health.CheckState, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 	return &DeploymentCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
+		HealthChan:               healthChannel,
 		ClusterTopologyCollector: clusterTopologyCollector,
 	}
 }
@@ -39,6 +42,7 @@ func (dmc *DeploymentCollector) CollectorFunction() error {
 	for _, dep := range deployments {
 		component := dmc.deploymentToStackStateComponent(dep)
 		dmc.ComponentChan <- component
+		dmc.HealthChan <- dmc.deploymentToReplicasCheckState(dep)
 
 		dmc.RelationChan <- dmc.namespaceToDeploymentStackStateRelation(dmc.buildNamespaceExternalID(dep.Namespace), component.ExternalID)
 	}
@@ -84,3 +88,9 @@ func (dmc *DeploymentCollector) namespaceToDeploymentStackStateRelation(namespac
 
 	return relation
 }
+
+// Creates the available replicas health check state of a Kubernetes / OpenShift Deployment
+func (dmc *DeploymentCollector) deploymentToReplicasCheckState(deployment v1.Deployment) *health.CheckState {
+	state, message := replicasHealthState(desiredReplicas(deployment.Spec.Replicas), deployment.Status.AvailableReplicas)
+	return dmc.CreateCheckState(dmc.buildDeploymentExternalID(deployment.Namespace, deployment.Name), ReplicasAvailableHealthCheck, state, message)
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
@@ -25,17 +26,20 @@ func TestDeploymentCollector(t *testing.T) {
 	defer close(componentChannel)
 	relationChannel := make(chan *topology.Relation)
 	defer close(relationChannel)
+	healthChannel := make(chan *health.CheckState)
+	defer close(healthChannel)
 
 	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
 	replicas = int32(1)
 
-	cmc := NewDeploymentCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockDeploymentAPICollectorClient{}))
+	cmc := NewDeploymentCollector(componentChannel, relationChannel, healthChannel, NewTestCommonClusterCollector(MockDeploymentAPICollectorClient{}))
 	expectedCollectorName := "Deployment Collector"
 	RunCollectorTest(t, cmc, expectedCollectorName)
 
 	for _, tc := range []struct {
-		testCase string
-		expected *topology.Component
+		testCase       string
+		expected       *topology.Component
+		expectedHealth *health.CheckState
 	}{
 		{
 			testCase: "Test Deployment 1",
@@ -51,6 +55,12 @@ func TestDeploymentCollector(t *testing.T) {
 					"desiredReplicas":    &replicas,
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment-1:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Clear,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment-1",
+			},
 		},
 		{
 			testCase: "Test Deployment 2",
@@ -66,6 +76,13 @@ func TestDeploymentCollector(t *testing.T) {
 					"desiredReplicas":    &replicas,
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment-2:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Critical,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment-2",
+				Message:                   "1 of 1 desired replicas are unavailable",
+			},
 		},
 		{
 			testCase: "Test Deployment 3 - Kind + Generate Name",
@@ -83,12 +100,21 @@ func TestDeploymentCollector(t *testing.T) {
 					"desiredReplicas":    &replicas,
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment-3:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Clear,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment-3",
+			},
 		},
 	} {
 		t.Run(tc.testCase, func(t *testing.T) {
 			component := <-componentChannel
 			assert.EqualValues(t, tc.expected, component)
 
+			checkState := <-healthChannel
+			assert.EqualValues(t, tc.expectedHealth, checkState)
+
 			actualRelation := <-relationChannel
 			expectedRelation := &topology.Relation{
 				ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->" + component.ExternalID,
@@ -130,6 +156,13 @@ func (m MockDeploymentAPICollectorClient) GetDeployments() ([]appsV1.Deployment,
 				},
 				Replicas: &replicas,
 			},
+			Status: appsV1.DeploymentStatus{
+				AvailableReplicas: replicas,
+			},
+		}
+
+		if i == 2 {
+			deployment.Status.AvailableReplicas = 0
 		}
 
 		if i == 3 {
@@ -0,0 +1,45 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"fmt"
+
+	"github.com/StackVista/stackstate-agent/pkg/health"
+)
+
+// The names of the health checks derived from the state of the cluster resources
+const (
+	PodCrashLoopHealthCheck      = "Pod CrashLoopBackOff"
+	ReplicasAvailableHealthCheck = "Replicas Available"
+	NodeReadyHealthCheck         = "Node Ready"
+	PersistentVolumeHealthCheck  = "Persistent Volume Phase"
+	VolumeClaimsBoundHealthCheck = "Volume Claims Bound"
+)
+
+const (
+	crashLoopBackOffReason         = "CrashLoopBackOff"
+	defaultWorkloadDesiredReplicas = 1
+)
+
+// replicasHealthState determines the health of a workload from its desired and available replicas. The workload is
+// critical when none of the desired replicas is available, and deviating when only part of them is
+func replicasHealthState(desired, available int32) (health.State, string) {
+	if available >= desired {
+		return health.Clear, ""
+	}
+
+	message := fmt.Sprintf("%d of %d desired replicas are unavailable", desired-available, desired)
+	if available <= 0 {
+		return health.Critical, message
+	}
+	return health.Deviating, message
+}
+
+// desiredReplicas returns the replicas of a workload spec, which defaults to 1 when it is not set
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return defaultWorkloadDesiredReplicas
+	}
+	return *replicas
+}
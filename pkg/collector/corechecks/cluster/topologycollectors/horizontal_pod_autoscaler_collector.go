@@ -0,0 +1,117 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"errors"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"k8s.io/api/autoscaling/v2beta1"
+)
+
+// HorizontalPodAutoscalerCollector implements the ClusterTopologyCollector interface.
+type HorizontalPodAutoscalerCollector struct {
+	ComponentChan chan<- *topology.Component
+	RelationChan  chan<- *topology.Relation
+	ClusterTopologyCollector
+}
+
+// NewHorizontalPodAutoscalerCollector
+func NewHorizontalPodAutoscalerCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	return &HorizontalPodAutoscalerCollector{
+		ComponentChan:            componentChannel,
+		RelationChan:             relationChannel,
+		ClusterTopologyCollector: clusterTopologyCollector,
+	}
+}
+
+// GetName returns the name of the Collector
+func (*HorizontalPodAutoscalerCollector) GetName() string {
+	return "HorizontalPodAutoscaler Collector"
+}
+
+// Collects and Published the HorizontalPodAutoscaler Components
+func (hc *HorizontalPodAutoscalerCollector) CollectorFunction() error {
+	hpas, err := hc.GetAPIClient().GetHorizontalPodAutoscalers()
+	if errors.Is(err, apiserver.ErrResourceNotCollected) {
+		log.Debugf("Skipping the HorizontalPodAutoscalers: %s", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, hpa := range hpas {
+		component := hc.horizontalPodAutoscalerToStackStateComponent(hpa)
+		hc.ComponentChan <- component
+
+		hc.RelationChan <- hc.namespaceToHorizontalPodAutoscalerStackStateRelation(hc.buildNamespaceExternalID(hpa.Namespace), component.ExternalID)
+
+		// Create relation to the scaled workload
+		targetRef := hpa.Spec.ScaleTargetRef
+		targetExternalID, err := hc.GetURNBuilder().BuildExternalID(targetRef.Kind, hpa.Namespace, targetRef.Name)
+		if err != nil {
+			log.Debugf("Not relating HorizontalPodAutoscaler '%s' to its scale target: %s", component.ExternalID, err)
+			continue
+		}
+		hc.RelationChan <- hc.horizontalPodAutoscalerToScaleTargetStackStateRelation(component.ExternalID, targetExternalID)
+	}
+
+	return nil
+}
+
+// Creates a StackState HorizontalPodAutoscaler component from a Kubernetes / OpenShift Cluster
+func (hc *HorizontalPodAutoscalerCollector) horizontalPodAutoscalerToStackStateComponent(hpa v2beta1.HorizontalPodAutoscaler) *topology.Component {
+	log.Tracef("Mapping HorizontalPodAutoscaler to StackState component: %s", hpa.String())
+
+	tags := hc.initTags(hpa.ObjectMeta)
+
+	hpaExternalID := hc.buildHorizontalPodAutoscalerExternalID(hpa.Namespace, hpa.Name)
+	component := &topology.Component{
+		ExternalID: hpaExternalID,
+		Type:       topology.Type{Name: "horizontal-pod-autoscaler"},
+		Data: map[string]interface{}{
+			"name":              hpa.Name,
+			"creationTimestamp": hpa.CreationTimestamp,
+			"tags":              tags,
+			"uid":               hpa.UID,
+			"minReplicas":       hpa.Spec.MinReplicas,
+			"maxReplicas":       hpa.Spec.MaxReplicas,
+			"currentReplicas":   hpa.Status.CurrentReplicas,
+			"desiredReplicas":   hpa.Status.DesiredReplicas,
+			"scaleTargetKind":   hpa.Spec.ScaleTargetRef.Kind,
+			"scaleTargetName":   hpa.Spec.ScaleTargetRef.Name,
+		},
+	}
+
+	component.Data.PutNonEmpty("generateName", hpa.GenerateName)
+	component.Data.PutNonEmpty("kind", hpa.Kind)
+
+	log.Tracef("Created StackState HorizontalPodAutoscaler component %s: %v", hpaExternalID, component.JSONString())
+
+	return component
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift Namespace to HorizontalPodAutoscaler relation
+func (hc *HorizontalPodAutoscalerCollector) namespaceToHorizontalPodAutoscalerStackStateRelation(namespaceExternalID, hpaExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes namespace to horizontal pod autoscaler relation: %s -> %s", namespaceExternalID, hpaExternalID)
+
+	relation := hc.CreateRelation(namespaceExternalID, hpaExternalID, "encloses")
+
+	log.Tracef("Created StackState namespace -> horizontal pod autoscaler relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift HorizontalPodAutoscaler to the workload it scales
+func (hc *HorizontalPodAutoscalerCollector) horizontalPodAutoscalerToScaleTargetStackStateRelation(hpaExternalID, targetExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes horizontal pod autoscaler to scale target relation: %s -> %s", hpaExternalID, targetExternalID)
+
+	relation := hc.CreateRelation(hpaExternalID, targetExternalID, "scales")
+
+	log.Tracef("Created StackState horizontal pod autoscaler -> scale target relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
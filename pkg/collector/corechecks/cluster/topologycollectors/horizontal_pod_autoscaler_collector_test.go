@@ -0,0 +1,200 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestHorizontalPodAutoscalerCollector(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
+	replicas = int32(1)
+
+	hc := NewHorizontalPodAutoscalerCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockHorizontalPodAutoscalerAPICollectorClient{}))
+	expectedCollectorName := "HorizontalPodAutoscaler Collector"
+	RunCollectorTest(t, hc, expectedCollectorName)
+
+	for _, tc := range []struct {
+		testCase          string
+		expected          *topology.Component
+		expectedRelations []*topology.Relation
+	}{
+		{
+			testCase: "Test HorizontalPodAutoscaler 1 - Deployment",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-1",
+				Type:       topology.Type{Name: "horizontal-pod-autoscaler"},
+				Data: topology.Data{
+					"name":              "test-hpa-1",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-hpa-1"),
+					"minReplicas":       &replicas,
+					"maxReplicas":       int32(5),
+					"currentReplicas":   int32(2),
+					"desiredReplicas":   int32(3),
+					"scaleTargetKind":   "Deployment",
+					"scaleTargetName":   "test-deployment",
+				},
+			},
+			expectedRelations: []*topology.Relation{
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-1",
+					Type:       topology.Type{Name: "encloses"},
+					SourceID:   "urn:kubernetes:/test-cluster-name:namespace/test-namespace",
+					TargetID:   "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-1",
+					Data:       map[string]interface{}{},
+				},
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-1->urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment",
+					Type:       topology.Type{Name: "scales"},
+					SourceID:   "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-1",
+					TargetID:   "urn:kubernetes:/test-cluster-name:test-namespace:deployment/test-deployment",
+					Data:       map[string]interface{}{},
+				},
+			},
+		},
+		{
+			testCase: "Test HorizontalPodAutoscaler 2 - StatefulSet",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-2",
+				Type:       topology.Type{Name: "horizontal-pod-autoscaler"},
+				Data: topology.Data{
+					"name":              "test-hpa-2",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-hpa-2"),
+					"minReplicas":       &replicas,
+					"maxReplicas":       int32(5),
+					"currentReplicas":   int32(2),
+					"desiredReplicas":   int32(3),
+					"scaleTargetKind":   "StatefulSet",
+					"scaleTargetName":   "test-statefulset",
+				},
+			},
+			expectedRelations: []*topology.Relation{
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-2",
+					Type:       topology.Type{Name: "encloses"},
+					SourceID:   "urn:kubernetes:/test-cluster-name:namespace/test-namespace",
+					TargetID:   "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-2",
+					Data:       map[string]interface{}{},
+				},
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-2->urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset",
+					Type:       topology.Type{Name: "scales"},
+					SourceID:   "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-2",
+					TargetID:   "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset",
+					Data:       map[string]interface{}{},
+				},
+			},
+		},
+		{
+			testCase: "Test HorizontalPodAutoscaler 3 - Unknown scale target kind",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-3",
+				Type:       topology.Type{Name: "horizontal-pod-autoscaler"},
+				Data: topology.Data{
+					"name":              "test-hpa-3",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-hpa-3"),
+					"minReplicas":       &replicas,
+					"maxReplicas":       int32(5),
+					"currentReplicas":   int32(2),
+					"desiredReplicas":   int32(3),
+					"scaleTargetKind":   "DeploymentConfig",
+					"scaleTargetName":   "test-deploymentconfig",
+				},
+			},
+			expectedRelations: []*topology.Relation{
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-3",
+					Type:       topology.Type{Name: "encloses"},
+					SourceID:   "urn:kubernetes:/test-cluster-name:namespace/test-namespace",
+					TargetID:   "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa-3",
+					Data:       map[string]interface{}{},
+				},
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			component := <-componentChannel
+			assert.EqualValues(t, tc.expected, component)
+
+			for _, expectedRelation := range tc.expectedRelations {
+				actualRelation := <-relationChannel
+				assert.EqualValues(t, expectedRelation, actualRelation)
+			}
+		})
+	}
+}
+
+type MockHorizontalPodAutoscalerAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockHorizontalPodAutoscalerAPICollectorClient) GetHorizontalPodAutoscalers() ([]v2beta1.HorizontalPodAutoscaler, error) {
+	hpas := make([]v2beta1.HorizontalPodAutoscaler, 0)
+	for i, target := range []v2beta1.CrossVersionObjectReference{
+		{Kind: "Deployment", Name: "test-deployment"},
+		{Kind: "StatefulSet", Name: "test-statefulset"},
+		{Kind: "DeploymentConfig", Name: "test-deploymentconfig"},
+	} {
+		hpas = append(hpas, v2beta1.HorizontalPodAutoscaler{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              fmt.Sprintf("test-hpa-%d", i+1),
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels: map[string]string{
+					"test": "label",
+				},
+				UID: types.UID(fmt.Sprintf("test-hpa-%d", i+1)),
+			},
+			Spec: v2beta1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: target,
+				MinReplicas:    &replicas,
+				MaxReplicas:    5,
+			},
+			Status: v2beta1.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: 2,
+				DesiredReplicas: 3,
+			},
+		})
+	}
+
+	return hpas, nil
+}
+
+func TestHorizontalPodAutoscalerCollectorSkipsNotCollected(t *testing.T) {
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	hc := NewHorizontalPodAutoscalerCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockNotCollectedHorizontalPodAutoscalerAPICollectorClient{}))
+	assert.NoError(t, hc.CollectorFunction())
+}
+
+type MockNotCollectedHorizontalPodAutoscalerAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockNotCollectedHorizontalPodAutoscalerAPICollectorClient) GetHorizontalPodAutoscalers() ([]v2beta1.HorizontalPodAutoscaler, error) {
+	return nil, fmt.Errorf("%w: the autoscaling horizontalpodautoscalers are not served", apiserver.ErrResourceNotCollected)
+}
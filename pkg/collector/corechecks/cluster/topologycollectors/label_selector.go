@@ -0,0 +1,25 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// selectPods returns the pods of the namespace whose labels match the selector
+func selectPods(pods []v1.Pod, namespace string, labelSelector *metav1.LabelSelector) ([]v1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]v1.Pod, 0)
+	for _, pod := range pods {
+		if pod.Namespace == namespace && selector.Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected, nil
+}
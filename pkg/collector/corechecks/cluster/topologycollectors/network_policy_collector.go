@@ -0,0 +1,122 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"errors"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkPolicyCollector implements the ClusterTopologyCollector interface.
+type NetworkPolicyCollector struct {
+	ComponentChan chan<- *topology.Component
+	RelationChan  chan<- *topology.Relation
+	ClusterTopologyCollector
+}
+
+// NewNetworkPolicyCollector
+func NewNetworkPolicyCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	return &NetworkPolicyCollector{
+		ComponentChan:            componentChannel,
+		RelationChan:             relationChannel,
+		ClusterTopologyCollector: clusterTopologyCollector,
+	}
+}
+
+// GetName returns the name of the Collector
+func (*NetworkPolicyCollector) GetName() string {
+	return "NetworkPolicy Collector"
+}
+
+// Collects and Published the NetworkPolicy Components
+func (npc *NetworkPolicyCollector) CollectorFunction() error {
+	networkPolicies, err := npc.GetAPIClient().GetNetworkPolicies()
+	if errors.Is(err, apiserver.ErrResourceNotCollected) {
+		log.Debugf("Skipping the NetworkPolicies: %s", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pods, err := npc.getPods()
+	if err != nil {
+		return err
+	}
+
+	for _, np := range networkPolicies {
+		component := npc.networkPolicyToStackStateComponent(np)
+		npc.ComponentChan <- component
+
+		npc.RelationChan <- npc.namespaceToNetworkPolicyStackStateRelation(npc.buildNamespaceExternalID(np.Namespace), component.ExternalID)
+
+		// an empty pod selector selects all the pods of the namespace
+		selectedPods, err := selectPods(pods, np.Namespace, &np.Spec.PodSelector)
+		if err != nil {
+			_ = log.Errorf("Invalid pod selector of NetworkPolicy '%s': %s", component.ExternalID, err)
+			continue
+		}
+		for _, pod := range selectedPods {
+			npc.RelationChan <- npc.networkPolicyToPodStackStateRelation(component.ExternalID, npc.buildPodExternalID(pod.Namespace, pod.Name))
+		}
+	}
+
+	return nil
+}
+
+// Creates a StackState NetworkPolicy component from a Kubernetes / OpenShift Cluster
+func (npc *NetworkPolicyCollector) networkPolicyToStackStateComponent(np v1.NetworkPolicy) *topology.Component {
+	log.Tracef("Mapping NetworkPolicy to StackState component: %s", np.String())
+
+	tags := npc.initTags(np.ObjectMeta)
+
+	networkPolicyExternalID := npc.buildNetworkPolicyExternalID(np.Namespace, np.Name)
+	component := &topology.Component{
+		ExternalID: networkPolicyExternalID,
+		Type:       topology.Type{Name: "network-policy"},
+		Data: map[string]interface{}{
+			"name":              np.Name,
+			"creationTimestamp": np.CreationTimestamp,
+			"tags":              tags,
+			"uid":               np.UID,
+			"podSelector":       metav1.FormatLabelSelector(&np.Spec.PodSelector),
+			"policyTypes":       np.Spec.PolicyTypes,
+			"ingressRules":      len(np.Spec.Ingress),
+			"egressRules":       len(np.Spec.Egress),
+		},
+	}
+
+	component.Data.PutNonEmpty("generateName", np.GenerateName)
+	component.Data.PutNonEmpty("kind", np.Kind)
+
+	log.Tracef("Created StackState NetworkPolicy component %s: %v", networkPolicyExternalID, component.JSONString())
+
+	return component
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift Namespace to NetworkPolicy relation
+func (npc *NetworkPolicyCollector) namespaceToNetworkPolicyStackStateRelation(namespaceExternalID, networkPolicyExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes namespace to network policy relation: %s -> %s", namespaceExternalID, networkPolicyExternalID)
+
+	relation := npc.CreateRelation(namespaceExternalID, networkPolicyExternalID, "encloses")
+
+	log.Tracef("Created StackState namespace -> network policy relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift NetworkPolicy to a Pod it selects
+func (npc *NetworkPolicyCollector) networkPolicyToPodStackStateRelation(networkPolicyExternalID, podExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes network policy to pod relation: %s -> %s", networkPolicyExternalID, podExternalID)
+
+	relation := npc.CreateRelation(networkPolicyExternalID, podExternalID, "applies_to")
+
+	log.Tracef("Created StackState network policy -> pod relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
@@ -0,0 +1,150 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	networkingV1 "k8s.io/api/networking/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNetworkPolicyCollector(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
+
+	npc := NewNetworkPolicyCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockNetworkPolicyAPICollectorClient{}))
+	expectedCollectorName := "NetworkPolicy Collector"
+	RunCollectorTest(t, npc, expectedCollectorName)
+
+	for _, tc := range []struct {
+		testCase     string
+		expected     *topology.Component
+		expectedPods []string
+	}{
+		{
+			testCase: "Test NetworkPolicy 1 - Selects the pods with matching labels",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:network-policy/test-network-policy-1",
+				Type:       topology.Type{Name: "network-policy"},
+				Data: topology.Data{
+					"name":              "test-network-policy-1",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-network-policy-1"),
+					"podSelector":       "app=frontend",
+					"policyTypes":       []networkingV1.PolicyType{networkingV1.PolicyTypeIngress},
+					"ingressRules":      1,
+					"egressRules":       0,
+				},
+			},
+			expectedPods: []string{"frontend-1", "frontend-2"},
+		},
+		{
+			testCase: "Test NetworkPolicy 2 - Empty selector selects all the pods of the namespace",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:network-policy/test-network-policy-2",
+				Type:       topology.Type{Name: "network-policy"},
+				Data: topology.Data{
+					"name":              "test-network-policy-2",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-network-policy-2"),
+					"podSelector":       "<none>",
+					"policyTypes":       []networkingV1.PolicyType{networkingV1.PolicyTypeIngress, networkingV1.PolicyTypeEgress},
+					"ingressRules":      0,
+					"egressRules":       0,
+				},
+			},
+			expectedPods: []string{"frontend-1", "frontend-2", "backend-1"},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			component := <-componentChannel
+			assert.EqualValues(t, tc.expected, component)
+
+			actualRelation := <-relationChannel
+			expectedRelation := &topology.Relation{
+				ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->" + component.ExternalID,
+				Type:       topology.Type{Name: "encloses"},
+				SourceID:   "urn:kubernetes:/test-cluster-name:namespace/test-namespace",
+				TargetID:   component.ExternalID,
+				Data:       map[string]interface{}{},
+			}
+			assert.EqualValues(t, expectedRelation, actualRelation)
+
+			for _, pod := range tc.expectedPods {
+				podExternalID := "urn:kubernetes:/test-cluster-name:test-namespace:pod/" + pod
+				actualRelation := <-relationChannel
+				expectedRelation := &topology.Relation{
+					ExternalID: component.ExternalID + "->" + podExternalID,
+					Type:       topology.Type{Name: "applies_to"},
+					SourceID:   component.ExternalID,
+					TargetID:   podExternalID,
+					Data:       map[string]interface{}{},
+				}
+				assert.EqualValues(t, expectedRelation, actualRelation)
+			}
+		})
+	}
+}
+
+type MockNetworkPolicyAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockNetworkPolicyAPICollectorClient) GetNetworkPolicies() ([]networkingV1.NetworkPolicy, error) {
+	return []networkingV1.NetworkPolicy{
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "test-network-policy-1",
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("test-network-policy-1"),
+			},
+			Spec: networkingV1.NetworkPolicySpec{
+				PodSelector: v1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}},
+				PolicyTypes: []networkingV1.PolicyType{networkingV1.PolicyTypeIngress},
+				Ingress:     []networkingV1.NetworkPolicyIngressRule{{}},
+			},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "test-network-policy-2",
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("test-network-policy-2"),
+			},
+			Spec: networkingV1.NetworkPolicySpec{
+				PolicyTypes: []networkingV1.PolicyType{networkingV1.PolicyTypeIngress, networkingV1.PolicyTypeEgress},
+			},
+		},
+	}, nil
+}
+
+func (m MockNetworkPolicyAPICollectorClient) GetPods() ([]coreV1.Pod, error) {
+	return selectorTestPods(), nil
+}
+
+// selectorTestPods returns pods in multiple namespaces to test the pod selectors of policies
+func selectorTestPods() []coreV1.Pod {
+	return []coreV1.Pod{
+		{ObjectMeta: v1.ObjectMeta{Name: "frontend-1", Namespace: "test-namespace", Labels: map[string]string{"app": "frontend"}}},
+		{ObjectMeta: v1.ObjectMeta{Name: "frontend-2", Namespace: "test-namespace", Labels: map[string]string{"app": "frontend"}}},
+		{ObjectMeta: v1.ObjectMeta{Name: "backend-1", Namespace: "test-namespace", Labels: map[string]string{"app": "backend"}}},
+		{ObjectMeta: v1.ObjectMeta{Name: "frontend-1", Namespace: "other-namespace", Labels: map[string]string{"app": "frontend"}}},
+	}
+}
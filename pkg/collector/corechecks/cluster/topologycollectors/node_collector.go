@@ -4,6 +4,7 @@ package topologycollectors
 
 import (
 	"fmt"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"k8s.io/api/core/v1"
@@ -14,6 +15,7 @@ import (
 type NodeCollector struct {
 	ComponentChan          chan<- *topology.Component
 	RelationChan           chan<- *topology.Relation
+	HealthChan             chan<- *health.CheckState
 	NodeIdentifierCorrChan chan<- *NodeIdentifierCorrelation
 	ClusterTopologyCollector
 }
@@ -27,10 +29,11 @@ type NodeStatus struct {
 
 // NewNodeCollector
 func NewNodeCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation,
-	nodeIdentifierCorrChan chan<- *NodeIdentifierCorrelation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	healthChannel chan<- *health.CheckState, nodeIdentifierCorrChan chan<- *NodeIdentifierCorrelation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 	return &NodeCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
+		HealthChan:               healthChannel,
 		NodeIdentifierCorrChan:   nodeIdentifierCorrChan,
 		ClusterTopologyCollector: clusterTopologyCollector,
 	}
@@ -58,6 +61,11 @@ func (nc *NodeCollector) CollectorFunction() error {
 		nc.ComponentChan <- component
 		nc.RelationChan <- relation
 
+		// nodes that don't report a ready condition yet have no health state
+		if checkState := nc.nodeToReadyCheckState(node); checkState != nil {
+			nc.HealthChan <- checkState
+		}
+
 		// send the node identifier to be correlated
 		if node.Spec.ProviderID != "" {
 			nodeIdentifier := extractInstanceIDFromProviderID(node.Spec)
@@ -135,6 +143,25 @@ func (nc *NodeCollector) nodeToStackStateComponent(node v1.Node) *topology.Compo
 	return component
 }
 
+// Creates the ready health check state of a Kubernetes Node, returns nil when the node has no ready condition
+func (nc *NodeCollector) nodeToReadyCheckState(node v1.Node) *health.CheckState {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != v1.NodeReady {
+			continue
+		}
+
+		nodeExternalID := nc.buildNodeExternalID(node.Name)
+		if condition.Status == v1.ConditionTrue {
+			return nc.CreateCheckState(nodeExternalID, NodeReadyHealthCheck, health.Clear, "")
+		}
+
+		message := fmt.Sprintf("Node is not ready (%s): %s", condition.Reason, condition.Message)
+		return nc.CreateCheckState(nodeExternalID, NodeReadyHealthCheck, health.Critical, message)
+	}
+
+	return nil
+}
+
 // Creates a StackState relation from a Kubernetes Pod to Node relation
 func (nc *NodeCollector) nodeToClusterStackStateRelation(node v1.Node) *topology.Relation {
 	nodeExternalID := nc.buildNodeExternalID(node.Name)
@@ -8,6 +8,7 @@ package topologycollectors
 
 import (
 	"fmt"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
@@ -31,11 +32,13 @@ func TestNodeCollector(t *testing.T) {
 	defer close(componentChannel)
 	relationChannel := make(chan *topology.Relation)
 	defer close(relationChannel)
+	healthChannel := make(chan *health.CheckState)
+	defer close(healthChannel)
 	nodeIdentifierCorrelationChannel := make(chan *NodeIdentifierCorrelation)
 
 	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
 
-	ic := NewNodeCollector(componentChannel, relationChannel, nodeIdentifierCorrelationChannel, NewTestCommonClusterCollector(MockNodeAPICollectorClient{}))
+	ic := NewNodeCollector(componentChannel, relationChannel, healthChannel, nodeIdentifierCorrelationChannel, NewTestCommonClusterCollector(MockNodeAPICollectorClient{}))
 	expectedCollectorName := "Node Collector"
 	RunCollectorTest(t, ic, expectedCollectorName)
 
@@ -124,6 +127,16 @@ func TestNodeCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedRelation, relation)
 				},
+				func() {
+					checkState := <-healthChannel
+					expectedCheckState := &health.CheckState{
+						CheckStateID:              "urn:kubernetes:/test-cluster-name:node/test-node-2:node-ready",
+						Name:                      "Node Ready",
+						Health:                    health.Clear,
+						TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:node/test-node-2",
+					}
+					assert.EqualValues(t, expectedCheckState, checkState)
+				},
 			},
 		},
 		{
@@ -170,6 +183,17 @@ func TestNodeCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedRelation, relation)
 				},
+				func() {
+					checkState := <-healthChannel
+					expectedCheckState := &health.CheckState{
+						CheckStateID:              "urn:kubernetes:/test-cluster-name:node/test-node-3:node-ready",
+						Name:                      "Node Ready",
+						Health:                    health.Critical,
+						TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:node/test-node-3",
+						Message:                   "Node is not ready (KubeletNotReady): container runtime is down",
+					}
+					assert.EqualValues(t, expectedCheckState, checkState)
+				},
 				func() {
 					nodeIdentifier := <-nodeIdentifierCorrelationChannel
 					expectedNodeIdentifier := &NodeIdentifierCorrelation{
@@ -235,6 +259,10 @@ func (m MockNodeAPICollectorClient) GetNodes() ([]coreV1.Node, error) {
 				{Type: coreV1.NodeInternalIP, Address: "10.20.01.01"},
 				{Type: coreV1.NodeExternalIP, Address: "10.20.01.02"},
 			}
+			node.Status.Conditions = []coreV1.NodeCondition{
+				{Type: coreV1.NodeMemoryPressure, Status: coreV1.ConditionFalse},
+				{Type: coreV1.NodeReady, Status: coreV1.ConditionTrue},
+			}
 		}
 
 		if i == 3 {
@@ -247,6 +275,9 @@ func (m MockNodeAPICollectorClient) GetNodes() ([]coreV1.Node, error) {
 				{Type: coreV1.NodeInternalDNS, Address: "cluster.internal.dns.test-node-3"},
 				{Type: coreV1.NodeExternalDNS, Address: "my-organization.test-node-3"},
 			}
+			node.Status.Conditions = []coreV1.NodeCondition{
+				{Type: coreV1.NodeReady, Status: coreV1.ConditionFalse, Reason: "KubeletNotReady", Message: "container runtime is down"},
+			}
 		}
 
 		nodes = append(nodes, node)
@@ -0,0 +1,109 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	v1 "k8s.io/api/core/v1"
+)
+
+// PersistentVolumeClaimCollector implements the ClusterTopologyCollector interface.
+type PersistentVolumeClaimCollector struct {
+	ComponentChan chan<- *topology.Component
+	RelationChan  chan<- *topology.Relation
+	ClusterTopologyCollector
+}
+
+// NewPersistentVolumeClaimCollector
+func NewPersistentVolumeClaimCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	return &PersistentVolumeClaimCollector{
+		ComponentChan:            componentChannel,
+		RelationChan:             relationChannel,
+		ClusterTopologyCollector: clusterTopologyCollector,
+	}
+}
+
+// GetName returns the name of the Collector
+func (*PersistentVolumeClaimCollector) GetName() string {
+	return "Persistent Volume Claim Collector"
+}
+
+// Collects and Published the Persistent Volume Claim Components
+func (pvcc *PersistentVolumeClaimCollector) CollectorFunction() error {
+	persistentVolumeClaims, err := pvcc.GetAPIClient().GetPersistentVolumeClaims()
+	if err != nil {
+		return err
+	}
+
+	for _, pvc := range persistentVolumeClaims {
+		component := pvcc.persistentVolumeClaimToStackStateComponent(pvc)
+		pvcc.ComponentChan <- component
+
+		pvcc.RelationChan <- pvcc.namespaceToPersistentVolumeClaimStackStateRelation(pvcc.buildNamespaceExternalID(pvc.Namespace), component.ExternalID)
+
+		// a pending claim is not bound to a persistent volume yet
+		if pvc.Spec.VolumeName != "" {
+			pvcc.RelationChan <- pvcc.persistentVolumeClaimToPersistentVolumeStackStateRelation(component.ExternalID, pvcc.buildPersistentVolumeExternalID(pvc.Spec.VolumeName))
+		}
+	}
+
+	return nil
+}
+
+// Creates a Persistent Volume Claim StackState component from a Kubernetes / OpenShift Cluster
+func (pvcc *PersistentVolumeClaimCollector) persistentVolumeClaimToStackStateComponent(pvc v1.PersistentVolumeClaim) *topology.Component {
+	log.Tracef("Mapping PersistentVolumeClaim to StackState component: %s", pvc.String())
+
+	tags := pvcc.initTags(pvc.ObjectMeta)
+
+	persistentVolumeClaimExternalID := pvcc.buildPersistentVolumeClaimExternalID(pvc.Namespace, pvc.Name)
+	component := &topology.Component{
+		ExternalID: persistentVolumeClaimExternalID,
+		Type:       topology.Type{Name: "persistent-volume-claim"},
+		Data: map[string]interface{}{
+			"name":              pvc.Name,
+			"creationTimestamp": pvc.CreationTimestamp,
+			"tags":              tags,
+			"uid":               pvc.UID,
+			"status":            pvc.Status.Phase,
+			"accessModes":       pvc.Spec.AccessModes,
+		},
+	}
+
+	if pvc.Spec.StorageClassName != nil {
+		component.Data.PutNonEmpty("storageClassName", *pvc.Spec.StorageClassName)
+	}
+	if storage, ok := pvc.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		component.Data.PutNonEmpty("requestedStorage", storage.String())
+	}
+	component.Data.PutNonEmpty("volumeName", pvc.Spec.VolumeName)
+	component.Data.PutNonEmpty("generateName", pvc.GenerateName)
+	component.Data.PutNonEmpty("kind", pvc.Kind)
+
+	log.Tracef("Created StackState persistent volume claim component %s: %v", persistentVolumeClaimExternalID, component.JSONString())
+
+	return component
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift Namespace to PersistentVolumeClaim relation
+func (pvcc *PersistentVolumeClaimCollector) namespaceToPersistentVolumeClaimStackStateRelation(namespaceExternalID, persistentVolumeClaimExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes namespace to persistent volume claim relation: %s -> %s", namespaceExternalID, persistentVolumeClaimExternalID)
+
+	relation := pvcc.CreateRelation(namespaceExternalID, persistentVolumeClaimExternalID, "encloses")
+
+	log.Tracef("Created StackState namespace -> persistent volume claim relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift PersistentVolumeClaim to the PersistentVolume it is bound to
+func (pvcc *PersistentVolumeClaimCollector) persistentVolumeClaimToPersistentVolumeStackStateRelation(persistentVolumeClaimExternalID, persistentVolumeExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes persistent volume claim to persistent volume relation: %s -> %s", persistentVolumeClaimExternalID, persistentVolumeExternalID)
+
+	relation := pvcc.CreateRelation(persistentVolumeClaimExternalID, persistentVolumeExternalID, "bound_to")
+
+	log.Tracef("Created StackState persistent volume claim -> persistent volume relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
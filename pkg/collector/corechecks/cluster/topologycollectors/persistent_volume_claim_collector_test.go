@@ -0,0 +1,136 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPersistentVolumeClaimCollector(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
+
+	pvcc := NewPersistentVolumeClaimCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockPersistentVolumeClaimAPICollectorClient{}))
+	expectedCollectorName := "Persistent Volume Claim Collector"
+	RunCollectorTest(t, pvcc, expectedCollectorName)
+
+	for _, tc := range []struct {
+		testCase                 string
+		expected                 *topology.Component
+		expectedPersistentVolume string
+	}{
+		{
+			testCase: "Test PersistentVolumeClaim 1 - Bound to a persistent volume",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:persistent-volume-claim/test-persistent-volume-claim-1",
+				Type:       topology.Type{Name: "persistent-volume-claim"},
+				Data: topology.Data{
+					"name":              "test-persistent-volume-claim-1",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-persistent-volume-claim-1"),
+					"status":            coreV1.ClaimBound,
+					"accessModes":       []coreV1.PersistentVolumeAccessMode{coreV1.ReadWriteOnce},
+					"storageClassName":  "standard",
+					"requestedStorage":  "1Gi",
+					"volumeName":        "test-persistent-volume-1",
+				},
+			},
+			expectedPersistentVolume: "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-1",
+		},
+		{
+			testCase: "Test PersistentVolumeClaim 2 - Pending without a persistent volume",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:persistent-volume-claim/test-persistent-volume-claim-2",
+				Type:       topology.Type{Name: "persistent-volume-claim"},
+				Data: topology.Data{
+					"name":              "test-persistent-volume-claim-2",
+					"creationTimestamp": creationTime,
+					"tags":              map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":               types.UID("test-persistent-volume-claim-2"),
+					"status":            coreV1.ClaimPending,
+					"accessModes":       []coreV1.PersistentVolumeAccessMode(nil),
+				},
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			component := <-componentChannel
+			assert.EqualValues(t, tc.expected, component)
+
+			actualRelation := <-relationChannel
+			expectedRelation := &topology.Relation{
+				ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->" + component.ExternalID,
+				Type:       topology.Type{Name: "encloses"},
+				SourceID:   "urn:kubernetes:/test-cluster-name:namespace/test-namespace",
+				TargetID:   component.ExternalID,
+				Data:       map[string]interface{}{},
+			}
+			assert.EqualValues(t, expectedRelation, actualRelation)
+
+			if tc.expectedPersistentVolume != "" {
+				actualRelation := <-relationChannel
+				expectedRelation := &topology.Relation{
+					ExternalID: component.ExternalID + "->" + tc.expectedPersistentVolume,
+					Type:       topology.Type{Name: "bound_to"},
+					SourceID:   component.ExternalID,
+					TargetID:   tc.expectedPersistentVolume,
+					Data:       map[string]interface{}{},
+				}
+				assert.EqualValues(t, expectedRelation, actualRelation)
+			}
+		})
+	}
+}
+
+type MockPersistentVolumeClaimAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockPersistentVolumeClaimAPICollectorClient) GetPersistentVolumeClaims() ([]coreV1.PersistentVolumeClaim, error) {
+	storageClassName := "standard"
+	return []coreV1.PersistentVolumeClaim{
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "test-persistent-volume-claim-1",
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("test-persistent-volume-claim-1"),
+			},
+			Spec: coreV1.PersistentVolumeClaimSpec{
+				AccessModes:      []coreV1.PersistentVolumeAccessMode{coreV1.ReadWriteOnce},
+				StorageClassName: &storageClassName,
+				VolumeName:       "test-persistent-volume-1",
+				Resources: coreV1.ResourceRequirements{
+					Requests: coreV1.ResourceList{coreV1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+			Status: coreV1.PersistentVolumeClaimStatus{Phase: coreV1.ClaimBound},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "test-persistent-volume-claim-2",
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("test-persistent-volume-claim-2"),
+			},
+			Status: coreV1.PersistentVolumeClaimStatus{Phase: coreV1.ClaimPending},
+		},
+	}, nil
+}
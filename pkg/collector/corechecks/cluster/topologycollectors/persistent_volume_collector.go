@@ -3,6 +3,9 @@
 package topologycollectors
 
 import (
+	"fmt"
+
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	v1 "k8s.io/api/core/v1"
@@ -12,14 +15,16 @@ import (
 type PersistentVolumeCollector struct {
 	ComponentChan chan<- *topology.Component
 	RelationChan  chan<- *topology.Relation
+	HealthChan    chan<- *health.CheckState
 	ClusterTopologyCollector
 }
 
 // NewPersistentVolumeCollector
-func NewPersistentVolumeCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+func NewPersistentVolumeCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, healthChannel chan<- *health.CheckState, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 	return &PersistentVolumeCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
+		HealthChan:               healthChannel,
 		ClusterTopologyCollector: clusterTopologyCollector,
 	}
 }
@@ -39,6 +44,7 @@ func (pvc *PersistentVolumeCollector) CollectorFunction() error {
 	for _, pv := range persistentVolumes {
 		component := pvc.persistentVolumeToStackStateComponent(pv)
 		pvc.ComponentChan <- component
+		pvc.HealthChan <- pvc.persistentVolumeToPhaseCheckState(pv)
 
 		volumeSource, err := pvc.persistentVolumeSourceToStackStateComponent(pv)
 		if err != nil {
@@ -105,6 +111,27 @@ func (pvc *PersistentVolumeCollector) persistentVolumeToStackStateComponent(pers
 	return component
 }
 
+// Creates the phase health check state of a Kubernetes / OpenShift Persistent Volume
+func (pvc *PersistentVolumeCollector) persistentVolumeToPhaseCheckState(persistentVolume v1.PersistentVolume) *health.CheckState {
+	state := health.Clear
+	switch persistentVolume.Status.Phase {
+	case v1.VolumeFailed:
+		state = health.Critical
+	case v1.VolumePending:
+		state = health.Deviating
+	}
+
+	message := ""
+	if state != health.Clear {
+		message = fmt.Sprintf("Persistent volume is %s", persistentVolume.Status.Phase)
+		if persistentVolume.Status.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, persistentVolume.Status.Message)
+		}
+	}
+
+	return pvc.CreateCheckState(pvc.buildPersistentVolumeExternalID(persistentVolume.Name), PersistentVolumeHealthCheck, state, message)
+}
+
 func (pvc *PersistentVolumeCollector) createStackStateVolumeSourceComponent(pv v1.PersistentVolume, name, externalID string, identifiers []string, addTags map[string]string) (*topology.Component, error) {
 
 	tags := pvc.initTags(pv.ObjectMeta)
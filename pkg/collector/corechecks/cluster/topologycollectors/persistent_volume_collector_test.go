@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +28,9 @@ func TestPersistentVolumeCollector(t *testing.T) {
 	relationChannel := make(chan *topology.Relation)
 	defer close(relationChannel)
 
+	healthChannel := make(chan *health.CheckState)
+	defer close(healthChannel)
+
 	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
 	pathType = coreV1.HostPathFileOrCreate
 	gcePersistentDisk = coreV1.GCEPersistentDiskVolumeSource{
@@ -40,7 +44,7 @@ func TestPersistentVolumeCollector(t *testing.T) {
 		Type: &pathType,
 	}
 
-	cmc := NewPersistentVolumeCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockPersistentVolumeAPICollectorClient{}))
+	cmc := NewPersistentVolumeCollector(componentChannel, relationChannel, healthChannel, NewTestCommonClusterCollector(MockPersistentVolumeAPICollectorClient{}))
 	expectedCollectorName := "Persistent Volume Collector"
 	RunCollectorTest(t, cmc, expectedCollectorName)
 
@@ -68,6 +72,16 @@ func TestPersistentVolumeCollector(t *testing.T) {
 						}}
 					assert.EqualValues(t, expected, component)
 				},
+				func(t *testing.T) {
+					checkState := <-healthChannel
+					expected := &health.CheckState{
+						CheckStateID:              "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-1:persistent-volume-phase",
+						Name:                      "Persistent Volume Phase",
+						Health:                    health.Clear,
+						TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-1",
+					}
+					assert.EqualValues(t, expected, checkState)
+				},
 				func(t *testing.T) {
 					component := <-componentChannel
 					expected := &topology.Component{
@@ -116,6 +130,16 @@ func TestPersistentVolumeCollector(t *testing.T) {
 						}}
 					assert.EqualValues(t, expected, component)
 				},
+				func(t *testing.T) {
+					checkState := <-healthChannel
+					expected := &health.CheckState{
+						CheckStateID:              "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-2:persistent-volume-phase",
+						Name:                      "Persistent Volume Phase",
+						Health:                    health.Clear,
+						TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-2",
+					}
+					assert.EqualValues(t, expected, checkState)
+				},
 				func(t *testing.T) {
 					component := <-componentChannel
 					expected := &topology.Component{
@@ -160,13 +184,24 @@ func TestPersistentVolumeCollector(t *testing.T) {
 							"identifiers":       []string{},
 							"kind":              "some-specified-kind",
 							"generateName":      "some-specified-generation",
-							"status":            coreV1.VolumeAvailable,
-							"statusMessage":     "Volume is available for use",
+							"status":            coreV1.VolumeFailed,
+							"statusMessage":     "Volume failed to attach",
 							"storageClassName":  "Storage-Class-Name",
 						},
 					}
 					assert.EqualValues(t, expected, component)
 				},
+				func(t *testing.T) {
+					checkState := <-healthChannel
+					expected := &health.CheckState{
+						CheckStateID:              "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-3:persistent-volume-phase",
+						Name:                      "Persistent Volume Phase",
+						Health:                    health.Critical,
+						TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:persistent-volume/test-persistent-volume-3",
+						Message:                   "Persistent volume is Failed: Volume failed to attach",
+					}
+					assert.EqualValues(t, expected, checkState)
+				},
 			},
 		},
 	} {
@@ -226,6 +261,10 @@ func (m MockPersistentVolumeAPICollectorClient) GetPersistentVolumes() ([]coreV1
 			}
 			persistentVolume.TypeMeta.Kind = "some-specified-kind"
 			persistentVolume.ObjectMeta.GenerateName = "some-specified-generation"
+			persistentVolume.Status = coreV1.PersistentVolumeStatus{
+				Phase:   coreV1.VolumeFailed,
+				Message: "Volume failed to attach",
+			}
 		}
 
 		persistentVolumes = append(persistentVolumes, persistentVolume)
@@ -5,6 +5,7 @@ package topologycollectors
 import (
 	"fmt"
 
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	v1 "k8s.io/api/core/v1"
@@ -14,6 +15,7 @@ import (
 type PodCollector struct {
 	ComponentChan     chan<- *topology.Component
 	RelationChan      chan<- *topology.Relation
+	HealthChan        chan<- *health.CheckState
 	ContainerCorrChan chan<- *ContainerCorrelation
 	VolumeCorrChan    chan<- *VolumeCorrelation
 	ClusterTopologyCollector
@@ -27,12 +29,13 @@ type ContainerPort struct {
 
 // NewPodCollector
 func NewPodCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation,
-	containerCorrChannel chan<- *ContainerCorrelation, volumeCorrChannel chan<- *VolumeCorrelation,
+	healthChannel chan<- *health.CheckState, containerCorrChannel chan<- *ContainerCorrelation, volumeCorrChannel chan<- *VolumeCorrelation,
 	clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 
 	return &PodCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
+		HealthChan:               healthChannel,
 		ContainerCorrChan:        containerCorrChannel,
 		VolumeCorrChan:           volumeCorrChannel,
 		ClusterTopologyCollector: clusterTopologyCollector,
@@ -46,7 +49,7 @@ func (*PodCollector) GetName() string {
 
 // Collects and Published the Pod Components
 func (pc *PodCollector) CollectorFunction() error {
-	pods, err := pc.GetAPIClient().GetPods()
+	pods, err := pc.getPods()
 	if err != nil {
 		return err
 	}
@@ -58,6 +61,7 @@ func (pc *PodCollector) CollectorFunction() error {
 		// creates and publishes StackState pod component with relations
 		component = pc.podToStackStateComponent(pod)
 		pc.ComponentChan <- component
+		pc.HealthChan <- pc.podToCrashLoopCheckState(pod)
 
 		// pod could not be scheduled for some reason
 		if pod.Spec.NodeName != "" {
@@ -202,6 +206,24 @@ func (pc *PodCollector) podToStackStateComponent(pod v1.Pod) *topology.Component
 	return component
 }
 
+// Creates the CrashLoopBackOff health check state of a Kubernetes / OpenShift Pod
+func (pc *PodCollector) podToCrashLoopCheckState(pod v1.Pod) *health.CheckState {
+	podExternalID := pc.buildPodExternalID(pod.Namespace, pod.Name)
+
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopBackOffReason {
+			message := fmt.Sprintf("Container %s is in %s after %d restarts", status.Name, crashLoopBackOffReason, status.RestartCount)
+			if status.State.Waiting.Message != "" {
+				message = fmt.Sprintf("%s: %s", message, status.State.Waiting.Message)
+			}
+			return pc.CreateCheckState(podExternalID, PodCrashLoopHealthCheck, health.Critical, message)
+		}
+	}
+
+	return pc.CreateCheckState(podExternalID, PodCrashLoopHealthCheck, health.Clear, "")
+}
+
 // podTags creates the tags for a pod
 func (pc *PodCollector) podTags(pod v1.Pod) map[string]string {
 	tags := pc.initTags(pod.ObjectMeta)
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
@@ -21,12 +22,20 @@ import (
 
 var configMap coreV1.ConfigMapVolumeSource
 var secret coreV1.SecretVolumeSource
+var crashLoopBackOffState = coreV1.ContainerState{
+	Waiting: &coreV1.ContainerStateWaiting{
+		Reason:  "CrashLoopBackOff",
+		Message: "back-off 5m0s restarting failed container",
+	},
+}
 
 func TestPodCollector(t *testing.T) {
 	componentChannel := make(chan *topology.Component)
 	defer close(componentChannel)
 	relationChannel := make(chan *topology.Relation)
 	defer close(relationChannel)
+	healthChannel := make(chan *health.CheckState)
+	defer close(healthChannel)
 	containerCorrelationChannel := make(chan *ContainerCorrelation)
 	volumeCorrelationChannel := make(chan *VolumeCorrelation)
 
@@ -51,7 +60,7 @@ func TestPodCollector(t *testing.T) {
 		Type: &pathType,
 	}
 
-	ic := NewPodCollector(componentChannel, relationChannel, containerCorrelationChannel, volumeCorrelationChannel, NewTestCommonClusterCollector(MockPodAPICollectorClient{}))
+	ic := NewPodCollector(componentChannel, relationChannel, healthChannel, containerCorrelationChannel, volumeCorrelationChannel, NewTestCommonClusterCollector(MockPodAPICollectorClient{}))
 	expectedCollectorName := "Pod Collector"
 	RunCollectorTest(t, ic, expectedCollectorName)
 
@@ -86,6 +95,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-1", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-1"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-1"),
 			},
@@ -123,6 +133,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-2", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-2"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-2"),
 			},
@@ -154,6 +165,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-3", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-3"),
 				func() {
 					relation := <-relationChannel
@@ -244,6 +256,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-4", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-4"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-4"),
 				func() {
@@ -282,6 +295,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-5", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-5"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-5"),
 				func() {
@@ -337,6 +351,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-6", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-6"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-6"),
 				func() {
@@ -392,6 +407,8 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-7", health.Critical,
+					"Container container-2 is in CrashLoopBackOff after 5 restarts: back-off 5m0s restarting failed container"),
 				expectPodNodeRelation(t, relationChannel, "test-pod-7"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-7"),
 				func() {
@@ -412,8 +429,10 @@ func TestPodCollector(t *testing.T) {
 								Image: "docker/image/repo/container-1:latest",
 							},
 							{
-								Name:  "container-2",
-								Image: "docker/image/repo/container-2:latest",
+								Name:         "container-2",
+								Image:        "docker/image/repo/container-2:latest",
+								RestartCount: 5,
+								State:        crashLoopBackOffState,
 							},
 						},
 					}
@@ -448,6 +467,7 @@ func TestPodCollector(t *testing.T) {
 					}
 					assert.EqualValues(t, expectedComponent, component)
 				},
+				expectPodCrashLoopHealth(t, healthChannel, "test-pod-8", health.Clear, ""),
 				expectPodNodeRelation(t, relationChannel, "test-pod-8"),
 				expectNamespaceRelation(t, relationChannel, "test-pod-8"),
 				func() {
@@ -587,8 +607,10 @@ func (m MockPodAPICollectorClient) GetPods() ([]coreV1.Pod, error) {
 					Image: "docker/image/repo/container-1:latest",
 				},
 				{
-					Name:  "container-2",
-					Image: "docker/image/repo/container-2:latest",
+					Name:         "container-2",
+					Image:        "docker/image/repo/container-2:latest",
+					RestartCount: 5,
+					State:        crashLoopBackOffState,
 				},
 			}
 		}
@@ -621,6 +643,21 @@ func expectNamespaceRelation(t *testing.T, ch chan *topology.Relation, podName s
 	}
 }
 
+func expectPodCrashLoopHealth(t *testing.T, ch chan *health.CheckState, podName string, state health.State, message string) func() {
+	return func() {
+		checkState := <-ch
+		podExternalID := fmt.Sprintf("urn:kubernetes:/test-cluster-name:test-namespace:pod/%s", podName)
+		expected := &health.CheckState{
+			CheckStateID:              podExternalID + ":pod-crashloopbackoff",
+			Name:                      "Pod CrashLoopBackOff",
+			Health:                    state,
+			TopologyElementIdentifier: podExternalID,
+			Message:                   message,
+		}
+		assert.EqualValues(t, expected, checkState)
+	}
+}
+
 func expectPodNodeRelation(t *testing.T, ch chan *topology.Relation, podName string) func() {
 	return func() {
 		relation := <-ch
@@ -0,0 +1,134 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"errors"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodDisruptionBudgetCollector implements the ClusterTopologyCollector interface.
+type PodDisruptionBudgetCollector struct {
+	ComponentChan chan<- *topology.Component
+	RelationChan  chan<- *topology.Relation
+	ClusterTopologyCollector
+}
+
+// NewPodDisruptionBudgetCollector
+func NewPodDisruptionBudgetCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	return &PodDisruptionBudgetCollector{
+		ComponentChan:            componentChannel,
+		RelationChan:             relationChannel,
+		ClusterTopologyCollector: clusterTopologyCollector,
+	}
+}
+
+// GetName returns the name of the Collector
+func (*PodDisruptionBudgetCollector) GetName() string {
+	return "PodDisruptionBudget Collector"
+}
+
+// Collects and Published the PodDisruptionBudget Components
+func (pdbc *PodDisruptionBudgetCollector) CollectorFunction() error {
+	pdbs, err := pdbc.GetAPIClient().GetPodDisruptionBudgets()
+	if errors.Is(err, apiserver.ErrResourceNotCollected) {
+		log.Debugf("Skipping the PodDisruptionBudgets: %s", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pods, err := pdbc.getPods()
+	if err != nil {
+		return err
+	}
+
+	for _, pdb := range pdbs {
+		component := pdbc.podDisruptionBudgetToStackStateComponent(pdb)
+		pdbc.ComponentChan <- component
+
+		pdbc.RelationChan <- pdbc.namespaceToPodDisruptionBudgetStackStateRelation(pdbc.buildNamespaceExternalID(pdb.Namespace), component.ExternalID)
+
+		// a missing or empty selector of a policy/v1beta1 PodDisruptionBudget selects no pods
+		if pdb.Spec.Selector == nil || (len(pdb.Spec.Selector.MatchLabels) == 0 && len(pdb.Spec.Selector.MatchExpressions) == 0) {
+			continue
+		}
+		selectedPods, err := selectPods(pods, pdb.Namespace, pdb.Spec.Selector)
+		if err != nil {
+			_ = log.Errorf("Invalid pod selector of PodDisruptionBudget '%s': %s", component.ExternalID, err)
+			continue
+		}
+		for _, pod := range selectedPods {
+			pdbc.RelationChan <- pdbc.podDisruptionBudgetToPodStackStateRelation(component.ExternalID, pdbc.buildPodExternalID(pod.Namespace, pod.Name))
+		}
+	}
+
+	return nil
+}
+
+// Creates a StackState PodDisruptionBudget component from a Kubernetes / OpenShift Cluster
+func (pdbc *PodDisruptionBudgetCollector) podDisruptionBudgetToStackStateComponent(pdb v1beta1.PodDisruptionBudget) *topology.Component {
+	log.Tracef("Mapping PodDisruptionBudget to StackState component: %s", pdb.String())
+
+	tags := pdbc.initTags(pdb.ObjectMeta)
+
+	pdbExternalID := pdbc.buildPodDisruptionBudgetExternalID(pdb.Namespace, pdb.Name)
+	component := &topology.Component{
+		ExternalID: pdbExternalID,
+		Type:       topology.Type{Name: "pod-disruption-budget"},
+		Data: map[string]interface{}{
+			"name":               pdb.Name,
+			"creationTimestamp":  pdb.CreationTimestamp,
+			"tags":               tags,
+			"uid":                pdb.UID,
+			"currentHealthy":     pdb.Status.CurrentHealthy,
+			"desiredHealthy":     pdb.Status.DesiredHealthy,
+			"disruptionsAllowed": pdb.Status.PodDisruptionsAllowed,
+			"expectedPods":       pdb.Status.ExpectedPods,
+		},
+	}
+
+	if pdb.Spec.Selector != nil {
+		component.Data.PutNonEmpty("selector", metav1.FormatLabelSelector(pdb.Spec.Selector))
+	}
+	if pdb.Spec.MinAvailable != nil {
+		component.Data.PutNonEmpty("minAvailable", pdb.Spec.MinAvailable.String())
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		component.Data.PutNonEmpty("maxUnavailable", pdb.Spec.MaxUnavailable.String())
+	}
+	component.Data.PutNonEmpty("generateName", pdb.GenerateName)
+	component.Data.PutNonEmpty("kind", pdb.Kind)
+
+	log.Tracef("Created StackState PodDisruptionBudget component %s: %v", pdbExternalID, component.JSONString())
+
+	return component
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift Namespace to PodDisruptionBudget relation
+func (pdbc *PodDisruptionBudgetCollector) namespaceToPodDisruptionBudgetStackStateRelation(namespaceExternalID, pdbExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes namespace to pod disruption budget relation: %s -> %s", namespaceExternalID, pdbExternalID)
+
+	relation := pdbc.CreateRelation(namespaceExternalID, pdbExternalID, "encloses")
+
+	log.Tracef("Created StackState namespace -> pod disruption budget relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift PodDisruptionBudget to a Pod it protects
+func (pdbc *PodDisruptionBudgetCollector) podDisruptionBudgetToPodStackStateRelation(pdbExternalID, podExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes pod disruption budget to pod relation: %s -> %s", pdbExternalID, podExternalID)
+
+	relation := pdbc.CreateRelation(pdbExternalID, podExternalID, "protects")
+
+	log.Tracef("Created StackState pod disruption budget -> pod relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
@@ -0,0 +1,152 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/api/policy/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPodDisruptionBudgetCollector(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
+
+	pdbc := NewPodDisruptionBudgetCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockPodDisruptionBudgetAPICollectorClient{}))
+	expectedCollectorName := "PodDisruptionBudget Collector"
+	RunCollectorTest(t, pdbc, expectedCollectorName)
+
+	for _, tc := range []struct {
+		testCase     string
+		expected     *topology.Component
+		expectedPods []string
+	}{
+		{
+			testCase: "Test PodDisruptionBudget 1 - Protects the pods with matching labels",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:pod-disruption-budget/test-pdb-1",
+				Type:       topology.Type{Name: "pod-disruption-budget"},
+				Data: topology.Data{
+					"name":               "test-pdb-1",
+					"creationTimestamp":  creationTime,
+					"tags":               map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":                types.UID("test-pdb-1"),
+					"selector":           "app=frontend",
+					"minAvailable":       "1",
+					"currentHealthy":     int32(2),
+					"desiredHealthy":     int32(1),
+					"disruptionsAllowed": int32(1),
+					"expectedPods":       int32(2),
+				},
+			},
+			expectedPods: []string{"frontend-1", "frontend-2"},
+		},
+		{
+			testCase: "Test PodDisruptionBudget 2 - Empty selector protects no pods",
+			expected: &topology.Component{
+				ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:pod-disruption-budget/test-pdb-2",
+				Type:       topology.Type{Name: "pod-disruption-budget"},
+				Data: topology.Data{
+					"name":               "test-pdb-2",
+					"creationTimestamp":  creationTime,
+					"tags":               map[string]string{"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace"},
+					"uid":                types.UID("test-pdb-2"),
+					"selector":           "<none>",
+					"maxUnavailable":     "25%",
+					"currentHealthy":     int32(0),
+					"desiredHealthy":     int32(0),
+					"disruptionsAllowed": int32(0),
+					"expectedPods":       int32(0),
+				},
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			component := <-componentChannel
+			assert.EqualValues(t, tc.expected, component)
+
+			actualRelation := <-relationChannel
+			expectedRelation := &topology.Relation{
+				ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->" + component.ExternalID,
+				Type:       topology.Type{Name: "encloses"},
+				SourceID:   "urn:kubernetes:/test-cluster-name:namespace/test-namespace",
+				TargetID:   component.ExternalID,
+				Data:       map[string]interface{}{},
+			}
+			assert.EqualValues(t, expectedRelation, actualRelation)
+
+			for _, pod := range tc.expectedPods {
+				podExternalID := "urn:kubernetes:/test-cluster-name:test-namespace:pod/" + pod
+				actualRelation := <-relationChannel
+				expectedRelation := &topology.Relation{
+					ExternalID: component.ExternalID + "->" + podExternalID,
+					Type:       topology.Type{Name: "protects"},
+					SourceID:   component.ExternalID,
+					TargetID:   podExternalID,
+					Data:       map[string]interface{}{},
+				}
+				assert.EqualValues(t, expectedRelation, actualRelation)
+			}
+		})
+	}
+}
+
+type MockPodDisruptionBudgetAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockPodDisruptionBudgetAPICollectorClient) GetPodDisruptionBudgets() ([]v1beta1.PodDisruptionBudget, error) {
+	minAvailable := intstr.FromInt(1)
+	maxUnavailable := intstr.FromString("25%")
+	return []v1beta1.PodDisruptionBudget{
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "test-pdb-1",
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("test-pdb-1"),
+			},
+			Spec: v1beta1.PodDisruptionBudgetSpec{
+				Selector:     &v1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}},
+				MinAvailable: &minAvailable,
+			},
+			Status: v1beta1.PodDisruptionBudgetStatus{
+				CurrentHealthy:        2,
+				DesiredHealthy:        1,
+				PodDisruptionsAllowed: 1,
+				ExpectedPods:          2,
+			},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "test-pdb-2",
+				CreationTimestamp: creationTime,
+				Namespace:         "test-namespace",
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("test-pdb-2"),
+			},
+			Spec: v1beta1.PodDisruptionBudgetSpec{
+				Selector:       &v1.LabelSelector{},
+				MaxUnavailable: &maxUnavailable,
+			},
+		},
+	}, nil
+}
+
+func (m MockPodDisruptionBudgetAPICollectorClient) GetPods() ([]coreV1.Pod, error) {
+	return selectorTestPods(), nil
+}
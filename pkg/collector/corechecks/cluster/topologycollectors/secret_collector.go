@@ -3,11 +3,8 @@
 package topologycollectors
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"sort"
-
 	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	v1 "k8s.io/api/core/v1"
 )
@@ -39,19 +36,14 @@ func (cmc *SecretCollector) CollectorFunction() error {
 	}
 
 	for _, cm := range secrets {
-		comp, err := cmc.secretToStackStateComponent(cm)
-		if err != nil {
-			return err
-		}
-
-		cmc.ComponentChan <- comp
+		cmc.ComponentChan <- cmc.secretToStackStateComponent(cm)
 	}
 
 	return nil
 }
 
 // Creates a StackState Secret component from a Kubernetes / OpenShift Cluster
-func (cmc *SecretCollector) secretToStackStateComponent(secret v1.Secret) (*topology.Component, error) {
+func (cmc *SecretCollector) secretToStackStateComponent(secret v1.Secret) *topology.Component {
 	log.Tracef("Mapping Secret to StackState component: %s", secret.String())
 
 	tags := cmc.initTags(secret.ObjectMeta)
@@ -72,48 +64,14 @@ func (cmc *SecretCollector) secretToStackStateComponent(secret v1.Secret) (*topo
 	component.Data.PutNonEmpty("generateName", secret.GenerateName)
 	component.Data.PutNonEmpty("kind", secret.Kind)
 
-	hash, err := secure(secret.Data)
-	if err != nil {
-		return nil, err
+	// the secrets of the informer cache only hold the digest of their data
+	hash, found := secret.Annotations[apiserver.SecretDataDigestAnnotation]
+	if !found {
+		hash = apiserver.SecretDataDigest(secret.Data)
 	}
 	component.Data.PutNonEmpty("data", hash)
 
 	log.Tracef("Created StackState Secret component %s: %v", secretExternalID, component.JSONString())
 
-	return component, nil
-}
-
-func secure(data map[string][]byte) (string, error) {
-	hash := sha256.New()
-	if len(data) == 0 {
-		return hex.EncodeToString(hash.Sum(nil)), nil
-	}
-
-	k := keys(data)
-	sort.Strings(k) // Sort so that we have a stable hash
-
-	for _, key := range k {
-		if _, err := hash.Write([]byte(key)); err != nil {
-			return "", err
-		}
-
-		val := data[key]
-		if _, err := hash.Write(val); err != nil {
-			return "", err
-		}
-	}
-
-	return hex.EncodeToString(hash.Sum(nil)), nil
-}
-
-func keys(data map[string][]byte) []string {
-	keys := make([]string, len(data))
-	i := 0
-
-	for k := range data {
-		keys[i] = k
-		i++
-	}
-
-	return keys
+	return component
 }
@@ -4,35 +4,48 @@ package topologycollectors
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks/cluster/dns"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	v1 "k8s.io/api/core/v1"
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
 )
 
+// endpointsOverCapacityAnnotation is set on Endpoints that are truncated to the first 1000 addresses
+const endpointsOverCapacityAnnotation = "endpoints.kubernetes.io/over-capacity"
+
+// addressTypeFQDN is the address type of EndpointSlices of fully qualified domain names, the v1beta1 and v1 versions of
+// the EndpointSlices use it
+const addressTypeFQDN = discoveryV1A1.AddressType("FQDN")
+
 // ServiceCollector implements the ClusterTopologyCollector interface.
 type ServiceCollector struct {
 	ComponentChan chan<- *topology.Component
 	RelationChan  chan<- *topology.Relation
 	ClusterTopologyCollector
-	DNS dns.Resolver
+	DNS           dns.Resolver
+	ClusterDomain string
 }
 
 // EndpointID contains the definition of a cluster ip
 type EndpointID struct {
 	URL           string
 	RefExternalID string
+	// DNSName is the DNS name of the endpoint when its service is headless
+	DNSName string
 }
 
 // NewServiceCollector
 func NewServiceCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation,
-	clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	clusterDomain string, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 	return &ServiceCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
 		ClusterTopologyCollector: clusterTopologyCollector,
 		DNS:                      dns.StandardResolver,
+		ClusterDomain:            clusterDomain,
 	}
 }
 
@@ -48,50 +61,17 @@ func (sc *ServiceCollector) CollectorFunction() error {
 		return err
 	}
 
-	endpoints, err := sc.GetAPIClient().GetEndpoints()
+	serviceEndpointIdentifiers, err := sc.serviceEndpointIdentifiers()
 	if err != nil {
 		return err
 	}
 
-	serviceEndpointIdentifiers := make(map[string][]EndpointID, 0)
-
-	// Get all the endpoints for the Service
-	for _, endpoint := range endpoints {
-		serviceID := buildServiceID(endpoint.Namespace, endpoint.Name)
-		for _, subset := range endpoint.Subsets {
-			for _, address := range subset.Addresses {
-				for _, port := range subset.Ports {
-					endpointID := EndpointID{
-						URL: fmt.Sprintf("%s:%d", address.IP, port.Port),
-					}
-
-					// check if the target reference is populated, so we can create relations
-					if address.TargetRef != nil {
-						switch kind := address.TargetRef.Kind; kind {
-						// add endpoint url as identifier, will be used for service -> pod relation
-						case "Pod":
-							if address.TargetRef.Namespace != "" {
-								endpointID.RefExternalID = sc.buildPodExternalID(address.TargetRef.Namespace, address.TargetRef.Name)
-							} else {
-								endpointID.RefExternalID = sc.buildPodExternalID(endpoint.Namespace, address.TargetRef.Name)
-							}
-						// ignore different Kind's for now, create no relation
-						default:
-						}
-					}
-
-					serviceEndpointIdentifiers[serviceID] = append(serviceEndpointIdentifiers[serviceID], endpointID)
-				}
-			}
-		}
-	}
-
 	serviceMap := make(map[string][]string)
 
 	for _, service := range services {
 		// creates and publishes StackState service component with relations
 		serviceID := buildServiceID(service.Namespace, service.Name)
-		component := sc.serviceToStackStateComponent(service)
+		component := sc.serviceToStackStateComponent(service, serviceEndpointIdentifiers[serviceID])
 
 		sc.ComponentChan <- component
 
@@ -128,8 +108,138 @@ func (sc *ServiceCollector) CollectorFunction() error {
 	return nil
 }
 
+// serviceEndpointIdentifiers gets the endpoints of all the services from the EndpointSlices, which are not truncated
+// for large services and contain the addresses of both ip families. The Endpoints are used when the API server does not
+// serve the EndpointSlices
+func (sc *ServiceCollector) serviceEndpointIdentifiers() (map[string][]EndpointID, error) {
+	endpointSlices, err := sc.GetAPIClient().GetEndpointSlices()
+	if err == nil {
+		return sc.endpointSliceIdentifiers(endpointSlices), nil
+	}
+	log.Debugf("Could not get the EndpointSlices, falling back to the Endpoints: %s", err)
+
+	endpoints, err := sc.GetAPIClient().GetEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.endpointIdentifiers(endpoints), nil
+}
+
+// endpointSliceIdentifiers gets the endpoints of the services from the ready endpoints of their EndpointSlices
+func (sc *ServiceCollector) endpointSliceIdentifiers(endpointSlices []discoveryV1A1.EndpointSlice) map[string][]EndpointID {
+	serviceEndpointIdentifiers := make(map[string][]EndpointID, 0)
+
+	for _, endpointSlice := range endpointSlices {
+		serviceName, ok := endpointSlice.Labels[discoveryV1A1.LabelServiceName]
+		if !ok {
+			// EndpointSlices that are not managed for a service are not related to one
+			continue
+		}
+		serviceID := buildServiceID(endpointSlice.Namespace, serviceName)
+
+		for _, endpoint := range endpointSlice.Endpoints {
+			// an unknown readiness is interpreted as ready
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+
+			hostname := ""
+			if endpoint.Hostname != nil {
+				hostname = *endpoint.Hostname
+			}
+
+			for _, address := range endpoint.Addresses {
+				for _, port := range endpointSlice.Ports {
+					if port.Port == nil {
+						continue
+					}
+
+					endpointID := EndpointID{
+						URL: fmt.Sprintf("%s:%d", address, *port.Port),
+					}
+					// fully qualified domain names are not served by the DNS of the cluster
+					if endpointSlice.AddressType == nil || *endpointSlice.AddressType != addressTypeFQDN {
+						endpointID.DNSName = sc.podDNSName(hostname, address, endpointSlice.Namespace, serviceName)
+					}
+					endpointID.RefExternalID = sc.targetRefExternalID(endpoint.TargetRef, endpointSlice.Namespace)
+
+					serviceEndpointIdentifiers[serviceID] = append(serviceEndpointIdentifiers[serviceID], endpointID)
+				}
+			}
+		}
+	}
+
+	return serviceEndpointIdentifiers
+}
+
+// endpointIdentifiers gets the endpoints of the services from their Endpoints
+func (sc *ServiceCollector) endpointIdentifiers(endpoints []v1.Endpoints) map[string][]EndpointID {
+	serviceEndpointIdentifiers := make(map[string][]EndpointID, 0)
+
+	// Get all the endpoints for the Service
+	for _, endpoint := range endpoints {
+		if _, ok := endpoint.Annotations[endpointsOverCapacityAnnotation]; ok {
+			log.Warnf("The Endpoints of service %s/%s are truncated, not all pods of the service are related to it", endpoint.Namespace, endpoint.Name)
+		}
+
+		serviceID := buildServiceID(endpoint.Namespace, endpoint.Name)
+		for _, subset := range endpoint.Subsets {
+			for _, address := range subset.Addresses {
+				for _, port := range subset.Ports {
+					endpointID := EndpointID{
+						URL:     fmt.Sprintf("%s:%d", address.IP, port.Port),
+						DNSName: sc.podDNSName(address.Hostname, address.IP, endpoint.Namespace, endpoint.Name),
+					}
+
+					// check if the target reference is populated, so we can create relations
+					endpointID.RefExternalID = sc.targetRefExternalID(address.TargetRef, endpoint.Namespace)
+
+					serviceEndpointIdentifiers[serviceID] = append(serviceEndpointIdentifiers[serviceID], endpointID)
+				}
+			}
+		}
+	}
+
+	return serviceEndpointIdentifiers
+}
+
+// targetRefExternalID returns the external id of the pod that is the target of an endpoint, used for the service ->
+// pod relation
+func (sc *ServiceCollector) targetRefExternalID(targetRef *v1.ObjectReference, namespace string) string {
+	if targetRef == nil {
+		return ""
+	}
+
+	switch kind := targetRef.Kind; kind {
+	// add endpoint url as identifier, will be used for service -> pod relation
+	case "Pod":
+		if targetRef.Namespace != "" {
+			return sc.buildPodExternalID(targetRef.Namespace, targetRef.Name)
+		}
+		return sc.buildPodExternalID(namespace, targetRef.Name)
+	// ignore different Kind's for now, create no relation
+	default:
+		return ""
+	}
+}
+
+// podDNSName returns the DNS name of a pod behind a headless service, which is its hostname or else its dashed ip
+// address in the domain of the service
+func (sc *ServiceCollector) podDNSName(hostname, address, namespace, serviceName string) string {
+	if hostname == "" {
+		hostname = strings.NewReplacer(".", "-", ":", "-").Replace(address)
+	}
+	return fmt.Sprintf("%s.%s", hostname, sc.serviceDNSName(namespace, serviceName))
+}
+
+// serviceDNSName returns the DNS name of a service in the cluster domain
+func (sc *ServiceCollector) serviceDNSName(namespace, serviceName string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", serviceName, namespace, sc.ClusterDomain)
+}
+
 // Creates a StackState component from a Kubernetes / OpenShift Service
-func (sc *ServiceCollector) serviceToStackStateComponent(service v1.Service) *topology.Component {
+func (sc *ServiceCollector) serviceToStackStateComponent(service v1.Service, endpoints []EndpointID) *topology.Component {
 	log.Tracef("Mapping kubernetes pod service to StackState component: %s", service.String())
 	// create identifier list to merge with StackState components
 	identifiers := make([]string, 0)
@@ -193,6 +303,19 @@ func (sc *ServiceCollector) serviceToStackStateComponent(service v1.Service) *to
 		}
 	}
 
+	// headless services have no cluster ip, they are addressed by their DNS name and the DNS names of their pods
+	if service.Spec.ClusterIP == "None" {
+		identifiers = append(identifiers, sc.buildEndpointExternalID(sc.serviceDNSName(service.Namespace, service.Name)))
+
+		dnsNames := make(map[string]bool, 0)
+		for _, endpoint := range endpoints {
+			if endpoint.DNSName != "" && !dnsNames[endpoint.DNSName] {
+				identifiers = append(identifiers, sc.buildEndpointExternalID(endpoint.DNSName))
+				dnsNames[endpoint.DNSName] = true
+			}
+		}
+	}
+
 	// add identifier for this service name
 	serviceID := buildServiceID(service.Namespace, service.Name)
 	identifiers = append(identifiers, fmt.Sprintf("urn:service:/%s:%s", sc.GetInstance().URL, serviceID))
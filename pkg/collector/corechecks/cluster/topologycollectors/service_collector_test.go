@@ -15,6 +15,7 @@ import (
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
 	coreV1 "k8s.io/api/core/v1"
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -29,7 +30,7 @@ func TestServiceCollector(t *testing.T) {
 
 	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
 
-	cjc := NewServiceCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockServiceAPICollectorClient{}))
+	cjc := NewServiceCollector(componentChannel, relationChannel, "cluster.local", NewTestCommonClusterCollector(MockServiceAPICollectorClient{}))
 	// Mock out DNS resolution function for test
 	cjc.(*ServiceCollector).DNS = func(name string) ([]string, error) {
 		return []string{"10.10.42.42", "10.10.42.43"}, nil
@@ -177,8 +178,11 @@ func TestServiceCollector(t *testing.T) {
 						"tags": map[string]string{
 							"test": "label", "cluster-name": "test-cluster-name", "namespace": "test-namespace", "service": "headless", "service-type": "ClusterIP",
 						},
-						"uid":         types.UID("test-service-5"),
-						"identifiers": []string{"urn:service:/test-cluster-name:test-namespace:test-service-5"},
+						"uid": types.UID("test-service-5"),
+						"identifiers": []string{
+							"urn:endpoint:/test-cluster-name:test-service-5.test-namespace.svc.cluster.local",
+							"urn:service:/test-cluster-name:test-namespace:test-service-5",
+						},
 					},
 				},
 			},
@@ -405,6 +409,10 @@ func (m MockServiceAPICollectorClient) GetServices() ([]coreV1.Service, error) {
 	return services, nil
 }
 
+func (m MockServiceAPICollectorClient) GetEndpointSlices() ([]discoveryV1A1.EndpointSlice, error) {
+	return nil, apiserver.ErrEndpointSlicesNotServed
+}
+
 func (m MockServiceAPICollectorClient) GetEndpoints() ([]coreV1.Endpoints, error) {
 	endpoints := make([]coreV1.Endpoints, 0)
 	// endpoints for test case 1
@@ -464,3 +472,158 @@ func (m MockServiceAPICollectorClient) GetEndpoints() ([]coreV1.Endpoints, error
 
 	return endpoints, nil
 }
+
+func TestServiceCollectorEndpointSlices(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
+
+	sc := NewServiceCollector(componentChannel, relationChannel, "cluster.example", NewTestCommonClusterCollector(MockEndpointSliceServiceAPICollectorClient{}))
+	expectedCollectorName := "Service Collector"
+	RunCollectorTest(t, sc, expectedCollectorName)
+
+	for _, tc := range []struct {
+		testCase           string
+		expectedComponents []*topology.Component
+		expectedPods       []string
+	}{
+		{
+			testCase: "Dual-stack service - Pod relations from the EndpointSlices of both ip families",
+			expectedComponents: []*topology.Component{
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:service/dual-stack",
+					Type:       topology.Type{Name: "service"},
+					Data: topology.Data{
+						"name":              "dual-stack",
+						"creationTimestamp": creationTime,
+						"tags":              map[string]string{"cluster-name": "test-cluster-name", "namespace": "test-namespace", "service-type": "ClusterIP"},
+						"uid":               types.UID("dual-stack"),
+						"identifiers": []string{
+							"urn:endpoint:/test-cluster-name:10.100.200.30",
+							"urn:service:/test-cluster-name:test-namespace:dual-stack",
+						},
+					},
+				},
+			},
+			// the not ready pod is not exposed, the pod of both ip families is related once
+			expectedPods: []string{"pod-1", "pod-2", "pod-3"},
+		},
+		{
+			testCase: "Headless service - Identifiers for the DNS names of the service and its pods",
+			expectedComponents: []*topology.Component{
+				{
+					ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:service/headless",
+					Type:       topology.Type{Name: "service"},
+					Data: topology.Data{
+						"name":              "headless",
+						"creationTimestamp": creationTime,
+						"tags":              map[string]string{"cluster-name": "test-cluster-name", "namespace": "test-namespace", "service": "headless", "service-type": "ClusterIP"},
+						"uid":               types.UID("headless"),
+						"identifiers": []string{
+							"urn:endpoint:/test-cluster-name:headless.test-namespace.svc.cluster.example",
+							"urn:endpoint:/test-cluster-name:web-0.headless.test-namespace.svc.cluster.example",
+							"urn:endpoint:/test-cluster-name:10-0-0-5.headless.test-namespace.svc.cluster.example",
+							"urn:service:/test-cluster-name:test-namespace:headless",
+						},
+					},
+				},
+			},
+			expectedPods: []string{"web-0", "web-1"},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			for _, expectedComponent := range tc.expectedComponents {
+				component := <-componentChannel
+				assert.EqualValues(t, expectedComponent, component)
+			}
+
+			namespaceRelation := <-relationChannel
+			assert.Equal(t, "encloses", namespaceRelation.Type.Name)
+
+			for _, expectedPod := range tc.expectedPods {
+				podRelation := <-relationChannel
+				assert.EqualValues(t, &topology.Relation{
+					ExternalID: fmt.Sprintf("%s->urn:kubernetes:/test-cluster-name:test-namespace:pod/%s", tc.expectedComponents[0].ExternalID, expectedPod),
+					Type:       topology.Type{Name: "exposes"},
+					SourceID:   tc.expectedComponents[0].ExternalID,
+					TargetID:   fmt.Sprintf("urn:kubernetes:/test-cluster-name:test-namespace:pod/%s", expectedPod),
+					Data:       map[string]interface{}{},
+				}, podRelation)
+			}
+		})
+	}
+}
+
+type MockEndpointSliceServiceAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockEndpointSliceServiceAPICollectorClient) GetServices() ([]coreV1.Service, error) {
+	return []coreV1.Service{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "dual-stack", Namespace: "test-namespace", CreationTimestamp: creationTime, UID: types.UID("dual-stack")},
+			Spec:       coreV1.ServiceSpec{Type: coreV1.ServiceTypeClusterIP, ClusterIP: "10.100.200.30"},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "headless", Namespace: "test-namespace", CreationTimestamp: creationTime, UID: types.UID("headless")},
+			Spec:       coreV1.ServiceSpec{Type: coreV1.ServiceTypeClusterIP, ClusterIP: "None"},
+		},
+	}, nil
+}
+
+func (m MockEndpointSliceServiceAPICollectorClient) GetEndpointSlices() ([]discoveryV1A1.EndpointSlice, error) {
+	ipv4 := discoveryV1A1.AddressType("IPv4")
+	ipv6 := discoveryV1A1.AddressType("IPv6")
+	ready := true
+	notReady := false
+	hostname := "web-0"
+	port := int32(8080)
+	podRef := func(name string) *coreV1.ObjectReference {
+		return &coreV1.ObjectReference{Kind: "Pod", Name: name, Namespace: "test-namespace"}
+	}
+	serviceLabels := func(service string) map[string]string {
+		return map[string]string{discoveryV1A1.LabelServiceName: service}
+	}
+
+	return []discoveryV1A1.EndpointSlice{
+		{
+			ObjectMeta:  v1.ObjectMeta{Name: "dual-stack-ipv4", Namespace: "test-namespace", Labels: serviceLabels("dual-stack")},
+			AddressType: &ipv4,
+			Endpoints: []discoveryV1A1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryV1A1.EndpointConditions{Ready: &ready}, TargetRef: podRef("pod-1")},
+				{Addresses: []string{"10.0.0.2"}, TargetRef: podRef("pod-2")},
+				{Addresses: []string{"10.0.0.4"}, Conditions: discoveryV1A1.EndpointConditions{Ready: &notReady}, TargetRef: podRef("pod-4")},
+			},
+			Ports: []discoveryV1A1.EndpointPort{{Port: &port}},
+		},
+		{
+			ObjectMeta:  v1.ObjectMeta{Name: "dual-stack-ipv6", Namespace: "test-namespace", Labels: serviceLabels("dual-stack")},
+			AddressType: &ipv6,
+			Endpoints: []discoveryV1A1.Endpoint{
+				{Addresses: []string{"fd00::1"}, TargetRef: podRef("pod-1")},
+				{Addresses: []string{"fd00::3"}, TargetRef: podRef("pod-3")},
+			},
+			Ports: []discoveryV1A1.EndpointPort{{Port: &port}},
+		},
+		{
+			// EndpointSlices without a service are not related
+			ObjectMeta:  v1.ObjectMeta{Name: "custom", Namespace: "test-namespace"},
+			AddressType: &ipv4,
+			Endpoints:   []discoveryV1A1.Endpoint{{Addresses: []string{"10.0.0.9"}, TargetRef: podRef("pod-9")}},
+			Ports:       []discoveryV1A1.EndpointPort{{Port: &port}},
+		},
+		{
+			ObjectMeta:  v1.ObjectMeta{Name: "headless-ipv4", Namespace: "test-namespace", Labels: serviceLabels("headless")},
+			AddressType: &ipv4,
+			Endpoints: []discoveryV1A1.Endpoint{
+				{Addresses: []string{"10.0.0.6"}, Hostname: &hostname, TargetRef: podRef("web-0")},
+				{Addresses: []string{"10.0.0.5"}, TargetRef: podRef("web-1")},
+			},
+			Ports: []discoveryV1A1.EndpointPort{{Port: &port}},
+		},
+	}, nil
+}
@@ -3,6 +3,7 @@
 package topologycollectors
 
 import (
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"k8s.io/api/apps/v1"
@@ -12,14 +13,16 @@ import (
 type StatefulSetCollector struct {
 	ComponentChan chan<- *topology.Component
 	RelationChan  chan<- *topology.Relation
+	HealthChan    chan<- *health.CheckState
 	ClusterTopologyCollector
 }
 
 // NewStatefulSetCollector
-func NewStatefulSetCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+func NewStatefulSetCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, healthChannel chan<- *health.CheckState, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
 	return &StatefulSetCollector{
 		ComponentChan:            componentChannel,
 		RelationChan:             relationChannel,
+		HealthChan:               healthChannel,
 		ClusterTopologyCollector: clusterTopologyCollector,
 	}
 }
@@ -39,6 +42,7 @@ func (ssc *StatefulSetCollector) CollectorFunction() error {
 	for _, ss := range statefulSets {
 		component := ssc.statefulSetToStackStateComponent(ss)
 		ssc.ComponentChan <- component
+		ssc.HealthChan <- ssc.statefulSetToReplicasCheckState(ss)
 		ssc.RelationChan <- ssc.namespaceToStatefulSetStackStateRelation(ssc.buildNamespaceExternalID(ss.Namespace), component.ExternalID)
 	}
 
@@ -85,3 +89,9 @@ func (ssc *StatefulSetCollector) namespaceToStatefulSetStackStateRelation(namesp
 
 	return relation
 }
+
+// Creates the available replicas health check state of a Kubernetes / OpenShift StatefulSet
+func (ssc *StatefulSetCollector) statefulSetToReplicasCheckState(statefulSet v1.StatefulSet) *health.CheckState {
+	state, message := replicasHealthState(desiredReplicas(statefulSet.Spec.Replicas), statefulSet.Status.ReadyReplicas)
+	return ssc.CreateCheckState(ssc.buildStatefulSetExternalID(statefulSet.Namespace, statefulSet.Name), ReplicasAvailableHealthCheck, state, message)
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
 	"github.com/stretchr/testify/assert"
@@ -25,18 +26,21 @@ func TestStatefulSetCollector(t *testing.T) {
 	defer close(componentChannel)
 	relationChannel := make(chan *topology.Relation)
 	defer close(relationChannel)
+	healthChannel := make(chan *health.CheckState)
+	defer close(healthChannel)
 
 	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
 
 	replicas = int32(1)
 
-	cmc := NewStatefulSetCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockStatefulSetAPICollectorClient{}))
+	cmc := NewStatefulSetCollector(componentChannel, relationChannel, healthChannel, NewTestCommonClusterCollector(MockStatefulSetAPICollectorClient{}))
 	expectedCollectorName := "StatefulSet Collector"
 	RunCollectorTest(t, cmc, expectedCollectorName)
 
 	for _, tc := range []struct {
-		testCase string
-		expected *topology.Component
+		testCase       string
+		expected       *topology.Component
+		expectedHealth *health.CheckState
 	}{
 		{
 			testCase: "Test StatefulSet 1",
@@ -54,6 +58,12 @@ func TestStatefulSetCollector(t *testing.T) {
 					"serviceName":         "statefulset-service-name",
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset-1:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Clear,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset-1",
+			},
 		},
 		{
 			testCase: "Test StatefulSet 2",
@@ -71,6 +81,13 @@ func TestStatefulSetCollector(t *testing.T) {
 					"serviceName":         "statefulset-service-name",
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset-2:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Critical,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset-2",
+				Message:                   "1 of 1 desired replicas are unavailable",
+			},
 		},
 		{
 			testCase: "Test StatefulSet 3 - Kind + Generate Name",
@@ -90,12 +107,21 @@ func TestStatefulSetCollector(t *testing.T) {
 					"serviceName":         "statefulset-service-name",
 				},
 			},
+			expectedHealth: &health.CheckState{
+				CheckStateID:              "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset-3:replicas-available",
+				Name:                      "Replicas Available",
+				Health:                    health.Clear,
+				TopologyElementIdentifier: "urn:kubernetes:/test-cluster-name:test-namespace:statefulset/test-statefulset-3",
+			},
 		},
 	} {
 		t.Run(tc.testCase, func(t *testing.T) {
 			component := <-componentChannel
 			assert.EqualValues(t, tc.expected, component)
 
+			checkState := <-healthChannel
+			assert.EqualValues(t, tc.expectedHealth, checkState)
+
 			actualRelation := <-relationChannel
 			expectedRelation := &topology.Relation{
 				ExternalID: "urn:kubernetes:/test-cluster-name:namespace/test-namespace->" + component.ExternalID,
@@ -138,6 +164,13 @@ func (m MockStatefulSetAPICollectorClient) GetStatefulSets() ([]appsV1.StatefulS
 				PodManagementPolicy: appsV1.OrderedReadyPodManagement,
 				ServiceName:         "statefulset-service-name",
 			},
+			Status: appsV1.StatefulSetStatus{
+				ReadyReplicas: replicas,
+			},
+		}
+
+		if i == 2 {
+			statefulSet.Status.ReadyReplicas = 0
 		}
 
 		if i == 3 {
@@ -0,0 +1,115 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"errors"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	v1 "k8s.io/api/storage/v1"
+)
+
+// StorageClassCollector implements the ClusterTopologyCollector interface.
+type StorageClassCollector struct {
+	ComponentChan chan<- *topology.Component
+	RelationChan  chan<- *topology.Relation
+	ClusterTopologyCollector
+}
+
+// NewStorageClassCollector
+func NewStorageClassCollector(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, clusterTopologyCollector ClusterTopologyCollector) ClusterTopologyCollector {
+	return &StorageClassCollector{
+		ComponentChan:            componentChannel,
+		RelationChan:             relationChannel,
+		ClusterTopologyCollector: clusterTopologyCollector,
+	}
+}
+
+// GetName returns the name of the Collector
+func (*StorageClassCollector) GetName() string {
+	return "StorageClass Collector"
+}
+
+// Collects and Published the StorageClass Components and relates the persistent volume claims to the StorageClass they
+// request their volume from
+func (scc *StorageClassCollector) CollectorFunction() error {
+	storageClasses, err := scc.GetAPIClient().GetStorageClasses()
+	if errors.Is(err, apiserver.ErrResourceNotCollected) {
+		log.Debugf("Skipping the StorageClasses: %s", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, sc := range storageClasses {
+		scc.ComponentChan <- scc.storageClassToStackStateComponent(sc)
+	}
+
+	persistentVolumeClaims, err := scc.GetAPIClient().GetPersistentVolumeClaims()
+	if err != nil {
+		return err
+	}
+
+	for _, pvc := range persistentVolumeClaims {
+		// a claim without a storage class name is provisioned by the default storage class, an empty name disables
+		// dynamic provisioning
+		if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+			continue
+		}
+
+		scc.RelationChan <- scc.persistentVolumeClaimToStorageClassStackStateRelation(scc.buildPersistentVolumeClaimExternalID(pvc.Namespace, pvc.Name), scc.buildStorageClassExternalID(*pvc.Spec.StorageClassName))
+	}
+
+	return nil
+}
+
+// Creates a StackState StorageClass component from a Kubernetes / OpenShift Cluster
+func (scc *StorageClassCollector) storageClassToStackStateComponent(sc v1.StorageClass) *topology.Component {
+	log.Tracef("Mapping StorageClass to StackState component: %s", sc.String())
+
+	tags := scc.initTags(sc.ObjectMeta)
+
+	storageClassExternalID := scc.buildStorageClassExternalID(sc.Name)
+	component := &topology.Component{
+		ExternalID: storageClassExternalID,
+		Type:       topology.Type{Name: "storage-class"},
+		Data: map[string]interface{}{
+			"name":              sc.Name,
+			"creationTimestamp": sc.CreationTimestamp,
+			"tags":              tags,
+			"uid":               sc.UID,
+			"provisioner":       sc.Provisioner,
+		},
+	}
+
+	component.Data.PutNonEmpty("parameters", sc.Parameters)
+	if sc.ReclaimPolicy != nil {
+		component.Data.PutNonEmpty("reclaimPolicy", string(*sc.ReclaimPolicy))
+	}
+	if sc.VolumeBindingMode != nil {
+		component.Data.PutNonEmpty("volumeBindingMode", string(*sc.VolumeBindingMode))
+	}
+	if sc.AllowVolumeExpansion != nil {
+		component.Data.PutNonEmpty("allowVolumeExpansion", *sc.AllowVolumeExpansion)
+	}
+	component.Data.PutNonEmpty("generateName", sc.GenerateName)
+	component.Data.PutNonEmpty("kind", sc.Kind)
+
+	log.Tracef("Created StackState StorageClass component %s: %v", storageClassExternalID, component.JSONString())
+
+	return component
+}
+
+// Creates a StackState relation from a Kubernetes / OpenShift PersistentVolumeClaim to the StorageClass it is provisioned by
+func (scc *StorageClassCollector) persistentVolumeClaimToStorageClassStackStateRelation(persistentVolumeClaimExternalID, storageClassExternalID string) *topology.Relation {
+	log.Tracef("Mapping kubernetes persistent volume claim to storage class relation: %s -> %s", persistentVolumeClaimExternalID, storageClassExternalID)
+
+	relation := scc.CreateRelation(persistentVolumeClaimExternalID, storageClassExternalID, "provisioned_by")
+
+	log.Tracef("Created StackState persistent volume claim -> storage class relation %s->%s", relation.SourceID, relation.TargetID)
+
+	return relation
+}
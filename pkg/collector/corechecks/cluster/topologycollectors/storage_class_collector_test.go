@@ -0,0 +1,100 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/kubernetes/apiserver"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	storageV1 "k8s.io/api/storage/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStorageClassCollector(t *testing.T) {
+
+	componentChannel := make(chan *topology.Component)
+	defer close(componentChannel)
+	relationChannel := make(chan *topology.Relation)
+	defer close(relationChannel)
+
+	creationTime = v1.Time{Time: time.Now().Add(-1 * time.Hour)}
+
+	scc := NewStorageClassCollector(componentChannel, relationChannel, NewTestCommonClusterCollector(MockStorageClassAPICollectorClient{}))
+	expectedCollectorName := "StorageClass Collector"
+	RunCollectorTest(t, scc, expectedCollectorName)
+
+	component := <-componentChannel
+	assert.EqualValues(t, &topology.Component{
+		ExternalID: "urn:kubernetes:/test-cluster-name:storage-class/standard",
+		Type:       topology.Type{Name: "storage-class"},
+		Data: topology.Data{
+			"name":                 "standard",
+			"creationTimestamp":    creationTime,
+			"tags":                 map[string]string{"test": "label", "cluster-name": "test-cluster-name"},
+			"uid":                  types.UID("standard"),
+			"provisioner":          "kubernetes.io/aws-ebs",
+			"parameters":           map[string]string{"type": "gp2"},
+			"reclaimPolicy":        "Delete",
+			"volumeBindingMode":    "WaitForFirstConsumer",
+			"allowVolumeExpansion": true,
+		},
+	}, component)
+
+	// only the persistent volume claim with a storage class name is related
+	relation := <-relationChannel
+	assert.EqualValues(t, &topology.Relation{
+		ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:persistent-volume-claim/test-persistent-volume-claim-1->urn:kubernetes:/test-cluster-name:storage-class/standard",
+		Type:       topology.Type{Name: "provisioned_by"},
+		SourceID:   "urn:kubernetes:/test-cluster-name:test-namespace:persistent-volume-claim/test-persistent-volume-claim-1",
+		TargetID:   "urn:kubernetes:/test-cluster-name:storage-class/standard",
+		Data:       map[string]interface{}{},
+	}, relation)
+}
+
+type MockStorageClassAPICollectorClient struct {
+	apiserver.APICollectorClient
+}
+
+func (m MockStorageClassAPICollectorClient) GetStorageClasses() ([]storageV1.StorageClass, error) {
+	reclaimPolicy := coreV1.PersistentVolumeReclaimDelete
+	volumeBindingMode := storageV1.VolumeBindingWaitForFirstConsumer
+	allowVolumeExpansion := true
+	return []storageV1.StorageClass{
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              "standard",
+				CreationTimestamp: creationTime,
+				Labels:            map[string]string{"test": "label"},
+				UID:               types.UID("standard"),
+			},
+			Provisioner:          "kubernetes.io/aws-ebs",
+			Parameters:           map[string]string{"type": "gp2"},
+			ReclaimPolicy:        &reclaimPolicy,
+			VolumeBindingMode:    &volumeBindingMode,
+			AllowVolumeExpansion: &allowVolumeExpansion,
+		},
+	}, nil
+}
+
+func (m MockStorageClassAPICollectorClient) GetPersistentVolumeClaims() ([]coreV1.PersistentVolumeClaim, error) {
+	storageClassName := "standard"
+	noStorageClassName := ""
+	return []coreV1.PersistentVolumeClaim{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "test-persistent-volume-claim-1", Namespace: "test-namespace"},
+			Spec:       coreV1.PersistentVolumeClaimSpec{StorageClassName: &storageClassName},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "test-persistent-volume-claim-2", Namespace: "test-namespace"},
+			Spec:       coreV1.PersistentVolumeClaimSpec{StorageClassName: &noStorageClassName},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "test-persistent-volume-claim-3", Namespace: "test-namespace"},
+		},
+	}, nil
+}
@@ -4,8 +4,10 @@ package topologycollectors
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks/cluster/urn"
+	"github.com/StackVista/stackstate-agent/pkg/health"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
 	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	v1 "k8s.io/api/core/v1"
@@ -39,15 +41,17 @@ type VolumeCorrelation struct {
 type VolumeCorrelator struct {
 	ComponentChan  chan<- *topology.Component
 	RelationChan   chan<- *topology.Relation
+	HealthChan     chan<- *health.CheckState
 	VolumeCorrChan <-chan *VolumeCorrelation
 	ClusterTopologyCorrelator
 }
 
 // NewVolumeCorrelator instantiates the VolumeCorrelator
-func NewVolumeCorrelator(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, volumeCorrChannel chan *VolumeCorrelation, clusterTopologyCorrelator ClusterTopologyCorrelator) ClusterTopologyCorrelator {
+func NewVolumeCorrelator(componentChannel chan<- *topology.Component, relationChannel chan<- *topology.Relation, healthChannel chan<- *health.CheckState, volumeCorrChannel chan *VolumeCorrelation, clusterTopologyCorrelator ClusterTopologyCorrelator) ClusterTopologyCorrelator {
 	return &VolumeCorrelator{
 		ComponentChan:             componentChannel,
 		RelationChan:              relationChannel,
+		HealthChan:                healthChannel,
 		VolumeCorrChan:            volumeCorrChannel,
 		ClusterTopologyCorrelator: clusterTopologyCorrelator,
 	}
@@ -80,6 +84,11 @@ func (vc *VolumeCorrelator) CorrelateFunction() error {
 			}
 		}
 
+		// only pods that claim persistent volumes get a health state for their claims
+		if checkState := vc.podToVolumeClaimsCheckState(pod, volumeCorrelation.Volumes, pvcLookup); checkState != nil {
+			vc.HealthChan <- checkState
+		}
+
 		for _, container := range volumeCorrelation.Containers {
 			for _, mount := range container.VolumeMounts {
 				volumeExternalID, ok := volumeLookup[mount.Name]
@@ -98,9 +107,10 @@ func (vc *VolumeCorrelator) CorrelateFunction() error {
 	return nil
 }
 
-// buildPersistentVolumeClaimLookup builds a lookup table of PersistentVolumeClaim.Name to PersistentVolume.Name
-func (vc *VolumeCorrelator) buildPersistentVolumeClaimLookup() (map[string]string, error) {
-	pvcMapping := map[string]string{}
+// buildPersistentVolumeClaimLookup builds a lookup table of PersistentVolumeClaim namespace and name to
+// PersistentVolumeClaim, claims are namespaced so claims with the same name in different namespaces are distinct
+func (vc *VolumeCorrelator) buildPersistentVolumeClaimLookup() (map[string]v1.PersistentVolumeClaim, error) {
+	pvcMapping := map[string]v1.PersistentVolumeClaim{}
 
 	pvcs, err := vc.GetAPIClient().GetPersistentVolumeClaims()
 	if err != nil {
@@ -108,20 +118,59 @@ func (vc *VolumeCorrelator) buildPersistentVolumeClaimLookup() (map[string]strin
 	}
 
 	for _, persistentVolumeClaim := range pvcs {
-		pvcMapping[persistentVolumeClaim.Name] = vc.buildPersistentVolumeExternalID(persistentVolumeClaim.Spec.VolumeName)
+		pvcMapping[persistentVolumeClaimKey(persistentVolumeClaim.Namespace, persistentVolumeClaim.Name)] = persistentVolumeClaim
 	}
 
 	return pvcMapping, nil
 }
 
+// persistentVolumeClaimKey returns the key of a PersistentVolumeClaim in the lookup table
+func persistentVolumeClaimKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// podToVolumeClaimsCheckState creates the health check state of the persistent volume claims of a Pod, returns nil when
+// the Pod does not claim any persistent volume
+func (vc *VolumeCorrelator) podToVolumeClaimsCheckState(pod PodIdentifier, volumes []v1.Volume, pvcMapping map[string]v1.PersistentVolumeClaim) *health.CheckState {
+	claims := 0
+	state := health.Clear
+	problems := make([]string, 0)
+	for _, volume := range volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claims++
+
+		claim, ok := pvcMapping[persistentVolumeClaimKey(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)]
+		if !ok {
+			continue
+		}
+
+		switch claim.Status.Phase {
+		case v1.ClaimPending:
+			state = health.Worst(state, health.Deviating)
+			problems = append(problems, fmt.Sprintf("PersistentVolumeClaim %s is %s", claim.Name, claim.Status.Phase))
+		case v1.ClaimLost:
+			state = health.Worst(state, health.Critical)
+			problems = append(problems, fmt.Sprintf("PersistentVolumeClaim %s is %s", claim.Name, claim.Status.Phase))
+		}
+	}
+
+	if claims == 0 {
+		return nil
+	}
+
+	return vc.CreateCheckState(pod.ExternalID, VolumeClaimsBoundHealthCheck, state, strings.Join(problems, ", "))
+}
+
 // mapVolumeAndRelationToStackState sends (potential) Volume component to StackState and relates it to the Pod, returning the ExternalID of the Volume component
-func (vc *VolumeCorrelator) mapVolumeAndRelationToStackState(pod PodIdentifier, volume v1.Volume, pvcMapping map[string]string) (string, error) {
+func (vc *VolumeCorrelator) mapVolumeAndRelationToStackState(pod PodIdentifier, volume v1.Volume, pvcMapping map[string]v1.PersistentVolumeClaim) (string, error) {
 	var volumeExternalID string
 
 	if volume.DownwardAPI != nil {
 		return "", nil // The downward API does not need a volume
 	} else if volume.PersistentVolumeClaim != nil {
-		claimedPVExtID, ok := pvcMapping[volume.PersistentVolumeClaim.ClaimName]
+		claim, ok := pvcMapping[persistentVolumeClaimKey(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)]
 
 		if !ok {
 			log.Errorf("Unknown PersistentVolumeClaim '%s' referenced from Pod '%s'", volume.PersistentVolumeClaim.ClaimName, pod.ExternalID)
@@ -129,7 +178,7 @@ func (vc *VolumeCorrelator) mapVolumeAndRelationToStackState(pod PodIdentifier,
 			return "", nil
 		}
 
-		volumeExternalID = claimedPVExtID
+		volumeExternalID = vc.buildPersistentVolumeExternalID(claim.Spec.VolumeName)
 	} else {
 		var toCreate *VolumeComponentsToCreate
 		var err error
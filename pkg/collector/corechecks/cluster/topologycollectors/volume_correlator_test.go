@@ -0,0 +1,98 @@
+// +build kubeapiserver
+
+package topologycollectors
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVolumeCorrelatorVolumeClaimsHealth(t *testing.T) {
+	clusterTopologyCommon := NewClusterTopologyCommon(topology.Instance{Type: "kubernetes", URL: "test-cluster-name"}, nil)
+	vc := NewVolumeCorrelator(nil, nil, nil, nil, NewClusterTopologyCorrelator(clusterTopologyCommon)).(*VolumeCorrelator)
+
+	pod := PodIdentifier{
+		ExternalID: "urn:kubernetes:/test-cluster-name:test-namespace:pod/test-pod",
+		Namespace:  "test-namespace",
+		Name:       "test-pod",
+	}
+	pvcLookup := map[string]coreV1.PersistentVolumeClaim{}
+	for _, claim := range []coreV1.PersistentVolumeClaim{
+		persistentVolumeClaim("test-namespace", "bound-claim", coreV1.ClaimBound),
+		persistentVolumeClaim("test-namespace", "pending-claim", coreV1.ClaimPending),
+		persistentVolumeClaim("test-namespace", "lost-claim", coreV1.ClaimLost),
+		// a claim with the same name in another namespace is not used by the pod
+		persistentVolumeClaim("other-namespace", "bound-claim", coreV1.ClaimLost),
+	} {
+		pvcLookup[persistentVolumeClaimKey(claim.Namespace, claim.Name)] = claim
+	}
+
+	for _, tc := range []struct {
+		testCase string
+		volumes  []coreV1.Volume
+		expected *health.CheckState
+	}{
+		{
+			testCase: "Pod without persistent volume claims",
+			volumes:  []coreV1.Volume{{Name: "empty-dir"}},
+			expected: nil,
+		},
+		{
+			testCase: "Pod with bound claim",
+			volumes:  []coreV1.Volume{claimVolume("bound-claim")},
+			expected: &health.CheckState{
+				CheckStateID:              pod.ExternalID + ":volume-claims-bound",
+				Name:                      "Volume Claims Bound",
+				Health:                    health.Clear,
+				TopologyElementIdentifier: pod.ExternalID,
+			},
+		},
+		{
+			testCase: "Pod with pending claim",
+			volumes:  []coreV1.Volume{claimVolume("bound-claim"), claimVolume("pending-claim")},
+			expected: &health.CheckState{
+				CheckStateID:              pod.ExternalID + ":volume-claims-bound",
+				Name:                      "Volume Claims Bound",
+				Health:                    health.Deviating,
+				TopologyElementIdentifier: pod.ExternalID,
+				Message:                   "PersistentVolumeClaim pending-claim is Pending",
+			},
+		},
+		{
+			testCase: "Pod with pending and lost claim",
+			volumes:  []coreV1.Volume{claimVolume("pending-claim"), claimVolume("lost-claim")},
+			expected: &health.CheckState{
+				CheckStateID:              pod.ExternalID + ":volume-claims-bound",
+				Name:                      "Volume Claims Bound",
+				Health:                    health.Critical,
+				TopologyElementIdentifier: pod.ExternalID,
+				Message:                   "PersistentVolumeClaim pending-claim is Pending, PersistentVolumeClaim lost-claim is Lost",
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			assert.EqualValues(t, tc.expected, vc.podToVolumeClaimsCheckState(pod, tc.volumes, pvcLookup))
+		})
+	}
+}
+
+func persistentVolumeClaim(namespace, name string, phase coreV1.PersistentVolumeClaimPhase) coreV1.PersistentVolumeClaim {
+	return coreV1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     coreV1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func claimVolume(claimName string) coreV1.Volume {
+	return coreV1.Volume{
+		Name: claimName,
+		VolumeSource: coreV1.VolumeSource{
+			PersistentVolumeClaim: &coreV1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	}
+}
@@ -37,8 +37,14 @@ type Builder interface {
 	BuildVolumeExternalID(namespace, volumeName string) string
 	BuildExternalVolumeExternalID(volumeType string, volumeComponents ...string) string
 	BuildPersistentVolumeExternalID(persistentVolumeName string) string
+	BuildPersistentVolumeClaimExternalID(namespace, persistentVolumeClaimName string) string
 	BuildComponentExternalID(component, namespace, name string) string
 	BuildEndpointExternalID(endpointID string) string
+	BuildHorizontalPodAutoscalerExternalID(namespace, horizontalPodAutoscalerName string) string
+	BuildNetworkPolicyExternalID(namespace, networkPolicyName string) string
+	BuildPodDisruptionBudgetExternalID(namespace, podDisruptionBudgetName string) string
+	BuildStorageClassExternalID(storageClassName string) string
+	BuildCustomResourceExternalID(group, kind, namespace, customResourceName string) string
 }
 
 type urnBuilder struct {
@@ -96,8 +102,18 @@ func (b *urnBuilder) BuildExternalID(kind, namespace, objName string) (string, e
 		urn = b.BuildVolumeExternalID(namespace, objName)
 	case "PersistentVolume":
 		urn = b.BuildPersistentVolumeExternalID(objName)
+	case "PersistentVolumeClaim":
+		urn = b.BuildPersistentVolumeClaimExternalID(namespace, objName)
 	case "Endpoint":
 		urn = b.BuildEndpointExternalID(objName)
+	case "HorizontalPodAutoscaler":
+		urn = b.BuildHorizontalPodAutoscalerExternalID(namespace, objName)
+	case "NetworkPolicy":
+		urn = b.BuildNetworkPolicyExternalID(namespace, objName)
+	case "PodDisruptionBudget":
+		urn = b.BuildPodDisruptionBudgetExternalID(namespace, objName)
+	case "StorageClass":
+		urn = b.BuildStorageClassExternalID(objName)
 	}
 
 	if urn == "" {
@@ -196,6 +212,41 @@ func (b *urnBuilder) BuildPersistentVolumeExternalID(persistentVolumeName string
 	return b.BuildComponentExternalID("persistent-volume", "", persistentVolumeName)
 }
 
+// BuildPersistentVolumeClaimExternalID creates the urn external identifier for a cluster persistent volume claim
+func (b *urnBuilder) BuildPersistentVolumeClaimExternalID(namespace, persistentVolumeClaimName string) string {
+	return b.BuildComponentExternalID("persistent-volume-claim", namespace, persistentVolumeClaimName)
+}
+
+// BuildHorizontalPodAutoscalerExternalID creates the urn external identifier for a cluster horizontal pod autoscaler
+func (b *urnBuilder) BuildHorizontalPodAutoscalerExternalID(namespace, horizontalPodAutoscalerName string) string {
+	return b.BuildComponentExternalID("horizontal-pod-autoscaler", namespace, horizontalPodAutoscalerName)
+}
+
+// BuildNetworkPolicyExternalID creates the urn external identifier for a cluster network policy
+func (b *urnBuilder) BuildNetworkPolicyExternalID(namespace, networkPolicyName string) string {
+	return b.BuildComponentExternalID("network-policy", namespace, networkPolicyName)
+}
+
+// BuildPodDisruptionBudgetExternalID creates the urn external identifier for a cluster pod disruption budget
+func (b *urnBuilder) BuildPodDisruptionBudgetExternalID(namespace, podDisruptionBudgetName string) string {
+	return b.BuildComponentExternalID("pod-disruption-budget", namespace, podDisruptionBudgetName)
+}
+
+// BuildStorageClassExternalID creates the urn external identifier for a cluster storage class
+func (b *urnBuilder) BuildStorageClassExternalID(storageClassName string) string {
+	return b.BuildComponentExternalID("storage-class", "", storageClassName)
+}
+
+// BuildCustomResourceExternalID creates the urn external identifier for a cluster custom resource, the component is
+// the lower cased kind qualified with the api group so custom resources never clash with the built-in resources
+func (b *urnBuilder) BuildCustomResourceExternalID(group, kind, namespace, customResourceName string) string {
+	component := strings.ToLower(kind)
+	if group != "" {
+		component = fmt.Sprintf("%s.%s", component, group)
+	}
+	return b.BuildComponentExternalID(component, namespace, customResourceName)
+}
+
 // BuildComponentExternalID creates the urn external identifier for a specific component type
 func (b *urnBuilder) BuildComponentExternalID(component, namespace, name string) string {
 	if namespace != "" {
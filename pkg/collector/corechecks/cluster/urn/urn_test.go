@@ -0,0 +1,97 @@
+package urn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildExternalIDs(t *testing.T) {
+	builder := NewURNBuilder(Kubernetes, "test-cluster-name")
+
+	for _, tc := range []struct {
+		testCase string
+		actual   string
+		expected string
+	}{
+		{
+			testCase: "HorizontalPodAutoscaler",
+			actual:   builder.BuildHorizontalPodAutoscalerExternalID("test-namespace", "test-hpa"),
+			expected: "urn:kubernetes:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa",
+		},
+		{
+			testCase: "NetworkPolicy",
+			actual:   builder.BuildNetworkPolicyExternalID("test-namespace", "test-network-policy"),
+			expected: "urn:kubernetes:/test-cluster-name:test-namespace:network-policy/test-network-policy",
+		},
+		{
+			testCase: "PodDisruptionBudget",
+			actual:   builder.BuildPodDisruptionBudgetExternalID("test-namespace", "test-pdb"),
+			expected: "urn:kubernetes:/test-cluster-name:test-namespace:pod-disruption-budget/test-pdb",
+		},
+		{
+			testCase: "StorageClass is not namespaced",
+			actual:   builder.BuildStorageClassExternalID("standard"),
+			expected: "urn:kubernetes:/test-cluster-name:storage-class/standard",
+		},
+		{
+			testCase: "PersistentVolumeClaim",
+			actual:   builder.BuildPersistentVolumeClaimExternalID("test-namespace", "test-pvc"),
+			expected: "urn:kubernetes:/test-cluster-name:test-namespace:persistent-volume-claim/test-pvc",
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.actual)
+		})
+	}
+}
+
+func TestBuildExternalIDByKind(t *testing.T) {
+	builder := NewURNBuilder(OpenShift, "test-cluster-name")
+
+	for _, tc := range []struct {
+		kind      string
+		namespace string
+		name      string
+		expected  string
+	}{
+		{
+			kind:      "HorizontalPodAutoscaler",
+			namespace: "test-namespace",
+			name:      "test-hpa",
+			expected:  "urn:openshift:/test-cluster-name:test-namespace:horizontal-pod-autoscaler/test-hpa",
+		},
+		{
+			kind:      "NetworkPolicy",
+			namespace: "test-namespace",
+			name:      "test-network-policy",
+			expected:  "urn:openshift:/test-cluster-name:test-namespace:network-policy/test-network-policy",
+		},
+		{
+			kind:      "PodDisruptionBudget",
+			namespace: "test-namespace",
+			name:      "test-pdb",
+			expected:  "urn:openshift:/test-cluster-name:test-namespace:pod-disruption-budget/test-pdb",
+		},
+		{
+			kind:     "StorageClass",
+			name:     "standard",
+			expected: "urn:openshift:/test-cluster-name:storage-class/standard",
+		},
+		{
+			kind:      "PersistentVolumeClaim",
+			namespace: "test-namespace",
+			name:      "test-pvc",
+			expected:  "urn:openshift:/test-cluster-name:test-namespace:persistent-volume-claim/test-pvc",
+		},
+	} {
+		t.Run(tc.kind, func(t *testing.T) {
+			actual, err := builder.BuildExternalID(tc.kind, tc.namespace, tc.name)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	_, err := builder.BuildExternalID("UnknownKind", "test-namespace", "test-name")
+	assert.Error(t, err)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
 	core "github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks/containers/topology"
 	"github.com/StackVista/stackstate-agent/pkg/metrics"
 	"github.com/StackVista/stackstate-agent/pkg/tagger"
 	"github.com/StackVista/stackstate-agent/pkg/tagger/collectors"
@@ -45,12 +46,15 @@ type ContainerdCheck struct {
 	instance *ContainerdConfig
 	sub      *subscriber
 	filters  *ddContainers.Filter
+	// sts
+	topologyCollector *topology.ContainerdTopologyCollector
 }
 
 // ContainerdConfig contains the custom options and configurations set by the user.
 type ContainerdConfig struct {
-	ContainerdFilters []string `yaml:"filters"`
-	CollectEvents     bool     `yaml:"collect_events"`
+	ContainerdFilters        []string `yaml:"filters"`
+	CollectEvents            bool     `yaml:"collect_events"`
+	CollectContainerTopology bool     `yaml:"collect_container_topology"`
 }
 
 func init() {
@@ -60,14 +64,18 @@ func init() {
 // ContainerdFactory is used to create register the check and initialize it.
 func ContainerdFactory() check.Check {
 	return &ContainerdCheck{
-		CheckBase: corechecks.NewCheckBase(containerdCheckName),
-		instance:  &ContainerdConfig{},
-		sub:       &subscriber{},
+		CheckBase:         corechecks.NewCheckBase(containerdCheckName),
+		instance:          &ContainerdConfig{},
+		sub:               &subscriber{},
+		topologyCollector: topology.MakeContainerdTopologyCollector(),
 	}
 }
 
 // Parse is used to get the configuration set by the user
 func (co *ContainerdConfig) Parse(data []byte) error {
+	// default values
+	co.CollectContainerTopology = true
+
 	if err := yaml.Unmarshal(data, co); err != nil {
 		return err
 	}
@@ -128,6 +136,14 @@ func (c *ContainerdCheck) Run() error {
 	}
 
 	computeMetrics(sender, cu, c.filters)
+
+	// sts
+	// Collect container topology
+	if c.instance.CollectContainerTopology {
+		if err := c.topologyCollector.BuildContainerTopology(cu, c.filters); err != nil {
+			c.Warnf("Could not collect container topology: %s", err) //nolint:errcheck
+		}
+	}
 	return nil
 }
 
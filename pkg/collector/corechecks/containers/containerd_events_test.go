@@ -33,6 +33,7 @@ type mockItf struct {
 	mockImageSize   func(ctn containerd.Container) (int64, error)
 	mockTaskMetrics func(ctn containerd.Container) (*types.Metric, error)
 	mockTaskPids    func(ctn containerd.Container) ([]containerd.ProcessInfo, error)
+	mockTaskStatus  func(ctn containerd.Container) (containerd.Status, error)
 	mockInfo        func(ctn containerd.Container) (containers.Container, error)
 	mockNamespace   func() string
 	mockSpec        func(ctn containerd.Container) (*oci.Spec, error)
@@ -54,6 +55,10 @@ func (m *mockItf) TaskPids(ctn containerd.Container) ([]containerd.ProcessInfo,
 	return m.mockTaskPids(ctn)
 }
 
+func (m *mockItf) TaskStatus(ctn containerd.Container) (containerd.Status, error) {
+	return m.mockTaskStatus(ctn)
+}
+
 func (m *mockItf) Metadata() (containerd.Version, error) {
 	return m.mockMetadata()
 }
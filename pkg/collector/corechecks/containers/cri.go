@@ -17,6 +17,7 @@ import (
 	"github.com/StackVista/stackstate-agent/pkg/autodiscovery/integration"
 	"github.com/StackVista/stackstate-agent/pkg/collector/check"
 	core "github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks/containers/topology"
 	"github.com/StackVista/stackstate-agent/pkg/tagger"
 	"github.com/StackVista/stackstate-agent/pkg/tagger/collectors"
 	"github.com/StackVista/stackstate-agent/pkg/util/containers"
@@ -30,13 +31,16 @@ const (
 
 // CRIConfig holds the config of the check
 type CRIConfig struct {
-	CollectDisk bool `yaml:"collect_disk"`
+	CollectDisk              bool `yaml:"collect_disk"`
+	CollectContainerTopology bool `yaml:"collect_container_topology"`
 }
 
 // CRICheck grabs CRI metrics
 type CRICheck struct {
 	core.CheckBase
 	instance *CRIConfig
+	// sts
+	topologyCollector *topology.CRITopologyCollector
 }
 
 func init() {
@@ -46,8 +50,9 @@ func init() {
 // CRIFactory is exported for integration testing
 func CRIFactory() check.Check {
 	return &CRICheck{
-		CheckBase: core.NewCheckBase(criCheckName),
-		instance:  &CRIConfig{},
+		CheckBase:         core.NewCheckBase(criCheckName),
+		instance:          &CRIConfig{},
+		topologyCollector: topology.MakeCRITopologyCollector(),
 	}
 }
 
@@ -55,6 +60,7 @@ func CRIFactory() check.Check {
 func (c *CRIConfig) Parse(data []byte) error {
 	// default values
 	c.CollectDisk = false
+	c.CollectContainerTopology = true
 
 	if err := yaml.Unmarshal(data, c); err != nil {
 		return err
@@ -92,6 +98,14 @@ func (c *CRICheck) Run() error {
 	}
 	c.generateMetrics(sender, containerStats, util)
 
+	// sts
+	// Collect container topology
+	if c.instance.CollectContainerTopology {
+		if err := c.topologyCollector.BuildContainerTopology(util); err != nil {
+			c.Warnf("Could not collect container topology: %s", err) //nolint:errcheck
+		}
+	}
+
 	sender.Commit()
 	return nil
 }
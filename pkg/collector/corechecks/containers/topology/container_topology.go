@@ -0,0 +1,72 @@
+package topology
+
+import (
+	"fmt"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+)
+
+const (
+	containerType = "container"
+	// containerHostRelationType is the relation type between a container and the host it runs on
+	containerHostRelationType = "runs_on"
+)
+
+// buildContainerExternalID creates the external identifier of a container component, it is the same for all runtimes
+func buildContainerExternalID(containerID string) string {
+	return fmt.Sprintf("urn:%s:/%s", containerType, containerID)
+}
+
+// buildHostExternalID creates the external identifier of the host component produced by the disk topology check
+func buildHostExternalID(hostname string) string {
+	return fmt.Sprintf("urn:host:/%s", hostname)
+}
+
+// createContainerHostRelations creates a relation from every container component to the host
+func createContainerHostRelations(hostname string, containerComponents []*topology.Component) []*topology.Relation {
+	hostExternalID := buildHostExternalID(hostname)
+	relations := make([]*topology.Relation, 0, len(containerComponents))
+	for _, component := range containerComponents {
+		relations = append(relations, createRelation(component.ExternalID, hostExternalID, containerHostRelationType))
+	}
+	return relations
+}
+
+// collectContainerHostRelations creates the relations from the container components to the host of the agent. The
+// containers are still reported when the host is unknown, only the relations are skipped.
+func collectContainerHostRelations(containerComponents []*topology.Component) []*topology.Relation {
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Can't get hostname for host running the containers, not reporting container to host relations: %s", err)
+		return []*topology.Relation{}
+	}
+	return createContainerHostRelations(hostname, containerComponents)
+}
+
+// createRelation creates a topology.Relation between two components
+func createRelation(sourceExternalID, targetExternalID, typeName string) *topology.Relation {
+	return &topology.Relation{
+		ExternalID: fmt.Sprintf("%s->%s", sourceExternalID, targetExternalID),
+		SourceID:   sourceExternalID,
+		TargetID:   targetExternalID,
+		Type:       topology.Type{Name: typeName},
+		Data:       topology.Data{},
+	}
+}
+
+// submitTopology submits the collected topology components and relations and completes the check run
+func submitTopology(ct corechecks.CheckTopologyCollector, sender batcher.Batcher, components []*topology.Component, relations []*topology.Relation) {
+	for _, component := range components {
+		sender.SubmitComponent(ct.CheckID, ct.TopologyInstance, *component)
+	}
+
+	for _, relation := range relations {
+		sender.SubmitRelation(ct.CheckID, ct.TopologyInstance, *relation)
+	}
+
+	sender.SubmitComplete(ct.CheckID)
+}
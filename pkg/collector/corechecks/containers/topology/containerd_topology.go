@@ -0,0 +1,144 @@
+// +build containerd
+
+package topology
+
+import (
+	"errors"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	cutil "github.com/StackVista/stackstate-agent/pkg/util/containerd"
+	ddContainers "github.com/StackVista/stackstate-agent/pkg/util/containers"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/containerd/containerd"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	containerdTopologyCheckName = "containerd_topology"
+	containerdRuntime           = "containerd"
+	// kubernetesContainerNameLabel is set by the CRI plugin, containerd itself has no container names
+	kubernetesContainerNameLabel = "io.kubernetes.container.name"
+	kubernetesNamespaceLabel     = "io.kubernetes.pod.namespace"
+)
+
+// ContainerdTopologyCollector contains the checkID and topology instance for the containerd topology check
+type ContainerdTopologyCollector struct {
+	corechecks.CheckTopologyCollector
+}
+
+// MakeContainerdTopologyCollector returns a new instance of ContainerdTopologyCollector
+func MakeContainerdTopologyCollector() *ContainerdTopologyCollector {
+	return &ContainerdTopologyCollector{
+		corechecks.MakeCheckTopologyCollector(containerdTopologyCheckName, topology.Instance{
+			Type: containerdRuntime,
+			URL:  "agents",
+		}),
+	}
+}
+
+// BuildContainerTopology collects all containerd container topology, containers excluded by the filter are skipped
+func (ct *ContainerdTopologyCollector) BuildContainerTopology(cu cutil.ContainerdItf, fil *ddContainers.Filter) error {
+	sender := batcher.GetBatcher()
+	if sender == nil {
+		return errors.New("no batcher instance available, skipping BuildContainerTopology")
+	}
+
+	// collect all containers as topology components
+	containerComponents, err := ct.collectContainers(cu, fil)
+	if err != nil {
+		return err
+	}
+
+	// submit all collected topology components and their relations to the host
+	submitTopology(ct.CheckTopologyCollector, sender, containerComponents, collectContainerHostRelations(containerComponents))
+
+	return nil
+}
+
+// collectContainers collects containers from the containerd util and produces topology.Component
+func (ct *ContainerdTopologyCollector) collectContainers(cu cutil.ContainerdItf, fil *ddContainers.Filter) ([]*topology.Component, error) {
+	cList, err := cu.Containers()
+	if err != nil {
+		return nil, err
+	}
+
+	containerComponents := make([]*topology.Component, 0)
+	for _, ctn := range cList {
+		info, err := cu.Info(ctn)
+		if err != nil {
+			log.Errorf("Could not retrieve the metadata of the container %s: %s", ctn.ID(), err)
+			continue
+		}
+		// The container name is not available in Containerd, we only rely on image name and kube namespace based exclusion
+		if fil != nil && fil.IsExcluded("", info.Image, info.Labels[kubernetesNamespaceLabel]) {
+			continue
+		}
+
+		var mounts []types.MountPoint
+		spec, err := cu.Spec(ctn)
+		if err != nil {
+			log.Debugf("Could not retrieve the spec of the container %s: %s", ctn.ID(), err)
+		} else {
+			mounts = convertOCIMounts(spec.Mounts)
+		}
+
+		// containers without a task have been created but never started
+		state := containerd.Created
+		status, err := cu.TaskStatus(ctn)
+		if err != nil {
+			log.Debugf("Could not retrieve the task status of the container %s: %s", ctn.ID(), err)
+		} else {
+			state = status.Status
+		}
+
+		name, found := info.Labels[kubernetesContainerNameLabel]
+		if !found {
+			name = ctn.ID()
+		}
+
+		containerComponent := &topology.Component{
+			ExternalID: buildContainerExternalID(ctn.ID()),
+			Type:       topology.Type{Name: containerType},
+			Data: topology.Data{
+				"type":        containerdRuntime,
+				"containerID": ctn.ID(),
+				"name":        name,
+				"image":       info.Image,
+				"mounts":      mounts,
+				"state":       string(state),
+			},
+		}
+
+		containerComponents = append(containerComponents, containerComponent)
+	}
+
+	return containerComponents, nil
+}
+
+// convertOCIMounts converts the mounts of an OCI spec to the docker mount points reported by the docker topology
+func convertOCIMounts(ociMounts []specs.Mount) []types.MountPoint {
+	mounts := make([]types.MountPoint, 0, len(ociMounts))
+	for _, m := range ociMounts {
+		mountPoint := types.MountPoint{
+			Type:        mount.Type(m.Type),
+			Source:      m.Source,
+			Destination: m.Destination,
+			RW:          true,
+		}
+		for _, option := range m.Options {
+			switch option {
+			case "ro":
+				mountPoint.RW = false
+				mountPoint.Mode = option
+			case "private", "rprivate", "shared", "rshared", "slave", "rslave":
+				mountPoint.Propagation = mount.Propagation(option)
+			}
+		}
+		mounts = append(mounts, mountPoint)
+	}
+	return mounts
+}
@@ -0,0 +1,153 @@
+// +build containerd
+
+package topology
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	cutil "github.com/StackVista/stackstate-agent/pkg/util/containerd"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/oci"
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockContainer struct {
+	containerd.Container
+	id string
+}
+
+func (m mockContainer) ID() string {
+	return m.id
+}
+
+type mockContainerdItf struct {
+	cutil.ContainerdItf
+	infos    map[string]containers.Container
+	specs    map[string]*oci.Spec
+	statuses map[string]containerd.Status
+}
+
+func (m mockContainerdItf) Containers() ([]containerd.Container, error) {
+	return []containerd.Container{mockContainer{id: "running"}, mockContainer{id: "created"}, mockContainer{id: "gone"}}, nil
+}
+
+func (m mockContainerdItf) Info(ctn containerd.Container) (containers.Container, error) {
+	if info, found := m.infos[ctn.ID()]; found {
+		return info, nil
+	}
+	return containers.Container{}, errors.New("container not found")
+}
+
+func (m mockContainerdItf) Spec(ctn containerd.Container) (*oci.Spec, error) {
+	return m.specs[ctn.ID()], nil
+}
+
+func (m mockContainerdItf) TaskStatus(ctn containerd.Container) (containerd.Status, error) {
+	if status, found := m.statuses[ctn.ID()]; found {
+		return status, nil
+	}
+	return containerd.Status{}, errors.New("no running task found")
+}
+
+func TestMakeContainerdTopologyCollector(t *testing.T) {
+	ct := MakeContainerdTopologyCollector()
+	assert.Equal(t, check.ID("containerd_topology"), ct.CheckID)
+	assert.Equal(t, topology.Instance{Type: "containerd", URL: "agents"}, ct.TopologyInstance)
+}
+
+func TestContainerdTopologyCollector_BuildContainerTopology(t *testing.T) {
+	// set up the mock batcher
+	mockBatcher := batcher.NewMockBatcher()
+	// set mock hostname
+	testHostname := "test-hostname"
+	config.Datadog.Set("hostname", testHostname)
+
+	cu := mockContainerdItf{
+		infos: map[string]containers.Container{
+			"running": {
+				ID:     "running",
+				Image:  "docker.io/library/redis:latest",
+				Labels: map[string]string{"io.kubernetes.container.name": "redis"},
+			},
+			"created": {ID: "created", Image: "docker.io/library/nginx:latest"},
+		},
+		specs: map[string]*oci.Spec{
+			"running": {Mounts: []specs.Mount{
+				{Destination: "/data", Type: "bind", Source: "/var/lib/redis", Options: []string{"rbind", "rprivate", "ro"}},
+			}},
+			"created": {},
+		},
+		statuses: map[string]containerd.Status{"running": {Status: containerd.Running}},
+	}
+
+	ct := MakeContainerdTopologyCollector()
+	err := ct.BuildContainerTopology(cu, nil)
+	assert.NoError(t, err)
+
+	producedTopology := mockBatcher.CollectedTopology.Flush()
+	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
+		"containerd_topology": {
+			Health: make(map[string]health.Health),
+			Topology: &topology.Topology{
+				StartSnapshot: false,
+				StopSnapshot:  false,
+				Instance:      topology.Instance{Type: "containerd", URL: "agents"},
+				Components: []topology.Component{
+					{
+						ExternalID: "urn:container:/running",
+						Type:       topology.Type{Name: "container"},
+						Data: topology.Data{
+							"type":        "containerd",
+							"containerID": "running",
+							"name":        "redis",
+							"image":       "docker.io/library/redis:latest",
+							"mounts": []types.MountPoint{
+								{Type: "bind", Source: "/var/lib/redis", Destination: "/data", Mode: "ro", RW: false, Propagation: "rprivate"},
+							},
+							"state": "running",
+						},
+					},
+					{
+						ExternalID: "urn:container:/created",
+						Type:       topology.Type{Name: "container"},
+						Data: topology.Data{
+							"type":        "containerd",
+							"containerID": "created",
+							"name":        "created",
+							"image":       "docker.io/library/nginx:latest",
+							"mounts":      []types.MountPoint{},
+							"state":       "created",
+						},
+					},
+				},
+				Relations: []topology.Relation{
+					{
+						ExternalID: "urn:container:/running->urn:host:/test-hostname",
+						SourceID:   "urn:container:/running",
+						TargetID:   "urn:host:/test-hostname",
+						Type:       topology.Type{Name: "runs_on"},
+						Data:       topology.Data{},
+					},
+					{
+						ExternalID: "urn:container:/created->urn:host:/test-hostname",
+						SourceID:   "urn:container:/created",
+						TargetID:   "urn:host:/test-hostname",
+						Type:       topology.Type{Name: "runs_on"},
+						Data:       topology.Data{},
+					},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, expectedTopology, producedTopology)
+}
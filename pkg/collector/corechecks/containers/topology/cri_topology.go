@@ -0,0 +1,120 @@
+// +build cri
+
+package topology
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers/cri"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	pb "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+const (
+	criTopologyCheckName = "cri_topology"
+)
+
+// CRITopologyCollector contains the checkID and topology instance for the cri topology check
+type CRITopologyCollector struct {
+	corechecks.CheckTopologyCollector
+}
+
+// MakeCRITopologyCollector returns a new instance of CRITopologyCollector
+func MakeCRITopologyCollector() *CRITopologyCollector {
+	return &CRITopologyCollector{
+		corechecks.MakeCheckTopologyCollector(criTopologyCheckName, topology.Instance{
+			Type: "cri",
+			URL:  "agents",
+		}),
+	}
+}
+
+// BuildContainerTopology collects all cri container topology
+func (ct *CRITopologyCollector) BuildContainerTopology(criUtil cri.CRIClient) error {
+	sender := batcher.GetBatcher()
+	if sender == nil {
+		return errors.New("no batcher instance available, skipping BuildContainerTopology")
+	}
+
+	// collect all containers as topology components
+	containerComponents, err := ct.collectContainers(criUtil)
+	if err != nil {
+		return err
+	}
+
+	// submit all collected topology components and their relations to the host
+	submitTopology(ct.CheckTopologyCollector, sender, containerComponents, collectContainerHostRelations(containerComponents))
+
+	return nil
+}
+
+// collectContainers collects containers from the cri util and produces topology.Component
+func (ct *CRITopologyCollector) collectContainers(criUtil cri.CRIClient) ([]*topology.Component, error) {
+	containerStats, err := criUtil.ListContainerStats()
+	if err != nil {
+		return nil, err
+	}
+
+	containerComponents := make([]*topology.Component, 0)
+	for cid := range containerStats {
+		ctnStatus, err := criUtil.GetContainerStatus(cid)
+		if err != nil || ctnStatus == nil {
+			log.Errorf("Could not retrieve the status of the container %s: %v", cid, err)
+			continue
+		}
+
+		containerComponent := &topology.Component{
+			ExternalID: buildContainerExternalID(cid),
+			Type:       topology.Type{Name: containerType},
+			Data: topology.Data{
+				"type":        criUtil.GetRuntime(),
+				"containerID": cid,
+				"name":        ctnStatus.GetMetadata().GetName(),
+				"image":       ctnStatus.GetImage().GetImage(),
+				"mounts":      convertCRIMounts(ctnStatus.GetMounts()),
+				"state":       criContainerState(ctnStatus.GetState()),
+			},
+		}
+
+		containerComponents = append(containerComponents, containerComponent)
+	}
+
+	return containerComponents, nil
+}
+
+// criContainerState converts CONTAINER_RUNNING to the running state reported by the docker topology
+func criContainerState(state pb.ContainerState) string {
+	return strings.ToLower(strings.TrimPrefix(state.String(), "CONTAINER_"))
+}
+
+// convertCRIMounts converts the cri mounts to the docker mount points reported by the docker topology
+func convertCRIMounts(criMounts []*pb.Mount) []types.MountPoint {
+	mounts := make([]types.MountPoint, 0, len(criMounts))
+	for _, m := range criMounts {
+		mountPoint := types.MountPoint{
+			Type:        mount.TypeBind,
+			Source:      m.GetHostPath(),
+			Destination: m.GetContainerPath(),
+			RW:          !m.GetReadonly(),
+		}
+		switch m.GetPropagation() {
+		case pb.MountPropagation_PROPAGATION_PRIVATE:
+			mountPoint.Propagation = mount.PropagationRPrivate
+		case pb.MountPropagation_PROPAGATION_HOST_TO_CONTAINER:
+			mountPoint.Propagation = mount.PropagationRSlave
+		case pb.MountPropagation_PROPAGATION_BIDIRECTIONAL:
+			mountPoint.Propagation = mount.PropagationRShared
+		}
+		if m.GetReadonly() {
+			mountPoint.Mode = "ro"
+		}
+		mounts = append(mounts, mountPoint)
+	}
+	return mounts
+}
@@ -0,0 +1,88 @@
+// +build cri
+
+package topology
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers/cri/crimock"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	pb "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+func TestMakeCRITopologyCollector(t *testing.T) {
+	ct := MakeCRITopologyCollector()
+	assert.Equal(t, check.ID("cri_topology"), ct.CheckID)
+	assert.Equal(t, topology.Instance{Type: "cri", URL: "agents"}, ct.TopologyInstance)
+}
+
+func TestCRITopologyCollector_BuildContainerTopology(t *testing.T) {
+	// set up the mock batcher
+	mockBatcher := batcher.NewMockBatcher()
+	// set mock hostname
+	testHostname := "test-hostname"
+	config.Datadog.Set("hostname", testHostname)
+
+	mockedCriUtil := new(crimock.MockCRIClient)
+	mockedCriUtil.On("ListContainerStats").Return(map[string]*pb.ContainerStats{"foobar": {}}, nil)
+	mockedCriUtil.On("GetContainerStatus", "foobar").Return(&pb.ContainerStatus{
+		Id:       "foobar",
+		Metadata: &pb.ContainerMetadata{Name: "redis"},
+		State:    pb.ContainerState_CONTAINER_RUNNING,
+		Image:    &pb.ImageSpec{Image: "docker.io/library/redis:latest"},
+		Mounts: []*pb.Mount{
+			{ContainerPath: "/data", HostPath: "/var/lib/redis", Readonly: true, Propagation: pb.MountPropagation_PROPAGATION_PRIVATE},
+			{ContainerPath: "/etc/hosts", HostPath: "/var/lib/kubelet/pods/etc-hosts"},
+		},
+	}, nil)
+
+	ct := MakeCRITopologyCollector()
+	err := ct.BuildContainerTopology(mockedCriUtil)
+	assert.NoError(t, err)
+
+	producedTopology := mockBatcher.CollectedTopology.Flush()
+	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
+		"cri_topology": {
+			Health: make(map[string]health.Health),
+			Topology: &topology.Topology{
+				StartSnapshot: false,
+				StopSnapshot:  false,
+				Instance:      topology.Instance{Type: "cri", URL: "agents"},
+				Components: []topology.Component{
+					{
+						ExternalID: "urn:container:/foobar",
+						Type:       topology.Type{Name: "container"},
+						Data: topology.Data{
+							"type":        "fakeruntime",
+							"containerID": "foobar",
+							"name":        "redis",
+							"image":       "docker.io/library/redis:latest",
+							"mounts": []types.MountPoint{
+								{Type: "bind", Source: "/var/lib/redis", Destination: "/data", Mode: "ro", RW: false, Propagation: "rprivate"},
+								{Type: "bind", Source: "/var/lib/kubelet/pods/etc-hosts", Destination: "/etc/hosts", RW: true, Propagation: "rprivate"},
+							},
+							"state": "running",
+						},
+					},
+				},
+				Relations: []topology.Relation{
+					{
+						ExternalID: "urn:container:/foobar->urn:host:/test-hostname",
+						SourceID:   "urn:container:/foobar",
+						TargetID:   "urn:host:/test-hostname",
+						Type:       topology.Type{Name: "runs_on"},
+						Data:       topology.Data{},
+					},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, expectedTopology, producedTopology)
+}
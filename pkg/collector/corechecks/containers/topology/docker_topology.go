@@ -1,18 +1,37 @@
+// +build docker
+
 package topology
 
 import (
 	"errors"
 	"fmt"
+
 	"github.com/StackVista/stackstate-agent/pkg/batcher"
 	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
 	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers"
 	"github.com/StackVista/stackstate-agent/pkg/util/docker"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
 )
 
 const (
 	dockerTopologyCheckName = "docker_topology"
-	containerType           = "container"
+	networkType             = "docker-network"
+	volumeType              = "docker-volume"
+	composeProjectType      = "docker-compose-project"
+	composeServiceType      = "docker-compose-service"
+)
+
+// labels set by docker compose on the containers it creates
+const (
+	composeProjectLabel         = "com.docker.compose.project"
+	composeServiceLabel         = "com.docker.compose.service"
+	composeWorkingDirLabel      = "com.docker.compose.project.working_dir"
+	composeConfigFilesLabel     = "com.docker.compose.project.config_files"
+	composeContainerNumberLabel = "com.docker.compose.container-number"
 )
 
 // DockerTopologyCollector contains the checkID and topology instance for the docker topology check
@@ -38,40 +57,130 @@ func MakeDockerTopologyCollector() *DockerTopologyCollector {
 	}
 }
 
-// BuildContainerTopology collects all docker container topology
+// BuildContainerTopology collects all docker container, network, volume and compose topology
 func (dt *DockerTopologyCollector) BuildContainerTopology(du *docker.DockerUtil) error {
 	sender := batcher.GetBatcher()
 	if sender == nil {
 		return errors.New("no batcher instance available, skipping BuildContainerTopology")
 	}
 
-	// collect all containers as topology components
-	containerComponents, err := dt.collectContainers(du)
+	// try to get the agent hostname, volumes and compose projects are only unique within a host. The containers and
+	// networks are still reported when the host is unknown.
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Can't get hostname for host running the containers, not reporting container to host relations, volumes and compose projects: %s", err)
+		hostname = ""
+	}
+
+	cList, err := du.ListContainers(&docker.ContainerListConfig{IncludeExited: false, FlagExcluded: true})
 	if err != nil {
 		return err
 	}
 
-	// submit all collected topology components
-	for _, component := range containerComponents {
-		sender.SubmitComponent(dt.CheckID, dt.TopologyInstance, *component)
+	// networks and volumes are reported on a best effort basis, the containers are still reported without them
+	networks, err := du.ListNetworks()
+	if err != nil {
+		log.Warnf("Could not collect docker network topology: %s", err)
+	}
+	volumes, err := du.ListVolumes()
+	if err != nil {
+		log.Warnf("Could not collect docker volume topology: %s", err)
 	}
 
-	sender.SubmitComplete(dt.CheckID)
+	// submit all collected topology components and relations
+	components, relations := dt.buildTopology(hostname, cList, networks, volumes)
+	submitTopology(dt.CheckTopologyCollector, sender, components, relations)
 
 	return nil
 }
 
-// collectContainers collects containers from the docker util and produces topology.Component
-func (dt *DockerTopologyCollector) collectContainers(du *docker.DockerUtil) ([]*topology.Component, error) {
-	cList, err := du.ListContainers(&docker.ContainerListConfig{IncludeExited: false, FlagExcluded: true})
-	if err != nil {
-		return nil, err
+// buildTopology produces the container, network, volume and compose topology.Component and the relations between them.
+// The components that are keyed by the host, the volumes and compose projects, and the relations to the host are
+// skipped when the hostname is empty.
+func (dt *DockerTopologyCollector) buildTopology(hostname string, cList []*containers.Container, networks []types.NetworkResource,
+	volumes []*types.Volume) ([]*topology.Component, []*topology.Relation) {
+	containerComponents := dt.collectContainers(cList)
+	relations := make([]*topology.Relation, 0, len(containerComponents))
+	if hostname != "" {
+		relations = append(relations, createContainerHostRelations(hostname, containerComponents)...)
+	} else {
+		volumes = nil
+	}
+
+	networkComponents := make([]*topology.Component, 0, len(networks))
+	networkExternalIDs := make(map[string]string, len(networks))
+	for _, network := range networks {
+		component := dt.networkToComponent(network)
+		networkExternalIDs[network.ID] = component.ExternalID
+		networkComponents = append(networkComponents, component)
+	}
+
+	volumeComponents := make([]*topology.Component, 0, len(volumes))
+	volumeExternalIDs := make(map[string]string, len(volumes))
+	for _, volume := range volumes {
+		if volume == nil {
+			continue
+		}
+		component := dt.volumeToComponent(hostname, volume)
+		volumeExternalIDs[volume.Name] = component.ExternalID
+		volumeComponents = append(volumeComponents, component)
+	}
+
+	composeComponents := make([]*topology.Component, 0)
+	composeExternalIDs := make(map[string]bool)
+	for _, ctr := range cList {
+		containerExternalID := buildContainerExternalID(ctr.ID)
+
+		for _, networkID := range ctr.NetworkIDs {
+			if networkExternalID, found := networkExternalIDs[networkID]; found {
+				relations = append(relations, createRelation(containerExternalID, networkExternalID, "connected_to"))
+			}
+		}
+
+		for _, m := range ctr.Mounts {
+			if m.Type != mount.TypeVolume {
+				continue
+			}
+			if volumeExternalID, found := volumeExternalIDs[m.Name]; found {
+				relations = append(relations, createRelation(containerExternalID, volumeExternalID, "uses"))
+			}
+		}
+
+		project, service := ctr.Labels[composeProjectLabel], ctr.Labels[composeServiceLabel]
+		if project == "" || service == "" || hostname == "" {
+			continue
+		}
+
+		projectComponent := dt.composeProjectToComponent(hostname, project, ctr.Labels)
+		serviceComponent := dt.composeServiceToComponent(hostname, project, service)
+		// every compose project and service is reported once, even if it runs multiple containers
+		if !composeExternalIDs[projectComponent.ExternalID] {
+			composeExternalIDs[projectComponent.ExternalID] = true
+			composeComponents = append(composeComponents, projectComponent)
+		}
+		if !composeExternalIDs[serviceComponent.ExternalID] {
+			composeExternalIDs[serviceComponent.ExternalID] = true
+			composeComponents = append(composeComponents, serviceComponent)
+			relations = append(relations, createRelation(projectComponent.ExternalID, serviceComponent.ExternalID, "encloses"))
+		}
+		relations = append(relations, createRelation(serviceComponent.ExternalID, containerExternalID, "creates"))
 	}
 
+	components := make([]*topology.Component, 0, len(containerComponents)+len(networkComponents)+len(volumeComponents)+len(composeComponents))
+	components = append(components, containerComponents...)
+	components = append(components, networkComponents...)
+	components = append(components, volumeComponents...)
+	components = append(components, composeComponents...)
+
+	return components, relations
+}
+
+// collectContainers produces a topology.Component for every container listed by the docker util
+func (dt *DockerTopologyCollector) collectContainers(cList []*containers.Container) []*topology.Component {
 	containerComponents := make([]*topology.Component, 0)
 	for _, ctr := range cList {
 		containerComponent := &topology.Component{
-			ExternalID: fmt.Sprintf("urn:%s:/%s", containerType, ctr.ID),
+			ExternalID: buildContainerExternalID(ctr.ID),
 			Type:       topology.Type{Name: containerType},
 			Data: topology.Data{
 				"type":        ctr.Type,
@@ -84,8 +193,71 @@ func (dt *DockerTopologyCollector) collectContainers(du *docker.DockerUtil) ([]*
 			},
 		}
 
+		if number, found := ctr.Labels[composeContainerNumberLabel]; found {
+			containerComponent.Data["composeContainerNumber"] = number
+		}
+
 		containerComponents = append(containerComponents, containerComponent)
 	}
 
-	return containerComponents, nil
+	return containerComponents
+}
+
+// networkToComponent produces a topology.Component for a docker network, network ids are unique across hosts
+func (dt *DockerTopologyCollector) networkToComponent(network types.NetworkResource) *topology.Component {
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s", networkType, network.ID),
+		Type:       topology.Type{Name: networkType},
+		Data: topology.Data{
+			"name":      network.Name,
+			"networkID": network.ID,
+			"driver":    network.Driver,
+			"scope":     network.Scope,
+			"internal":  network.Internal,
+		},
+	}
+	component.Data.PutNonEmpty("labels", network.Labels)
+	return component
+}
+
+// volumeToComponent produces a topology.Component for a docker volume, volume names are only unique within a host
+func (dt *DockerTopologyCollector) volumeToComponent(hostname string, volume *types.Volume) *topology.Component {
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%s", volumeType, hostname, volume.Name),
+		Type:       topology.Type{Name: volumeType},
+		Data: topology.Data{
+			"name":       volume.Name,
+			"driver":     volume.Driver,
+			"mountpoint": volume.Mountpoint,
+			"scope":      volume.Scope,
+		},
+	}
+	component.Data.PutNonEmpty("labels", volume.Labels)
+	return component
+}
+
+// composeProjectToComponent produces a topology.Component for a compose project from the labels of one of its containers
+func (dt *DockerTopologyCollector) composeProjectToComponent(hostname, project string, labels map[string]string) *topology.Component {
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%s", composeProjectType, hostname, project),
+		Type:       topology.Type{Name: composeProjectType},
+		Data: topology.Data{
+			"name": project,
+		},
+	}
+	component.Data.PutNonEmpty("workingDir", labels[composeWorkingDirLabel])
+	component.Data.PutNonEmpty("configFiles", labels[composeConfigFilesLabel])
+	return component
+}
+
+// composeServiceToComponent produces a topology.Component for a service of a compose project
+func (dt *DockerTopologyCollector) composeServiceToComponent(hostname, project, service string) *topology.Component {
+	return &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%s:%s", composeServiceType, hostname, project, service),
+		Type:       topology.Type{Name: composeServiceType},
+		Data: topology.Data{
+			"name":    service,
+			"project": project,
+		},
+	}
 }
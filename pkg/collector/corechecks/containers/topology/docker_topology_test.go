@@ -0,0 +1,182 @@
+// +build docker
+
+package topology
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeDockerTopologyCollector(t *testing.T) {
+	dt := MakeDockerTopologyCollector()
+	assert.Equal(t, check.ID("docker_topology"), dt.CheckID)
+	assert.Equal(t, topology.Instance{Type: "docker", URL: "agents"}, dt.TopologyInstance)
+}
+
+func TestDockerTopologyCollector_buildTopology(t *testing.T) {
+	composeLabels := func(service, number string) map[string]string {
+		return map[string]string{
+			"com.docker.compose.project":              "shop",
+			"com.docker.compose.service":              service,
+			"com.docker.compose.project.working_dir":  "/srv/shop",
+			"com.docker.compose.project.config_files": "docker-compose.yml",
+			"com.docker.compose.container-number":     number,
+		}
+	}
+	dataMount := types.MountPoint{Type: mount.TypeVolume, Name: "shop_data", Source: "/var/lib/docker/volumes/shop_data/_data", Destination: "/data", RW: true}
+	configMount := types.MountPoint{Type: mount.TypeBind, Source: "/srv/shop/nginx.conf", Destination: "/etc/nginx/nginx.conf"}
+	cList := []*containers.Container{
+		{Type: "Docker", ID: "web-1", Name: "/shop_web_1", Image: "nginx:latest", State: "running", Mounts: []types.MountPoint{configMount},
+			Labels: composeLabels("web", "1"), NetworkIDs: []string{"shop-network"}},
+		{Type: "Docker", ID: "web-2", Name: "/shop_web_2", Image: "nginx:latest", State: "running",
+			Labels: composeLabels("web", "2"), NetworkIDs: []string{"shop-network"}},
+		{Type: "Docker", ID: "db-1", Name: "/shop_db_1", Image: "postgres:12", State: "running", Health: "healthy", Mounts: []types.MountPoint{dataMount},
+			Labels: composeLabels("db", "1"), NetworkIDs: []string{"shop-network", "unknown-network"}},
+		{Type: "Docker", ID: "standalone", Name: "/standalone", Image: "redis:latest", State: "running"},
+	}
+	networks := []types.NetworkResource{
+		{ID: "shop-network", Name: "shop_default", Driver: "bridge", Scope: "local", Labels: map[string]string{"com.docker.compose.project": "shop"}},
+	}
+	volumes := []*types.Volume{
+		{Name: "shop_data", Driver: "local", Mountpoint: "/var/lib/docker/volumes/shop_data/_data", Scope: "local"},
+	}
+
+	dt := MakeDockerTopologyCollector()
+	components, relations := dt.buildTopology("test-hostname", cList, networks, volumes)
+
+	containerComponent := func(ctr *containers.Container, number string) *topology.Component {
+		component := &topology.Component{
+			ExternalID: "urn:container:/" + ctr.ID,
+			Type:       topology.Type{Name: "container"},
+			Data: topology.Data{
+				"type":        "Docker",
+				"containerID": ctr.ID,
+				"name":        ctr.Name,
+				"image":       ctr.Image,
+				"mounts":      ctr.Mounts,
+				"state":       "running",
+				"health":      ctr.Health,
+			},
+		}
+		if number != "" {
+			component.Data["composeContainerNumber"] = number
+		}
+		return component
+	}
+	assert.Equal(t, []*topology.Component{
+		containerComponent(cList[0], "1"),
+		containerComponent(cList[1], "2"),
+		containerComponent(cList[2], "1"),
+		containerComponent(cList[3], ""),
+		{
+			ExternalID: "urn:docker-network:/shop-network",
+			Type:       topology.Type{Name: "docker-network"},
+			Data: topology.Data{
+				"name":      "shop_default",
+				"networkID": "shop-network",
+				"driver":    "bridge",
+				"scope":     "local",
+				"internal":  false,
+				"labels":    map[string]string{"com.docker.compose.project": "shop"},
+			},
+		},
+		{
+			ExternalID: "urn:docker-volume:/test-hostname:shop_data",
+			Type:       topology.Type{Name: "docker-volume"},
+			Data: topology.Data{
+				"name":       "shop_data",
+				"driver":     "local",
+				"mountpoint": "/var/lib/docker/volumes/shop_data/_data",
+				"scope":      "local",
+			},
+		},
+		{
+			ExternalID: "urn:docker-compose-project:/test-hostname:shop",
+			Type:       topology.Type{Name: "docker-compose-project"},
+			Data: topology.Data{
+				"name":        "shop",
+				"workingDir":  "/srv/shop",
+				"configFiles": "docker-compose.yml",
+			},
+		},
+		{
+			ExternalID: "urn:docker-compose-service:/test-hostname:shop:web",
+			Type:       topology.Type{Name: "docker-compose-service"},
+			Data:       topology.Data{"name": "web", "project": "shop"},
+		},
+		{
+			ExternalID: "urn:docker-compose-service:/test-hostname:shop:db",
+			Type:       topology.Type{Name: "docker-compose-service"},
+			Data:       topology.Data{"name": "db", "project": "shop"},
+		},
+	}, components)
+
+	projectExternalID := "urn:docker-compose-project:/test-hostname:shop"
+	webExternalID := "urn:docker-compose-service:/test-hostname:shop:web"
+	dbExternalID := "urn:docker-compose-service:/test-hostname:shop:db"
+	networkExternalID := "urn:docker-network:/shop-network"
+	expectedRelations := make([]*topology.Relation, 0)
+	for _, expected := range []struct {
+		source, target, relationType string
+	}{
+		{"urn:container:/web-1", "urn:host:/test-hostname", "runs_on"},
+		{"urn:container:/web-2", "urn:host:/test-hostname", "runs_on"},
+		{"urn:container:/db-1", "urn:host:/test-hostname", "runs_on"},
+		{"urn:container:/standalone", "urn:host:/test-hostname", "runs_on"},
+		{"urn:container:/web-1", networkExternalID, "connected_to"},
+		{projectExternalID, webExternalID, "encloses"},
+		{webExternalID, "urn:container:/web-1", "creates"},
+		{"urn:container:/web-2", networkExternalID, "connected_to"},
+		{webExternalID, "urn:container:/web-2", "creates"},
+		// networks and volumes that are not reported are not related
+		{"urn:container:/db-1", networkExternalID, "connected_to"},
+		{"urn:container:/db-1", "urn:docker-volume:/test-hostname:shop_data", "uses"},
+		{projectExternalID, dbExternalID, "encloses"},
+		{dbExternalID, "urn:container:/db-1", "creates"},
+	} {
+		expectedRelations = append(expectedRelations, &topology.Relation{
+			ExternalID: expected.source + "->" + expected.target,
+			SourceID:   expected.source,
+			TargetID:   expected.target,
+			Type:       topology.Type{Name: expected.relationType},
+			Data:       topology.Data{},
+		})
+	}
+	assert.Equal(t, expectedRelations, relations)
+}
+
+func TestDockerTopologyCollector_buildTopologyWithoutHostname(t *testing.T) {
+	dataMount := types.MountPoint{Type: mount.TypeVolume, Name: "shop_data", Destination: "/data"}
+	cList := []*containers.Container{
+		{Type: "Docker", ID: "db-1", Name: "/shop_db_1", Image: "postgres:12", State: "running", Mounts: []types.MountPoint{dataMount},
+			Labels: map[string]string{"com.docker.compose.project": "shop", "com.docker.compose.service": "db"}, NetworkIDs: []string{"shop-network"}},
+		{Type: "Docker", ID: "standalone", Name: "/standalone", Image: "redis:latest", State: "running"},
+	}
+	networks := []types.NetworkResource{{ID: "shop-network", Name: "shop_default", Driver: "bridge", Scope: "local"}}
+	volumes := []*types.Volume{{Name: "shop_data", Driver: "local", Scope: "local"}}
+
+	dt := MakeDockerTopologyCollector()
+	components, relations := dt.buildTopology("", cList, networks, volumes)
+
+	// the containers and networks are reported, the volumes and compose projects are keyed by the unknown host
+	externalIDs := make([]string, 0, len(components))
+	for _, component := range components {
+		externalIDs = append(externalIDs, component.ExternalID)
+	}
+	assert.Equal(t, []string{"urn:container:/db-1", "urn:container:/standalone", "urn:docker-network:/shop-network"}, externalIDs)
+	assert.Equal(t, []*topology.Relation{
+		{
+			ExternalID: "urn:container:/db-1->urn:docker-network:/shop-network",
+			SourceID:   "urn:container:/db-1",
+			TargetID:   "urn:docker-network:/shop-network",
+			Type:       topology.Type{Name: "connected_to"},
+			Data:       topology.Data{},
+		},
+	}, relations)
+}
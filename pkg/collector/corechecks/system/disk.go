@@ -32,6 +32,9 @@ type diskConfig struct {
 	excludedMountpointRe *regexp.Regexp
 	allPartitions        bool
 	deviceTagRe          map[*regexp.Regexp][]string
+	// sts
+	collectHostTopology bool
+	usageThresholds     usageThresholds
 }
 
 func (c *DiskCheck) excludeDisk(mountpoint, device, fstype string) bool {
@@ -80,7 +83,9 @@ func (c *DiskCheck) excludeDisk(mountpoint, device, fstype string) bool {
 
 func (c *DiskCheck) instanceConfigure(data integration.Data) error {
 	conf := make(map[interface{}]interface{})
-	c.cfg = &diskConfig{}
+	c.cfg = &diskConfig{
+		usageThresholds: usageThresholds{deviating: defaultDeviatingUsage, critical: defaultCriticalUsage},
+	}
 	err := yaml.Unmarshal([]byte(data), &conf)
 	if err != nil {
 		return err
@@ -153,6 +158,19 @@ func (c *DiskCheck) instanceConfigure(data integration.Data) error {
 		}
 	}
 
+	// sts
+	collectHostTopology, found := conf["collect_host_topology"]
+	if collectHostTopology, ok := collectHostTopology.(bool); found && ok {
+		c.cfg.collectHostTopology = collectHostTopology
+	}
+
+	if deviating, ok := percentValue(conf["disk_usage_deviating_threshold"]); ok {
+		c.cfg.usageThresholds.deviating = deviating
+	}
+	if critical, ok := percentValue(conf["disk_usage_critical_threshold"]); ok {
+		c.cfg.usageThresholds.critical = critical
+	}
+
 	return nil
 }
 
@@ -165,6 +183,17 @@ func stringSliceContain(slice []string, x string) bool {
 	return false
 }
 
+// percentValue reads a percentage that is either written as an integer or a float in the yaml configuration
+func percentValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
 func (c *DiskCheck) applyDeviceTags(device, mountpoint string, tags []string) []string {
 	// apply device/mountpoint specific tags
 	for re, deviceTags := range c.cfg.deviceTagRe {
@@ -184,6 +213,8 @@ func diskFactory() check.Check {
 	return &DiskCheck{
 		CheckBase:         core.NewCheckBase(diskCheckName),
 		topologyCollector: MakeTopologyCollector(),
+		// sts
+		hostTopologyCollector: MakeHostTopologyCollector(),
 	}
 }
 
@@ -32,6 +32,8 @@ type DiskCheck struct {
 	// sts
 	// topologyCollector collects all disk topology and produces it using the Batcher
 	topologyCollector *DiskTopologyCollector
+	// hostTopologyCollector collects the block devices, filesystems and network interfaces of the host
+	hostTopologyCollector *HostTopologyCollector
 }
 
 // Run executes the check
@@ -41,7 +43,7 @@ func (c *DiskCheck) Run() error {
 		return err
 	}
 
-	partitions, err := c.collectPartitionMetrics(sender)
+	partitions, usages, err := c.collectPartitionMetrics(sender)
 	if err != nil {
 		return err
 	}
@@ -57,19 +59,28 @@ func (c *DiskCheck) Run() error {
 	if err != nil {
 		return err
 	}
+
+	// produce the host topology with the disk usage health of the filesystems
+	if c.cfg.collectHostTopology {
+		err = c.hostTopologyCollector.BuildTopology(partitions, usages, c.cfg.usageThresholds, int(c.Interval().Seconds()))
+		if err != nil {
+			return err
+		}
+	}
 	//sts
 
 	return nil
 }
 
-func (c *DiskCheck) collectPartitionMetrics(sender aggregator.Sender) ([]disk.PartitionStat, error) {
+func (c *DiskCheck) collectPartitionMetrics(sender aggregator.Sender) ([]disk.PartitionStat, map[string]*disk.UsageStat, error) {
 	partitions, err := diskPartitions(true)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// sts - collect disk partitions to create host topology
+	// sts - collect disk partitions and their usage to create host topology
 	parts := make([]disk.PartitionStat, 0)
+	usages := make(map[string]*disk.UsageStat)
 	for _, partition := range partitions {
 		if c.excludeDisk(partition.Mountpoint, partition.Device, partition.Fstype) {
 			continue
@@ -105,11 +116,12 @@ func (c *DiskCheck) collectPartitionMetrics(sender aggregator.Sender) ([]disk.Pa
 
 		// sts - keep the partitions
 		parts = append(parts, partition)
+		usages[partition.Mountpoint] = usage
 
 		c.sendPartitionMetrics(sender, usage, tags)
 	}
 
-	return parts, nil
+	return parts, usages, nil
 }
 
 func (c *DiskCheck) collectDiskMetrics(sender aggregator.Sender) error {
@@ -0,0 +1,326 @@
+// +build !windows
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers/collectors"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+)
+
+const (
+	hostTopologyCheckID  = "host_topology"
+	blockDeviceType      = "block-device"
+	filesystemType       = "filesystem"
+	networkInterfaceType = "network-interface"
+	diskUsageHealthCheck = "Disk Usage"
+)
+
+// the default disk and inode usage percentages at which a filesystem becomes deviating or critical
+const (
+	defaultDeviatingUsage = 80.0
+	defaultCriticalUsage  = 95.0
+)
+
+// for testing
+var (
+	netInterfaces  = net.Interfaces
+	listContainers = detectContainers
+)
+
+// containerDetector finds the container runtime used to resolve the filesystems mounted by containers
+var containerDetector *collectors.Detector
+
+// HostTopologyCollector contains all the metadata needed to produce the block device, filesystem and network interface
+// topology of a host
+type HostTopologyCollector struct {
+	corechecks.CheckTopologyCollector
+	HealthStream health.Stream
+}
+
+// usageThresholds are the disk and inode usage percentages at which a filesystem becomes deviating or critical
+type usageThresholds struct {
+	deviating float64
+	critical  float64
+}
+
+// MakeHostTopologyCollector returns an instance of the HostTopologyCollector
+func MakeHostTopologyCollector() *HostTopologyCollector {
+	return &HostTopologyCollector{
+		CheckTopologyCollector: corechecks.MakeCheckTopologyCollector(hostTopologyCheckID, topology.Instance{
+			Type: "host",
+			URL:  "agents",
+		}),
+		HealthStream: health.Stream{Urn: "urn:health:host:agents"},
+	}
+}
+
+// BuildTopology collects the block devices, filesystems and network interfaces of the host and produces them with
+// the disk usage health of the filesystems
+func (htc *HostTopologyCollector) BuildTopology(partitions []disk.PartitionStat, usages map[string]*disk.UsageStat,
+	thresholds usageThresholds, intervalSeconds int) error {
+	sender := batcher.GetBatcher()
+	if sender == nil {
+		return errors.New("no batcher instance available, skipping BuildTopology")
+	}
+
+	// try to get the agent hostname, devices, filesystems and interfaces are only unique within a host
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Can't get hostname for host running the disk integration, not reporting host topology: %s", err)
+		return err
+	}
+
+	// interfaces and containers are reported on a best effort basis, the disks are still reported without them
+	interfaces, err := netInterfaces()
+	if err != nil {
+		log.Warnf("Could not collect network interface topology: %s", err)
+	}
+	cList, err := listContainers()
+	if err != nil {
+		log.Debugf("Could not resolve the filesystems mounted by containers: %s", err)
+	}
+
+	components, relations := htc.buildTopology(hostname, partitions, usages, interfaces, cList)
+	for _, component := range components {
+		sender.SubmitComponent(htc.CheckID, htc.TopologyInstance, *component)
+	}
+	for _, relation := range relations {
+		sender.SubmitRelation(htc.CheckID, htc.TopologyInstance, *relation)
+	}
+	sender.SubmitComplete(htc.CheckID)
+
+	// every run reports the disk usage of all filesystems, so it is sent as a snapshot
+	stream := htc.HealthStream
+	stream.SubStream = hostname
+	sender.SubmitHealthStartSnapshot(htc.CheckID, stream, intervalSeconds, 0)
+	for _, checkState := range filesystemCheckStates(hostname, partitions, usages, thresholds) {
+		sender.SubmitHealthCheckData(htc.CheckID, stream, checkState.CheckData())
+	}
+	sender.SubmitHealthStopSnapshot(htc.CheckID, stream)
+
+	return nil
+}
+
+// buildTopology produces the block device, filesystem and network interface components and their relations to the
+// host and to the containers mounting the filesystems
+func (htc *HostTopologyCollector) buildTopology(hostname string, partitions []disk.PartitionStat, usages map[string]*disk.UsageStat,
+	interfaces []net.InterfaceStat, cList []*containers.Container) ([]*topology.Component, []*topology.Relation) {
+	hostExternalID := fmt.Sprintf("urn:host:/%s", hostname)
+	components := make([]*topology.Component, 0)
+	relations := make([]*topology.Relation, 0)
+
+	deviceExternalIDs := make(map[string]string)
+	for _, partition := range partitions {
+		// only partitions backed by a device node are block devices, the others are virtual or network filesystems
+		if !strings.HasPrefix(partition.Device, "/dev/") {
+			continue
+		}
+		if _, found := deviceExternalIDs[partition.Device]; found {
+			continue
+		}
+		component := blockDeviceToComponent(hostname, partition.Device)
+		deviceExternalIDs[partition.Device] = component.ExternalID
+		components = append(components, component)
+		relations = append(relations, createHostRelation(hostExternalID, component.ExternalID, "encloses"))
+	}
+
+	filesystemExternalIDs := make(map[string]string, len(partitions))
+	for _, partition := range partitions {
+		if _, found := filesystemExternalIDs[partition.Mountpoint]; found {
+			continue
+		}
+		component := filesystemToComponent(hostname, partition, usages[partition.Mountpoint])
+		filesystemExternalIDs[partition.Mountpoint] = component.ExternalID
+		components = append(components, component)
+		relations = append(relations, createHostRelation(hostExternalID, component.ExternalID, "encloses"))
+		if deviceExternalID, found := deviceExternalIDs[partition.Device]; found {
+			relations = append(relations, createHostRelation(component.ExternalID, deviceExternalID, "uses"))
+		}
+	}
+
+	for _, iface := range interfaces {
+		component := networkInterfaceToComponent(hostname, iface)
+		components = append(components, component)
+		relations = append(relations, createHostRelation(hostExternalID, component.ExternalID, "encloses"))
+	}
+
+	for _, ctr := range cList {
+		containerExternalID := fmt.Sprintf("urn:container:/%s", ctr.ID)
+		// a container mounting several paths of the same filesystem uses it only once
+		used := make(map[string]bool)
+		for _, m := range ctr.Mounts {
+			mountpoint, found := resolveMountpoint(m.Source, filesystemExternalIDs)
+			if !found || used[mountpoint] {
+				continue
+			}
+			used[mountpoint] = true
+			relations = append(relations, createHostRelation(containerExternalID, filesystemExternalIDs[mountpoint], "uses"))
+		}
+	}
+
+	return components, relations
+}
+
+// blockDeviceToComponent produces a topology.Component for a block device of the host
+func blockDeviceToComponent(hostname, device string) *topology.Component {
+	return &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%s", blockDeviceType, hostname, device),
+		Type:       topology.Type{Name: blockDeviceType},
+		Data: topology.Data{
+			"name":   filepath.Base(device),
+			"device": device,
+			"host":   hostname,
+		},
+	}
+}
+
+// filesystemToComponent produces a topology.Component for a filesystem mounted on the host, the usage is left out as
+// it changes on every run and is reported as health instead
+func filesystemToComponent(hostname string, partition disk.PartitionStat, usage *disk.UsageStat) *topology.Component {
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%s", filesystemType, hostname, partition.Mountpoint),
+		Type:       topology.Type{Name: filesystemType},
+		Data: topology.Data{
+			"name":       partition.Mountpoint,
+			"mountpoint": partition.Mountpoint,
+			"host":       hostname,
+		},
+	}
+	component.Data.PutNonEmpty("device", partition.Device)
+	component.Data.PutNonEmpty("fstype", partition.Fstype)
+	component.Data.PutNonEmpty("options", partition.Opts)
+	if usage != nil {
+		component.Data["total"] = usage.Total
+	}
+	return component
+}
+
+// networkInterfaceToComponent produces a topology.Component for a network interface of the host
+func networkInterfaceToComponent(hostname string, iface net.InterfaceStat) *topology.Component {
+	addresses := make([]string, 0, len(iface.Addrs))
+	for _, addr := range iface.Addrs {
+		addresses = append(addresses, addr.Addr)
+	}
+
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%s", networkInterfaceType, hostname, iface.Name),
+		Type:       topology.Type{Name: networkInterfaceType},
+		Data: topology.Data{
+			"name":      iface.Name,
+			"host":      hostname,
+			"mtu":       iface.MTU,
+			"flags":     iface.Flags,
+			"addresses": addresses,
+		},
+	}
+	component.Data.PutNonEmpty("hardwareAddress", iface.HardwareAddr)
+	return component
+}
+
+// createHostRelation creates a topology.Relation of the given type between the source and target components
+func createHostRelation(sourceExternalID, targetExternalID, relationType string) *topology.Relation {
+	return &topology.Relation{
+		ExternalID: fmt.Sprintf("%s->%s", sourceExternalID, targetExternalID),
+		SourceID:   sourceExternalID,
+		TargetID:   targetExternalID,
+		Type:       topology.Type{Name: relationType},
+		Data:       topology.Data{},
+	}
+}
+
+// resolveMountpoint returns the mountpoint of the filesystem that contains the given path, that is the longest
+// mountpoint the path is located under
+func resolveMountpoint(path string, mountpoints map[string]string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+
+	candidates := make([]string, 0, len(mountpoints))
+	for mountpoint := range mountpoints {
+		candidates = append(candidates, mountpoint)
+	}
+	// longest first, so nested mountpoints take precedence over the filesystems they are mounted on
+	sort.Slice(candidates, func(i, j int) bool { return len(candidates[i]) > len(candidates[j]) })
+
+	for _, mountpoint := range candidates {
+		if path == mountpoint || mountpoint == "/" || strings.HasPrefix(path, strings.TrimSuffix(mountpoint, "/")+"/") {
+			return mountpoint, true
+		}
+	}
+	return "", false
+}
+
+// filesystemCheckStates creates a disk usage health check state for every filesystem with a known usage
+func filesystemCheckStates(hostname string, partitions []disk.PartitionStat, usages map[string]*disk.UsageStat,
+	thresholds usageThresholds) []*health.CheckState {
+	checkStates := make([]*health.CheckState, 0, len(usages))
+	reported := make(map[string]bool, len(usages))
+	for _, partition := range partitions {
+		usage, found := usages[partition.Mountpoint]
+		if !found || usage == nil || reported[partition.Mountpoint] {
+			continue
+		}
+		reported[partition.Mountpoint] = true
+
+		externalID := fmt.Sprintf("urn:%s:/%s:%s", filesystemType, hostname, partition.Mountpoint)
+		checkStates = append(checkStates, diskUsageCheckState(externalID, partition.Mountpoint, usage, thresholds))
+	}
+	return checkStates
+}
+
+// diskUsageCheckState is deviating or critical when either the disk space or the inodes of the filesystem are used
+// beyond the thresholds, a filesystem without free inodes is as full as one without free space
+func diskUsageCheckState(externalID, mountpoint string, usage *disk.UsageStat, thresholds usageThresholds) *health.CheckState {
+	state := health.Worst(usageState(usage.UsedPercent, thresholds), usageState(usage.InodesUsedPercent, thresholds))
+
+	message := ""
+	if state != health.Clear {
+		message = fmt.Sprintf("Disk usage of %s is %.1f%%, inode usage is %.1f%%", mountpoint, usage.UsedPercent, usage.InodesUsedPercent)
+	}
+
+	return &health.CheckState{
+		CheckStateID:              fmt.Sprintf("%s:%s", externalID, strings.ToLower(strings.ReplaceAll(diskUsageHealthCheck, " ", "-"))),
+		Name:                      diskUsageHealthCheck,
+		Health:                    state,
+		TopologyElementIdentifier: externalID,
+		Message:                   message,
+	}
+}
+
+// usageState maps a usage percentage to a health state using the thresholds
+func usageState(percent float64, thresholds usageThresholds) health.State {
+	switch {
+	case percent >= thresholds.critical:
+		return health.Critical
+	case percent >= thresholds.deviating:
+		return health.Deviating
+	default:
+		return health.Clear
+	}
+}
+
+// detectContainers lists the containers of the preferred container runtime of the host
+func detectContainers() ([]*containers.Container, error) {
+	if containerDetector == nil {
+		containerDetector = collectors.NewDetector("")
+	}
+	l, _, err := containerDetector.GetPreferred()
+	if err != nil {
+		return nil, err
+	}
+	return l.List()
+}
@@ -0,0 +1,222 @@
+// +build !windows
+
+package system
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/autodiscovery/integration"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util/containers"
+	"github.com/docker/docker/api/types"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	interfaceSamples = []net.InterfaceStat{
+		{
+			Index:        2,
+			MTU:          1500,
+			Name:         "eth0",
+			HardwareAddr: "02:42:ac:11:00:02",
+			Flags:        []string{"up", "broadcast", "multicast"},
+			Addrs:        []net.InterfaceAddr{{Addr: "172.17.0.2/16"}},
+		},
+	}
+	containerSamples = []*containers.Container{
+		{
+			ID: "abc123",
+			Mounts: []types.MountPoint{
+				{Type: "bind", Source: "/boot/efi/EFI", Destination: "/efi"},
+				{Type: "bind", Source: "/var/lib/data", Destination: "/data"},
+				{Type: "bind", Source: "/var/lib/logs", Destination: "/logs"},
+			},
+		},
+	}
+)
+
+func TestMakeHostTopologyCollector(t *testing.T) {
+	htc := MakeHostTopologyCollector()
+	assert.Equal(t, check.ID("host_topology"), htc.CheckID)
+	assert.Equal(t, topology.Instance{Type: "host", URL: "agents"}, htc.TopologyInstance)
+	assert.Equal(t, health.Stream{Urn: "urn:health:host:agents"}, htc.HealthStream)
+}
+
+func TestHostTopologyCollector_BuildTopology(t *testing.T) {
+	netInterfaces = func() ([]net.InterfaceStat, error) { return interfaceSamples, nil }
+	listContainers = func() ([]*containers.Container, error) { return containerSamples, nil }
+	defer func() {
+		netInterfaces = net.Interfaces
+		listContainers = detectContainers
+	}()
+
+	// set up the mock batcher
+	mockBatcher := batcher.NewMockBatcher()
+	// set mock hostname
+	testHostname := "test-hostname"
+	config.Datadog.Set("hostname", testHostname)
+
+	usages := map[string]*disk.UsageStat{
+		"/":         {UsedPercent: 85, InodesUsedPercent: 10, Total: 52045545472},
+		"/boot/efi": {UsedPercent: 1, InodesUsedPercent: 99, Total: 535805952},
+	}
+	htc := MakeHostTopologyCollector()
+	err := htc.BuildTopology(diskSamples, usages, usageThresholds{deviating: 80, critical: 95}, 15)
+	assert.NoError(t, err)
+
+	hostID := "urn:host:/test-hostname"
+	sda2ID := "urn:block-device:/test-hostname:/dev/sda2"
+	sda1ID := "urn:block-device:/test-hostname:/dev/sda1"
+	rootID := "urn:filesystem:/test-hostname:/"
+	efiID := "urn:filesystem:/test-hostname:/boot/efi"
+	eth0ID := "urn:network-interface:/test-hostname:eth0"
+	containerID := "urn:container:/abc123"
+
+	relation := func(source, target, relationType string) topology.Relation {
+		return *createHostRelation(source, target, relationType)
+	}
+	stream := health.Stream{Urn: "urn:health:host:agents", SubStream: testHostname}
+
+	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
+		"host_topology": {
+			Health: map[string]health.Health{
+				stream.GoString(): {
+					StartSnapshot: &health.StartSnapshotMetadata{RepeatIntervalS: 15},
+					StopSnapshot:  &health.StopSnapshotMetadata{},
+					Stream:        stream,
+					CheckStates: []health.CheckData{
+						{
+							"checkStateId":              rootID + ":disk-usage",
+							"name":                      "Disk Usage",
+							"health":                    string(health.Deviating),
+							"topologyElementIdentifier": rootID,
+							"message":                   "Disk usage of / is 85.0%, inode usage is 10.0%",
+						},
+						{
+							"checkStateId":              efiID + ":disk-usage",
+							"name":                      "Disk Usage",
+							"health":                    string(health.Critical),
+							"topologyElementIdentifier": efiID,
+							"message":                   "Disk usage of /boot/efi is 1.0%, inode usage is 99.0%",
+						},
+					},
+				},
+			},
+			Topology: &topology.Topology{
+				StartSnapshot: false,
+				StopSnapshot:  false,
+				Instance:      topology.Instance{Type: "host", URL: "agents"},
+				Components: []topology.Component{
+					{
+						ExternalID: sda2ID,
+						Type:       topology.Type{Name: "block-device"},
+						Data:       topology.Data{"name": "sda2", "device": "/dev/sda2", "host": testHostname},
+					},
+					{
+						ExternalID: sda1ID,
+						Type:       topology.Type{Name: "block-device"},
+						Data:       topology.Data{"name": "sda1", "device": "/dev/sda1", "host": testHostname},
+					},
+					{
+						ExternalID: rootID,
+						Type:       topology.Type{Name: "filesystem"},
+						Data: topology.Data{
+							"name":       "/",
+							"mountpoint": "/",
+							"host":       testHostname,
+							"device":     "/dev/sda2",
+							"fstype":     "ext4",
+							"options":    "rw,relatime,errors=remount-ro,data=ordered",
+							"total":      uint64(52045545472),
+						},
+					},
+					{
+						ExternalID: efiID,
+						Type:       topology.Type{Name: "filesystem"},
+						Data: topology.Data{
+							"name":       "/boot/efi",
+							"mountpoint": "/boot/efi",
+							"host":       testHostname,
+							"device":     "/dev/sda1",
+							"fstype":     "vfat",
+							"options":    "rw,relatime,fmask=0077,dmask=0077,codepage=437,iocharset=iso8859-1,shortname=mixed,errors=remount-ro",
+							"total":      uint64(535805952),
+						},
+					},
+					{
+						ExternalID: eth0ID,
+						Type:       topology.Type{Name: "network-interface"},
+						Data: topology.Data{
+							"name":            "eth0",
+							"host":            testHostname,
+							"mtu":             1500,
+							"flags":           []string{"up", "broadcast", "multicast"},
+							"addresses":       []string{"172.17.0.2/16"},
+							"hardwareAddress": "02:42:ac:11:00:02",
+						},
+					},
+				},
+				Relations: []topology.Relation{
+					relation(hostID, sda2ID, "encloses"),
+					relation(hostID, sda1ID, "encloses"),
+					relation(hostID, rootID, "encloses"),
+					relation(rootID, sda2ID, "uses"),
+					relation(hostID, efiID, "encloses"),
+					relation(efiID, sda1ID, "uses"),
+					relation(hostID, eth0ID, "encloses"),
+					// the nested mountpoint takes precedence, both /var/lib paths are on the root filesystem
+					relation(containerID, efiID, "uses"),
+					relation(containerID, rootID, "uses"),
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, expectedTopology, mockBatcher.CollectedTopology.Flush())
+}
+
+func TestResolveMountpoint(t *testing.T) {
+	mountpoints := map[string]string{"/": "root", "/var": "var", "/var/lib/docker": "docker"}
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+		found    bool
+	}{
+		{path: "/etc/hosts", expected: "/", found: true},
+		{path: "/var", expected: "/var", found: true},
+		{path: "/var/log/syslog", expected: "/var", found: true},
+		{path: "/var/lib/docker/volumes/data", expected: "/var/lib/docker", found: true},
+		{path: "/variable", expected: "/", found: true},
+		{path: "", found: false},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			mountpoint, found := resolveMountpoint(tc.path, mountpoints)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, mountpoint)
+		})
+	}
+
+	_, found := resolveMountpoint("/var/log", map[string]string{"/boot": "boot"})
+	assert.False(t, found)
+}
+
+func TestDiskCheckHostTopologyConfig(t *testing.T) {
+	diskCheck := diskFactory().(*DiskCheck)
+	err := diskCheck.Configure(nil, nil, "test")
+	assert.NoError(t, err)
+	assert.False(t, diskCheck.cfg.collectHostTopology)
+	assert.Equal(t, usageThresholds{deviating: 80, critical: 95}, diskCheck.cfg.usageThresholds)
+
+	conf := integration.Data("collect_host_topology: true\ndisk_usage_deviating_threshold: 70\ndisk_usage_critical_threshold: 90.5")
+	err = diskCheck.Configure(conf, nil, "test")
+	assert.NoError(t, err)
+	assert.True(t, diskCheck.cfg.collectHostTopology)
+	assert.Equal(t, usageThresholds{deviating: 70, critical: 90.5}, diskCheck.cfg.usageThresholds)
+}
@@ -139,6 +139,8 @@ type SystemdCheck struct {
 	core.CheckBase
 	stats  systemdStats
 	config systemdConfig
+	// sts
+	topologyCollector *SystemdTopologyCollector
 }
 type unitSubstateMapping = map[string]string
 
@@ -146,6 +148,8 @@ type systemdInstanceConfig struct {
 	PrivateSocket         string                         `yaml:"private_socket"`
 	UnitNames             []string                       `yaml:"unit_names"`
 	SubstateStatusMapping map[string]unitSubstateMapping `yaml:"substate_status_mapping"`
+	// sts
+	CollectTopology bool `yaml:"collect_topology"`
 }
 
 type systemdInitConfig struct{}
@@ -221,12 +225,21 @@ func (c *SystemdCheck) Run() error {
 	c.submitVersion(conn)
 	c.submitSystemdState(sender, conn)
 
-	err = c.submitMetrics(sender, conn)
+	units, err := c.submitMetrics(sender, conn)
 	if err != nil {
 		return err
 	}
 	sender.Commit()
 
+	// sts
+	// the topology is reported on a best effort basis, the metrics and service checks are already submitted
+	if c.config.instance.CollectTopology && c.topologyCollector != nil {
+		err = c.topologyCollector.BuildTopology(units, int(c.Interval().Seconds()))
+		if err != nil {
+			log.Warnf("Could not collect systemd topology: %s", err)
+		}
+	}
+
 	return nil
 }
 
@@ -307,16 +320,17 @@ func (c *SystemdCheck) submitVersion(conn *dbus.Conn) {
 	inventories.SetCheckMetadata(checkID, "version.raw", version)
 }
 
-func (c *SystemdCheck) submitMetrics(sender aggregator.Sender, conn *dbus.Conn) error {
+func (c *SystemdCheck) submitMetrics(sender aggregator.Sender, conn *dbus.Conn) ([]monitoredUnit, error) {
 	units, err := c.stats.ListUnits(conn)
 	if err != nil {
-		return fmt.Errorf("error getting list of units: %v", err)
+		return nil, fmt.Errorf("error getting list of units: %v", err)
 	}
 
 	c.submitCountMetrics(sender, units)
 
 	loadedCount := 0
 	monitoredCount := 0
+	monitoredUnits := make([]monitoredUnit, 0)
 	for _, unit := range units {
 		if unit.LoadState == unitLoadedState {
 			loadedCount++
@@ -327,27 +341,37 @@ func (c *SystemdCheck) submitMetrics(sender aggregator.Sender, conn *dbus.Conn)
 		monitoredCount++
 		tags := []string{"unit:" + unit.Name}
 
-		sender.ServiceCheck(unitStateServiceCheck, getServiceCheckStatus(unit.ActiveState, serviceCheckStateMapping), "", tags, "")
+		// sts - the status of the unit is kept as its health in the topology
+		status := getServiceCheckStatus(unit.ActiveState, serviceCheckStateMapping)
+		sender.ServiceCheck(unitStateServiceCheck, status, "", tags, "")
 
 		if subStateMapping, found := c.config.instance.SubstateStatusMapping[unit.Name]; found {
 			// User provided a custom mapping for this unit. Submit the systemd.unit.substate service check based on that
+			subStateStatus := getServiceCheckStatus(unit.SubState, subStateMapping)
 			if _, ok := subStateMapping[unit.SubState]; !ok {
 				log.Debugf("The systemd unit %s has a substate value of %s that is not defined in the mapping set in the conf.yaml file. The service check will report 'UNKNOWN'", unit.Name, unit.SubState)
+			} else {
+				// sts - a mapped substate overrides the status of the active state, e.g. a oneshot service that exited
+				status = subStateStatus
 			}
-			sender.ServiceCheck(unitSubStateServiceCheck, getServiceCheckStatus(unit.SubState, subStateMapping), "", tags, "")
+			sender.ServiceCheck(unitSubStateServiceCheck, subStateStatus, "", tags, "")
 		}
 
-		c.submitBasicUnitMetrics(sender, conn, unit, tags)
+		unitProperties := c.submitBasicUnitMetrics(sender, conn, unit, tags)
 		c.submitPropertyMetricsAsGauge(sender, conn, unit, tags)
+		// sts - keep the unit properties for the dependencies in the topology
+		monitoredUnits = append(monitoredUnits, monitoredUnit{UnitStatus: unit, properties: unitProperties, status: status})
 	}
 
 	sender.Gauge("systemd.units_total", float64(len(units)), "", nil)
 	sender.Gauge("systemd.units_loaded_count", float64(loadedCount), "", nil)
 	sender.Gauge("systemd.units_monitored_count", float64(monitoredCount), "", nil)
-	return nil
+	return monitoredUnits, nil
 }
 
-func (c *SystemdCheck) submitBasicUnitMetrics(sender aggregator.Sender, conn *dbus.Conn, unit dbus.UnitStatus, tags []string) {
+// submitBasicUnitMetrics submits the unit metrics and returns the unit properties it read them from, or nil when the
+// properties could not be read
+func (c *SystemdCheck) submitBasicUnitMetrics(sender aggregator.Sender, conn *dbus.Conn, unit dbus.UnitStatus, tags []string) map[string]interface{} {
 	active := 0
 	if unit.ActiveState == unitActiveState {
 		active = 1
@@ -363,14 +387,15 @@ func (c *SystemdCheck) submitBasicUnitMetrics(sender aggregator.Sender, conn *db
 	unitProperties, err := c.stats.GetUnitTypeProperties(conn, unit.Name, dbusTypeMap[typeUnit])
 	if err != nil {
 		log.Warnf("Error getting unit unitProperties: %s", unit.Name)
-		return
+		return nil
 	}
 	activeEnterTimestamp, err := getPropertyUint64(unitProperties, "ActiveEnterTimestamp")
 	if err != nil {
 		log.Warnf("Error getting property ActiveEnterTimestamp: %v", err)
-		return
+		return unitProperties
 	}
 	sender.Gauge("systemd.unit.uptime", float64(computeUptime(unit.ActiveState, activeEnterTimestamp, c.stats.UnixNow())), "", tags)
+	return unitProperties
 }
 
 func (c *SystemdCheck) submitCountMetrics(sender aggregator.Sender, units []dbus.UnitStatus) {
@@ -473,6 +498,18 @@ func getPropertyString(properties map[string]interface{}, propertyName string) (
 	return propValue, nil
 }
 
+func getPropertyStringSlice(properties map[string]interface{}, propertyName string) ([]string, error) {
+	prop, ok := properties[propertyName]
+	if !ok {
+		return nil, fmt.Errorf("property %s not found", propertyName)
+	}
+	propValue, ok := prop.([]string)
+	if !ok {
+		return nil, fmt.Errorf("property %s (%T) cannot be converted to []string", propertyName, prop)
+	}
+	return propValue, nil
+}
+
 func getPropertyBool(properties map[string]interface{}, propertyName string) (bool, error) {
 	prop, ok := properties[propertyName]
 	if !ok {
@@ -527,6 +564,8 @@ func (c *SystemdCheck) Configure(rawInstance integration.Data, rawInitConfig int
 	if err != nil {
 		return err
 	}
+	// sts - the topology is collected unless it is disabled explicitly
+	c.config.instance.CollectTopology = true
 	err = yaml.Unmarshal(rawInstance, &c.config.instance)
 	if err != nil {
 		return err
@@ -557,6 +596,8 @@ func systemdFactory() check.Check {
 	return &SystemdCheck{
 		stats:     &defaultSystemdStats{},
 		CheckBase: core.NewCheckBase(systemdCheckName),
+		// sts
+		topologyCollector: MakeSystemdTopologyCollector(),
 	}
 }
 
@@ -0,0 +1,200 @@
+// +build systemd
+
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/metrics"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/StackVista/stackstate-agent/pkg/util"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/coreos/go-systemd/dbus"
+)
+
+const (
+	systemdTopologyCheckID = "systemd_topology"
+	systemdUnitType        = "systemd-unit"
+	unitStateHealthCheck   = "Unit State"
+)
+
+// unitDependencyProperties maps the dependency properties of a unit to the type of the relation they produce
+var unitDependencyProperties = []struct {
+	propertyName string
+	relationType string
+}{
+	{propertyName: "Requires", relationType: "requires"},
+	{propertyName: "Wants", relationType: "wants"},
+	{propertyName: "After", relationType: "starts_after"},
+}
+
+// unitHealthMapping maps the service check status of a unit to its health, so the health of a unit matches its
+// systemd.unit.state service check, or its systemd.unit.substate service check when its substate is mapped
+var unitHealthMapping = map[metrics.ServiceCheckStatus]health.State{
+	metrics.ServiceCheckOK:       health.Clear,
+	metrics.ServiceCheckWarning:  health.Deviating,
+	metrics.ServiceCheckCritical: health.Critical,
+	metrics.ServiceCheckUnknown:  health.Unknown,
+}
+
+// monitoredUnit is a monitored unit with the properties of its org.freedesktop.systemd1.Unit D-Bus interface and the
+// status of its service checks
+type monitoredUnit struct {
+	dbus.UnitStatus
+	properties map[string]interface{}
+	status     metrics.ServiceCheckStatus
+}
+
+// SystemdTopologyCollector contains the checkID, topology instance and health stream of the systemd topology
+type SystemdTopologyCollector struct {
+	corechecks.CheckTopologyCollector
+	HealthStream health.Stream
+}
+
+// MakeSystemdTopologyCollector returns a new instance of SystemdTopologyCollector
+func MakeSystemdTopologyCollector() *SystemdTopologyCollector {
+	return &SystemdTopologyCollector{
+		CheckTopologyCollector: corechecks.MakeCheckTopologyCollector(systemdTopologyCheckID, topology.Instance{
+			Type: "systemd",
+			URL:  "agents",
+		}),
+		HealthStream: health.Stream{Urn: "urn:health:systemd:agents"},
+	}
+}
+
+// BuildTopology produces a component for every monitored unit, related to the host and to the monitored units it
+// depends on, and the status of the units as health
+func (stc *SystemdTopologyCollector) BuildTopology(units []monitoredUnit, intervalSeconds int) error {
+	sender := batcher.GetBatcher()
+	if sender == nil {
+		return errors.New("no batcher instance available, skipping BuildTopology")
+	}
+
+	// try to get the agent hostname, unit names are only unique within a host
+	hostname, err := util.GetHostname()
+	if err != nil {
+		log.Warnf("Can't get hostname for host running the systemd units, not reporting systemd topology: %s", err)
+		return err
+	}
+
+	components, relations := stc.buildTopology(hostname, units)
+	for _, component := range components {
+		sender.SubmitComponent(stc.CheckID, stc.TopologyInstance, *component)
+	}
+	for _, relation := range relations {
+		sender.SubmitRelation(stc.CheckID, stc.TopologyInstance, *relation)
+	}
+	sender.SubmitComplete(stc.CheckID)
+
+	// every run reports the state of all monitored units, so it is sent as a snapshot per host
+	stream := stc.HealthStream
+	stream.SubStream = hostname
+	sender.SubmitHealthStartSnapshot(stc.CheckID, stream, intervalSeconds, 0)
+	for _, unit := range units {
+		checkState := unitCheckState(buildUnitExternalID(hostname, unit.Name), unit)
+		sender.SubmitHealthCheckData(stc.CheckID, stream, checkState.CheckData())
+	}
+	sender.SubmitHealthStopSnapshot(stc.CheckID, stream)
+
+	return nil
+}
+
+// buildTopology produces the unit components, the relations of the units to the host and their dependencies. Only
+// dependencies on other monitored units are related, the others would point to components that are never reported.
+func (stc *SystemdTopologyCollector) buildTopology(hostname string, units []monitoredUnit) ([]*topology.Component, []*topology.Relation) {
+	hostExternalID := fmt.Sprintf("urn:host:/%s", hostname)
+
+	monitored := make(map[string]bool, len(units))
+	for _, unit := range units {
+		monitored[unit.Name] = true
+	}
+
+	components := make([]*topology.Component, 0, len(units))
+	relations := make([]*topology.Relation, 0)
+	for _, unit := range units {
+		component := unitToComponent(hostname, unit)
+		components = append(components, component)
+		relations = append(relations, createRelation(component.ExternalID, hostExternalID, "runs_on"))
+
+		for _, dependency := range unitDependencyProperties {
+			dependencies, err := getPropertyStringSlice(unit.properties, dependency.propertyName)
+			if err != nil {
+				log.Debugf("Cannot read the %s dependencies of unit %s: %v", dependency.propertyName, unit.Name, err)
+				continue
+			}
+			for _, dependencyName := range dependencies {
+				if !monitored[dependencyName] {
+					continue
+				}
+				relations = append(relations, createRelation(component.ExternalID, buildUnitExternalID(hostname, dependencyName), dependency.relationType))
+			}
+		}
+	}
+
+	return components, relations
+}
+
+// unitToComponent produces a topology.Component for a systemd unit, the active state is left out as it is reported as
+// health instead
+func unitToComponent(hostname string, unit monitoredUnit) *topology.Component {
+	component := &topology.Component{
+		ExternalID: buildUnitExternalID(hostname, unit.Name),
+		Type:       topology.Type{Name: systemdUnitType},
+		Data: topology.Data{
+			"name":      unit.Name,
+			"host":      hostname,
+			"loadState": unit.LoadState,
+		},
+	}
+	if index := strings.LastIndex(unit.Name, "."); index >= 0 {
+		component.Data["unitType"] = unit.Name[index+1:]
+	}
+	component.Data.PutNonEmpty("description", unit.Description)
+	if fragmentPath, err := getPropertyString(unit.properties, "FragmentPath"); err == nil {
+		component.Data.PutNonEmpty("fragmentPath", fragmentPath)
+	}
+	return component
+}
+
+// unitCheckState creates the health check state of a unit from the status of its service checks
+func unitCheckState(externalID string, unit monitoredUnit) *health.CheckState {
+	state, found := unitHealthMapping[unit.status]
+	if !found {
+		state = health.Unknown
+	}
+
+	message := ""
+	if state != health.Clear {
+		message = fmt.Sprintf("Unit %s is %s (%s)", unit.Name, unit.ActiveState, unit.SubState)
+	}
+
+	return &health.CheckState{
+		CheckStateID:              fmt.Sprintf("%s:%s", externalID, strings.ToLower(strings.ReplaceAll(unitStateHealthCheck, " ", "-"))),
+		Name:                      unitStateHealthCheck,
+		Health:                    state,
+		TopologyElementIdentifier: externalID,
+		Message:                   message,
+	}
+}
+
+// buildUnitExternalID creates the external id of a unit, unit names are only unique within a host
+func buildUnitExternalID(hostname, unitName string) string {
+	return fmt.Sprintf("urn:%s:/%s:%s", systemdUnitType, hostname, unitName)
+}
+
+// createRelation creates a topology.Relation of the given type between the source and target components, the type is
+// part of the external id as a unit can have several dependencies on the same unit, e.g. Requires and After
+func createRelation(sourceExternalID, targetExternalID, relationType string) *topology.Relation {
+	return &topology.Relation{
+		ExternalID: fmt.Sprintf("%s-%s->%s", sourceExternalID, relationType, targetExternalID),
+		SourceID:   sourceExternalID,
+		TargetID:   targetExternalID,
+		Type:       topology.Type{Name: relationType},
+		Data:       topology.Data{},
+	}
+}
@@ -0,0 +1,280 @@
+// +build systemd
+
+package systemd
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/aggregator/mocksender"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/config"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/metrics"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMakeSystemdTopologyCollector(t *testing.T) {
+	stc := MakeSystemdTopologyCollector()
+	assert.Equal(t, check.ID("systemd_topology"), stc.CheckID)
+	assert.Equal(t, topology.Instance{Type: "systemd", URL: "agents"}, stc.TopologyInstance)
+	assert.Equal(t, health.Stream{Urn: "urn:health:systemd:agents"}, stc.HealthStream)
+}
+
+func TestSystemdTopology(t *testing.T) {
+	rawInstanceConfig := []byte(`
+unit_names:
+ - nginx.service
+ - postgresql.service
+ - backup.timer
+`)
+
+	stats := createDefaultMockSystemdStats()
+	stats.On("ListUnits", mock.Anything).Return([]dbus.UnitStatus{
+		{Name: "nginx.service", Description: "A high performance web server", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+		{Name: "postgresql.service", Description: "PostgreSQL RDBMS", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+		{Name: "backup.timer", LoadState: "loaded", ActiveState: "unknown-state", SubState: "waiting"},
+		{Name: "network.target", LoadState: "loaded", ActiveState: "active", SubState: "active"},
+	}, nil)
+	stats.On("UnixNow").Return(int64(1000 * 1000))
+	stats.On("GetUnitTypeProperties", mock.Anything, mock.Anything, dbusTypeMap[typeService]).Return(map[string]interface{}{}, nil)
+	stats.On("GetUnitTypeProperties", mock.Anything, "nginx.service", dbusTypeMap[typeUnit]).Return(map[string]interface{}{
+		"ActiveEnterTimestamp": uint64(100),
+		"FragmentPath":         "/lib/systemd/system/nginx.service",
+		// dependencies on units that are not monitored are not related
+		"Requires": []string{"postgresql.service", "system.slice"},
+		"Wants":    []string{"network.target"},
+		"After":    []string{"postgresql.service", "network.target"},
+	}, nil)
+	stats.On("GetUnitTypeProperties", mock.Anything, "postgresql.service", dbusTypeMap[typeUnit]).Return(map[string]interface{}{
+		"ActiveEnterTimestamp": uint64(100),
+		"Requires":             []string{},
+	}, nil)
+	stats.On("GetUnitTypeProperties", mock.Anything, "backup.timer", dbusTypeMap[typeUnit]).Return(map[string]interface{}{}, assert.AnError)
+	stats.On("GetVersion", mock.Anything).Return(systemdVersion)
+
+	systemdCheck := systemdFactory().(*SystemdCheck)
+	systemdCheck.stats = stats
+	err := systemdCheck.Configure(rawInstanceConfig, nil, "test")
+	assert.NoError(t, err)
+	assert.True(t, systemdCheck.config.instance.CollectTopology)
+
+	mockSender := mocksender.NewMockSender(systemdCheck.ID())
+	mockSender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockSender.On("ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockSender.On("Commit").Return()
+
+	// set up the mock batcher
+	mockBatcher := batcher.NewMockBatcher()
+	// set mock hostname
+	testHostname := "test-hostname"
+	config.Datadog.Set("hostname", testHostname)
+
+	err = systemdCheck.Run()
+	assert.NoError(t, err)
+
+	hostID := "urn:host:/test-hostname"
+	nginxID := "urn:systemd-unit:/test-hostname:nginx.service"
+	postgresID := "urn:systemd-unit:/test-hostname:postgresql.service"
+	backupID := "urn:systemd-unit:/test-hostname:backup.timer"
+	relation := func(source, target, relationType string) topology.Relation {
+		return topology.Relation{
+			ExternalID: source + "-" + relationType + "->" + target,
+			SourceID:   source,
+			TargetID:   target,
+			Type:       topology.Type{Name: relationType},
+			Data:       topology.Data{},
+		}
+	}
+	stream := health.Stream{Urn: "urn:health:systemd:agents", SubStream: testHostname}
+
+	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
+		"systemd_topology": {
+			Health: map[string]health.Health{
+				stream.GoString(): {
+					StartSnapshot: &health.StartSnapshotMetadata{RepeatIntervalS: int(systemdCheck.Interval().Seconds())},
+					StopSnapshot:  &health.StopSnapshotMetadata{},
+					Stream:        stream,
+					CheckStates: []health.CheckData{
+						unitCheckState(nginxID, monitoredUnit{UnitStatus: dbus.UnitStatus{Name: "nginx.service", ActiveState: "active"}, status: metrics.ServiceCheckOK}).CheckData(),
+						{
+							"checkStateId":              postgresID + ":unit-state",
+							"name":                      "Unit State",
+							"health":                    string(health.Critical),
+							"topologyElementIdentifier": postgresID,
+							"message":                   "Unit postgresql.service is failed (failed)",
+						},
+						{
+							"checkStateId":              backupID + ":unit-state",
+							"name":                      "Unit State",
+							"health":                    string(health.Unknown),
+							"topologyElementIdentifier": backupID,
+							"message":                   "Unit backup.timer is unknown-state (waiting)",
+						},
+					},
+				},
+			},
+			Topology: &topology.Topology{
+				StartSnapshot: false,
+				StopSnapshot:  false,
+				Instance:      topology.Instance{Type: "systemd", URL: "agents"},
+				Components: []topology.Component{
+					{
+						ExternalID: nginxID,
+						Type:       topology.Type{Name: "systemd-unit"},
+						Data: topology.Data{
+							"name":         "nginx.service",
+							"host":         testHostname,
+							"loadState":    "loaded",
+							"unitType":     "service",
+							"description":  "A high performance web server",
+							"fragmentPath": "/lib/systemd/system/nginx.service",
+						},
+					},
+					{
+						ExternalID: postgresID,
+						Type:       topology.Type{Name: "systemd-unit"},
+						Data: topology.Data{
+							"name":        "postgresql.service",
+							"host":        testHostname,
+							"loadState":   "loaded",
+							"unitType":    "service",
+							"description": "PostgreSQL RDBMS",
+						},
+					},
+					{
+						ExternalID: backupID,
+						Type:       topology.Type{Name: "systemd-unit"},
+						Data: topology.Data{
+							"name":      "backup.timer",
+							"host":      testHostname,
+							"loadState": "loaded",
+							"unitType":  "timer",
+						},
+					},
+				},
+				Relations: []topology.Relation{
+					relation(nginxID, hostID, "runs_on"),
+					relation(nginxID, postgresID, "requires"),
+					relation(nginxID, postgresID, "starts_after"),
+					relation(postgresID, hostID, "runs_on"),
+					relation(backupID, hostID, "runs_on"),
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, expectedTopology, mockBatcher.CollectedTopology.Flush())
+}
+
+func TestSystemdTopologyDisabled(t *testing.T) {
+	rawInstanceConfig := []byte(`
+unit_names:
+ - nginx.service
+collect_topology: false
+`)
+
+	stats := createDefaultMockSystemdStats()
+	stats.On("ListUnits", mock.Anything).Return([]dbus.UnitStatus{
+		{Name: "nginx.service", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+	}, nil)
+	stats.On("UnixNow").Return(int64(1000 * 1000))
+	stats.On("GetUnitTypeProperties", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+	stats.On("GetVersion", mock.Anything).Return(systemdVersion)
+
+	systemdCheck := systemdFactory().(*SystemdCheck)
+	systemdCheck.stats = stats
+	err := systemdCheck.Configure(rawInstanceConfig, nil, "test")
+	assert.NoError(t, err)
+
+	mockSender := mocksender.NewMockSender(systemdCheck.ID())
+	mockSender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockSender.On("ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockSender.On("Commit").Return()
+
+	mockBatcher := batcher.NewMockBatcher()
+
+	err = systemdCheck.Run()
+	assert.NoError(t, err)
+	assert.Empty(t, mockBatcher.CollectedTopology.Flush())
+}
+
+func TestUnitCheckState(t *testing.T) {
+	for _, tc := range []struct {
+		status   metrics.ServiceCheckStatus
+		expected health.State
+	}{
+		{metrics.ServiceCheckOK, health.Clear},
+		{metrics.ServiceCheckWarning, health.Deviating},
+		{metrics.ServiceCheckCritical, health.Critical},
+		{metrics.ServiceCheckUnknown, health.Unknown},
+	} {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			checkState := unitCheckState("urn:systemd-unit:/test-hostname:backup.service",
+				monitoredUnit{UnitStatus: dbus.UnitStatus{Name: "backup.service", ActiveState: "inactive", SubState: "dead"}, status: tc.status})
+			assert.Equal(t, tc.expected, checkState.Health)
+		})
+	}
+}
+
+func TestSystemdTopologyHealthFollowsTheServiceChecks(t *testing.T) {
+	rawInstanceConfig := []byte(`
+unit_names:
+ - backup.service
+ - cleanup.service
+ - worker.service
+substate_status_mapping:
+  backup.service:
+    exited: ok
+  worker.service:
+    exited: ok
+`)
+
+	stats := createDefaultMockSystemdStats()
+	stats.On("ListUnits", mock.Anything).Return([]dbus.UnitStatus{
+		// a oneshot service that exited, its substate is mapped
+		{Name: "backup.service", LoadState: "loaded", ActiveState: "inactive", SubState: "exited"},
+		// an inactive service without a substate mapping is critical, like its systemd.unit.state service check
+		{Name: "cleanup.service", LoadState: "loaded", ActiveState: "inactive", SubState: "dead"},
+		// a substate that is not in the mapping keeps the status of the active state
+		{Name: "worker.service", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+	}, nil)
+	stats.On("UnixNow").Return(int64(1000 * 1000))
+	stats.On("GetUnitTypeProperties", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+	stats.On("GetVersion", mock.Anything).Return(systemdVersion)
+
+	systemdCheck := systemdFactory().(*SystemdCheck)
+	systemdCheck.stats = stats
+	err := systemdCheck.Configure(rawInstanceConfig, nil, "test")
+	assert.NoError(t, err)
+
+	mockSender := mocksender.NewMockSender(systemdCheck.ID())
+	mockSender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockSender.On("ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	mockSender.On("Commit").Return()
+
+	mockBatcher := batcher.NewMockBatcher()
+	testHostname := "test-hostname"
+	config.Datadog.Set("hostname", testHostname)
+
+	err = systemdCheck.Run()
+	assert.NoError(t, err)
+
+	mockSender.AssertCalled(t, "ServiceCheck", unitStateServiceCheck, metrics.ServiceCheckCritical, "", []string{"unit:backup.service"}, "")
+	mockSender.AssertCalled(t, "ServiceCheck", unitSubStateServiceCheck, metrics.ServiceCheckOK, "", []string{"unit:backup.service"}, "")
+
+	stream := health.Stream{Urn: "urn:health:systemd:agents", SubStream: testHostname}
+	checkStates := mockBatcher.CollectedTopology.Flush()["systemd_topology"].Health[stream.GoString()].CheckStates
+	healthByUnit := make(map[string]interface{}, len(checkStates))
+	for _, checkState := range checkStates {
+		healthByUnit[checkState["topologyElementIdentifier"].(string)] = checkState["health"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"urn:systemd-unit:/test-hostname:backup.service":  string(health.Clear),
+		"urn:systemd-unit:/test-hostname:cleanup.service": string(health.Critical),
+		"urn:systemd-unit:/test-hostname:worker.service":  string(health.Critical),
+	}, healthByUnit)
+}
@@ -223,6 +223,16 @@ func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Da
 		}
 	}
 
+	// [sts] Override the batcher settings for this check
+	if commonOptions.BatcherMaxCapacity > 0 || commonOptions.BatcherFlushInterval > 0 {
+		if b := batcher.GetBatcher(); b != nil {
+			b.SubmitCheckBatchConfig(c.id, batcher.CheckBatchConfig{
+				MaxCapacity:   commonOptions.BatcherMaxCapacity,
+				FlushInterval: time.Duration(commonOptions.BatcherFlushInterval) * time.Second,
+			})
+		}
+	}
+
 	cInitConfig := TrackedCString(string(initConfig))
 	cInstance := TrackedCString(string(data))
 	cCheckID := TrackedCString(string(c.id))
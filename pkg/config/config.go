@@ -187,6 +187,20 @@ func InitConfig(config Config) {
 
 	// [sts] batcher environment variables
 	config.BindEnvAndSetDefault("batcher_capacity", DefaultBatcherBufferSize)
+	config.BindEnvAndSetDefault("batcher_max_payload_size", 2*megaByte+megaByte/2)
+	config.BindEnvAndSetDefault("batcher_flush_interval", 0) // in seconds, 0 means disabled
+	config.BindEnvAndSetDefault("batcher_spool.enabled", false)
+	config.BindEnvAndSetDefault("batcher_spool.max_size_mb", 100)
+	config.BindEnvAndSetDefault("batcher_spool.max_age", 3600)      // in seconds
+	config.BindEnvAndSetDefault("batcher_spool.retry_interval", 15) // in seconds
+	config.BindEnvAndSetDefault("batcher_topology_deltas.enabled", false)
+	config.BindEnvAndSetDefault("batcher_topology_deltas.full_snapshot_interval", 3600) // in seconds
+	config.BindEnvAndSetDefault("batcher_validation.enabled", false)
+	config.BindEnvAndSetDefault("batcher_validation.drop_dangling_relations", false)
+	config.BindEnvAndSetDefault("batcher_validation.snapshot_timeout", 3600) // in seconds, 0 means never
+	config.BindEnvAndSetDefault("batcher_health.deduplicate", true)
+	config.BindEnvAndSetDefault("batcher_health.deduplicate_window", 300) // in seconds, 0 means only within snapshots
+	config.BindEnvAndSetDefault("batcher_health.state_expiry", 3600)      // in seconds, 0 means never
 
 	// overridden in IoT Agent main
 	config.BindEnvAndSetDefault("iot_host", false)
@@ -405,7 +419,11 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("collect_kubernetes_events", false)
 	config.BindEnvAndSetDefault("collect_kubernetes_metrics", false)
 	config.BindEnvAndSetDefault("collect_kubernetes_topology", false)
+	config.BindEnvAndSetDefault("collect_kubernetes_health", true)
 	config.BindEnvAndSetDefault("collect_kubernetes_timeout", 10)
+	config.BindEnvAndSetDefault("kubernetes_topology_use_informers", false)
+	config.BindEnvAndSetDefault("kubernetes_topology_change_interval", 0)
+	config.BindEnvAndSetDefault("kubernetes_cluster_domain", "cluster.local")
 	config.BindEnvAndSetDefault("kubelet_client_ca", "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt")
 
 	config.BindEnvAndSetDefault("kubelet_auth_token_path", "")
@@ -669,6 +687,7 @@ func InitConfig(config Config) {
 	config.SetKnown("process_config.container_source")
 	config.SetKnown("process_config.intervals.connections")
 	config.SetKnown("process_config.expvar_port")
+	config.SetKnown("process_config.connections_topology_enabled")
 
 	// System probe
 	config.SetKnown("system_probe_config.enabled")
@@ -694,6 +713,10 @@ func InitConfig(config Config) {
 	config.SetKnown("system_probe_config.closed_channel_size")
 	config.SetKnown("system_probe_config.dns_timeout_in_s")
 	config.SetKnown("system_probe_config.collect_dns_stats")
+	config.SetKnown("system_probe_config.max_dns_domains_tracked")
+	config.SetKnown("system_probe_config.enable_http_monitoring")
+	config.SetKnown("system_probe_config.aggregate_connections")
+	config.SetKnown("system_probe_config.collect_tcp_failures")
 	config.SetKnown("system_probe_config.offset_guess_threshold")
 	config.SetKnown("system_probe_config.enable_tcp_queue_length")
 	config.SetKnown("system_probe_config.enable_oom_kill")
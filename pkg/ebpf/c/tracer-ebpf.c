@@ -69,6 +69,45 @@ struct bpf_map_def SEC("maps/tcp_stats") tcp_stats = {
     .namespace = "",
 };
 
+/* This map tracks the TCP connections in SYN_SENT, from tcp_connect until the handshake completes or fails. Failed
+ * connects are reported from tcp_done, which does not run in the context of the process that connected.
+ * Key: the struct sock * of the connection
+ * Value: the conn_tuple_t of the connection, with the PID of the process that connected
+ */
+struct bpf_map_def SEC("maps/tcp_ongoing_connect") tcp_ongoing_connect = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(void*),
+    .value_size = sizeof(conn_tuple_t),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This is a key/value store with the keys being a conn_tuple_t (but without the PID being used)
+ * and the values being the timestamp of the last zero window probe sent on the connection.
+ */
+struct bpf_map_def SEC("maps/tcp_zero_window") tcp_zero_window = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(conn_tuple_t),
+    .value_size = sizeof(__u64),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
+/* This is a key/value store with the keys being a conn_tuple_t (but without the PID being used)
+ * and the values being the tcp_failures_t of the connection.
+ * The entries are deleted from userspace, after the failures of a closed connection have been read.
+ */
+struct bpf_map_def SEC("maps/tcp_failures") tcp_failures = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = sizeof(conn_tuple_t),
+    .value_size = sizeof(tcp_failures_t),
+    .max_entries = 0, // This will get overridden at runtime using max_tracked_connections
+    .pinning = 0,
+    .namespace = "",
+};
+
 /* Will hold the tcp close events
  * The keys are the cpu number and the values a perf file descriptor for a perf event
  */
@@ -486,6 +525,36 @@ static void update_tcp_stats(conn_tuple_t* t, tcp_stats_t stats) {
     }
 }
 
+// get_tcp_failures returns the TCP failures of a connection, creating them if needed
+__attribute__((always_inline))
+static tcp_failures_t* get_tcp_failures(conn_tuple_t* t) {
+    // query failures without the PID from the tuple
+    u32 pid = t->pid;
+    t->pid = 0;
+
+    // initialize-if-no-exist the failures, since they are keyed by the connection tuple
+    tcp_failures_t empty = {};
+    bpf_map_update_elem(&tcp_failures, t, &empty, BPF_NOEXIST);
+
+    tcp_failures_t* val = bpf_map_lookup_elem(&tcp_failures, t);
+    t->pid = pid;
+    return val;
+}
+
+// get_tracked_tcp_failures returns the TCP failures of a connection that is still tracked. The failures are not
+// created for the connections that tcp_close already reported, userspace would never clean them up.
+__attribute__((always_inline))
+static tcp_failures_t* get_tracked_tcp_failures(conn_tuple_t* t) {
+    u32 pid = t->pid;
+    t->pid = 0;
+    tcp_stats_t* tracked = bpf_map_lookup_elem(&tcp_stats, t);
+    t->pid = pid;
+    if (tracked == NULL) {
+        return NULL;
+    }
+    return get_tcp_failures(t);
+}
+
 __attribute__((always_inline))
 static void cleanup_tcp_conn(struct pt_regs* ctx, conn_tuple_t* tup) {
     u32 cpu = bpf_get_smp_processor_id();
@@ -500,6 +569,7 @@ static void cleanup_tcp_conn(struct pt_regs* ctx, conn_tuple_t* tup) {
     conn.tup.pid = 0;
     tst = bpf_map_lookup_elem(&tcp_stats, &(conn.tup));
     bpf_map_delete_elem(&tcp_stats, &(conn.tup));
+    bpf_map_delete_elem(&tcp_zero_window, &(conn.tup));
     conn.tup.pid = tup->pid;
 
     cst = bpf_map_lookup_elem(&conn_stats, &(conn.tup));
@@ -740,6 +810,9 @@ int kprobe__tcp_close(struct pt_regs* ctx) {
     u64 pid_tgid = bpf_get_current_pid_tgid();
     sk = (struct sock*)PT_REGS_PARM1(ctx);
 
+    // The connection may be closed before the handshake completes
+    bpf_map_delete_elem(&tcp_ongoing_connect, &sk);
+
     status = bpf_map_lookup_elem(&tracer_status, &zero);
     if (status == NULL) {
         return 0;
@@ -927,6 +1000,149 @@ int kprobe__tcp_retransmit_skb(struct pt_regs* ctx) {
     return handle_retransmit(sk, status);
 }
 
+SEC("kprobe/tcp_connect")
+int kprobe__tcp_connect(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    u64 zero = 0;
+    tracer_status_t* status = bpf_map_lookup_elem(&tracer_status, &zero);
+    if (status == NULL) {
+        return 0;
+    }
+    log_debug("kprobe/tcp_connect: pid_tgid: %d\n", pid_tgid);
+
+    // The source port is already assigned when the SYN is sent
+    conn_tuple_t t = {};
+    if (!read_conn_tuple(&t, status, sk, pid_tgid, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    bpf_map_update_elem(&tcp_ongoing_connect, &sk, &t, BPF_ANY);
+    return 0;
+}
+
+SEC("kprobe/tcp_finish_connect")
+int kprobe__tcp_finish_connect(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    bpf_map_delete_elem(&tcp_ongoing_connect, &sk);
+    return 0;
+}
+
+SEC("kprobe/tcp_done")
+int kprobe__tcp_done(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    conn_tuple_t* connecting = bpf_map_lookup_elem(&tcp_ongoing_connect, &sk);
+    if (connecting == NULL) {
+        return 0;
+    }
+
+    conn_tuple_t t = {};
+    bpf_probe_read(&t, sizeof(conn_tuple_t), connecting);
+    bpf_map_delete_elem(&tcp_ongoing_connect, &sk);
+    log_debug("kprobe/tcp_done: failed connect: pid: %d, dport: %d\n", t.pid, t.dport);
+
+    tcp_failures_t* failures = get_tcp_failures(&t);
+    if (failures != NULL) {
+        failures->connect_failures |= TCP_FAILED_CONNECT;
+    }
+
+    // The connection never became established, so report it as closed now. The socket has no source port anymore
+    // once tcp_done returns, so tcp_close can't report it.
+    handle_message(&t, 0, 0);
+    cleanup_tcp_conn(ctx, &t);
+    return 0;
+}
+
+SEC("kprobe/tcp_reset")
+int kprobe__tcp_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    u64 zero = 0;
+    tracer_status_t* status = bpf_map_lookup_elem(&tracer_status, &zero);
+    if (status == NULL) {
+        return 0;
+    }
+
+    conn_tuple_t t = {};
+
+    // A reset in response to the SYN refuses the connection, which is reported by tcp_done
+    conn_tuple_t* connecting = bpf_map_lookup_elem(&tcp_ongoing_connect, &sk);
+    if (connecting != NULL) {
+        bpf_probe_read(&t, sizeof(conn_tuple_t), connecting);
+        tcp_failures_t* failures = get_tcp_failures(&t);
+        if (failures != NULL) {
+            __sync_fetch_and_add(&failures->resets_received, 1);
+            failures->connect_failures |= TCP_REFUSED_CONNECT;
+        }
+        return 0;
+    }
+
+    if (!read_conn_tuple(&t, status, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_failures_t* failures = get_tracked_tcp_failures(&t);
+    if (failures != NULL) {
+        __sync_fetch_and_add(&failures->resets_received, 1);
+    }
+    return 0;
+}
+
+SEC("kprobe/tcp_send_active_reset")
+int kprobe__tcp_send_active_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    u64 zero = 0;
+    tracer_status_t* status = bpf_map_lookup_elem(&tracer_status, &zero);
+    if (status == NULL) {
+        return 0;
+    }
+
+    // The resets sent by tcp_close after moving the socket to TCP_CLOSE are missed, the socket has no source port
+    // anymore at that point
+    conn_tuple_t t = {};
+    if (!read_conn_tuple(&t, status, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_failures_t* failures = get_tracked_tcp_failures(&t);
+    if (failures != NULL) {
+        __sync_fetch_and_add(&failures->resets_sent, 1);
+    }
+    return 0;
+}
+
+// Zero window probes are sent with an exponential backoff that is capped by TCP_RTO_MAX (120s)
+#define TCP_RTO_MAX_NS 120000000000ULL
+
+SEC("kprobe/tcp_send_probe0")
+int kprobe__tcp_send_probe0(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    u64 ts = bpf_ktime_get_ns();
+    u64 zero = 0;
+    tracer_status_t* status = bpf_map_lookup_elem(&tracer_status, &zero);
+    if (status == NULL) {
+        return 0;
+    }
+
+    conn_tuple_t t = {};
+    if (!read_conn_tuple(&t, status, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_failures_t* failures = get_tracked_tcp_failures(&t);
+    if (failures == NULL) {
+        return 0;
+    }
+
+    // The peer advertised a zero window at least since the previous probe. The time between the zero window and the
+    // first probe, and after the last probe, is not accounted for.
+    u64* last = bpf_map_lookup_elem(&tcp_zero_window, &t);
+    if (last != NULL && ts > *last && ts - *last <= TCP_RTO_MAX_NS) {
+        __sync_fetch_and_add(&failures->zero_window_ms, (u32)((ts - *last) / 1000000));
+    }
+    bpf_map_update_elem(&tcp_zero_window, &t, &ts, BPF_ANY);
+    return 0;
+}
+
 SEC("kretprobe/inet_csk_accept")
 int kretprobe__inet_csk_accept(struct pt_regs* ctx) {
     struct sock* newsk = (struct sock*)PT_REGS_RC(ctx);
@@ -1219,6 +1435,62 @@ int socket__dns_filter(struct __sk_buff* skb) {
     return -1;
 }
 
+// The first four bytes of the TCP payloads of HTTP/1.x requests and responses, as loaded by load_word
+#define HTTP_PREFIX_GET        0x47455420 // "GET "
+#define HTTP_PREFIX_POST       0x504F5354 // "POST"
+#define HTTP_PREFIX_PUT        0x50555420 // "PUT "
+#define HTTP_PREFIX_HEAD       0x48454144 // "HEAD"
+#define HTTP_PREFIX_DELETE     0x44454C45 // "DELE"
+#define HTTP_PREFIX_PATCH      0x50415443 // "PATC"
+#define HTTP_PREFIX_OPTIONS    0x4F505449 // "OPTI"
+#define HTTP_PREFIX_RESPONSE   0x48545450 // "HTTP"
+
+// This function is meant to be used as a BPF_PROG_TYPE_SOCKET_FILTER.
+// When attached to a RAW_SOCKET, this code filters out everything but TCP segments that start an HTTP/1.x request or
+// response. Like the DNS filter it assumes IP headers without options, the payload is parsed in user space.
+SEC("socket/http_filter")
+int socket__http_filter(struct __sk_buff* skb) {
+    __u16 l3_proto = load_half(skb, offsetof(struct ethhdr, h_proto));
+    __u8 l4_proto;
+    size_t ip_hdr_size;
+
+    switch (l3_proto) {
+    case ETH_P_IP:
+        ip_hdr_size = sizeof(struct iphdr);
+        l4_proto = load_byte(skb, ETH_HLEN + offsetof(struct iphdr, protocol));
+        break;
+    case ETH_P_IPV6:
+        ip_hdr_size = sizeof(struct ipv6hdr);
+        l4_proto = load_byte(skb, ETH_HLEN + offsetof(struct ipv6hdr, nexthdr));
+        break;
+    default:
+        return 0;
+    }
+
+    if (l4_proto != IPPROTO_TCP)
+        return 0;
+
+    // the data offset is the upper nibble of the 13th byte of the TCP header, in 32-bit words
+    size_t tcp_hdr_size = (load_byte(skb, ETH_HLEN + ip_hdr_size + 12) >> 4) * 4;
+    size_t payload_offset = ETH_HLEN + ip_hdr_size + tcp_hdr_size;
+    if (skb->len < payload_offset + 4)
+        return 0;
+
+    switch (load_word(skb, payload_offset)) {
+    case HTTP_PREFIX_GET:
+    case HTTP_PREFIX_POST:
+    case HTTP_PREFIX_PUT:
+    case HTTP_PREFIX_HEAD:
+    case HTTP_PREFIX_DELETE:
+    case HTTP_PREFIX_PATCH:
+    case HTTP_PREFIX_OPTIONS:
+    case HTTP_PREFIX_RESPONSE:
+        return -1;
+    default:
+        return 0;
+    }
+}
+
 // This number will be interpreted by gobpf-elf-loader to set the current running kernel version
 __u32 _version SEC("version") = 0xFFFFFFFE;
 
@@ -60,6 +60,24 @@ typedef struct {
     __u32 rtt_var;
 } tcp_stats_t;
 
+// Connect failure bit masks of tcp_failures_t
+typedef enum {
+    // The connection never completed the handshake (SYN timeout, reset or unreachable destination)
+    TCP_FAILED_CONNECT = 1 << 0,
+    // The connection was refused by a reset in response to the SYN
+    TCP_REFUSED_CONNECT = 1 << 1,
+} tcp_connect_failure_mask_t;
+
+// TCP failures of a connection, kept apart from tcp_stats_t which is embedded in the closed connection batches
+typedef struct {
+    __u32 resets_sent;
+    __u32 resets_received;
+    // Time in ms the peer advertised a zero window, measured between the zero window probes
+    __u32 zero_window_ms;
+    // Bitmask of tcp_connect_failure_mask_t
+    __u32 connect_failures;
+} tcp_failures_t;
+
 // Full data for a tcp connection
 typedef struct {
     conn_tuple_t tup;
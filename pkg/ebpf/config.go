@@ -29,6 +29,10 @@ type Config struct {
 	// DNSTimeout determines the length of time to wait before considering a DNS Query to have timed out
 	DNSTimeout time.Duration
 
+	// MaxDNSDomainsTracked is the maximum number of queried domains the DNS stats are broken down by. The least recently
+	// seen domain is dropped when a new domain exceeds it.
+	MaxDNSDomainsTracked int
+
 	// UDPConnTimeout determines the length of traffic inactivity between two (IP, port)-pairs before declaring a UDP
 	// connection as inactive.
 	// Note: As UDP traffic is technically "connection-less", for tracking, we consider a UDP connection to be traffic
@@ -55,6 +59,21 @@ type Config struct {
 	// get flushed on every client request (default 30s check interval)
 	MaxDNSStatsBufferred int
 
+	// EnableHTTPMonitoring enables the aggregation of the HTTP/1.x requests on the TCP connections
+	EnableHTTPMonitoring bool
+
+	// MaxHTTPStatsBuffered represents the maximum number of HTTP stats we'll buffer in memory. These stats
+	// get flushed on every client request (default 30s check interval)
+	MaxHTTPStatsBuffered int
+
+	// CollectTCPFailures enables the collection of the failed connects, resets and zero window time of the TCP
+	// connections. It is disabled with a warning when the eBPF module was built without the TCP failure probes.
+	CollectTCPFailures bool
+
+	// AggregateConnections collapses the connections of a process to the same service, that only differ in the
+	// ephemeral port, into a single connection
+	AggregateConnections bool
+
 	// MaxConnectionsStateBuffered represents the maximum number of state objects that we'll store in memory. These state objects store
 	// the stats for a connection so we can accurately determine traffic change between client requests.
 	MaxConnectionsStateBuffered int
@@ -111,15 +130,18 @@ func NewDefaultConfig() *Config {
 		ProcRoot:              "/proc",
 		BPFDebug:              false,
 		EnableConntrack:       true,
+		CollectTCPFailures:    true,
 		// With clients checking connection stats roughly every 30s, this gives us roughly ~1.6k + ~2.5k objects a second respectively.
 		MaxClosedConnectionsBuffered: 50000,
 		MaxConnectionsStateBuffered:  75000,
 		MaxDNSStatsBufferred:         75000,
+		MaxHTTPStatsBuffered:         75000,
 		ClientStateExpiry:            2 * time.Minute,
 		ClosedChannelSize:            500,
 		// DNS Stats related configurations
 		CollectDNSStats:      false,
 		DNSTimeout:           15 * time.Second,
+		MaxDNSDomainsTracked: 1000,
 		OffsetGuessThreshold: 400,
 	}
 }
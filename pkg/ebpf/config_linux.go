@@ -2,6 +2,16 @@
 
 package ebpf
 
+// tcpFailureProbes are the kprobes that collect the TCP failures of the connections
+var tcpFailureProbes = []KProbeName{
+	TCPConnect,
+	TCPFinishConnect,
+	TCPDone,
+	TCPReset,
+	TCPSendActiveReset,
+	TCPSendProbe0,
+}
+
 // EnabledKProbes returns a map of kprobes that are enabled per config settings.
 // This map does not include the probes used exclusively in the offset guessing process.
 func (c *Config) EnabledKProbes(pre410Kernel bool) map[KProbeName]struct{} {
@@ -20,6 +30,12 @@ func (c *Config) EnabledKProbes(pre410Kernel bool) map[KProbeName]struct{} {
 		enabled[InetCskAcceptReturn] = struct{}{}
 		enabled[TCPv4DestroySock] = struct{}{}
 
+		if c.CollectTCPFailures {
+			for _, probe := range tcpFailureProbes {
+				enabled[probe] = struct{}{}
+			}
+		}
+
 		if c.BPFDebug {
 			enabled[TCPSendMsgReturn] = struct{}{}
 		}
@@ -3,6 +3,7 @@
 package ebpf
 
 import (
+	"encoding/binary"
 	"unsafe"
 
 	"github.com/StackVista/stackstate-agent/pkg/network"
@@ -77,6 +78,14 @@ __u32 rtt_var;
 */
 type TCPStats C.tcp_stats_t
 
+/* tcp_failures_t
+__u32 resets_sent;
+__u32 resets_received;
+__u32 zero_window_ms;
+__u32 connect_failures;
+*/
+type TCPFailures C.tcp_failures_t
+
 /*
 __u32 tcp_sent_miscounts;
 */
@@ -149,6 +158,46 @@ func connStats(t *ConnTuple, s *ConnStatsWithTimestamp, tcpStats *TCPStats) netw
 	}
 }
 
+// addTCPFailures sets the TCP failures of a connection
+func addTCPFailures(conn *network.ConnectionStats, f *TCPFailures) {
+	conn.FailedConnects = connectFailure(f, C.TCP_FAILED_CONNECT)
+	conn.RefusedConnects = connectFailure(f, C.TCP_REFUSED_CONNECT)
+	conn.ResetsSent = uint32(f.resets_sent)
+	conn.ResetsReceived = uint32(f.resets_received)
+	conn.ZeroWindowTime = uint32(f.zero_window_ms)
+}
+
+// connectFailure returns 1 if the connect failure bit is set in the TCP failures of a connection
+func connectFailure(f *TCPFailures, failure uint) uint32 {
+	if uint(f.connect_failures)&failure == 0 {
+		return 0
+	}
+	return 1
+}
+
+// connTuple returns the tuple of a TCP connection, without the pid, which is the key of the TCP maps
+func connTuple(conn *network.ConnectionStats) *ConnTuple {
+	t := &ConnTuple{
+		sport:    C.__u16(conn.SPort),
+		dport:    C.__u16(conn.DPort),
+		netns:    C.__u32(conn.NetNS),
+		metadata: C.CONN_TYPE_TCP,
+	}
+
+	source, dest := conn.Source.Bytes(), conn.Dest.Bytes()
+	if conn.Family == network.AFINET6 {
+		t.metadata |= C.CONN_V6
+		t.saddr_h = C.__u64(binary.LittleEndian.Uint64(source[:8]))
+		t.saddr_l = C.__u64(binary.LittleEndian.Uint64(source[8:]))
+		t.daddr_h = C.__u64(binary.LittleEndian.Uint64(dest[:8]))
+		t.daddr_l = C.__u64(binary.LittleEndian.Uint64(dest[8:]))
+	} else {
+		t.saddr_l = C.__u64(binary.LittleEndian.Uint32(source))
+		t.daddr_l = C.__u64(binary.LittleEndian.Uint32(dest))
+	}
+	return t
+}
+
 func connType(m uint) network.ConnectionType {
 	// First bit of metadata indicates if the connection is TCP or UDP
 	if m&C.CONN_TYPE_TCP == 0 {
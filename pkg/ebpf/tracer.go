@@ -22,7 +22,7 @@ import (
 
 var (
 	expvarEndpoints map[string]*expvar.Map
-	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns"}
+	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns", "http"}
 )
 
 func init() {
@@ -45,6 +45,13 @@ type Tracer struct {
 
 	reverseDNS network.ReverseDNS
 
+	// sts
+	httpMonitor network.HTTPMonitor
+	// sts - nil unless connections are aggregated
+	aggregator *network.ConnectionAggregator
+	// sts - nil unless the TCP failures are collected
+	tcpFailures *bpflib.Map
+
 	perfMap      *bpflib.PerfMap
 	batchManager *PerfBatchManager
 
@@ -111,7 +118,8 @@ func NewTracer(config *Config) (*Tracer, error) {
 	}
 
 	enableSocketFilter := config.DNSInspection && !pre410Kernel
-	err = m.Load(SectionsFromConfig(config, enableSocketFilter))
+	enableHTTPFilter := config.EnableHTTPMonitoring && !pre410Kernel
+	err = m.Load(SectionsFromConfig(config, enableSocketFilter, enableHTTPFilter))
 	if err != nil {
 		return nil, fmt.Errorf("could not load bpf module: %s", err)
 	}
@@ -137,6 +145,17 @@ func NewTracer(config *Config) (*Tracer, error) {
 	// Use the config to determine what kernel probes should be enabled
 	enabledProbes := config.EnabledKProbes(pre410Kernel)
 
+	// sts - the TCP failures are only collected when the eBPF module was built with their map and probes
+	var tcpFailuresMp *bpflib.Map
+	if config.CollectTCPFailures {
+		if tcpFailuresMp = m.Map(string(tcpFailuresMap)); tcpFailuresMp == nil {
+			log.Warnf("TCP failure collection is disabled: the %s map is not in the eBPF module", tcpFailuresMap)
+			for _, probe := range tcpFailureProbes {
+				delete(enabledProbes, probe)
+			}
+		}
+	}
+
 	prefix, err := getSyscallPrefix()
 	if err != nil {
 		return nil, fmt.Errorf("cannot get syscall prefix: %v", err)
@@ -190,6 +209,7 @@ func NewTracer(config *Config) (*Tracer, error) {
 			config.CollectDNSStats,
 			config.CollectLocalDNS,
 			config.DNSTimeout,
+			config.MaxDNSDomainsTracked,
 		); err == nil {
 			reverseDNS = snooper
 		} else {
@@ -197,6 +217,18 @@ func NewTracer(config *Config) (*Tracer, error) {
 		}
 	}
 
+	// the HTTP monitoring is optional, the tracer keeps tracing the connections without it
+	var httpMonitor network.HTTPMonitor = network.NewNullHTTPMonitor()
+	if enableHTTPFilter {
+		if filter := m.SocketFilter("socket/http_filter"); filter == nil {
+			log.Warnf("HTTP traffic monitoring is disabled: the http socket filter is not in the eBPF module")
+		} else if monitor, err := network.NewSocketFilterHTTPMonitor(config.ProcRoot, filter, config.MaxHTTPStatsBuffered); err != nil {
+			log.Warnf("HTTP traffic monitoring is disabled: %s", err)
+		} else {
+			httpMonitor = monitor
+		}
+	}
+
 	portMapping := network.NewPortMapping(config.ProcRoot, config.CollectTCPConns, config.CollectIPv6Conns)
 	udpPortMapping := network.NewPortMapping(config.ProcRoot, config.CollectTCPConns, config.CollectIPv6Conns)
 	if err := portMapping.ReadInitialState(); err != nil {
@@ -221,6 +253,7 @@ func NewTracer(config *Config) (*Tracer, error) {
 		config.MaxClosedConnectionsBuffered,
 		config.MaxConnectionsStateBuffered,
 		config.MaxDNSStatsBufferred,
+		config.MaxHTTPStatsBuffered,
 	)
 
 	tr := &Tracer{
@@ -230,6 +263,8 @@ func NewTracer(config *Config) (*Tracer, error) {
 		portMapping:    portMapping,
 		udpPortMapping: udpPortMapping,
 		reverseDNS:     reverseDNS,
+		httpMonitor:    httpMonitor,
+		tcpFailures:    tcpFailuresMp,
 		buffer:         make([]network.ConnectionStats, 0, 512),
 		buf:            &bytes.Buffer{},
 		conntracker:    conntracker,
@@ -237,6 +272,10 @@ func NewTracer(config *Config) (*Tracer, error) {
 		destExcludes:   network.ParseConnectionFilters(config.ExcludedDestinationConnections),
 	}
 
+	if config.AggregateConnections {
+		tr.aggregator = network.NewConnectionAggregator(config.ClientStateExpiry)
+	}
+
 	tcpCloseMap, _ := tr.getMap(tcpCloseBatchMap)
 	batchManager, err := NewPerfBatchManager(m, tcpCloseMap, config.TCPClosedTimeout)
 	if err != nil {
@@ -345,6 +384,10 @@ func (t *Tracer) shouldSkipConnection(conn *network.ConnectionStats) bool {
 }
 
 func (t *Tracer) storeClosedConn(cs network.ConnectionStats) {
+	if t.tcpFailures != nil && cs.Type == network.TCP {
+		t.popTCPFailures(&cs)
+	}
+
 	cs.Direction = t.determineConnectionDirection(&cs)
 	if t.shouldSkipConnection(&cs) {
 		atomic.AddInt64(&t.skippedConns, 1)
@@ -361,6 +404,7 @@ func (t *Tracer) storeClosedConn(cs network.ConnectionStats) {
 
 func (t *Tracer) Stop() {
 	t.reverseDNS.Close()
+	t.httpMonitor.Close()
 	_ = t.m.Close()
 	t.perfMap.PollStop()
 	t.conntracker.Close()
@@ -382,7 +426,11 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		t.buffer = make([]network.ConnectionStats, 0, cap(t.buffer)/2)
 	}
 
+	t.state.StoreHTTPStats(t.httpMonitor.GetHTTPStats())
 	conns := t.state.Connections(clientID, latestTime, latestConns, t.reverseDNS.GetDNSStats())
+	if t.aggregator != nil {
+		conns = t.aggregator.Aggregate(clientID, latestConns, conns)
+	}
 	names := t.reverseDNS.Resolve(conns)
 	tm := t.getConnTelemetry(len(latestConns))
 
@@ -458,6 +506,7 @@ func (t *Tracer) getConnections(active []network.ConnectionStats) ([]network.Con
 	// Iterate through all key-value pairs in map
 	key, nextKey, stats := &ConnTuple{}, &ConnTuple{}, &ConnStatsWithTimestamp{}
 	seen := make(map[ConnTuple]struct{})
+	seenFailures := make(map[ConnTuple]struct{})
 	var expired []*ConnTuple
 	for {
 		hasNext, _ := t.m.LookupNextElement(mp, unsafe.Pointer(key), unsafe.Pointer(nextKey), unsafe.Pointer(stats))
@@ -471,6 +520,9 @@ func (t *Tracer) getConnections(active []network.ConnectionStats) ([]network.Con
 			atomic.AddInt64(&t.closedConns, 1)
 		} else {
 			conn := connStats(nextKey, stats, t.getTCPStats(tcpMp, nextKey, seen))
+			if t.tcpFailures != nil && nextKey.isTCP() {
+				t.getTCPFailures(&conn, nextKey, seenFailures)
+			}
 			conn.Direction = t.determineConnectionDirection(&conn)
 
 			if t.shouldSkipConnection(&conn) {
@@ -515,6 +567,8 @@ func (t *Tracer) removeEntries(mp, tcpMp *bpflib.Map, entries []*ConnTuple) {
 	keys := make([]string, 0, len(entries))
 	// Used to create the keys
 	statsWithTs, tcpStats := &ConnStatsWithTimestamp{}, &TCPStats{}
+	// sts - the zero window probes and the TCP failures are tracked like the TCP stats, without the pid
+	zeroWindowMp, _ := t.getMap(tcpZeroWindowMap)
 	// Remove the entries from the eBPF Map
 	for i := range entries {
 		err := t.m.DeleteElement(mp, unsafe.Pointer(entries[i]))
@@ -544,6 +598,12 @@ func (t *Tracer) removeEntries(mp, tcpMp *bpflib.Map, entries []*ConnTuple) {
 		entries[i].pid = 0
 		// We can ignore the error for this map since it will not always contain the entry
 		_ = t.m.DeleteElement(tcpMp, unsafe.Pointer(entries[i]))
+		if zeroWindowMp != nil {
+			_ = t.m.DeleteElement(zeroWindowMp, unsafe.Pointer(entries[i]))
+		}
+		if t.tcpFailures != nil {
+			_ = t.m.DeleteElement(t.tcpFailures, unsafe.Pointer(entries[i]))
+		}
 	}
 
 	t.state.RemoveConnections(keys)
@@ -577,6 +637,36 @@ func (t *Tracer) getTCPStats(mp *bpflib.Map, tuple *ConnTuple, seen map[ConnTupl
 	return stats
 }
 
+// getTCPFailures reads the TCP failures of an active connection
+func (t *Tracer) getTCPFailures(conn *network.ConnectionStats, tuple *ConnTuple, seen map[ConnTuple]struct{}) {
+	// The PID isn't used as a key in the failures map, we will temporarily set it to 0 here and reset it when we're done
+	pid := tuple.pid
+	tuple.pid = 0
+	defer func() { tuple.pid = pid }()
+
+	// Like the retransmits, the failures are only reported once for the connections sharing the same socket
+	if _, reported := seen[*tuple]; reported {
+		return
+	}
+	seen[*tuple] = struct{}{}
+
+	failures := new(TCPFailures)
+	if err := t.m.LookupElement(t.tcpFailures, unsafe.Pointer(tuple), unsafe.Pointer(failures)); err == nil {
+		addTCPFailures(conn, failures)
+	}
+}
+
+// popTCPFailures reads the TCP failures of a closed connection and removes them from the eBPF map
+func (t *Tracer) popTCPFailures(conn *network.ConnectionStats) {
+	tuple := connTuple(conn)
+	failures := new(TCPFailures)
+	if err := t.m.LookupElement(t.tcpFailures, unsafe.Pointer(tuple), unsafe.Pointer(failures)); err != nil {
+		return
+	}
+	addTCPFailures(conn, failures)
+	_ = t.m.DeleteElement(t.tcpFailures, unsafe.Pointer(tuple))
+}
+
 // getLatestTimestamp reads the most recent timestamp captured by the eBPF
 // module.  if the eBFP module has not yet captured a timestamp (as will be the
 // case if the eBPF module has just started), the second return value will be
@@ -675,6 +765,7 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 		"ebpf":    t.getEbpfTelemetry(),
 		"kprobes": GetProbeStats(),
 		"dns":     t.reverseDNS.GetStats(),
+		"http":    t.httpMonitor.GetStats(),
 	}, nil
 }
 
@@ -741,7 +832,7 @@ func (t *Tracer) determineConnectionDirection(conn *network.ConnectionStats) net
 }
 
 // SectionsFromConfig returns a map of string -> gobpf.SectionParams used to configure the way we load the BPF program (bpf map sizes)
-func SectionsFromConfig(c *Config, enableSocketFilter bool) map[string]bpflib.SectionParams {
+func SectionsFromConfig(c *Config, enableSocketFilter bool, enableHTTPFilter bool) map[string]bpflib.SectionParams {
 	return map[string]bpflib.SectionParams{
 		connMap.sectionName(): {
 			MapMaxEntries: int(c.MaxTrackedConnections),
@@ -758,9 +849,21 @@ func SectionsFromConfig(c *Config, enableSocketFilter bool) map[string]bpflib.Se
 		tcpCloseEventMap.sectionName(): {
 			MapMaxEntries: 1024,
 		},
+		tcpOngoingConnectMap.sectionName(): {
+			MapMaxEntries: int(c.MaxTrackedConnections),
+		},
+		tcpZeroWindowMap.sectionName(): {
+			MapMaxEntries: int(c.MaxTrackedConnections),
+		},
+		tcpFailuresMap.sectionName(): {
+			MapMaxEntries: int(c.MaxTrackedConnections),
+		},
 		"socket/dns_filter": {
 			Disabled: !enableSocketFilter,
 		},
+		"socket/http_filter": {
+			Disabled: !enableHTTPFilter,
+		},
 	}
 }
 
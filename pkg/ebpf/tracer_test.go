@@ -1175,6 +1175,33 @@ func TestIsSyscall(t *testing.T) {
 	assert.False(t, isSysCall("kprobe/tcp_send"))
 }
 
+func TestConnTupleOfConnection(t *testing.T) {
+	for _, tuple := range []ConnTuple{
+		{
+			saddr_l:  _Ctype_ulonglong(binary.LittleEndian.Uint32(net.ParseIP("10.0.0.1").To4())),
+			daddr_l:  _Ctype_ulonglong(binary.LittleEndian.Uint32(net.ParseIP("10.0.0.2").To4())),
+			sport:    50000,
+			dport:    443,
+			netns:    4026531992,
+			metadata: 1, // TCP/IPv4
+		},
+		{
+			saddr_h:  0x0000000000000000,
+			saddr_l:  0x0100000000000000,
+			daddr_h:  0x00000000000080fe,
+			daddr_l:  0x0200000000000000,
+			sport:    50000,
+			dport:    443,
+			netns:    4026531992,
+			metadata: 3, // TCP/IPv6
+		},
+	} {
+		conn := connStats(&tuple, &ConnStatsWithTimestamp{}, &TCPStats{})
+		conn.Pid = 123
+		assert.Equal(t, tuple, *connTuple(&conn))
+	}
+}
+
 func removeConnection(t *testing.T, tr *Tracer, c *network.ConnectionStats) {
 	mp, err := tr.getMap(connMap)
 	require.NoError(t, err)
@@ -38,6 +38,8 @@ type Tracer struct {
 	stopChan        chan struct{}
 	state           network.State
 	reverseDNS      network.ReverseDNS
+	// sts - nil unless connections are aggregated
+	aggregator *network.ConnectionAggregator
 
 	timerInterval int
 
@@ -58,6 +60,7 @@ func NewTracer(config *Config) (*Tracer, error) {
 		config.MaxClosedConnectionsBuffered,
 		config.MaxConnectionsStateBuffered,
 		config.MaxDNSStatsBufferred,
+		config.MaxHTTPStatsBuffered,
 	)
 
 	tr := &Tracer{
@@ -67,6 +70,9 @@ func NewTracer(config *Config) (*Tracer, error) {
 		state:           state,
 		reverseDNS:      network.NewNullReverseDNS(),
 	}
+	if config.AggregateConnections {
+		tr.aggregator = network.NewConnectionAggregator(config.ClientStateExpiry)
+	}
 
 	go tr.expvarStats(tr.stopChan)
 	return tr, nil
@@ -125,6 +131,9 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	// check for expired clients in the state
 	t.state.RemoveExpiredClients(time.Now())
 	conns := t.state.Connections(clientID, uint64(time.Now().Nanosecond()), connStatsActive, t.reverseDNS.GetDNSStats())
+	if t.aggregator != nil {
+		conns = t.aggregator.Aggregate(clientID, connStatsActive, conns)
+	}
 	return &network.Connections{Conns: conns}, nil
 }
 
@@ -56,6 +56,19 @@ const (
 	// TCPRetransmit traces the return value for the tcp_retransmit_skb() system call
 	TCPRetransmit KProbeName = "kprobe/tcp_retransmit_skb"
 
+	// TCPConnect traces the tcp_connect() function, which sends the SYN of an outbound connection
+	TCPConnect KProbeName = "kprobe/tcp_connect"
+	// TCPFinishConnect traces the tcp_finish_connect() function, which completes the handshake of an outbound connection
+	TCPFinishConnect KProbeName = "kprobe/tcp_finish_connect"
+	// TCPDone traces the tcp_done() function, which reports the outbound connections that failed to connect
+	TCPDone KProbeName = "kprobe/tcp_done"
+	// TCPReset traces the tcp_reset() function, called when a reset is received
+	TCPReset KProbeName = "kprobe/tcp_reset"
+	// TCPSendActiveReset traces the tcp_send_active_reset() function, called when a reset is sent
+	TCPSendActiveReset KProbeName = "kprobe/tcp_send_active_reset"
+	// TCPSendProbe0 traces the tcp_send_probe0() function, called when a zero window probe is sent
+	TCPSendProbe0 KProbeName = "kprobe/tcp_send_probe0"
+
 	// InetCskAcceptReturn traces the return value for the inet_csk_accept syscall
 	InetCskAcceptReturn KProbeName = "kretprobe/inet_csk_accept"
 
@@ -86,6 +99,10 @@ const (
 	telemetryMap       bpfMapName = "telemetry"
 	configMap          bpfMapName = "config"
 	tcpCloseBatchMap   bpfMapName = "tcp_close_batch"
+	// sts
+	tcpOngoingConnectMap bpfMapName = "tcp_ongoing_connect"
+	tcpZeroWindowMap     bpfMapName = "tcp_zero_window"
+	tcpFailuresMap       bpfMapName = "tcp_failures"
 )
 
 // sectionName returns the sectionName for the given BPF map
@@ -6,6 +6,7 @@
 package forwarder
 
 import (
+	"errors"
 	"expvar"
 	"fmt"
 	"net/http"
@@ -104,6 +105,10 @@ const (
 	useragentHTTPHeaderKey = "User-Agent"
 )
 
+// ErrTransactionRejected is returned by SubmitV1IntakeAndWait when the intake rejected a payload, sending the payload
+// again does not help
+var ErrTransactionRejected = errors.New("the payload was rejected by the intake")
+
 // The amount of time the forwarder will wait to receive process-like response payloads before giving up
 // This is a var so that it can be changed for testing
 var defaultResponseTimeout = 30 * time.Second
@@ -137,6 +142,7 @@ type Forwarder interface {
 	Stop()
 	SubmitV1Series(payload Payloads, extra http.Header) error
 	SubmitV1Intake(payload Payloads, extra http.Header) error
+	SubmitV1IntakeAndWait(payload Payloads, extra http.Header) error
 	SubmitV1CheckRuns(payload Payloads, extra http.Header) error
 	SubmitSeries(payload Payloads, extra http.Header) error
 	SubmitEvents(payload Payloads, extra http.Header) error
@@ -415,15 +421,61 @@ func (f *DefaultForwarder) SubmitV1CheckRuns(payload Payloads, extra http.Header
 
 // SubmitV1Intake will send payloads to the universal `/intake/` endpoint used by Agent v.5
 func (f *DefaultForwarder) SubmitV1Intake(payload Payloads, extra http.Header) error {
+	transactions := f.createV1IntakeTransactions(payload, extra)
+
+	transactionsIntakeV1.Add(1)
+	return f.sendHTTPTransactions(transactions)
+}
+
+// SubmitV1IntakeAndWait sends payloads to the universal `/intake/` endpoint like SubmitV1Intake, but waits until the
+// transactions completed. The transactions are not retried, an error is returned when any of them failed, was
+// rejected by the intake or did not complete in time, so the caller can keep the payloads itself.
+func (f *DefaultForwarder) SubmitV1IntakeAndWait(payload Payloads, extra http.Header) error {
+	transactions := f.createV1IntakeTransactions(payload, extra)
+
+	responses := make(chan Response, len(transactions))
+	for _, txn := range transactions {
+		txn.retryable = false
+		txn.completionHandler = func(transaction *HTTPTransaction, statusCode int, body []byte, err error) {
+			responses <- Response{
+				Domain:     transaction.Domain,
+				Body:       body,
+				StatusCode: statusCode,
+				Err:        err,
+			}
+		}
+	}
+
+	transactionsIntakeV1.Add(1)
+	if err := f.sendHTTPTransactions(transactions); err != nil {
+		return err
+	}
+
+	timeout := time.After(defaultResponseTimeout)
+	for received := 0; received < len(transactions); received++ {
+		select {
+		case response := <-responses:
+			if response.Err != nil {
+				return fmt.Errorf("error sending the payload to %s: %s", response.Domain, response.Err)
+			}
+			if response.StatusCode >= 400 {
+				return fmt.Errorf("%w: %s responded with status code %d", ErrTransactionRejected, response.Domain, response.StatusCode)
+			}
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for the responses, received %d/%d", received, len(transactions))
+		}
+	}
+	return nil
+}
+
+func (f *DefaultForwarder) createV1IntakeTransactions(payload Payloads, extra http.Header) []*HTTPTransaction {
 	transactions := f.createHTTPTransactions(v1IntakeEndpoint, payload, true, extra)
 
 	// the intake endpoint requires the Content-Type header to be set
 	for _, t := range transactions {
 		t.Headers.Set("Content-Type", "application/json")
 	}
-
-	transactionsIntakeV1.Add(1)
-	return f.sendHTTPTransactions(transactions)
+	return transactions
 }
 
 // SubmitProcessChecks sends process checks
@@ -6,6 +6,7 @@
 package forwarder
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -280,6 +281,45 @@ func TestTransactionEventHandlers(t *testing.T) {
 	assert.Equal(t, int64(1), atomic.LoadInt64(&attempts))
 }
 
+func TestSubmitV1IntakeAndWait(t *testing.T) {
+	status := int64(http.StatusOK)
+	requests := int64(0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.WriteHeader(int(atomic.LoadInt64(&status)))
+	}))
+	defer ts.Close()
+	mockConfig := config.Mock()
+	ddURL := mockConfig.Get("dd_url")
+	mockConfig.Set("dd_url", ts.URL)
+	defer mockConfig.Set("dd_url", ddURL)
+
+	f := NewDefaultForwarder(NewOptions(map[string][]string{
+		ts.URL: {"api_key1"},
+	}))
+	f.healthChecker.disableAPIKeyChecking = true
+
+	_ = f.Start()
+	defer f.Stop()
+
+	data := []byte("data payload")
+	payload := Payloads{&data}
+
+	assert.NoError(t, f.SubmitV1IntakeAndWait(payload, http.Header{}))
+
+	// a failed transaction is not retried, the error is returned instead
+	atomic.StoreInt64(&status, http.StatusServiceUnavailable)
+	atomic.StoreInt64(&requests, 0)
+	err := f.SubmitV1IntakeAndWait(payload, http.Header{})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrTransactionRejected))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+
+	atomic.StoreInt64(&status, http.StatusBadRequest)
+	err = f.SubmitV1IntakeAndWait(payload, http.Header{})
+	assert.True(t, errors.Is(err, ErrTransactionRejected))
+}
+
 func TestTransactionEventHandlersOnRetry(t *testing.T) {
 	requests := int64(0)
 
@@ -78,6 +78,11 @@ func (tf *MockedForwarder) SubmitV1Intake(payload Payloads, extra http.Header) e
 	return tf.Called(payload, extra).Error(0)
 }
 
+// SubmitV1IntakeAndWait updates the internal mock struct
+func (tf *MockedForwarder) SubmitV1IntakeAndWait(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
 // SubmitV1CheckRuns updates the internal mock struct
 func (tf *MockedForwarder) SubmitV1CheckRuns(payload Payloads, extra http.Header) error {
 	return tf.Called(payload, extra).Error(0)
@@ -0,0 +1,95 @@
+package health
+
+import "strings"
+
+// State is the health state of a check state, as understood by StackState
+type State string
+
+const (
+	// Clear means the element is healthy
+	Clear State = "CLEAR"
+	// Deviating means the element is not fully healthy
+	Deviating State = "DEVIATING"
+	// Critical means the element is unhealthy
+	Critical State = "CRITICAL"
+	// Unknown is used when the health state is absent or not recognized
+	Unknown State = "UNKNOWN"
+)
+
+// The standard fields of health check data
+const (
+	CheckStateIDField              = "checkStateId"
+	NameField                      = "name"
+	HealthField                    = "health"
+	TopologyElementIdentifierField = "topologyElementIdentifier"
+	MessageField                   = "message"
+)
+
+// severity orders the health states, unknown is the least severe as it carries no information
+func (s State) severity() int {
+	switch s {
+	case Clear:
+		return 1
+	case Deviating:
+		return 2
+	case Critical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Worst returns the most severe of two health states
+func Worst(a, b State) State {
+	if b.severity() > a.severity() {
+		return b
+	}
+	return a
+}
+
+// CheckState is the standard content of health check data
+type CheckState struct {
+	CheckStateID              string `json:"checkStateId"`
+	Name                      string `json:"name"`
+	Health                    State  `json:"health"`
+	TopologyElementIdentifier string `json:"topologyElementIdentifier"`
+	Message                   string `json:"message,omitempty"`
+}
+
+// CheckData converts the CheckState to the CheckData submitted to the batcher
+func (s CheckState) CheckData() CheckData {
+	data := CheckData{
+		CheckStateIDField:              s.CheckStateID,
+		NameField:                      s.Name,
+		HealthField:                    string(s.Health),
+		TopologyElementIdentifierField: s.TopologyElementIdentifier,
+	}
+	if s.Message != "" {
+		data[MessageField] = s.Message
+	}
+	return data
+}
+
+// CheckState reads the standard fields of the check data, fields that are absent or not a string are left empty
+func (c CheckData) CheckState() CheckState {
+	health := Unknown
+	switch h := State(strings.ToUpper(c.stringField(HealthField))); h {
+	case Clear, Deviating, Critical:
+		health = h
+	}
+
+	return CheckState{
+		CheckStateID:              c.stringField(CheckStateIDField),
+		Name:                      c.stringField(NameField),
+		Health:                    health,
+		TopologyElementIdentifier: c.stringField(TopologyElementIdentifierField),
+		Message:                   c.stringField(MessageField),
+	}
+}
+
+func (c CheckData) stringField(field string) string {
+	if value, ok := c[field].(string); ok {
+		return value
+	}
+	return ""
+}
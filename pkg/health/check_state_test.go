@@ -0,0 +1,40 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStateRoundTrip(t *testing.T) {
+	state := CheckState{
+		CheckStateID:              "id",
+		Name:                      "name",
+		Health:                    Critical,
+		TopologyElementIdentifier: "urn:component",
+		Message:                   "msg",
+	}
+
+	data := state.CheckData()
+	assert.Equal(t, CheckData{
+		"checkStateId":              "id",
+		"name":                      "name",
+		"health":                    "CRITICAL",
+		"topologyElementIdentifier": "urn:component",
+		"message":                   "msg",
+	}, data)
+	assert.Equal(t, state, data.CheckState())
+}
+
+func TestCheckStateFromLooseData(t *testing.T) {
+	assert.Equal(t, CheckState{CheckStateID: "id", Health: Deviating},
+		CheckData{"checkStateId": "id", "health": "deviating", "name": 42}.CheckState())
+	assert.Equal(t, CheckState{Health: Unknown}, CheckData{"health": "BROKEN"}.CheckState())
+}
+
+func TestWorst(t *testing.T) {
+	assert.Equal(t, Clear, Worst(Unknown, Clear))
+	assert.Equal(t, Deviating, Worst(Deviating, Clear))
+	assert.Equal(t, Critical, Worst(Deviating, Critical))
+	assert.Equal(t, Critical, Worst(Critical, Unknown))
+}
@@ -0,0 +1,248 @@
+package network
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/quantile"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+)
+
+// rttConfig is the configuration of the sketches of the RTTs of aggregated connections
+var rttConfig = quantile.Default()
+
+// aggregationKey identifies the service of a connection, the connections of a process to or from the same service
+// only differ in the ephemeral port of the client side
+type aggregationKey struct {
+	pid       uint32
+	netNS     uint32
+	connType  ConnectionType
+	family    ConnectionFamily
+	direction ConnectionDirection
+	source    util.Address
+	dest      util.Address
+	sport     uint16
+	dport     uint16
+
+	// the NAT'd address of the connection, if any
+	replSrcIP   util.Address
+	replDstIP   util.Address
+	replSrcPort uint16
+	replDstPort uint16
+}
+
+// aggregationClient holds the keys of the active connections of the previous fetch of a client
+type aggregationClient struct {
+	lastFetch time.Time
+	active    map[string]struct{}
+}
+
+// ConnectionAggregator collapses the connections fetched by a client into a connection per process and service. It
+// keeps the active connections of every client to count the connections that were opened since the previous fetch.
+type ConnectionAggregator struct {
+	mux          sync.Mutex
+	clients      map[string]*aggregationClient
+	clientExpiry time.Duration
+	buf          *bytes.Buffer
+}
+
+// NewConnectionAggregator creates a new ConnectionAggregator, clients that did not fetch connections for clientExpiry
+// are forgotten
+func NewConnectionAggregator(clientExpiry time.Duration) *ConnectionAggregator {
+	return &ConnectionAggregator{
+		clients:      make(map[string]*aggregationClient),
+		clientExpiry: clientExpiry,
+		buf:          &bytes.Buffer{},
+	}
+}
+
+// Aggregate collapses conns, the connections that State.Connections returned for the client, by process, direction,
+// local and remote address and the port of the server side. The port of the client side of the aggregated connection
+// is 0. active are the connections that are still open, the connections in conns that are not active were closed
+// since the previous fetch. Opened connections are not counted on the first fetch of a client, like the last stats
+// that State.Connections resets on the first fetch.
+func (a *ConnectionAggregator) Aggregate(clientID string, active []ConnectionStats, conns []ConnectionStats) []ConnectionStats {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	now := time.Now()
+	a.removeExpiredClients(now)
+
+	activeKeys := make(map[string]struct{}, len(active))
+	for _, c := range active {
+		key, err := c.ByteKey(a.buf)
+		if err != nil {
+			log.Warnf("failed to create byte key: %s", err)
+			continue
+		}
+		activeKeys[string(key)] = struct{}{}
+	}
+
+	client, known := a.clients[clientID]
+	if !known {
+		client = &aggregationClient{}
+		a.clients[clientID] = client
+	}
+
+	aggregated := make([]ConnectionStats, 0, len(conns))
+	indexes := make(map[aggregationKey]int, len(conns))
+	for _, c := range conns {
+		if c.Direction != INCOMING && c.Direction != OUTGOING {
+			aggregated = append(aggregated, c)
+			continue
+		}
+
+		key, err := c.ByteKey(a.buf)
+		if err != nil {
+			log.Warnf("failed to create byte key: %s", err)
+			continue
+		}
+		_, isActive := activeKeys[string(key)]
+		_, wasActive := client.active[string(key)]
+
+		aggKey := makeAggregationKey(c)
+		i, ok := indexes[aggKey]
+		if !ok {
+			i = len(aggregated)
+			indexes[aggKey] = i
+			aggregated = append(aggregated, newAggregatedConnection(aggKey, c))
+		}
+
+		agg := &aggregated[i]
+		agg.add(c)
+		if known && !wasActive {
+			agg.ConnectionsOpened++
+		}
+		if !isActive {
+			agg.ConnectionsClosed++
+		}
+	}
+
+	client.lastFetch = now
+	client.active = activeKeys
+	return aggregated
+}
+
+// RemoveClient stops tracking the active connections of a given client
+func (a *ConnectionAggregator) RemoveClient(clientID string) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	delete(a.clients, clientID)
+}
+
+func (a *ConnectionAggregator) removeExpiredClients(now time.Time) {
+	for id, c := range a.clients {
+		if c.lastFetch.Add(a.clientExpiry).Before(now) {
+			log.Debugf("expiring aggregation client: %s", id)
+			delete(a.clients, id)
+		}
+	}
+}
+
+func makeAggregationKey(c ConnectionStats) aggregationKey {
+	key := aggregationKey{
+		pid:       c.Pid,
+		netNS:     c.NetNS,
+		connType:  c.Type,
+		family:    c.Family,
+		direction: c.Direction,
+		source:    c.Source,
+		dest:      c.Dest,
+		sport:     c.SPort,
+		dport:     c.DPort,
+	}
+	if c.IPTranslation != nil {
+		key.replSrcIP = c.IPTranslation.ReplSrcIP
+		key.replDstIP = c.IPTranslation.ReplDstIP
+		key.replSrcPort = c.IPTranslation.ReplSrcPort
+		key.replDstPort = c.IPTranslation.ReplDstPort
+	}
+
+	// the reply of a NAT'd connection is sent from the remote side to the local side
+	if c.Direction == OUTGOING {
+		key.sport = 0
+		key.replDstPort = 0
+	} else {
+		key.dport = 0
+		key.replSrcPort = 0
+	}
+	return key
+}
+
+// newAggregatedConnection returns the empty aggregated connection for the service of c
+func newAggregatedConnection(key aggregationKey, c ConnectionStats) ConnectionStats {
+	agg := ConnectionStats{
+		Source:    key.source,
+		Dest:      key.dest,
+		Pid:       key.pid,
+		NetNS:     key.netNS,
+		SPort:     key.sport,
+		DPort:     key.dport,
+		Type:      key.connType,
+		Family:    key.family,
+		Direction: key.direction,
+	}
+	if c.IPTranslation != nil {
+		agg.IPTranslation = &IPTranslation{
+			ReplSrcIP:   key.replSrcIP,
+			ReplDstIP:   key.replDstIP,
+			ReplSrcPort: key.replSrcPort,
+			ReplDstPort: key.replDstPort,
+		}
+	}
+	return agg
+}
+
+// add adds the stats of other to the aggregated connection c, RTT and RTTVar are the means of the aggregated TCP
+// connections
+func (c *ConnectionStats) add(other ConnectionStats) {
+	c.AggregatedConnections++
+	c.MonotonicSentBytes += other.MonotonicSentBytes
+	c.LastSentBytes += other.LastSentBytes
+	c.MonotonicRecvBytes += other.MonotonicRecvBytes
+	c.LastRecvBytes += other.LastRecvBytes
+	c.MonotonicRetransmits += other.MonotonicRetransmits
+	c.LastRetransmits += other.LastRetransmits
+	if other.LastUpdateEpoch > c.LastUpdateEpoch {
+		c.LastUpdateEpoch = other.LastUpdateEpoch
+	}
+	c.IntraHost = c.IntraHost || other.IntraHost
+	c.addTCPFailures(other)
+
+	if other.Type == TCP && other.RTT > 0 {
+		if c.RTTs == nil {
+			c.RTTs = &quantile.Sketch{}
+		}
+		n := uint64(c.RTTs.Basic.Cnt)
+		c.RTT = uint32((uint64(c.RTT)*n + uint64(other.RTT)) / (n + 1))
+		c.RTTVar = uint32((uint64(c.RTTVar)*n + uint64(other.RTTVar)) / (n + 1))
+		c.RTTs.Insert(rttConfig, float64(other.RTT))
+	}
+
+	c.DNSSuccessfulResponses += other.DNSSuccessfulResponses
+	c.DNSFailedResponses += other.DNSFailedResponses
+	c.DNSTimeouts += other.DNSTimeouts
+	c.DNSSuccessLatencySum += other.DNSSuccessLatencySum
+	c.DNSFailureLatencySum += other.DNSFailureLatencySum
+	c.DNSStatsByDomain = mergeDomainStats(c.DNSStatsByDomain, other.DNSStatsByDomain)
+	c.HTTPStatsByPath = mergeHTTPStats(c.HTTPStatsByPath, other.HTTPStatsByPath)
+}
+
+// addTCPFailures adds the TCP failures of other to c
+func (c *ConnectionStats) addTCPFailures(other ConnectionStats) {
+	c.FailedConnects += other.FailedConnects
+	c.RefusedConnects += other.RefusedConnects
+	c.ResetsSent += other.ResetsSent
+	c.ResetsReceived += other.ResetsReceived
+	c.ZeroWindowTime += other.ZeroWindowTime
+}
+
+// RTTPercentile returns the RTT in µs of the aggregated TCP connections at the percentile p in [0, 1]
+func (c ConnectionStats) RTTPercentile(p float64) float64 {
+	if c.RTTs == nil {
+		return 0
+	}
+	return c.RTTs.Quantile(rttConfig, p)
+}
@@ -0,0 +1,184 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeServiceConn(pid uint32, direction ConnectionDirection, sport, dport uint16, sent uint64, rtt uint32) ConnectionStats {
+	return ConnectionStats{
+		Pid:                pid,
+		Source:             util.AddressFromString("10.0.0.1"),
+		Dest:               util.AddressFromString("10.0.0.2"),
+		SPort:              sport,
+		DPort:              dport,
+		Type:               TCP,
+		Family:             AFINET,
+		Direction:          direction,
+		MonotonicSentBytes: sent,
+		LastSentBytes:      sent,
+		RTT:                rtt,
+		RTTVar:             rtt / 10,
+	}
+}
+
+func findAggregate(conns []ConnectionStats, pid uint32, sport, dport uint16) *ConnectionStats {
+	for i := range conns {
+		if conns[i].Pid == pid && conns[i].SPort == sport && conns[i].DPort == dport {
+			return &conns[i]
+		}
+	}
+	return nil
+}
+
+func TestAggregateByService(t *testing.T) {
+	conns := []ConnectionStats{
+		// three connections of the client to the database
+		makeServiceConn(1, OUTGOING, 50000, 5432, 100, 100),
+		makeServiceConn(1, OUTGOING, 50001, 5432, 200, 200),
+		makeServiceConn(1, OUTGOING, 50002, 5432, 300, 300),
+		// another process connecting to the same database
+		makeServiceConn(2, OUTGOING, 50003, 5432, 10, 0),
+		// two connections accepted by the web server
+		makeServiceConn(3, INCOMING, 8080, 40000, 1000, 0),
+		makeServiceConn(3, INCOMING, 8080, 40001, 2000, 0),
+		// connections without a direction are not aggregated
+		makeServiceConn(4, NONE, 40002, 9000, 5, 0),
+	}
+
+	aggregator := NewConnectionAggregator(time.Minute)
+	aggregated := aggregator.Aggregate("client", conns, conns)
+	require.Len(t, aggregated, 4)
+
+	db := findAggregate(aggregated, 1, 0, 5432)
+	require.NotNil(t, db)
+	assert.Equal(t, uint32(3), db.AggregatedConnections)
+	assert.Equal(t, uint64(600), db.MonotonicSentBytes)
+	assert.Equal(t, uint64(600), db.LastSentBytes)
+	assert.Equal(t, uint32(200), db.RTT)
+	assert.Equal(t, uint32(20), db.RTTVar)
+	assert.Equal(t, int64(3), db.RTTs.Basic.Cnt)
+	assert.InDelta(t, 200, db.RTTPercentile(0.5), 5)
+	// opened connections are not counted on the first fetch
+	assert.Equal(t, uint32(0), db.ConnectionsOpened)
+	assert.Equal(t, uint32(0), db.ConnectionsClosed)
+
+	other := findAggregate(aggregated, 2, 0, 5432)
+	require.NotNil(t, other)
+	assert.Equal(t, uint32(1), other.AggregatedConnections)
+	assert.Nil(t, other.RTTs)
+
+	web := findAggregate(aggregated, 3, 8080, 0)
+	require.NotNil(t, web)
+	assert.Equal(t, uint32(2), web.AggregatedConnections)
+	assert.Equal(t, uint64(3000), web.MonotonicSentBytes)
+
+	none := findAggregate(aggregated, 4, 40002, 9000)
+	require.NotNil(t, none)
+	assert.Equal(t, conns[6], *none)
+}
+
+func TestAggregateOpenedAndClosed(t *testing.T) {
+	aggregator := NewConnectionAggregator(time.Minute)
+
+	first := []ConnectionStats{
+		makeServiceConn(1, OUTGOING, 50000, 5432, 100, 0),
+		makeServiceConn(1, OUTGOING, 50001, 5432, 100, 0),
+	}
+	aggregator.Aggregate("client", first, first)
+
+	// 50000 stays open, 50001 closed, 50002 opened and 50003 opened and closed since the previous fetch
+	active := []ConnectionStats{
+		makeServiceConn(1, OUTGOING, 50000, 5432, 100, 0),
+		makeServiceConn(1, OUTGOING, 50002, 5432, 100, 0),
+	}
+	conns := append([]ConnectionStats{
+		makeServiceConn(1, OUTGOING, 50001, 5432, 100, 0),
+		makeServiceConn(1, OUTGOING, 50003, 5432, 100, 0),
+	}, active...)
+
+	aggregated := aggregator.Aggregate("client", active, conns)
+	require.Len(t, aggregated, 1)
+	assert.Equal(t, uint32(4), aggregated[0].AggregatedConnections)
+	assert.Equal(t, uint32(2), aggregated[0].ConnectionsOpened)
+	assert.Equal(t, uint32(2), aggregated[0].ConnectionsClosed)
+
+	// a new client does not count the connections as opened
+	aggregated = aggregator.Aggregate("other", active, conns)
+	require.Len(t, aggregated, 1)
+	assert.Equal(t, uint32(0), aggregated[0].ConnectionsOpened)
+	assert.Equal(t, uint32(2), aggregated[0].ConnectionsClosed)
+}
+
+func TestAggregateDNSStats(t *testing.T) {
+	conn := func(sport uint16, domain string) ConnectionStats {
+		c := makeServiceConn(1, OUTGOING, sport, 53, 10, 0)
+		c.Type = UDP
+		c.DNSSuccessfulResponses = 1
+		c.DNSSuccessLatencySum = 1000
+		c.DNSStatsByDomain = map[string]DNSDomainStats{domain: {SuccessfulResponses: 1}}
+		return c
+	}
+	conns := []ConnectionStats{conn(40000, "foo.com"), conn(40001, "foo.com"), conn(40002, "bar.com")}
+
+	aggregated := NewConnectionAggregator(time.Minute).Aggregate("client", conns, conns)
+	require.Len(t, aggregated, 1)
+	assert.Equal(t, uint32(3), aggregated[0].DNSSuccessfulResponses)
+	assert.Equal(t, uint64(3000), aggregated[0].DNSSuccessLatencySum)
+	assert.Equal(t, map[string]DNSDomainStats{
+		"foo.com": {SuccessfulResponses: 2},
+		"bar.com": {SuccessfulResponses: 1},
+	}, aggregated[0].DNSStatsByDomain)
+
+	// the stats of the aggregated connections are not modified
+	assert.Equal(t, map[string]DNSDomainStats{"foo.com": {SuccessfulResponses: 1}}, conns[0].DNSStatsByDomain)
+}
+
+func TestAggregateNATConnections(t *testing.T) {
+	conn := func(sport uint16, backend string) ConnectionStats {
+		c := makeServiceConn(1, OUTGOING, sport, 80, 10, 0)
+		c.IPTranslation = &IPTranslation{
+			ReplSrcIP:   util.AddressFromString(backend),
+			ReplDstIP:   util.AddressFromString("10.0.0.1"),
+			ReplSrcPort: 8080,
+			ReplDstPort: sport,
+		}
+		return c
+	}
+	conns := []ConnectionStats{conn(50000, "172.17.0.2"), conn(50001, "172.17.0.2"), conn(50002, "172.17.0.3")}
+
+	aggregated := NewConnectionAggregator(time.Minute).Aggregate("client", conns, conns)
+	require.Len(t, aggregated, 2)
+	for _, agg := range aggregated {
+		require.NotNil(t, agg.IPTranslation)
+		assert.Equal(t, uint16(8080), agg.IPTranslation.ReplSrcPort)
+		assert.Equal(t, uint16(0), agg.IPTranslation.ReplDstPort)
+	}
+}
+
+func TestAggregateTCPFailures(t *testing.T) {
+	refused := makeServiceConn(1, OUTGOING, 50000, 5432, 0, 0)
+	refused.FailedConnects = 1
+	refused.RefusedConnects = 1
+	refused.ResetsReceived = 1
+	timedOut := makeServiceConn(1, OUTGOING, 50001, 5432, 0, 0)
+	timedOut.FailedConnects = 1
+	established := makeServiceConn(1, OUTGOING, 50002, 5432, 100, 100)
+	established.ResetsSent = 1
+	established.ZeroWindowTime = 300
+	conns := []ConnectionStats{refused, timedOut, established}
+
+	aggregated := NewConnectionAggregator(time.Minute).Aggregate("client", conns[2:], conns)
+	require.Len(t, aggregated, 1)
+	assert.Equal(t, uint32(3), aggregated[0].AggregatedConnections)
+	assert.Equal(t, uint32(2), aggregated[0].FailedConnects)
+	assert.Equal(t, uint32(1), aggregated[0].RefusedConnects)
+	assert.Equal(t, uint32(1), aggregated[0].ResetsSent)
+	assert.Equal(t, uint32(1), aggregated[0].ResetsReceived)
+	assert.Equal(t, uint32(300), aggregated[0].ZeroWindowTime)
+	assert.Equal(t, uint32(2), aggregated[0].ConnectionsClosed)
+}
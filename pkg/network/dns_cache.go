@@ -36,14 +36,7 @@ type reverseDNSCache struct {
 }
 
 func newReverseDNSCache(size int, ttl, expirationPeriod time.Duration) *reverseDNSCache {
-	cache := &reverseDNSCache{
-		data:              make(map[util.Address]*dnsCacheVal),
-		exit:              make(chan struct{}),
-		ttl:               ttl,
-		size:              size,
-		oversizedLogLimit: util.NewLogLimit(10, time.Minute*10),
-		maxDomainsPerIP:   1000,
-	}
+	cache := makeReverseDNSCache(size, ttl)
 
 	ticker := time.NewTicker(expirationPeriod)
 	go func() {
@@ -60,6 +53,19 @@ func newReverseDNSCache(size int, ttl, expirationPeriod time.Duration) *reverseD
 	return cache
 }
 
+// makeReverseDNSCache returns a reverseDNSCache that does not expire its entries by itself, the caller calls Expire
+// instead
+func makeReverseDNSCache(size int, ttl time.Duration) *reverseDNSCache {
+	return &reverseDNSCache{
+		data:              make(map[util.Address]*dnsCacheVal),
+		exit:              make(chan struct{}),
+		ttl:               ttl,
+		size:              size,
+		oversizedLogLimit: util.NewLogLimit(10, time.Minute*10),
+		maxDomainsPerIP:   1000,
+	}
+}
+
 func (c *reverseDNSCache) Add(translation *translation, now time.Time) bool {
 	if translation == nil {
 		return false
@@ -4,6 +4,7 @@ package network
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/StackVista/stackstate-agent/pkg/process/util"
 	"github.com/google/gopacket"
@@ -80,6 +81,9 @@ func (p *dnsParser) ParseInto(data []byte, t *translation, pktInfo *dnsPacketInf
 		return nil
 	}
 
+	// parseAnswerInto only accepts payloads with a single question
+	pktInfo.question = strings.ToLower(string(p.dnsPayload.Questions[0].Name))
+
 	for _, layer := range p.layers {
 		switch layer {
 		case layers.LayerTypeIPv4:
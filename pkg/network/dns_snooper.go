@@ -53,6 +53,7 @@ func NewSocketFilterSnooper(
 	collectDNSStats bool,
 	collectLocalDNS bool,
 	dnsTimeout time.Duration,
+	maxDNSDomainsTracked int,
 ) (*SocketFilterSnooper, error) {
 
 	var (
@@ -74,7 +75,7 @@ func NewSocketFilterSnooper(
 	cache := newReverseDNSCache(dnsCacheSize, dnsCacheTTL, dnsCacheExpirationPeriod)
 	var statKeeper *dnsStatKeeper
 	if collectDNSStats {
-		statKeeper = newDNSStatkeeper(dnsTimeout)
+		statKeeper = newDNSStatkeeper(dnsTimeout, maxDNSDomainsTracked)
 	}
 	snooper := &SocketFilterSnooper{
 		source:          packetSrc,
@@ -123,6 +124,9 @@ func (s *SocketFilterSnooper) GetStats() map[string]int64 {
 	stats["packets_dropped"] = atomic.LoadInt64(&s.dropped)
 	stats["decoding_errors"] = atomic.LoadInt64(&s.decodingErrors)
 	stats["truncated_packets"] = atomic.LoadInt64(&s.truncatedPkts)
+	if s.statKeeper != nil {
+		stats["dns_domains_dropped"] = s.statKeeper.GetDomainsDropped()
+	}
 
 	return stats
 }
@@ -53,6 +53,7 @@ func getSnooper(
 		collectStats,
 		collectLocalDNS,
 		dnsTimeout,
+		DefaultMaxDNSDomainsTracked,
 	)
 	require.NoError(t, err)
 	return reverseDNS
@@ -240,6 +241,8 @@ func TestDNSOverTCPSnoopingWithFailedResponse(t *testing.T) {
 	assert.Equal(t, uint32(0), allStats[key].timeouts)
 	assert.Equal(t, uint64(0), allStats[key].successLatencySum)
 	assert.True(t, allStats[key].failureLatencySum > uint64(0))
+	require.Contains(t, allStats[key].domains, "agafsdfsdasdfsd")
+	assert.Equal(t, uint32(1), allStats[key].domains["agafsdfsdasdfsd"].FailedResponses)
 }
 
 func TestDNSOverUDPSnoopingWithTimedOutResponse(t *testing.T) {
@@ -258,6 +261,7 @@ func TestDNSOverUDPSnoopingWithTimedOutResponse(t *testing.T) {
 	assert.Equal(t, uint32(1), allStats[key].timeouts)
 	assert.Equal(t, uint64(0), allStats[key].successLatencySum)
 	assert.Equal(t, uint64(0), allStats[key].failureLatencySum)
+	assert.Equal(t, uint32(1), allStats[key].domains["agafsdfsdasdfsd"].Timeouts)
 }
 
 func TestParsingError(t *testing.T) {
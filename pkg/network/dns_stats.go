@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/hashicorp/golang-lru/simplelru"
 )
 
 type dnsStats struct {
@@ -14,6 +15,54 @@ type dnsStats struct {
 	successLatencySum   uint64 // Stored in µs
 	failureLatencySum   uint64
 	timeouts            uint32
+	// sts - the same stats broken down by the queried domain
+	domains map[string]DNSDomainStats
+}
+
+// DNSLatencyBuckets are the upper bounds in µs of the buckets of the per-domain latency histograms. Latencies above
+// the highest bound are counted in an additional last bucket.
+var DNSLatencyBuckets = [...]uint64{1000, 5000, 10000, 50000, 100000, 500000, 1000000}
+
+// DNSDomainStats contains the DNS responses and timeouts for a single queried domain
+type DNSDomainStats struct {
+	SuccessfulResponses uint32
+	FailedResponses     uint32
+	Timeouts            uint32
+	// LatencyBuckets counts the successful and failed responses per bucket of DNSLatencyBuckets
+	LatencyBuckets [len(DNSLatencyBuckets) + 1]uint32
+}
+
+func (s *DNSDomainStats) observeLatency(latency uint64) {
+	i := 0
+	for i < len(DNSLatencyBuckets) && latency > DNSLatencyBuckets[i] {
+		i++
+	}
+	s.LatencyBuckets[i]++
+}
+
+func (s *DNSDomainStats) merge(other DNSDomainStats) {
+	s.SuccessfulResponses += other.SuccessfulResponses
+	s.FailedResponses += other.FailedResponses
+	s.Timeouts += other.Timeouts
+	for i := range s.LatencyBuckets {
+		s.LatencyBuckets[i] += other.LatencyBuckets[i]
+	}
+}
+
+// mergeDomainStats adds the domain stats of src to dst, dst is allocated when needed so it is never shared with src
+func mergeDomainStats(dst, src map[string]DNSDomainStats) map[string]DNSDomainStats {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]DNSDomainStats, len(src))
+	}
+	for domain, stats := range src {
+		merged := dst[domain]
+		merged.merge(stats)
+		dst[domain] = merged
+	}
+	return dst
 }
 
 type dnsKey struct {
@@ -42,10 +91,14 @@ const (
 	MaxStateMapSize = 10000
 )
 
+// DefaultMaxDNSDomainsTracked is the default maximum number of domains dnsStatKeeper keeps per-domain stats for
+const DefaultMaxDNSDomainsTracked = 1000
+
 type dnsPacketInfo struct {
 	transactionID uint16
 	key           dnsKey
 	pktType       DNSPacketType
+	question      string // the queried domain, in lower case
 }
 
 type stateKey struct {
@@ -53,24 +106,29 @@ type stateKey struct {
 	id  uint16
 }
 
+// queryState is the in-flight query of a stateKey
+type queryState struct {
+	ts       uint64 // Stored in µs
+	question string
+}
+
 type dnsStatKeeper struct {
 	mux              sync.Mutex
 	stats            map[dnsKey]dnsStats
-	state            map[stateKey]uint64
+	state            map[stateKey]queryState
 	expirationPeriod time.Duration
 	exit             chan struct{}
 	maxSize          int // maximum size of the state map
 	deleteCount      int
+	// domains holds the domains that have per-domain stats with the keys they are recorded for. When a new domain
+	// exceeds maxDomains, the least recently seen domain is evicted and its stats are dropped.
+	domains        *simplelru.LRU
+	maxDomains     int
+	domainsDropped int64
 }
 
-func newDNSStatkeeper(timeout time.Duration) *dnsStatKeeper {
-	statsKeeper := &dnsStatKeeper{
-		stats:            make(map[dnsKey]dnsStats),
-		state:            make(map[stateKey]uint64),
-		expirationPeriod: timeout,
-		exit:             make(chan struct{}),
-		maxSize:          MaxStateMapSize,
-	}
+func newDNSStatkeeper(timeout time.Duration, maxDomains int) *dnsStatKeeper {
+	statsKeeper := makeDNSStatKeeper(timeout, maxDomains)
 
 	ticker := time.NewTicker(statsKeeper.expirationPeriod)
 	go func() {
@@ -87,6 +145,24 @@ func newDNSStatkeeper(timeout time.Duration) *dnsStatKeeper {
 	return statsKeeper
 }
 
+// makeDNSStatKeeper returns a dnsStatKeeper that does not expire the queries without response by itself, the caller
+// calls removeExpiredStates instead
+func makeDNSStatKeeper(timeout time.Duration, maxDomains int) *dnsStatKeeper {
+	if maxDomains <= 0 {
+		maxDomains = DefaultMaxDNSDomainsTracked
+	}
+	statsKeeper := &dnsStatKeeper{
+		stats:            make(map[dnsKey]dnsStats),
+		state:            make(map[stateKey]queryState),
+		expirationPeriod: timeout,
+		exit:             make(chan struct{}),
+		maxSize:          MaxStateMapSize,
+		maxDomains:       maxDomains,
+	}
+	statsKeeper.resetDomains()
+	return statsKeeper
+}
+
 func microSecs(t time.Time) uint64 {
 	return uint64(t.UnixNano() / 1000)
 }
@@ -102,13 +178,13 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 
 		if _, ok := d.state[sk]; !ok {
-			d.state[sk] = microSecs(ts)
+			d.state[sk] = queryState{ts: microSecs(ts), question: info.question}
 		}
 		return
 	}
 
 	// If a response does not have a corresponding query entry, we discard it
-	start, ok := d.state[sk]
+	query, ok := d.state[sk]
 
 	if !ok {
 		return
@@ -117,45 +193,103 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	delete(d.state, sk)
 	d.deleteCount++
 
-	latency := microSecs(ts) - start
+	latency := microSecs(ts) - query.ts
 
 	stats := d.stats[info.key]
+	domainStats := d.domainStats(&stats, info.key, query.question)
 
 	// Note: time.Duration in the agent version of go (1.12.9) does not have the Microseconds method.
 	if latency > uint64(d.expirationPeriod.Microseconds()) {
 		stats.timeouts++
+		domainStats.Timeouts++
 	} else {
 		if info.pktType == SuccessfulResponse {
 			stats.successfulResponses++
 			stats.successLatencySum += latency
+			domainStats.SuccessfulResponses++
 		} else if info.pktType == FailedResponse {
 			stats.failedResponses++
 			stats.failureLatencySum += latency
+			domainStats.FailedResponses++
 		}
+		domainStats.observeLatency(latency)
 	}
 
+	if query.question != "" {
+		stats.domains[query.question] = domainStats
+	}
 	d.stats[info.key] = stats
 }
 
+// domainStats returns the stats of the domain for the key and marks the domain as recently seen, evicting the least
+// recently seen domain when there are too many. The caller stores the returned stats in stats.domains.
+func (d *dnsStatKeeper) domainStats(stats *dnsStats, key dnsKey, question string) DNSDomainStats {
+	if question == "" {
+		return DNSDomainStats{}
+	}
+
+	var keys map[dnsKey]struct{}
+	if value, ok := d.domains.Get(question); ok {
+		keys = value.(map[dnsKey]struct{})
+	} else {
+		keys = make(map[dnsKey]struct{})
+		d.domains.Add(question, keys)
+	}
+	keys[key] = struct{}{}
+
+	if stats.domains == nil {
+		stats.domains = make(map[string]DNSDomainStats)
+	}
+	return stats.domains[question]
+}
+
+// evictDomain drops the stats of an evicted domain for all keys it was recorded for
+func (d *dnsStatKeeper) evictDomain(question interface{}, keys interface{}) {
+	for key := range keys.(map[dnsKey]struct{}) {
+		if stats, ok := d.stats[key]; ok {
+			delete(stats.domains, question.(string))
+		}
+	}
+	d.domainsDropped++
+}
+
+func (d *dnsStatKeeper) resetDomains() {
+	// NewLRU only fails on a non-positive size
+	d.domains, _ = simplelru.NewLRU(d.maxDomains, d.evictDomain)
+}
+
 func (d *dnsStatKeeper) GetAndResetAllStats() map[dnsKey]dnsStats {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	ret := d.stats
 	d.stats = make(map[dnsKey]dnsStats)
+	d.resetDomains()
 	return ret
 }
 
+// GetDomainsDropped returns the number of domains that were evicted along with their per-domain stats
+func (d *dnsStatKeeper) GetDomainsDropped() int64 {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return d.domainsDropped
+}
+
 func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 	deleteThreshold := 5000
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	threshold := microSecs(earliestTs)
 	for k, v := range d.state {
-		if v < threshold {
+		if v.ts < threshold {
 			delete(d.state, k)
 			d.deleteCount++
 			stats := d.stats[k.key]
 			stats.timeouts++
+			if v.question != "" {
+				domainStats := d.domainStats(&stats, k.key, v.question)
+				domainStats.Timeouts++
+				stats.domains[v.question] = domainStats
+			}
 			d.stats[k.key] = stats
 		}
 	}
@@ -165,7 +299,7 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 	}
 
 	// golang/go#20135 : maps do not shrink after elements removal (delete)
-	copied := make(map[stateKey]uint64, len(d.state))
+	copied := make(map[stateKey]queryState, len(d.state))
 	for k, v := range d.state {
 		copied[k] = v
 	}
@@ -24,21 +24,21 @@ func testLatency(
 	expectedFailureLatency uint64,
 	expectedTimeouts uint32,
 ) {
-	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, DefaultMaxDNSDomainsTracked)
 	key := dnsKey{
 		serverIP:   util.AddressFromString("8.8.8.8"),
 		clientIP:   util.AddressFromString("1.1.1.1"),
 		clientPort: 1000,
 		protocol:   UDP,
 	}
-	qPkt := dnsPacketInfo{transactionID: 1, pktType: Query, key: key}
+	qPkt := dnsPacketInfo{transactionID: 1, pktType: Query, key: key, question: "golang.org"}
 	then := time.Now()
 	sk.ProcessPacketInfo(qPkt, then)
 	stats := sk.GetAndResetAllStats()
 	assert.NotContains(t, stats, key)
 
 	now := then.Add(delta)
-	rPkt := dnsPacketInfo{transactionID: 1, key: key, pktType: respType}
+	rPkt := dnsPacketInfo{transactionID: 1, key: key, pktType: respType, question: "golang.org"}
 
 	sk.ProcessPacketInfo(rPkt, now)
 	stats = sk.GetAndResetAllStats()
@@ -47,6 +47,90 @@ func testLatency(
 	assert.Equal(t, expectedSuccessLatency, stats[key].successLatencySum)
 	assert.Equal(t, expectedFailureLatency, stats[key].failureLatencySum)
 	assert.Equal(t, expectedTimeouts, stats[key].timeouts)
+	require.Contains(t, stats[key].domains, "golang.org")
+	assert.Equal(t, expectedTimeouts, stats[key].domains["golang.org"].Timeouts)
+}
+
+func TestDomainStats(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, DefaultMaxDNSDomainsTracked)
+	key := dnsKey{
+		serverIP:   util.AddressFromString("8.8.8.8"),
+		clientIP:   util.AddressFromString("1.1.1.1"),
+		clientPort: 1000,
+		protocol:   UDP,
+	}
+	then := time.Now()
+	for i, tc := range []struct {
+		question string
+		respType DNSPacketType
+		latency  time.Duration
+	}{
+		{question: "golang.org", respType: SuccessfulResponse, latency: 500 * time.Microsecond},
+		{question: "golang.org", respType: SuccessfulResponse, latency: 20 * time.Millisecond},
+		{question: "golang.org", respType: FailedResponse, latency: 2 * time.Second},
+		{question: "agafsdfsdasdfsd", respType: FailedResponse, latency: 1 * time.Millisecond},
+	} {
+		id := uint16(i)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: Query, question: tc.question}, then)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: tc.respType, question: tc.question}, then.Add(tc.latency))
+	}
+
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	assert.Equal(t, uint32(2), stats[key].successfulResponses)
+	assert.Equal(t, uint32(2), stats[key].failedResponses)
+	assert.Equal(t, map[string]DNSDomainStats{
+		"golang.org": {
+			SuccessfulResponses: 2,
+			FailedResponses:     1,
+			LatencyBuckets:      [len(DNSLatencyBuckets) + 1]uint32{1, 0, 0, 1, 0, 0, 0, 1},
+		},
+		"agafsdfsdasdfsd": {
+			FailedResponses: 1,
+			LatencyBuckets:  [len(DNSLatencyBuckets) + 1]uint32{1, 0, 0, 0, 0, 0, 0, 0},
+		},
+	}, stats[key].domains)
+}
+
+func TestDomainStatsEviction(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 2)
+	key := dnsKey{
+		serverIP:   util.AddressFromString("8.8.8.8"),
+		clientIP:   util.AddressFromString("1.1.1.1"),
+		clientPort: 1000,
+		protocol:   UDP,
+	}
+	then := time.Now()
+	for i, question := range []string{"a.com", "b.com", "a.com", "c.com"} {
+		id := uint16(i)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: Query, question: question}, then)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: FailedResponse, question: question}, then)
+	}
+
+	// b.com is the least recently seen domain when c.com comes in, the totals still count all responses
+	stats := sk.GetAndResetAllStats()
+	assert.Equal(t, uint32(4), stats[key].failedResponses)
+	require.Len(t, stats[key].domains, 2)
+	assert.Equal(t, uint32(2), stats[key].domains["a.com"].FailedResponses)
+	assert.Equal(t, uint32(1), stats[key].domains["c.com"].FailedResponses)
+	assert.Equal(t, int64(1), sk.GetDomainsDropped())
+}
+
+func TestExpiredStateDomainTimeout(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, DefaultMaxDNSDomainsTracked)
+	key := dnsKey{
+		serverIP:   util.AddressFromString("8.8.8.8"),
+		clientIP:   util.AddressFromString("1.1.1.1"),
+		clientPort: 1000,
+		protocol:   UDP,
+	}
+	then := time.Now()
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, key: key, pktType: Query, question: "golang.org"}, then)
+	sk.removeExpiredStates(then.Add(time.Second))
+
+	stats := sk.GetAndResetAllStats()
+	assert.Equal(t, uint32(1), stats[key].timeouts)
+	assert.Equal(t, map[string]DNSDomainStats{"golang.org": {Timeouts: 1}}, stats[key].domains)
 }
 
 func TestSuccessLatency(t *testing.T) {
@@ -85,7 +169,7 @@ func BenchmarkStats(b *testing.B) {
 			b.ResetTimer()
 			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
-				sk := newDNSStatkeeper(1000 * time.Second)
+				sk := newDNSStatkeeper(1000*time.Second, DefaultMaxDNSDomainsTracked)
 				for j := 0; j < numPackets; j++ {
 					sk.ProcessPacketInfo(packets[j], ts)
 				}
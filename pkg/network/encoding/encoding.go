@@ -26,6 +26,8 @@ type Marshaler interface {
 // Unmarshaler is an interface implemented by all Connections deserializers
 type Unmarshaler interface {
 	Unmarshal([]byte) (*model.Connections, error)
+	// sts - UnmarshalExtension returns the ConnectionsExtension of the payload, it is empty when the payload has none
+	UnmarshalExtension([]byte) (*ConnectionsExtension, error)
 }
 
 // GetMarshaler returns the appropriate Marshaler based on the given accept header
@@ -1,12 +1,16 @@
 package encoding
 
 import (
+	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/StackVista/stackstate-agent/pkg/network"
 	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/quantile"
+	"github.com/gogo/protobuf/jsonpb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -155,3 +159,194 @@ func TestSerialization(t *testing.T) {
 		}
 	})
 }
+
+func TestSerializationExtension(t *testing.T) {
+	latencies := &quantile.Sketch{}
+	latencies.Insert(quantile.Default(), 1000, 1000)
+	rtts := &quantile.Sketch{}
+	rtts.Insert(quantile.Default(), 200, 300, 400)
+
+	in := &network.Connections{
+		Conns: []network.ConnectionStats{
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.2.2.2"),
+				SPort:  1000,
+				DPort:  53,
+				Type:   network.UDP,
+				DNSStatsByDomain: map[string]network.DNSDomainStats{
+					"golang.org":      {SuccessfulResponses: 2, LatencyBuckets: [8]uint32{1, 1}},
+					"agafsdfsdasdfsd": {FailedResponses: 1, Timeouts: 1, LatencyBuckets: [8]uint32{0, 0, 1}},
+				},
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.3.3.3"),
+				SPort:  1001,
+				DPort:  443,
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.4.4.4"),
+				SPort:  1002,
+				DPort:  80,
+				HTTPStatsByPath: map[string]network.HTTPStats{
+					"/api/users": {RequestCount: 2, StatusClassCounts: [5]uint32{0, 1, 0, 1, 0}, Latencies: latencies},
+				},
+			},
+			{
+				Source:                util.AddressFromString("10.1.1.1"),
+				Dest:                  util.AddressFromString("10.5.5.5"),
+				DPort:                 5432,
+				AggregatedConnections: 3,
+				ConnectionsOpened:     2,
+				ConnectionsClosed:     1,
+				RTTs:                  rtts,
+				ZeroWindowTime:        1500,
+			},
+			{
+				Source:          util.AddressFromString("10.1.1.1"),
+				Dest:            util.AddressFromString("10.6.6.6"),
+				SPort:           1003,
+				DPort:           8443,
+				FailedConnects:  1,
+				RefusedConnects: 1,
+				ResetsReceived:  1,
+			},
+		},
+		DNS: map[util.Address][]string{
+			util.AddressFromString("10.2.2.2"): {"dns.local"},
+		},
+	}
+
+	out := &model.Connections{
+		Conns: []*model.Connection{
+			{
+				Laddr:  &model.Addr{Ip: "10.1.1.1", Port: int32(1000)},
+				Raddr:  &model.Addr{Ip: "10.2.2.2", Port: int32(53)},
+				Type:   model.ConnectionType_udp,
+				Family: model.ConnectionFamily_v4,
+			},
+			{
+				Laddr:  &model.Addr{Ip: "10.1.1.1", Port: int32(1001)},
+				Raddr:  &model.Addr{Ip: "10.3.3.3", Port: int32(443)},
+				Family: model.ConnectionFamily_v4,
+			},
+			{
+				Laddr:  &model.Addr{Ip: "10.1.1.1", Port: int32(1002)},
+				Raddr:  &model.Addr{Ip: "10.4.4.4", Port: int32(80)},
+				Family: model.ConnectionFamily_v4,
+			},
+			{
+				Laddr:  &model.Addr{Ip: "10.1.1.1"},
+				Raddr:  &model.Addr{Ip: "10.5.5.5", Port: int32(5432)},
+				Family: model.ConnectionFamily_v4,
+			},
+			{
+				Laddr:  &model.Addr{Ip: "10.1.1.1", Port: int32(1003)},
+				Raddr:  &model.Addr{Ip: "10.6.6.6", Port: int32(8443)},
+				Family: model.ConnectionFamily_v4,
+			},
+		},
+		Dns: map[string]*model.DNSEntry{
+			"10.2.2.2": {Names: []string{"dns.local"}},
+		},
+	}
+
+	outExtension := &ConnectionsExtension{
+		Conns: []*ConnectionExtension{
+			{
+				DNSStatsByDomain: []*DNSDomainStats{
+					{Domain: "agafsdfsdasdfsd", FailedResponses: 1, Timeouts: 1, LatencyBuckets: []uint32{0, 0, 1, 0, 0, 0, 0, 0}},
+					{Domain: "golang.org", SuccessfulResponses: 2, LatencyBuckets: []uint32{1, 1, 0, 0, 0, 0, 0, 0}},
+				},
+			},
+			{},
+			{
+				HTTPStatsByPath: []*HTTPStats{
+					{
+						PathPrefix:        "/api/users",
+						RequestCount:      2,
+						StatusClassCounts: []uint32{0, 1, 0, 1, 0},
+						LatencyP50:        latencies.Quantile(quantile.Default(), 0.5),
+						LatencyP90:        latencies.Quantile(quantile.Default(), 0.9),
+						LatencyP99:        latencies.Quantile(quantile.Default(), 0.99),
+					},
+				},
+			},
+			{
+				AggregatedConnections: 3,
+				ConnectionsOpened:     2,
+				ConnectionsClosed:     1,
+				RttP50:                rtts.Quantile(quantile.Default(), 0.5),
+				RttP90:                rtts.Quantile(quantile.Default(), 0.9),
+				RttP99:                rtts.Quantile(quantile.Default(), 0.99),
+				ZeroWindowTime:        1500,
+			},
+			{
+				FailedConnects:  1,
+				RefusedConnects: 1,
+				ResetsReceived:  1,
+			},
+		},
+	}
+
+	for _, contentType := range []string{ContentTypeJSON, ContentTypeProtobuf} {
+		t.Run(contentType, func(t *testing.T) {
+			blob, err := GetMarshaler(contentType).Marshal(in)
+			require.NoError(t, err)
+
+			unmarshaler := GetUnmarshaler(contentType)
+			// the extension does not affect the decoding of the connections
+			result, err := unmarshaler.Unmarshal(blob)
+			require.NoError(t, err)
+			assert.Equal(t, out, result)
+
+			extension, err := unmarshaler.UnmarshalExtension(blob)
+			require.NoError(t, err)
+			assert.Equal(t, outExtension, extension)
+		})
+	}
+
+	t.Run("keeps the formatting of the marshaller", func(t *testing.T) {
+		indented := jsonSerializer{marshaller: jsonpb.Marshaler{EmitDefaults: true, Indent: "  "}}
+		blob, err := indented.Marshal(in)
+		require.NoError(t, err)
+		assert.True(t, json.Valid(blob))
+
+		// the payload is marshaled as is, the extension fields follow its last field
+		payload := new(bytes.Buffer)
+		require.NoError(t, indented.marshaller.Marshal(payload, out))
+		assert.True(t, strings.HasPrefix(string(blob), strings.TrimSuffix(payload.String(), "\n}")+",\n  \"connsExtension\": ["))
+
+		extension, err := indented.UnmarshalExtension(blob)
+		require.NoError(t, err)
+		assert.Equal(t, outExtension, extension)
+	})
+
+	t.Run("without extended stats", func(t *testing.T) {
+		blob, err := GetMarshaler(ContentTypeProtobuf).Marshal(&network.Connections{Conns: in.Conns[1:2]})
+		require.NoError(t, err)
+
+		extension, err := GetUnmarshaler(ContentTypeProtobuf).UnmarshalExtension(blob)
+		require.NoError(t, err)
+		assert.Empty(t, extension.Conns)
+	})
+}
+
+func TestAppendObjectFields(t *testing.T) {
+	for _, tc := range []struct {
+		testCase  string
+		payload   string
+		extension string
+		expected  string
+	}{
+		{"compact", `{"conns":[]}`, `{"connsExtension":[{}]}`, `{"conns":[],"connsExtension":[{}]}`},
+		{"indented", "{\n  \"conns\": []\n}", "{\n  \"connsExtension\": [{}]\n}", "{\n  \"conns\": [],\n  \"connsExtension\": [{}]\n}"},
+		{"empty payload", `{}`, `{"connsExtension":[{}]}`, `{"connsExtension":[{}]}`},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(appendObjectFields([]byte(tc.payload), []byte(tc.extension))))
+		})
+	}
+}
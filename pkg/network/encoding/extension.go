@@ -0,0 +1,176 @@
+package encoding
+
+import (
+	"sort"
+
+	"github.com/StackVista/stackstate-agent/pkg/network"
+	"github.com/gogo/protobuf/proto"
+)
+
+// ConnectionsExtension carries the connection stats that model.Connections has no fields for. Its field number is far
+// above the field numbers of model.Connections, so the encoded extension is appended to the encoded model.Connections
+// and decoders that only know model.Connections skip it.
+type ConnectionsExtension struct {
+	// Conns extends the connection at the same index of model.Connections.Conns
+	Conns []*ConnectionExtension `protobuf:"bytes,1000,rep,name=connsExtension,json=connsExtension,proto3" json:"connsExtension,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ConnectionsExtension) Reset() { *m = ConnectionsExtension{} }
+
+// String implements proto.Message
+func (m *ConnectionsExtension) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ConnectionsExtension) ProtoMessage() {}
+
+// ConnectionExtension contains the extended stats of a single connection
+type ConnectionExtension struct {
+	DNSStatsByDomain []*DNSDomainStats `protobuf:"bytes,1,rep,name=dnsStatsByDomain,json=dnsStatsByDomain,proto3" json:"dnsStatsByDomain,omitempty"`
+	HTTPStatsByPath  []*HTTPStats      `protobuf:"bytes,2,rep,name=httpStatsByPath,json=httpStatsByPath,proto3" json:"httpStatsByPath,omitempty"`
+	// AggregatedConnections is the number of connections the connection aggregates, the RTT percentiles are in µs
+	AggregatedConnections uint32  `protobuf:"varint,3,opt,name=aggregatedConnections,json=aggregatedConnections,proto3" json:"aggregatedConnections,omitempty"`
+	ConnectionsOpened     uint32  `protobuf:"varint,4,opt,name=connectionsOpened,json=connectionsOpened,proto3" json:"connectionsOpened,omitempty"`
+	ConnectionsClosed     uint32  `protobuf:"varint,5,opt,name=connectionsClosed,json=connectionsClosed,proto3" json:"connectionsClosed,omitempty"`
+	RttP50                float64 `protobuf:"fixed64,6,opt,name=rttP50,json=rttP50,proto3" json:"rttP50,omitempty"`
+	RttP90                float64 `protobuf:"fixed64,7,opt,name=rttP90,json=rttP90,proto3" json:"rttP90,omitempty"`
+	RttP99                float64 `protobuf:"fixed64,8,opt,name=rttP99,json=rttP99,proto3" json:"rttP99,omitempty"`
+	// The TCP failures of the connection since the last fetch: the connects are 0 or 1, the resets are counted and the
+	// zero window time is in ms
+	FailedConnects  uint32 `protobuf:"varint,9,opt,name=failedConnects,json=failedConnects,proto3" json:"failedConnects,omitempty"`
+	RefusedConnects uint32 `protobuf:"varint,10,opt,name=refusedConnects,json=refusedConnects,proto3" json:"refusedConnects,omitempty"`
+	ResetsSent      uint32 `protobuf:"varint,11,opt,name=resetsSent,json=resetsSent,proto3" json:"resetsSent,omitempty"`
+	ResetsReceived  uint32 `protobuf:"varint,12,opt,name=resetsReceived,json=resetsReceived,proto3" json:"resetsReceived,omitempty"`
+	ZeroWindowTime  uint32 `protobuf:"varint,13,opt,name=zeroWindowTime,json=zeroWindowTime,proto3" json:"zeroWindowTime,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *ConnectionExtension) Reset() { *m = ConnectionExtension{} }
+
+// String implements proto.Message
+func (m *ConnectionExtension) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*ConnectionExtension) ProtoMessage() {}
+
+func (m *ConnectionExtension) isEmpty() bool {
+	return len(m.DNSStatsByDomain) == 0 && len(m.HTTPStatsByPath) == 0 && m.AggregatedConnections == 0 &&
+		m.ConnectionsOpened == 0 && m.ConnectionsClosed == 0 && m.FailedConnects == 0 && m.RefusedConnects == 0 &&
+		m.ResetsSent == 0 && m.ResetsReceived == 0 && m.ZeroWindowTime == 0
+}
+
+// DNSDomainStats contains the DNS stats of a connection for a single queried domain, LatencyBuckets counts the
+// responses per bucket of network.DNSLatencyBuckets
+type DNSDomainStats struct {
+	Domain              string   `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	SuccessfulResponses uint32   `protobuf:"varint,2,opt,name=successfulResponses,json=successfulResponses,proto3" json:"successfulResponses,omitempty"`
+	FailedResponses     uint32   `protobuf:"varint,3,opt,name=failedResponses,json=failedResponses,proto3" json:"failedResponses,omitempty"`
+	Timeouts            uint32   `protobuf:"varint,4,opt,name=timeouts,proto3" json:"timeouts,omitempty"`
+	LatencyBuckets      []uint32 `protobuf:"varint,5,rep,packed,name=latencyBuckets,json=latencyBuckets,proto3" json:"latencyBuckets,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *DNSDomainStats) Reset() { *m = DNSDomainStats{} }
+
+// String implements proto.Message
+func (m *DNSDomainStats) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*DNSDomainStats) ProtoMessage() {}
+
+// HTTPStats contains the HTTP requests of a connection for a single path prefix, StatusClassCounts counts the responses
+// from 1xx at index 0 to 5xx at index 4 and the latency percentiles are in µs
+type HTTPStats struct {
+	PathPrefix        string   `protobuf:"bytes,1,opt,name=pathPrefix,json=pathPrefix,proto3" json:"pathPrefix,omitempty"`
+	RequestCount      uint32   `protobuf:"varint,2,opt,name=requestCount,json=requestCount,proto3" json:"requestCount,omitempty"`
+	StatusClassCounts []uint32 `protobuf:"varint,3,rep,packed,name=statusClassCounts,json=statusClassCounts,proto3" json:"statusClassCounts,omitempty"`
+	LatencyP50        float64  `protobuf:"fixed64,4,opt,name=latencyP50,json=latencyP50,proto3" json:"latencyP50,omitempty"`
+	LatencyP90        float64  `protobuf:"fixed64,5,opt,name=latencyP90,json=latencyP90,proto3" json:"latencyP90,omitempty"`
+	LatencyP99        float64  `protobuf:"fixed64,6,opt,name=latencyP99,json=latencyP99,proto3" json:"latencyP99,omitempty"`
+}
+
+// Reset implements proto.Message
+func (m *HTTPStats) Reset() { *m = HTTPStats{} }
+
+// String implements proto.Message
+func (m *HTTPStats) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements proto.Message
+func (*HTTPStats) ProtoMessage() {}
+
+// FormatConnectionsExtension converts the stats of the connections that model.Connection has no fields for into a
+// ConnectionsExtension, it returns nil when none of the connections has such stats
+func FormatConnectionsExtension(conns []network.ConnectionStats) *ConnectionsExtension {
+	extended := false
+	extensions := make([]*ConnectionExtension, len(conns))
+	for i, conn := range conns {
+		extensions[i] = FormatConnectionExtension(conn)
+		if !extensions[i].isEmpty() {
+			extended = true
+		}
+	}
+
+	if !extended {
+		return nil
+	}
+	return &ConnectionsExtension{Conns: extensions}
+}
+
+// FormatConnectionExtension converts the stats of a connection that model.Connection has no fields for into a
+// ConnectionExtension
+func FormatConnectionExtension(conn network.ConnectionStats) *ConnectionExtension {
+	return &ConnectionExtension{
+		DNSStatsByDomain:      formatDNSStatsByDomain(conn.DNSStatsByDomain),
+		HTTPStatsByPath:       formatHTTPStatsByPath(conn.HTTPStatsByPath),
+		AggregatedConnections: conn.AggregatedConnections,
+		ConnectionsOpened:     conn.ConnectionsOpened,
+		ConnectionsClosed:     conn.ConnectionsClosed,
+		RttP50:                conn.RTTPercentile(0.5),
+		RttP90:                conn.RTTPercentile(0.9),
+		RttP99:                conn.RTTPercentile(0.99),
+		FailedConnects:        conn.FailedConnects,
+		RefusedConnects:       conn.RefusedConnects,
+		ResetsSent:            conn.ResetsSent,
+		ResetsReceived:        conn.ResetsReceived,
+		ZeroWindowTime:        conn.ZeroWindowTime,
+	}
+}
+
+func formatDNSStatsByDomain(statsByDomain map[string]network.DNSDomainStats) []*DNSDomainStats {
+	if len(statsByDomain) == 0 {
+		return nil
+	}
+
+	formatted := make([]*DNSDomainStats, 0, len(statsByDomain))
+	for domain, stats := range statsByDomain {
+		formatted = append(formatted, &DNSDomainStats{
+			Domain:              domain,
+			SuccessfulResponses: stats.SuccessfulResponses,
+			FailedResponses:     stats.FailedResponses,
+			Timeouts:            stats.Timeouts,
+			LatencyBuckets:      append([]uint32(nil), stats.LatencyBuckets[:]...),
+		})
+	}
+	sort.Slice(formatted, func(i, j int) bool { return formatted[i].Domain < formatted[j].Domain })
+	return formatted
+}
+
+func formatHTTPStatsByPath(statsByPath map[string]network.HTTPStats) []*HTTPStats {
+	if len(statsByPath) == 0 {
+		return nil
+	}
+
+	formatted := make([]*HTTPStats, 0, len(statsByPath))
+	for path, stats := range statsByPath {
+		formatted = append(formatted, &HTTPStats{
+			PathPrefix:        path,
+			RequestCount:      stats.RequestCount,
+			StatusClassCounts: append([]uint32(nil), stats.StatusClassCounts[:]...),
+			LatencyP50:        stats.LatencyPercentile(0.5),
+			LatencyP90:        stats.LatencyPercentile(0.9),
+			LatencyP99:        stats.LatencyPercentile(0.99),
+		})
+	}
+	sort.Slice(formatted, func(i, j int) bool { return formatted[i].PathPrefix < formatted[j].PathPrefix })
+	return formatted
+}
@@ -11,6 +11,9 @@ import (
 // ContentTypeJSON holds the HTML content-type of a JSON payload
 const ContentTypeJSON = "application/json"
 
+// jUnmarshaller skips the fields of the payload that the message it unmarshals into does not know
+var jUnmarshaller = jsonpb.Unmarshaler{AllowUnknownFields: true}
+
 type jsonSerializer struct {
 	marshaller jsonpb.Marshaler
 }
@@ -22,19 +25,58 @@ func (j jsonSerializer) Marshal(conns *network.Connections) ([]byte, error) {
 	}
 	payload := &model.Connections{Conns: agentConns, Dns: FormatDNS(conns.DNS), Telemetry: FormatTelemetry(conns.Telemetry)}
 	writer := new(bytes.Buffer)
-	err := j.marshaller.Marshal(writer, payload)
-	return writer.Bytes(), err
+	if err := j.marshaller.Marshal(writer, payload); err != nil {
+		return nil, err
+	}
+
+	// sts - the fields of the extension are added to the payload object
+	extension := FormatConnectionsExtension(conns.Conns)
+	if extension == nil {
+		return writer.Bytes(), nil
+	}
+	// the extension leaves out the empty stats, most connections only have a few of the extended stats
+	extensionMarshaller := j.marshaller
+	extensionMarshaller.EmitDefaults = false
+	extensionWriter := new(bytes.Buffer)
+	if err := extensionMarshaller.Marshal(extensionWriter, extension); err != nil {
+		return nil, err
+	}
+	return appendObjectFields(writer.Bytes(), extensionWriter.Bytes()), nil
+}
+
+// appendObjectFields appends the fields of the JSON object extension to the JSON object payload, by replacing the
+// closing brace of the payload with the fields that follow the opening brace of the extension
+func appendObjectFields(payload, extension []byte) []byte {
+	payload = bytes.TrimRight(payload, " \t\r\n")
+	payload = bytes.TrimRight(payload[:len(payload)-1], " \t\r\n")
+	fields := bytes.TrimLeft(extension, " \t\r\n")[1:]
+
+	merged := make([]byte, 0, len(payload)+len(fields)+1)
+	merged = append(merged, payload...)
+	if !bytes.HasSuffix(payload, []byte("{")) {
+		merged = append(merged, ',')
+	}
+	return append(merged, fields...)
 }
 
 func (jsonSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
 	conns := new(model.Connections)
 	reader := bytes.NewReader(blob)
-	if err := jsonpb.Unmarshal(reader, conns); err != nil {
+	if err := jUnmarshaller.Unmarshal(reader, conns); err != nil {
 		return nil, err
 	}
 	return conns, nil
 }
 
+func (jsonSerializer) UnmarshalExtension(blob []byte) (*ConnectionsExtension, error) {
+	extension := new(ConnectionsExtension)
+	reader := bytes.NewReader(blob)
+	if err := jUnmarshaller.Unmarshal(reader, extension); err != nil {
+		return nil, err
+	}
+	return extension, nil
+}
+
 func (j jsonSerializer) ContentType() string {
 	return ContentTypeJSON
 }
@@ -24,7 +24,20 @@ func (protoSerializer) Marshal(conns *network.Connections) ([]byte, error) {
 		Telemetry: FormatTelemetry(conns.Telemetry),
 	}
 
-	return proto.Marshal(payload)
+	blob, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// sts - the extension is appended as the payload, decoders that do not know it skip its field
+	if extension := FormatConnectionsExtension(conns.Conns); extension != nil {
+		extensionBlob, err := proto.Marshal(extension)
+		if err != nil {
+			return nil, err
+		}
+		blob = append(blob, extensionBlob...)
+	}
+	return blob, nil
 }
 
 func (protoSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
@@ -35,6 +48,14 @@ func (protoSerializer) Unmarshal(blob []byte) (*model.Connections, error) {
 	return conns, nil
 }
 
+func (protoSerializer) UnmarshalExtension(blob []byte) (*ConnectionsExtension, error) {
+	extension := new(ConnectionsExtension)
+	if err := proto.Unmarshal(blob, extension); err != nil {
+		return nil, err
+	}
+	return extension, nil
+}
+
 func (p protoSerializer) ContentType() string {
 	return ContentTypeProtobuf
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/quantile"
 	"github.com/dustin/go-humanize"
 )
 
@@ -123,6 +124,27 @@ type ConnectionStats struct {
 	DNSTimeouts            uint32
 	DNSSuccessLatencySum   uint64
 	DNSFailureLatencySum   uint64
+	// sts - DNSStatsByDomain breaks the DNS stats down by the queried domain
+	DNSStatsByDomain map[string]DNSDomainStats
+	// sts - HTTPStatsByPath contains the HTTP requests on the connection by path prefix
+	HTTPStatsByPath map[string]HTTPStats
+	// sts - set when the ConnectionAggregator collapsed connections into this connection
+	AggregatedConnections uint32
+	ConnectionsOpened     uint32
+	ConnectionsClosed     uint32
+	// RTTs is the sketch of the RTTs in µs of the aggregated TCP connections
+	RTTs *quantile.Sketch
+	// sts - TCP failures of the connection. The tracer reports the failures over the lifetime of the connection, the
+	// network state reports the failures since the last fetch of the client, like LastSentBytes. FailedConnects is set
+	// for the outbound connections that never completed the handshake, RefusedConnects when the handshake was refused
+	// by a reset. ResetsSent and ResetsReceived count the resets. The failures are summed when connections are
+	// aggregated.
+	FailedConnects  uint32
+	RefusedConnects uint32
+	ResetsSent      uint32
+	ResetsReceived  uint32
+	// ZeroWindowTime is the time in ms the peer advertised a zero window
+	ZeroWindowTime uint32
 }
 
 // IPTranslation can be associated with a connection to show the connection is NAT'd
@@ -231,6 +253,21 @@ func ConnectionSummary(c ConnectionStats, names map[util.Address][]string) strin
 			time.Duration(c.RTT)*time.Microsecond,
 			time.Duration(c.RTTVar)*time.Microsecond,
 		)
+
+		// sts
+		if c.FailedConnects > 0 || c.ResetsSent > 0 || c.ResetsReceived > 0 || c.ZeroWindowTime > 0 {
+			str += fmt.Sprintf(
+				", %d failed connects (%d refused), %d resets sent, %d resets received, %s in zero window",
+				c.FailedConnects, c.RefusedConnects,
+				c.ResetsSent, c.ResetsReceived,
+				time.Duration(c.ZeroWindowTime)*time.Millisecond,
+			)
+		}
+	}
+
+	// sts
+	if c.AggregatedConnections > 0 {
+		str += fmt.Sprintf(", %d connections (%d opened, %d closed)", c.AggregatedConnections, c.ConnectionsOpened, c.ConnectionsClosed)
 	}
 
 	return str
@@ -0,0 +1,33 @@
+package network
+
+// HTTPMonitor aggregates the HTTP requests on the connections of the host
+type HTTPMonitor interface {
+	GetHTTPStats() map[httpKey]map[string]HTTPStats
+	GetStats() map[string]int64
+	Close()
+}
+
+// NewNullHTTPMonitor returns a dummy implementation of HTTPMonitor
+func NewNullHTTPMonitor() HTTPMonitor {
+	return nullHTTPMonitor{}
+}
+
+type nullHTTPMonitor struct{}
+
+func (nullHTTPMonitor) GetHTTPStats() map[httpKey]map[string]HTTPStats {
+	return nil
+}
+
+func (nullHTTPMonitor) GetStats() map[string]int64 {
+	return map[string]int64{
+		"packets_processed":     0,
+		"decoding_errors":       0,
+		"http_stats_dropped":    0,
+		"http_requests_expired": 0,
+		"http_requests_pending": 0,
+	}
+}
+
+func (nullHTTPMonitor) Close() {}
+
+var _ HTTPMonitor = nullHTTPMonitor{}
@@ -0,0 +1,193 @@
+// +build linux_bpf
+
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	bpflib "github.com/iovisor/gobpf/elf"
+)
+
+const httpRequestTimeout = 30 * time.Second
+
+var _ HTTPMonitor = &SocketFilterHTTPMonitor{}
+
+// SocketFilterHTTPMonitor is an HTTP/1.x traffic monitor built on top of an eBPF SOCKET_FILTER
+type SocketFilterHTTPMonitor struct {
+	source     *packetSource
+	parser     *httpParser
+	statKeeper *httpStatKeeper
+	exit       chan struct{}
+	wg         sync.WaitGroup
+
+	// packet telemetry
+	processed      int64
+	decodingErrors int64
+}
+
+// NewSocketFilterHTTPMonitor returns a new SocketFilterHTTPMonitor
+func NewSocketFilterHTTPMonitor(rootPath string, filter *bpflib.SocketFilter, maxHTTPStats int) (*SocketFilterHTTPMonitor, error) {
+	var (
+		packetSrc *packetSource
+		srcErr    error
+	)
+
+	// Create the RAW_SOCKET inside the root network namespace
+	nsErr := util.WithRootNS(rootPath, func() {
+		packetSrc, srcErr = newPacketSource(filter)
+	})
+	if nsErr != nil {
+		return nil, nsErr
+	}
+	if srcErr != nil {
+		return nil, srcErr
+	}
+
+	monitor := &SocketFilterHTTPMonitor{
+		source:     packetSrc,
+		parser:     newHTTPParser(),
+		statKeeper: newHTTPStatKeeper(httpRequestTimeout, maxHTTPStats),
+		exit:       make(chan struct{}),
+	}
+
+	// Start consuming packets
+	monitor.wg.Add(1)
+	go func() {
+		monitor.pollPackets()
+		monitor.wg.Done()
+	}()
+
+	return monitor, nil
+}
+
+// GetHTTPStats returns the stats of the HTTP requests since the last call
+func (m *SocketFilterHTTPMonitor) GetHTTPStats() map[httpKey]map[string]HTTPStats {
+	return m.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns the telemetry of the monitor
+func (m *SocketFilterHTTPMonitor) GetStats() map[string]int64 {
+	stats := m.statKeeper.GetStats()
+	stats["packets_processed"] = atomic.LoadInt64(&m.processed)
+	stats["decoding_errors"] = atomic.LoadInt64(&m.decodingErrors)
+	return stats
+}
+
+// Close terminates the HTTP traffic monitor as well as the underlying socket and the attached filter
+func (m *SocketFilterHTTPMonitor) Close() {
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+	m.statKeeper.Close()
+}
+
+// processPacket retrieves the HTTP request or response from the received packet data. The underlying packet data
+// can't be referenced after this method call since the underlying memory content gets invalidated by `afpacket`.
+func (m *SocketFilterHTTPMonitor) processPacket(data []byte) {
+	ts := time.Now() // record the timestamp before we do any processing
+	pktInfo := httpPacketInfo{}
+
+	atomic.AddInt64(&m.processed, 1)
+	if err := m.parser.ParseInto(data, &pktInfo); err != nil {
+		if err != errSkippedPayload {
+			atomic.AddInt64(&m.decodingErrors, 1)
+			log.Tracef("error decoding HTTP payload: %v", err)
+		}
+		return
+	}
+
+	m.statKeeper.ProcessPacketInfo(pktInfo, ts)
+}
+
+func (m *SocketFilterHTTPMonitor) pollPackets() {
+	for {
+		data, _, err := m.source.ZeroCopyReadPacketData()
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		if err == nil {
+			m.processPacket(data)
+			continue
+		}
+
+		// Immediately retry for EAGAIN
+		if err == syscall.EAGAIN {
+			continue
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type httpParser struct {
+	decoder     *gopacket.DecodingLayerParser
+	layers      []gopacket.LayerType
+	ipv4Payload *layers.IPv4
+	ipv6Payload *layers.IPv6
+	tcpPayload  *layers.TCP
+}
+
+func newHTTPParser() *httpParser {
+	ipv4Payload := &layers.IPv4{}
+	ipv6Payload := &layers.IPv6{}
+	tcpPayload := &layers.TCP{}
+
+	decoder := gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &layers.Ethernet{}, ipv4Payload, ipv6Payload, tcpPayload)
+	// the TCP payload is parsed by parseHTTPPayload
+	decoder.IgnoreUnsupported = true
+
+	return &httpParser{
+		decoder:     decoder,
+		ipv4Payload: ipv4Payload,
+		ipv6Payload: ipv6Payload,
+		tcpPayload:  tcpPayload,
+	}
+}
+
+// ParseInto parses an ethernet frame with the start of an HTTP request or response into pktInfo
+func (p *httpParser) ParseInto(data []byte, pktInfo *httpPacketInfo) error {
+	if err := p.decoder.DecodeLayers(data, &p.layers); err != nil {
+		return err
+	}
+
+	if len(p.layers) == 0 || p.layers[len(p.layers)-1] != layers.LayerTypeTCP {
+		return errSkippedPayload
+	}
+
+	if !parseHTTPPayload(p.tcpPayload.Payload, pktInfo) {
+		return errSkippedPayload
+	}
+
+	var srcIP, dstIP util.Address
+	for _, layer := range p.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			srcIP = util.AddressFromNetIP(p.ipv4Payload.SrcIP)
+			dstIP = util.AddressFromNetIP(p.ipv4Payload.DstIP)
+		case layers.LayerTypeIPv6:
+			srcIP = util.AddressFromNetIP(p.ipv6Payload.SrcIP)
+			dstIP = util.AddressFromNetIP(p.ipv6Payload.DstIP)
+		}
+	}
+	srcPort, dstPort := uint16(p.tcpPayload.SrcPort), uint16(p.tcpPayload.DstPort)
+
+	if pktInfo.pktType == HTTPRequest {
+		pktInfo.key = httpKey{clientIP: srcIP, clientPort: srcPort, serverIP: dstIP, serverPort: dstPort}
+	} else {
+		pktInfo.key = httpKey{clientIP: dstIP, clientPort: dstPort, serverIP: srcIP, serverPort: srcPort}
+	}
+	return nil
+}
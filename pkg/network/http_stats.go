@@ -0,0 +1,297 @@
+package network
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/quantile"
+)
+
+const (
+	// MaxHTTPInFlightRequests limits the number of requests that are waiting for their response
+	MaxHTTPInFlightRequests = 10000
+
+	// httpPathPrefixDepth is the number of path segments HTTP requests are aggregated by, /api/users/42?x=1 is
+	// aggregated into /api/users
+	httpPathPrefixDepth = 2
+)
+
+// httpLatencyConfig is the configuration of the sketches of the HTTP request latencies
+var httpLatencyConfig = quantile.Default()
+
+// HTTPStats contains the HTTP requests of a connection for a single path prefix
+type HTTPStats struct {
+	RequestCount uint32
+	// StatusClassCounts counts the responses per status code class, from 1xx at index 0 to 5xx at index 4
+	StatusClassCounts [5]uint32
+	// Latencies is the sketch of the latencies of the requests in µs
+	Latencies *quantile.Sketch
+}
+
+// LatencyPercentile returns the latency of the requests in µs at the percentile p in [0, 1]
+func (s *HTTPStats) LatencyPercentile(p float64) float64 {
+	if s.Latencies == nil {
+		return 0
+	}
+	return s.Latencies.Quantile(httpLatencyConfig, p)
+}
+
+func (s *HTTPStats) observe(statusCode int, latency uint64) {
+	s.RequestCount++
+	if class := statusCode/100 - 1; class >= 0 && class < len(s.StatusClassCounts) {
+		s.StatusClassCounts[class]++
+	}
+	if s.Latencies == nil {
+		s.Latencies = &quantile.Sketch{}
+	}
+	s.Latencies.Insert(httpLatencyConfig, float64(latency))
+}
+
+// merge adds other to s, the sketch of other is copied so it is never shared with s
+func (s *HTTPStats) merge(other HTTPStats) {
+	s.RequestCount += other.RequestCount
+	for i := range s.StatusClassCounts {
+		s.StatusClassCounts[i] += other.StatusClassCounts[i]
+	}
+	if other.Latencies == nil {
+		return
+	}
+	if s.Latencies == nil {
+		s.Latencies = other.Latencies.Copy()
+	} else {
+		s.Latencies.Merge(httpLatencyConfig, other.Latencies)
+	}
+}
+
+// mergeHTTPStats adds the path stats of src to dst, dst is allocated when needed so it is never shared with src
+func mergeHTTPStats(dst, src map[string]HTTPStats) map[string]HTTPStats {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]HTTPStats, len(src))
+	}
+	for path, stats := range src {
+		merged := dst[path]
+		merged.merge(stats)
+		dst[path] = merged
+	}
+	return dst
+}
+
+// httpKey identifies the TCP connection of HTTP requests by its client and server side
+type httpKey struct {
+	clientIP   util.Address
+	clientPort uint16
+	serverIP   util.Address
+	serverPort uint16
+}
+
+// HTTPPacketType tells us whether the packet starts an HTTP request or response
+type HTTPPacketType uint8
+
+const (
+	// HTTPRequest means the packet contains the request line of an HTTP request
+	HTTPRequest HTTPPacketType = iota
+	// HTTPResponse means the packet contains the status line of an HTTP response
+	HTTPResponse
+)
+
+type httpPacketInfo struct {
+	key        httpKey
+	pktType    HTTPPacketType
+	pathPrefix string // only set for requests
+	statusCode int    // only set for responses
+}
+
+// parseHTTPPayload parses the request or status line at the start of a TCP payload into pktInfo, the key is left to
+// the caller. It returns false when the payload does not start an HTTP/1.x message.
+func parseHTTPPayload(payload []byte, pktInfo *httpPacketInfo) bool {
+	if end := bytes.IndexByte(payload, '\n'); end >= 0 {
+		payload = payload[:end]
+	}
+	fields := bytes.Fields(payload)
+	if len(fields) < 2 {
+		return false
+	}
+
+	// HTTP/1.1 200 OK
+	if bytes.HasPrefix(fields[0], []byte("HTTP/1.")) {
+		statusCode := 0
+		if len(fields[1]) != 3 {
+			return false
+		}
+		for _, digit := range fields[1] {
+			if digit < '0' || digit > '9' {
+				return false
+			}
+			statusCode = statusCode*10 + int(digit-'0')
+		}
+		pktInfo.pktType = HTTPResponse
+		pktInfo.statusCode = statusCode
+		return true
+	}
+
+	// GET /api/users HTTP/1.1
+	if len(fields) < 3 || !bytes.HasPrefix(fields[2], []byte("HTTP/1.")) {
+		return false
+	}
+	pktInfo.pktType = HTTPRequest
+	pktInfo.pathPrefix = httpPathPrefix(string(fields[1]))
+	return true
+}
+
+// httpPathPrefix returns the first httpPathPrefixDepth segments of the path of a request target, without the query
+func httpPathPrefix(target string) string {
+	// absolute-form targets of requests to proxies, http://example.com/api/users
+	if i := strings.Index(target, "://"); i >= 0 {
+		target = target[i+3:]
+		if slash := strings.IndexByte(target, '/'); slash >= 0 {
+			target = target[slash:]
+		} else {
+			target = "/"
+		}
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	if !strings.HasPrefix(target, "/") {
+		// asterisk-form (OPTIONS *) and authority-form (CONNECT) targets
+		return target
+	}
+
+	depth := 0
+	for i := 1; i < len(target); i++ {
+		if target[i] == '/' {
+			depth++
+			if depth == httpPathPrefixDepth {
+				return target[:i]
+			}
+		}
+	}
+	return target
+}
+
+// httpRequest is a request that waits for its response
+type httpRequest struct {
+	ts         uint64 // Stored in µs
+	pathPrefix string
+}
+
+type httpStatKeeper struct {
+	mux      sync.Mutex
+	stats    map[httpKey]map[string]HTTPStats
+	inFlight map[httpKey]httpRequest
+	// numStats is the number of path stats in stats, limited to maxStats
+	numStats         int
+	maxStats         int
+	expirationPeriod time.Duration
+	exit             chan struct{}
+
+	statsDropped    int64
+	requestsExpired int64
+}
+
+func newHTTPStatKeeper(timeout time.Duration, maxStats int) *httpStatKeeper {
+	statKeeper := &httpStatKeeper{
+		stats:            make(map[httpKey]map[string]HTTPStats),
+		inFlight:         make(map[httpKey]httpRequest),
+		maxStats:         maxStats,
+		expirationPeriod: timeout,
+		exit:             make(chan struct{}),
+	}
+
+	ticker := time.NewTicker(statKeeper.expirationPeriod)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				statKeeper.removeExpiredRequests(now.Add(-statKeeper.expirationPeriod))
+			case <-statKeeper.exit:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return statKeeper
+}
+
+// ProcessPacketInfo keeps track of the request of every connection until the response comes in. HTTP/1.x sends the
+// responses in the order of the requests, only the latest request is matched when a client pipelines requests.
+func (h *httpStatKeeper) ProcessPacketInfo(info httpPacketInfo, ts time.Time) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if info.pktType == HTTPRequest {
+		if _, ok := h.inFlight[info.key]; !ok && len(h.inFlight) >= MaxHTTPInFlightRequests {
+			return
+		}
+		h.inFlight[info.key] = httpRequest{ts: microSecs(ts), pathPrefix: info.pathPrefix}
+		return
+	}
+
+	// If a response does not have a corresponding request, we discard it
+	request, ok := h.inFlight[info.key]
+	if !ok {
+		return
+	}
+	delete(h.inFlight, info.key)
+
+	pathStats, ok := h.stats[info.key]
+	if !ok {
+		pathStats = make(map[string]HTTPStats)
+		h.stats[info.key] = pathStats
+	}
+	stats, ok := pathStats[request.pathPrefix]
+	if !ok {
+		if h.numStats >= h.maxStats {
+			h.statsDropped++
+			return
+		}
+		h.numStats++
+	}
+	stats.observe(info.statusCode, microSecs(ts)-request.ts)
+	pathStats[request.pathPrefix] = stats
+}
+
+// GetAndResetAllStats returns the stats of the requests that got a response since the last call
+func (h *httpStatKeeper) GetAndResetAllStats() map[httpKey]map[string]HTTPStats {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	ret := h.stats
+	h.stats = make(map[httpKey]map[string]HTTPStats)
+	h.numStats = 0
+	return ret
+}
+
+// GetStats returns the telemetry of the stat keeper
+func (h *httpStatKeeper) GetStats() map[string]int64 {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return map[string]int64{
+		"http_stats_dropped":    h.statsDropped,
+		"http_requests_expired": h.requestsExpired,
+		"http_requests_pending": int64(len(h.inFlight)),
+	}
+}
+
+// removeExpiredRequests drops the requests that did not get a response, on connections that closed or that we missed
+// the response packet of
+func (h *httpStatKeeper) removeExpiredRequests(earliestTs time.Time) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	threshold := microSecs(earliestTs)
+	for k, v := range h.inFlight {
+		if v.ts < threshold {
+			delete(h.inFlight, k)
+			h.requestsExpired++
+		}
+	}
+}
+
+func (h *httpStatKeeper) Close() {
+	h.exit <- struct{}{}
+}
@@ -0,0 +1,135 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHTTPPayload(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		payload  string
+		ok       bool
+		expected httpPacketInfo
+	}{
+		{
+			name:     "request",
+			payload:  "GET /api/users/42?verbose=true HTTP/1.1\r\nHost: example.com\r\n\r\n",
+			ok:       true,
+			expected: httpPacketInfo{pktType: HTTPRequest, pathPrefix: "/api/users"},
+		},
+		{
+			name:     "request to a proxy",
+			payload:  "POST http://example.com/orders HTTP/1.0\r\n\r\n",
+			ok:       true,
+			expected: httpPacketInfo{pktType: HTTPRequest, pathPrefix: "/orders"},
+		},
+		{
+			name:     "response",
+			payload:  "HTTP/1.1 503 Service Unavailable\r\nContent-Length: 0\r\n\r\n",
+			ok:       true,
+			expected: httpPacketInfo{pktType: HTTPResponse, statusCode: 503},
+		},
+		{
+			name:    "invalid status code",
+			payload: "HTTP/1.1 5O3 Service Unavailable\r\n\r\n",
+		},
+		{
+			name:    "HTTP/2 preface",
+			payload: "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n",
+		},
+		{
+			name:    "request body",
+			payload: "{\"name\": \"GET / HTTP/1.1\"}",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var pktInfo httpPacketInfo
+			assert.Equal(t, tc.ok, parseHTTPPayload([]byte(tc.payload), &pktInfo))
+			if tc.ok {
+				assert.Equal(t, tc.expected, pktInfo)
+			}
+		})
+	}
+}
+
+func TestHTTPPathPrefix(t *testing.T) {
+	for target, expected := range map[string]string{
+		"/":                       "/",
+		"/health":                 "/health",
+		"/api/users":              "/api/users",
+		"/api/users/42/orders":    "/api/users",
+		"/api/users/?page=2":      "/api/users",
+		"/index.html#top":         "/index.html",
+		"http://example.com":      "/",
+		"https://example.com/a/b": "/a/b",
+		"*":                       "*",
+	} {
+		assert.Equal(t, expected, httpPathPrefix(target), target)
+	}
+}
+
+func TestHTTPStatKeeper(t *testing.T) {
+	hk := newHTTPStatKeeper(time.Minute, 2)
+	defer hk.Close()
+	key := httpKey{
+		clientIP:   util.AddressFromString("10.0.0.1"),
+		clientPort: 50000,
+		serverIP:   util.AddressFromString("10.0.0.2"),
+		serverPort: 8080,
+	}
+
+	then := time.Now()
+	request := func(path string, statusCode int, latency time.Duration) {
+		hk.ProcessPacketInfo(httpPacketInfo{key: key, pktType: HTTPRequest, pathPrefix: path}, then)
+		hk.ProcessPacketInfo(httpPacketInfo{key: key, pktType: HTTPResponse, statusCode: statusCode}, then.Add(latency))
+	}
+	request("/api/users", 200, 10*time.Millisecond)
+	request("/api/users", 201, 20*time.Millisecond)
+	request("/api/users", 404, 30*time.Millisecond)
+	request("/api/orders", 500, 100*time.Millisecond)
+	// responses without a request are discarded
+	hk.ProcessPacketInfo(httpPacketInfo{key: key, pktType: HTTPResponse, statusCode: 200}, then)
+	// a third path prefix exceeds the maximum number of stats
+	request("/health", 200, time.Millisecond)
+
+	stats := hk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	require.Len(t, stats[key], 2)
+
+	users := stats[key]["/api/users"]
+	assert.Equal(t, uint32(3), users.RequestCount)
+	assert.Equal(t, [5]uint32{0, 2, 0, 1, 0}, users.StatusClassCounts)
+	assert.InDelta(t, 20000, users.LatencyPercentile(0.5), 20000*0.02)
+	assert.InDelta(t, 30000, users.LatencyPercentile(0.99), 30000*0.02)
+
+	orders := stats[key]["/api/orders"]
+	assert.Equal(t, uint32(1), orders.RequestCount)
+	assert.Equal(t, [5]uint32{0, 0, 0, 0, 1}, orders.StatusClassCounts)
+
+	assert.Equal(t, int64(1), hk.GetStats()["http_stats_dropped"])
+	assert.Empty(t, hk.GetAndResetAllStats())
+}
+
+func TestHTTPStatKeeperExpiredRequests(t *testing.T) {
+	hk := newHTTPStatKeeper(time.Minute, 10)
+	defer hk.Close()
+	key := httpKey{
+		clientIP:   util.AddressFromString("10.0.0.1"),
+		clientPort: 50000,
+		serverIP:   util.AddressFromString("10.0.0.2"),
+		serverPort: 8080,
+	}
+
+	then := time.Now()
+	hk.ProcessPacketInfo(httpPacketInfo{key: key, pktType: HTTPRequest, pathPrefix: "/"}, then)
+	hk.removeExpiredRequests(then.Add(time.Second))
+	hk.ProcessPacketInfo(httpPacketInfo{key: key, pktType: HTTPResponse, statusCode: 200}, then.Add(2*time.Second))
+
+	assert.Empty(t, hk.GetAndResetAllStats())
+	assert.Equal(t, int64(1), hk.GetStats()["http_requests_expired"])
+}
@@ -0,0 +1,402 @@
+// +build linux_bpf
+
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// replayClientID is the client the replayed connections are fetched for
+const replayClientID = "replay"
+
+// pcapngMagic is the block type of the section header block every pcapng file starts with
+const pcapngMagic = 0x0A0D0D0A
+
+// Replay feeds the packets of a pcap or pcapng capture of ethernet frames through the DNS parser and, as a synthetic
+// stream of the connections in the capture, through State.Connections. onSnapshot is called with the connections of
+// every Interval of capture time and with the connections at the end of the capture. Replay returns the telemetry of
+// the replay, the DNS cache and the network state.
+func Replay(r io.Reader, cfg ReplayConfig, onSnapshot func(ReplaySnapshot)) (map[string]int64, error) {
+	source, err := newReplaySource(r)
+	if err != nil {
+		return nil, err
+	}
+
+	replayer := newReplayer(cfg)
+	for {
+		data, ci, err := source.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return replayer.GetStats(), fmt.Errorf("error reading packet %d: %s", replayer.packets+1, err)
+		}
+		replayer.processPacket(data, ci.Timestamp, onSnapshot)
+	}
+	replayer.snapshot(replayer.lastTs, onSnapshot)
+
+	return replayer.GetStats(), nil
+}
+
+// replaySource reads the packets of a pcap or pcapng capture
+type replaySource interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+func newReplaySource(r io.Reader) (replaySource, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("error reading capture header: %s", err)
+	}
+
+	var source replaySource
+	// the section header block type is a palindrome, it reads the same in both byte orders
+	if binary.BigEndian.Uint32(magic) == pcapngMagic {
+		source, err = pcapgo.NewNgReader(buffered, pcapgo.DefaultNgReaderOptions)
+	} else {
+		source, err = pcapgo.NewReader(buffered)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading capture header: %s", err)
+	}
+
+	// the DNS parser decodes ethernet frames, like the ones of the socket filter
+	if source.LinkType() != layers.LinkTypeEthernet {
+		return nil, fmt.Errorf("unsupported link type %s, only ethernet captures can be replayed", source.LinkType())
+	}
+	return source, nil
+}
+
+// flowKey identifies the connection of a packet in the direction of the packet
+type flowKey struct {
+	connType ConnectionType
+	srcIP    util.Address
+	srcPort  uint16
+	dstIP    util.Address
+	dstPort  uint16
+}
+
+func (k flowKey) reverse() flowKey {
+	return flowKey{connType: k.connType, srcIP: k.dstIP, srcPort: k.dstPort, dstIP: k.srcIP, dstPort: k.srcPort}
+}
+
+// replayFlow is a connection in the capture, conn is its state as the tracer would report it
+type replayFlow struct {
+	conn   ConnectionStats
+	closed bool
+}
+
+type replayer struct {
+	cfg        ReplayConfig
+	state      State
+	dnsParser  *dnsParser
+	flowParser *flowParser
+	cache      *reverseDNSCache
+	statKeeper *dnsStatKeeper
+	aggregator *ConnectionAggregator
+
+	// flows are the connections in the capture by the direction of their initiator
+	flows        map[flowKey]*replayFlow
+	translation  *translation
+	nextSnapshot time.Time
+	lastTs       time.Time
+
+	// replay telemetry
+	packets        int64
+	decodingErrors int64
+	truncatedPkts  int64
+	dnsPackets     int64
+	snapshots      int64
+}
+
+func newReplayer(cfg ReplayConfig) *replayer {
+	// the cache and the stat keeper expire their entries on the time of the capture instead of the current time
+	var statKeeper *dnsStatKeeper
+	if cfg.CollectDNSStats {
+		statKeeper = makeDNSStatKeeper(cfg.DNSTimeout, cfg.MaxDNSDomainsTracked)
+	}
+
+	replayer := &replayer{
+		cfg:        cfg,
+		state:      NewState(time.Hour, cfg.MaxClosedConnectionsBuffered, cfg.MaxConnectionsStateBuffered, cfg.MaxDNSStatsBuffered, 0),
+		dnsParser:  newDNSParser(cfg.CollectDNSStats),
+		flowParser: newFlowParser(),
+		cache:      makeReverseDNSCache(dnsCacheSize, dnsCacheTTL),
+		statKeeper: statKeeper,
+		flows:      make(map[flowKey]*replayFlow),
+	}
+	if cfg.AggregateConnections {
+		replayer.aggregator = NewConnectionAggregator(time.Hour)
+	}
+
+	// registers the client, closed connections are only stored for the clients that are known to the state
+	replayer.state.Connections(replayClientID, 0, nil, nil)
+	if replayer.aggregator != nil {
+		replayer.aggregator.Aggregate(replayClientID, nil, nil)
+	}
+	return replayer
+}
+
+func (r *replayer) processPacket(data []byte, ts time.Time, onSnapshot func(ReplaySnapshot)) {
+	r.packets++
+	if r.nextSnapshot.IsZero() {
+		r.nextSnapshot = ts.Add(r.cfg.Interval)
+	}
+	for r.cfg.Interval > 0 && !ts.Before(r.nextSnapshot) {
+		r.snapshot(r.nextSnapshot, onSnapshot)
+		r.nextSnapshot = r.nextSnapshot.Add(r.cfg.Interval)
+	}
+	if ts.After(r.lastTs) {
+		r.lastTs = ts
+	}
+
+	var pkt flowPacket
+	if err := r.flowParser.ParseInto(data, &pkt); err != nil {
+		if err != errSkippedPayload {
+			r.decodingErrors++
+			log.Tracef("error decoding packet %d: %v", r.packets, err)
+		}
+		return
+	}
+	r.processFlowPacket(pkt, ts)
+
+	if pkt.key.srcPort == 53 || pkt.key.dstPort == 53 {
+		r.processDNSPacket(data, ts)
+	}
+}
+
+// processFlowPacket adds the packet to its connection, the side that sends the first packet initiates the connection
+// unless the packet is the SYN-ACK of a TCP handshake
+func (r *replayer) processFlowPacket(pkt flowPacket, ts time.Time) {
+	sent := true
+	flow, ok := r.flows[pkt.key]
+	if !ok {
+		if flow, ok = r.flows[pkt.key.reverse()]; ok {
+			sent = false
+		}
+	}
+
+	if !ok {
+		// stray ACKs after the connection closed do not open a new connection
+		if pkt.key.connType == TCP && !pkt.syn && pkt.payloadLen == 0 {
+			return
+		}
+
+		key := pkt.key
+		if pkt.syn && pkt.ack {
+			key = key.reverse()
+			sent = false
+		}
+		flow = &replayFlow{conn: ConnectionStats{
+			Source:    key.srcIP,
+			Dest:      key.dstIP,
+			SPort:     key.srcPort,
+			DPort:     key.dstPort,
+			Type:      key.connType,
+			Family:    pkt.family,
+			Direction: OUTGOING,
+		}}
+		r.flows[key] = flow
+	}
+
+	if sent {
+		flow.conn.MonotonicSentBytes += uint64(pkt.payloadLen)
+	} else {
+		flow.conn.MonotonicRecvBytes += uint64(pkt.payloadLen)
+	}
+	flow.conn.LastUpdateEpoch = uint64(ts.UnixNano())
+	if pkt.fin || pkt.rst {
+		flow.closed = true
+	}
+}
+
+// processDNSPacket does what SocketFilterSnooper.processPacket does with the packets of the socket filter
+func (r *replayer) processDNSPacket(data []byte, ts time.Time) {
+	t := r.getCachedTranslation()
+	pktInfo := dnsPacketInfo{}
+
+	if err := r.dnsParser.ParseInto(data, t, &pktInfo); err != nil {
+		switch err {
+		case errSkippedPayload:
+		case errTruncated:
+			r.truncatedPkts++
+		default:
+			r.decodingErrors++
+			log.Tracef("error decoding DNS payload of packet %d: %v", r.packets, err)
+		}
+		return
+	}
+	r.dnsPackets++
+
+	if r.statKeeper != nil && (r.cfg.CollectLocalDNS || !pktInfo.key.serverIP.IsLoopback()) {
+		r.statKeeper.ProcessPacketInfo(pktInfo, ts)
+	}
+
+	if pktInfo.pktType == SuccessfulResponse {
+		r.cache.Add(t, ts)
+	}
+}
+
+// snapshot fetches the connections at the time now of the capture, like the tracer does for the connections check
+func (r *replayer) snapshot(now time.Time, onSnapshot func(ReplaySnapshot)) {
+	r.snapshots++
+
+	var dnsStats map[dnsKey]dnsStats
+	if r.statKeeper != nil {
+		r.statKeeper.removeExpiredStates(now.Add(-r.cfg.DNSTimeout))
+		dnsStats = r.statKeeper.GetAndResetAllStats()
+	}
+
+	active := make([]ConnectionStats, 0, len(r.flows))
+	for key, flow := range r.flows {
+		lastUpdate := time.Unix(0, int64(flow.conn.LastUpdateEpoch))
+		if !flow.closed && flow.conn.Type == UDP && now.Sub(lastUpdate) > r.cfg.UDPConnTimeout {
+			flow.closed = true
+		}
+
+		if flow.closed {
+			r.state.StoreClosedConnection(flow.conn)
+			delete(r.flows, key)
+			continue
+		}
+		active = append(active, flow.conn)
+	}
+
+	conns := r.state.Connections(replayClientID, uint64(now.UnixNano()), active, dnsStats)
+	if r.aggregator != nil {
+		conns = r.aggregator.Aggregate(replayClientID, active, conns)
+	}
+	names := r.cache.Get(conns, now)
+	r.cache.Expire(now)
+
+	onSnapshot(ReplaySnapshot{Time: now, Conns: conns, Names: names})
+}
+
+// GetStats returns the telemetry of the replay, the DNS cache and the network state
+func (r *replayer) GetStats() map[string]int64 {
+	stats := r.cache.Stats()
+	stats["packets_processed"] = r.packets
+	stats["decoding_errors"] = r.decodingErrors
+	stats["truncated_packets"] = r.truncatedPkts
+	stats["dns_packets"] = r.dnsPackets
+	stats["snapshots"] = r.snapshots
+	if r.statKeeper != nil {
+		stats["dns_domains_dropped"] = r.statKeeper.GetDomainsDropped()
+	}
+	if telemetry, ok := r.state.GetStats()["telemetry"].(map[string]int64); ok {
+		for name, value := range telemetry {
+			stats[name] = value
+		}
+	}
+	return stats
+}
+
+func (r *replayer) getCachedTranslation() *translation {
+	t := r.translation
+	if t == nil {
+		t = new(translation)
+		r.translation = t
+	}
+
+	// Recycle buffer if necessary
+	if t.ips == nil || len(t.ips) > maxIPBufferSize {
+		t.ips = make([]util.Address, 30)
+	}
+	t.ips = t.ips[:0]
+
+	return t
+}
+
+// flowPacket is the connection and the TCP flags of a packet
+type flowPacket struct {
+	key        flowKey
+	family     ConnectionFamily
+	payloadLen int
+	syn        bool
+	ack        bool
+	fin        bool
+	rst        bool
+}
+
+type flowParser struct {
+	decoder     *gopacket.DecodingLayerParser
+	layers      []gopacket.LayerType
+	ipv4Payload *layers.IPv4
+	ipv6Payload *layers.IPv6
+	tcpPayload  *layers.TCP
+	udpPayload  *layers.UDP
+}
+
+func newFlowParser() *flowParser {
+	ipv4Payload := &layers.IPv4{}
+	ipv6Payload := &layers.IPv6{}
+	tcpPayload := &layers.TCP{}
+	udpPayload := &layers.UDP{}
+
+	decoder := gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &layers.Ethernet{}, ipv4Payload, ipv6Payload, tcpPayload, udpPayload)
+	// only the length of the payloads is of interest
+	decoder.IgnoreUnsupported = true
+
+	return &flowParser{
+		decoder:     decoder,
+		ipv4Payload: ipv4Payload,
+		ipv6Payload: ipv6Payload,
+		tcpPayload:  tcpPayload,
+		udpPayload:  udpPayload,
+	}
+}
+
+// ParseInto parses the connection of an ethernet frame with a TCP or UDP packet into pkt
+func (p *flowParser) ParseInto(data []byte, pkt *flowPacket) error {
+	if err := p.decoder.DecodeLayers(data, &p.layers); err != nil {
+		return err
+	}
+
+	if p.decoder.Truncated {
+		return errTruncated
+	}
+
+	found := false
+	for _, layer := range p.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			pkt.family = AFINET
+			pkt.key.srcIP = util.AddressFromNetIP(p.ipv4Payload.SrcIP)
+			pkt.key.dstIP = util.AddressFromNetIP(p.ipv4Payload.DstIP)
+		case layers.LayerTypeIPv6:
+			pkt.family = AFINET6
+			pkt.key.srcIP = util.AddressFromNetIP(p.ipv6Payload.SrcIP)
+			pkt.key.dstIP = util.AddressFromNetIP(p.ipv6Payload.DstIP)
+		case layers.LayerTypeTCP:
+			found = true
+			pkt.key.connType = TCP
+			pkt.key.srcPort = uint16(p.tcpPayload.SrcPort)
+			pkt.key.dstPort = uint16(p.tcpPayload.DstPort)
+			pkt.payloadLen = len(p.tcpPayload.Payload)
+			pkt.syn, pkt.ack, pkt.fin, pkt.rst = p.tcpPayload.SYN, p.tcpPayload.ACK, p.tcpPayload.FIN, p.tcpPayload.RST
+		case layers.LayerTypeUDP:
+			found = true
+			pkt.key.connType = UDP
+			pkt.key.srcPort = uint16(p.udpPayload.SrcPort)
+			pkt.key.dstPort = uint16(p.udpPayload.DstPort)
+			pkt.payloadLen = len(p.udpPayload.Payload)
+		}
+	}
+
+	if !found {
+		return errSkippedPayload
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+)
+
+// ReplayConfig configures the replay of a capture
+type ReplayConfig struct {
+	// Interval is the capture time between two snapshots, like the interval of the connections check
+	Interval time.Duration
+	// UDPConnTimeout is the capture time after which a UDP connection without packets is closed
+	UDPConnTimeout time.Duration
+
+	CollectDNSStats      bool
+	CollectLocalDNS      bool
+	DNSTimeout           time.Duration
+	MaxDNSDomainsTracked int
+
+	MaxClosedConnectionsBuffered int
+	MaxConnectionsStateBuffered  int
+	MaxDNSStatsBuffered          int
+
+	// AggregateConnections collapses the connections of the snapshots with a ConnectionAggregator
+	AggregateConnections bool
+}
+
+// DefaultReplayConfig returns the replay configuration with the system-probe defaults and DNS stats enabled
+func DefaultReplayConfig() ReplayConfig {
+	return ReplayConfig{
+		Interval:                     30 * time.Second,
+		UDPConnTimeout:               30 * time.Second,
+		CollectDNSStats:              true,
+		CollectLocalDNS:              false,
+		DNSTimeout:                   15 * time.Second,
+		MaxDNSDomainsTracked:         DefaultMaxDNSDomainsTracked,
+		MaxClosedConnectionsBuffered: 50000,
+		MaxConnectionsStateBuffered:  75000,
+		MaxDNSStatsBuffered:          75000,
+	}
+}
+
+// ReplaySnapshot contains the connections as the connections check would have fetched them at Time of the capture
+type ReplaySnapshot struct {
+	Time  time.Time
+	Conns []ConnectionStats
+	// Names are the domains the addresses of the connections resolve to
+	Names map[util.Address][]string
+}
+
+// FormatReplaySnapshot writes the connections of a snapshot with their DNS stats in a human readable form
+func FormatReplaySnapshot(w io.Writer, snapshot ReplaySnapshot) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s: %d connections\n", snapshot.Time.UTC().Format(time.RFC3339Nano), len(snapshot.Conns))
+	for _, c := range snapshot.Conns {
+		fmt.Fprintf(&buf, "  %s\n", ConnectionSummary(c, snapshot.Names))
+		if c.DNSSuccessfulResponses == 0 && c.DNSFailedResponses == 0 && c.DNSTimeouts == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "    DNS: %d successful (latency %s), %d failed (latency %s), %d timeouts\n",
+			c.DNSSuccessfulResponses, time.Duration(c.DNSSuccessLatencySum)*time.Microsecond,
+			c.DNSFailedResponses, time.Duration(c.DNSFailureLatencySum)*time.Microsecond,
+			c.DNSTimeouts,
+		)
+		domains := make([]string, 0, len(c.DNSStatsByDomain))
+		for domain := range c.DNSStatsByDomain {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		for _, domain := range domains {
+			stats := c.DNSStatsByDomain[domain]
+			fmt.Fprintf(&buf, "      %s: %d successful, %d failed, %d timeouts, latency buckets %v\n",
+				domain, stats.SuccessfulResponses, stats.FailedResponses, stats.Timeouts, stats.LatencyBuckets)
+		}
+	}
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,170 @@
+// +build linux_bpf
+
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedPacket struct {
+	ts     time.Time
+	layers []gopacket.SerializableLayer
+}
+
+func writeCapture(t *testing.T, packets []capturedPacket) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	w := pcapgo.NewWriter(buf)
+	require.NoError(t, w.WriteFileHeader(65536, layers.LinkTypeEthernet))
+
+	for _, pkt := range packets {
+		data := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		require.NoError(t, gopacket.SerializeLayers(data, opts, pkt.layers...))
+		ci := gopacket.CaptureInfo{Timestamp: pkt.ts, CaptureLength: len(data.Bytes()), Length: len(data.Bytes())}
+		require.NoError(t, w.WritePacket(ci, data.Bytes()))
+	}
+	return buf
+}
+
+func ipv4Packet(src, dst string, transport gopacket.SerializableLayer, payload ...gopacket.SerializableLayer) []gopacket.SerializableLayer {
+	ip := &layers.IPv4{Version: 4, TTL: 64, SrcIP: net.ParseIP(src), DstIP: net.ParseIP(dst)}
+	switch l := transport.(type) {
+	case *layers.TCP:
+		ip.Protocol = layers.IPProtocolTCP
+		_ = l.SetNetworkLayerForChecksum(ip)
+	case *layers.UDP:
+		ip.Protocol = layers.IPProtocolUDP
+		_ = l.SetNetworkLayerForChecksum(ip)
+	}
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 1},
+		DstMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 2},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	return append([]gopacket.SerializableLayer{eth, ip, transport}, payload...)
+}
+
+func dnsQuery(id uint16, domain string) *layers.DNS {
+	return &layers.DNS{
+		ID:      id,
+		RD:      true,
+		OpCode:  layers.DNSOpCodeQuery,
+		QDCount: 1,
+		Questions: []layers.DNSQuestion{
+			{Name: []byte(domain), Type: layers.DNSTypeA, Class: layers.DNSClassIN},
+		},
+	}
+}
+
+func dnsResponse(id uint16, domain string, ip string) *layers.DNS {
+	response := dnsQuery(id, domain)
+	response.QR = true
+	response.ANCount = 1
+	response.Answers = []layers.DNSResourceRecord{
+		{Name: []byte(domain), Type: layers.DNSTypeA, Class: layers.DNSClassIN, TTL: 60, IP: net.ParseIP(ip)},
+	}
+	return response
+}
+
+func findConn(conns []ConnectionStats, connType ConnectionType, sport uint16) *ConnectionStats {
+	for i := range conns {
+		if conns[i].Type == connType && conns[i].SPort == sport {
+			return &conns[i]
+		}
+	}
+	return nil
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	tcp := func(sport, dport layers.TCPPort, syn, ack, fin bool) *layers.TCP {
+		return &layers.TCP{SrcPort: sport, DstPort: dport, SYN: syn, ACK: ack, FIN: fin, Window: 1024}
+	}
+	udp := func(sport, dport layers.UDPPort) *layers.UDP {
+		return &layers.UDP{SrcPort: sport, DstPort: dport}
+	}
+
+	capture := writeCapture(t, []capturedPacket{
+		// the client resolves example.com and connects to it
+		{at(0), ipv4Packet("10.0.0.1", "8.8.8.8", udp(40000, 53), dnsQuery(1, "example.com"))},
+		{at(5 * time.Millisecond), ipv4Packet("8.8.8.8", "10.0.0.1", udp(53, 40000), dnsResponse(1, "example.com", "93.184.216.34"))},
+		{at(time.Second), ipv4Packet("10.0.0.1", "93.184.216.34", tcp(50000, 443, true, false, false))},
+		{at(time.Second), ipv4Packet("93.184.216.34", "10.0.0.1", tcp(443, 50000, true, true, false))},
+		{at(time.Second), ipv4Packet("10.0.0.1", "93.184.216.34", tcp(50000, 443, false, true, false), gopacket.Payload(make([]byte, 100)))},
+		{at(time.Second), ipv4Packet("93.184.216.34", "10.0.0.1", tcp(443, 50000, false, true, false), gopacket.Payload(make([]byte, 200)))},
+		{at(time.Second), ipv4Packet("10.0.0.1", "93.184.216.34", tcp(50000, 443, false, true, true))},
+		{at(time.Second), ipv4Packet("93.184.216.34", "10.0.0.1", tcp(443, 50000, false, true, true))},
+		// stray ACK after the connection closed
+		{at(2 * time.Second), ipv4Packet("10.0.0.1", "93.184.216.34", tcp(50000, 443, false, true, false))},
+		// a query that never gets a response
+		{at(2 * time.Second), ipv4Packet("10.0.0.1", "8.8.8.8", udp(40000, 53), dnsQuery(2, "timeout.com"))},
+		// a packet after the first interval
+		{at(40 * time.Second), ipv4Packet("10.0.0.1", "10.0.0.2", udp(40001, 9999), gopacket.Payload(make([]byte, 10)))},
+	})
+
+	var snapshots []ReplaySnapshot
+	stats, err := Replay(capture, DefaultReplayConfig(), func(snapshot ReplaySnapshot) {
+		snapshots = append(snapshots, snapshot)
+	})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, int64(11), stats["packets_processed"])
+	assert.Equal(t, int64(3), stats["dns_packets"])
+	assert.Equal(t, int64(0), stats["decoding_errors"])
+
+	first := snapshots[0]
+	assert.True(t, at(30*time.Second).Equal(first.Time))
+	require.Len(t, first.Conns, 2)
+
+	https := findConn(first.Conns, TCP, 50000)
+	require.NotNil(t, https)
+	assert.Equal(t, util.AddressFromString("10.0.0.1"), https.Source)
+	assert.Equal(t, util.AddressFromString("93.184.216.34"), https.Dest)
+	assert.Equal(t, uint16(443), https.DPort)
+	assert.Equal(t, uint64(100), https.MonotonicSentBytes)
+	assert.Equal(t, uint64(200), https.MonotonicRecvBytes)
+	assert.Equal(t, []string{"example.com"}, first.Names[https.Dest])
+
+	dns := findConn(first.Conns, UDP, 40000)
+	require.NotNil(t, dns)
+	assert.Equal(t, uint16(53), dns.DPort)
+	assert.Equal(t, uint32(1), dns.DNSSuccessfulResponses)
+	assert.Equal(t, uint32(1), dns.DNSTimeouts)
+	assert.Equal(t, uint64(5000), dns.DNSSuccessLatencySum)
+	assert.Equal(t, uint32(1), dns.DNSStatsByDomain["example.com"].SuccessfulResponses)
+	assert.Equal(t, uint32(1), dns.DNSStatsByDomain["timeout.com"].Timeouts)
+
+	// the closed TCP connection is gone, the DNS connection timed out and is reported as closed
+	last := snapshots[1]
+	assert.True(t, at(40*time.Second).Equal(last.Time))
+	require.Len(t, last.Conns, 2)
+	assert.Nil(t, findConn(last.Conns, TCP, 50000))
+	dns = findConn(last.Conns, UDP, 40000)
+	require.NotNil(t, dns)
+	assert.Equal(t, uint64(0), dns.LastSentBytes)
+	assert.Equal(t, uint32(0), dns.DNSSuccessfulResponses)
+	other := findConn(last.Conns, UDP, 40001)
+	require.NotNil(t, other)
+	assert.Equal(t, uint64(10), other.MonotonicSentBytes)
+}
+
+func TestReplayUnsupportedCapture(t *testing.T) {
+	_, err := Replay(bytes.NewBufferString("not a capture"), DefaultReplayConfig(), func(ReplaySnapshot) {})
+	assert.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, pcapgo.NewWriter(buf).WriteFileHeader(65536, layers.LinkTypeLinuxSLL))
+	_, err = Replay(buf, DefaultReplayConfig(), func(ReplaySnapshot) {})
+	assert.Error(t, err)
+}
@@ -0,0 +1,13 @@
+// +build !linux_bpf
+
+package network
+
+import (
+	"errors"
+	"io"
+)
+
+// Replay is not implemented on non-linux systems
+func Replay(r io.Reader, cfg ReplayConfig, onSnapshot func(ReplaySnapshot)) (map[string]int64, error) {
+	return nil, errors.New("replaying captures is only supported on linux with eBPF enabled")
+}
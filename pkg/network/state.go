@@ -34,6 +34,10 @@ type State interface {
 	// StoreClosedConnection stores a new closed connection
 	StoreClosedConnection(conn ConnectionStats)
 
+	// StoreHTTPStats stores the latest HTTP stats for all clients, they are added to the connections of the next call
+	// to Connections of each client
+	StoreHTTPStats(stats map[httpKey]map[string]HTTPStats)
+
 	// RemoveClient stops tracking stateful data for a given client
 	RemoveClient(clientID string)
 
@@ -58,12 +62,49 @@ type telemetry struct {
 	timeSyncCollisions int64
 	dnsStatsDropped    int64
 	dnsPidCollisions   int64
+	httpStatsDropped   int64
 }
 
 type stats struct {
 	totalSent        uint64
 	totalRecv        uint64
 	totalRetransmits uint32
+	// sts
+	totalTCPFailures tcpFailures
+}
+
+// tcpFailures are the TCP failures of a connection over its lifetime, as reported by the tracer
+type tcpFailures struct {
+	failedConnects  uint32
+	refusedConnects uint32
+	resetsSent      uint32
+	resetsReceived  uint32
+	zeroWindowTime  uint32
+}
+
+func tcpFailuresOf(c *ConnectionStats) tcpFailures {
+	return tcpFailures{
+		failedConnects:  c.FailedConnects,
+		refusedConnects: c.RefusedConnects,
+		resetsSent:      c.ResetsSent,
+		resetsReceived:  c.ResetsReceived,
+		zeroWindowTime:  c.ZeroWindowTime,
+	}
+}
+
+// exceeds tells whether any of the failures is larger than the failures of the connection
+func (f tcpFailures) exceeds(c *ConnectionStats) bool {
+	return f.failedConnects > c.FailedConnects || f.refusedConnects > c.RefusedConnects ||
+		f.resetsSent > c.ResetsSent || f.resetsReceived > c.ResetsReceived || f.zeroWindowTime > c.ZeroWindowTime
+}
+
+// subtractFrom subtracts the failures from the failures of the connection
+func (f tcpFailures) subtractFrom(c *ConnectionStats) {
+	c.FailedConnects -= f.failedConnects
+	c.RefusedConnects -= f.refusedConnects
+	c.ResetsSent -= f.resetsSent
+	c.ResetsReceived -= f.resetsReceived
+	c.ZeroWindowTime -= f.zeroWindowTime
 }
 
 type client struct {
@@ -72,6 +113,7 @@ type client struct {
 	closedConnections map[string]ConnectionStats
 	stats             map[string]*stats
 	dnsStats          map[dnsKey]dnsStats
+	httpStats         map[httpKey]map[string]HTTPStats
 }
 
 type networkState struct {
@@ -88,10 +130,11 @@ type networkState struct {
 	maxClosedConns int
 	maxClientStats int
 	maxDNSStats    int
+	maxHTTPStats   int
 }
 
 // NewState creates a new network state
-func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, maxDNSStats int) State {
+func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, maxDNSStats int, maxHTTPStats int) State {
 	return &networkState{
 		clients:        map[string]*client{},
 		telemetry:      telemetry{},
@@ -99,6 +142,7 @@ func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, ma
 		maxClosedConns: maxClosedConns,
 		maxClientStats: maxClientStats,
 		maxDNSStats:    maxDNSStats,
+		maxHTTPStats:   maxHTTPStats,
 		buf:            &bytes.Buffer{},
 	}
 }
@@ -150,6 +194,7 @@ func (ns *networkState) Connections(
 			ns.storeDNSStats(dnsStats)
 			ns.addDNSStats(id, latestConns)
 		}
+		ns.addHTTPStats(id, latestConns)
 		return latestConns
 	}
 
@@ -174,6 +219,7 @@ func (ns *networkState) Connections(
 		ns.storeDNSStats(dnsStats)
 		ns.addDNSStats(id, conns)
 	}
+	ns.addHTTPStats(id, conns)
 	return conns
 }
 
@@ -202,6 +248,7 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 			conn.DNSTimeouts = dnsStats.timeouts
 			conn.DNSSuccessLatencySum = dnsStats.successLatencySum
 			conn.DNSFailureLatencySum = dnsStats.failureLatencySum
+			conn.DNSStatsByDomain = dnsStats.domains
 		}
 		seen[key] = struct{}{}
 	}
@@ -210,6 +257,46 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 	ns.clients[id].dnsStats = make(map[dnsKey]dnsStats)
 }
 
+// addHTTPStats adds the HTTP stats of the client to the TCP connections on both the client and the server side
+func (ns *networkState) addHTTPStats(id string, conns []ConnectionStats) {
+	client := ns.clients[id]
+	if len(client.httpStats) == 0 {
+		return
+	}
+
+	type sideKey struct {
+		key      httpKey
+		isServer bool
+	}
+	seen := make(map[sideKey]struct{})
+	for i := range conns {
+		conn := &conns[i]
+		if conn.Type != TCP {
+			continue
+		}
+
+		side := sideKey{key: httpKey{clientIP: conn.Source, clientPort: conn.SPort, serverIP: conn.Dest, serverPort: conn.DPort}}
+		pathStats, ok := client.httpStats[side.key]
+		if !ok {
+			side = sideKey{key: httpKey{clientIP: conn.Dest, clientPort: conn.DPort, serverIP: conn.Source, serverPort: conn.SPort}, isServer: true}
+			pathStats, ok = client.httpStats[side.key]
+		}
+		if !ok {
+			continue
+		}
+
+		// the same connection reported by multiple pids only gets the stats once
+		if _, alreadySeen := seen[side]; alreadySeen {
+			continue
+		}
+		seen[side] = struct{}{}
+		conn.HTTPStatsByPath = pathStats
+	}
+
+	// flush the HTTP stats
+	client.httpStats = make(map[httpKey]map[string]HTTPStats)
+}
+
 // getConnsByKey returns a mapping of byte-key -> connection for easier access + manipulation
 func getConnsByKey(conns []ConnectionStats, buf *bytes.Buffer) map[string]*ConnectionStats {
 	connsByKey := make(map[string]*ConnectionStats, len(conns))
@@ -248,6 +335,7 @@ func (ns *networkState) StoreClosedConnection(conn ConnectionStats) {
 			prev.MonotonicSentBytes += conn.MonotonicSentBytes
 			prev.MonotonicRecvBytes += conn.MonotonicRecvBytes
 			prev.MonotonicRetransmits += conn.MonotonicRetransmits
+			prev.addTCPFailures(conn)
 			// Also update the timestamp
 			prev.LastUpdateEpoch = conn.LastUpdateEpoch
 			client.closedConnections[string(key)] = prev
@@ -271,17 +359,39 @@ func (ns *networkState) storeDNSStats(stats map[dnsKey]dnsStats) {
 				prev.timeouts += dns.timeouts
 				prev.successLatencySum += dns.successLatencySum
 				prev.failureLatencySum += dns.failureLatencySum
+				prev.domains = mergeDomainStats(prev.domains, dns.domains)
 				client.dnsStats[key] = prev
 			} else if len(client.dnsStats) >= ns.maxDNSStats {
 				ns.telemetry.dnsStatsDropped++
 				continue
 			} else {
+				// every client gets its own copy of the domain stats, they are merged into per client
+				dns.domains = mergeDomainStats(nil, dns.domains)
 				client.dnsStats[key] = dns
 			}
 		}
 	}
 }
 
+// StoreHTTPStats stores latest HTTP stats for all clients
+func (ns *networkState) StoreHTTPStats(stats map[httpKey]map[string]HTTPStats) {
+	ns.Lock()
+	defer ns.Unlock()
+
+	for key, pathStats := range stats {
+		for _, client := range ns.clients {
+			// If we've seen HTTP stats for this key already, lets combine the two
+			if prev, ok := client.httpStats[key]; ok {
+				client.httpStats[key] = mergeHTTPStats(prev, pathStats)
+			} else if len(client.httpStats) >= ns.maxHTTPStats {
+				ns.telemetry.httpStatsDropped++
+			} else {
+				client.httpStats[key] = mergeHTTPStats(nil, pathStats)
+			}
+		}
+	}
+}
+
 // newClient creates a new client and returns true if the given client already exists
 func (ns *networkState) newClient(clientID string) (*client, bool) {
 	if c, ok := ns.clients[clientID]; ok {
@@ -293,6 +403,7 @@ func (ns *networkState) newClient(clientID string) (*client, bool) {
 		stats:             map[string]*stats{},
 		closedConnections: map[string]ConnectionStats{},
 		dnsStats:          map[dnsKey]dnsStats{},
+		httpStats:         map[httpKey]map[string]HTTPStats{},
 	}
 	ns.clients[clientID] = c
 	return c, false
@@ -320,6 +431,7 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 				closedConn.MonotonicSentBytes += activeConn.MonotonicSentBytes
 				closedConn.MonotonicRecvBytes += activeConn.MonotonicRecvBytes
 				closedConn.MonotonicRetransmits += activeConn.MonotonicRetransmits
+				closedConn.addTCPFailures(*activeConn)
 
 				ns.createStatsForKey(client, key)
 				ns.updateConnWithStatWithActiveConn(client, key, *activeConn, &closedConn)
@@ -330,6 +442,7 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 					stats.totalRetransmits = activeConn.MonotonicRetransmits
 					stats.totalSent = activeConn.MonotonicSentBytes
 					stats.totalRecv = activeConn.MonotonicRecvBytes
+					stats.totalTCPFailures = tcpFailuresOf(activeConn)
 				}
 			} else {
 				// Else the closed connection and the active connection have the same epoch
@@ -373,11 +486,14 @@ func (ns *networkState) updateConnWithStatWithActiveConn(client *client, key str
 		closed.LastSentBytes = closed.MonotonicSentBytes - st.totalSent
 		closed.LastRecvBytes = closed.MonotonicRecvBytes - st.totalRecv
 		closed.LastRetransmits = closed.MonotonicRetransmits - st.totalRetransmits
+		// sts - the TCP failures are reported since the last fetch
+		st.totalTCPFailures.subtractFrom(closed)
 
 		// Update stats object with latest values
 		st.totalSent = active.MonotonicSentBytes
 		st.totalRecv = active.MonotonicRecvBytes
 		st.totalRetransmits = active.MonotonicRetransmits
+		st.totalTCPFailures = tcpFailuresOf(&active)
 	} else {
 		closed.LastSentBytes = closed.MonotonicSentBytes
 		closed.LastRecvBytes = closed.MonotonicRecvBytes
@@ -393,11 +509,15 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 		c.LastSentBytes = c.MonotonicSentBytes - st.totalSent
 		c.LastRecvBytes = c.MonotonicRecvBytes - st.totalRecv
 		c.LastRetransmits = c.MonotonicRetransmits - st.totalRetransmits
+		// sts - the TCP failures are reported since the last fetch
+		totalTCPFailures := tcpFailuresOf(c)
+		st.totalTCPFailures.subtractFrom(c)
 
 		// Update stats object with latest values
 		st.totalSent = c.MonotonicSentBytes
 		st.totalRecv = c.MonotonicRecvBytes
 		st.totalRetransmits = c.MonotonicRetransmits
+		st.totalTCPFailures = totalTCPFailures
 	} else {
 		c.LastSentBytes = c.MonotonicSentBytes
 		c.LastRecvBytes = c.MonotonicRecvBytes
@@ -407,12 +527,13 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 
 // handleStatsUnderflow checks if we are going to have an underflow when computing last stats and if it's the case it resets the stats to avoid it
 func (ns *networkState) handleStatsUnderflow(key string, st *stats, c *ConnectionStats) {
-	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits {
+	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits || st.totalTCPFailures.exceeds(c) {
 		ns.telemetry.statsResets++
 		log.Debugf("Stats reset triggered for key:%s, stats:%+v, connection:%+v", BeautifyKey(key), *st, *c)
 		st.totalSent = 0
 		st.totalRecv = 0
 		st.totalRetransmits = 0
+		st.totalTCPFailures = tcpFailures{}
 	}
 }
 
@@ -464,6 +585,7 @@ func (ns *networkState) RemoveConnections(keys []string) {
 		s += " [%d closed connections dropped]"
 		s += " [%d dns stats dropped]"
 		s += " [%d DNS pid collisions]"
+		s += " [%d http stats dropped]"
 		s += " [%d time sync collisions]"
 		log.Warnf(s,
 			ns.telemetry.unorderedConns,
@@ -472,6 +594,7 @@ func (ns *networkState) RemoveConnections(keys []string) {
 			ns.telemetry.closedConnDropped,
 			ns.telemetry.dnsStatsDropped,
 			ns.telemetry.dnsPidCollisions,
+			ns.telemetry.httpStatsDropped,
 			ns.telemetry.timeSyncCollisions)
 	}
 
@@ -502,6 +625,7 @@ func (ns *networkState) GetStats() map[string]interface{} {
 			"time_sync_collisions": ns.telemetry.timeSyncCollisions,
 			"dns_stats_dropped":    ns.telemetry.dnsStatsDropped,
 			"dns_pid_collisions":   ns.telemetry.dnsPidCollisions,
+			"http_stats_dropped":   ns.telemetry.httpStatsDropped,
 		},
 		"current_time":       time.Now().Unix(),
 		"latest_bpf_time_ns": ns.latestTimeEpoch,
@@ -217,7 +217,7 @@ func TestRetrieveClosedConnection(t *testing.T) {
 func TestCleanupClient(t *testing.T) {
 	clientID := "1"
 
-	state := NewState(100*time.Millisecond, 50000, 75000, 75000)
+	state := NewState(100*time.Millisecond, 50000, 75000, 75000, 75000)
 	clients := state.(*networkState).getClients()
 	assert.Equal(t, 0, len(clients))
 
@@ -1133,6 +1133,101 @@ func TestAggregateClosedConnectionsTimestamp(t *testing.T) {
 	assert.Equal(t, conn.LastUpdateEpoch, state.Connections(client, latestEpochTime(), nil, nil)[0].LastUpdateEpoch)
 }
 
+func TestTCPFailuresOfClosedConnections(t *testing.T) {
+	conn := ConnectionStats{
+		Pid:            123,
+		Type:           TCP,
+		Family:         AFINET,
+		Source:         util.AddressFromString("10.0.0.1"),
+		Dest:           util.AddressFromString("10.0.0.2"),
+		SPort:          50000,
+		DPort:          443,
+		FailedConnects: 1,
+		ResetsReceived: 1,
+		ZeroWindowTime: 200,
+	}
+
+	client := "client"
+	state := newDefaultState()
+
+	// Register the client
+	assert.Len(t, state.Connections(client, latestEpochTime(), nil, nil), 0)
+
+	// the same connection fails twice within the interval
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+
+	conns := state.Connections(client, latestEpochTime(), nil, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(2), conns[0].FailedConnects)
+	assert.Equal(t, uint32(2), conns[0].ResetsReceived)
+	assert.Equal(t, uint32(0), conns[0].ResetsSent)
+	assert.Equal(t, uint32(400), conns[0].ZeroWindowTime)
+
+	// the failures are reported once
+	assert.Len(t, state.Connections(client, latestEpochTime(), nil, nil), 0)
+}
+
+func TestTCPFailuresOfActiveConnections(t *testing.T) {
+	conn := ConnectionStats{
+		Pid:            123,
+		Type:           TCP,
+		Family:         AFINET,
+		Source:         util.AddressFromString("10.0.0.1"),
+		Dest:           util.AddressFromString("10.0.0.2"),
+		SPort:          50000,
+		DPort:          443,
+		ResetsSent:     1,
+		ZeroWindowTime: 200,
+	}
+
+	client1 := "1"
+	client2 := "2"
+	state := newDefaultState()
+
+	// Register the clients
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil), 0)
+
+	conns := state.Connections(client1, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].ResetsSent)
+	assert.Equal(t, uint32(200), conns[0].ZeroWindowTime)
+
+	// the totals of the tracer did not change, there are no new failures
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(0), conns[0].ResetsSent)
+	assert.Equal(t, uint32(0), conns[0].ZeroWindowTime)
+
+	conn.ResetsReceived = 1
+	conn.ZeroWindowTime = 500
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(0), conns[0].ResetsSent)
+	assert.Equal(t, uint32(1), conns[0].ResetsReceived)
+	assert.Equal(t, uint32(300), conns[0].ZeroWindowTime)
+
+	// the other client did not fetch the connection yet, it gets the totals
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].ResetsSent)
+	assert.Equal(t, uint32(1), conns[0].ResetsReceived)
+	assert.Equal(t, uint32(500), conns[0].ZeroWindowTime)
+
+	// the connection closes after a reset, only the new failure is reported
+	conn.ResetsReceived = 2
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+	conns = state.Connections(client1, latestEpochTime(), nil, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(0), conns[0].ResetsSent)
+	assert.Equal(t, uint32(1), conns[0].ResetsReceived)
+	assert.Equal(t, uint32(0), conns[0].ZeroWindowTime)
+}
+
 func TestDNSStatsWithMultipleClients(t *testing.T) {
 	c := ConnectionStats{
 		Pid:    123,
@@ -1146,7 +1241,10 @@ func TestDNSStatsWithMultipleClients(t *testing.T) {
 
 	dKey := dnsKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
 	stats := make(map[dnsKey]dnsStats)
-	stats[dKey] = dnsStats{successfulResponses: 1}
+	stats[dKey] = dnsStats{
+		successfulResponses: 1,
+		domains:             map[string]DNSDomainStats{"golang.org": {SuccessfulResponses: 1}},
+	}
 
 	client1 := "client1"
 	client2 := "client2"
@@ -1163,17 +1261,20 @@ func TestDNSStatsWithMultipleClients(t *testing.T) {
 	conns := state.Connections(client1, latestEpochTime(), nil, stats)
 	require.Len(t, conns, 1)
 	assert.EqualValues(t, 1, conns[0].DNSSuccessfulResponses)
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain["golang.org"].SuccessfulResponses)
 
 	// Register the third client but also pass in dns stats
 	conns = state.Connections(client3, latestEpochTime(), []ConnectionStats{c}, stats)
 	require.Len(t, conns, 1)
 	// DNS stats should be available for the new client
 	assert.EqualValues(t, 1, conns[0].DNSSuccessfulResponses)
+	assert.EqualValues(t, 1, conns[0].DNSStatsByDomain["golang.org"].SuccessfulResponses)
 
 	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{c}, stats)
 	require.Len(t, conns, 1)
 	// 2nd client should get accumulated stats
 	assert.EqualValues(t, 3, conns[0].DNSSuccessfulResponses)
+	assert.EqualValues(t, 3, conns[0].DNSStatsByDomain["golang.org"].SuccessfulResponses)
 }
 
 func TestDNSStatsPIDCollisions(t *testing.T) {
@@ -1209,6 +1310,65 @@ func TestDNSStatsPIDCollisions(t *testing.T) {
 	assert.Equal(t, int64(1), state.(*networkState).telemetry.dnsPidCollisions)
 }
 
+func TestHTTPStatsWithMultipleClients(t *testing.T) {
+	client := ConnectionStats{
+		Pid:    123,
+		Type:   TCP,
+		Family: AFINET,
+		Source: util.AddressFromString("10.0.0.1"),
+		Dest:   util.AddressFromString("10.0.0.2"),
+		SPort:  50000,
+		DPort:  8080,
+	}
+	// the server side of the same connection, in another process on the host
+	server := ConnectionStats{
+		Pid:       456,
+		Type:      TCP,
+		Family:    AFINET,
+		Source:    client.Dest,
+		Dest:      client.Source,
+		SPort:     client.DPort,
+		DPort:     client.SPort,
+		Direction: INCOMING,
+	}
+
+	hKey := httpKey{clientIP: client.Source, clientPort: client.SPort, serverIP: client.Dest, serverPort: client.DPort}
+	stats := map[httpKey]map[string]HTTPStats{
+		hKey: {"/api/users": {RequestCount: 1, StatusClassCounts: [5]uint32{0, 1, 0, 0, 0}}},
+	}
+
+	client1 := "client1"
+	client2 := "client2"
+	state := newDefaultState()
+
+	// Register both clients
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil), 0)
+
+	state.StoreHTTPStats(stats)
+	conns := state.Connections(client1, latestEpochTime(), []ConnectionStats{client, server}, nil)
+	require.Len(t, conns, 2)
+	for _, conn := range conns {
+		assert.EqualValues(t, 1, conn.HTTPStatsByPath["/api/users"].RequestCount)
+	}
+
+	// the stats are flushed for the first client
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{client, server}, nil)
+	require.Len(t, conns, 2)
+	for _, conn := range conns {
+		assert.Empty(t, conn.HTTPStatsByPath)
+	}
+
+	// the second client gets the accumulated stats
+	state.StoreHTTPStats(stats)
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{client, server}, nil)
+	require.Len(t, conns, 2)
+	for _, conn := range conns {
+		assert.EqualValues(t, 2, conn.HTTPStatsByPath["/api/users"].RequestCount)
+		assert.Equal(t, [5]uint32{0, 2, 0, 0, 0}, conn.HTTPStatsByPath["/api/users"].StatusClassCounts)
+	}
+}
+
 func generateRandConnections(n int) []ConnectionStats {
 	cs := make([]ConnectionStats, 0, n)
 	for i := 0; i < n; i++ {
@@ -1236,5 +1396,5 @@ func latestEpochTime() uint64 {
 
 func newDefaultState() State {
 	// Using values from ebpf.NewDefaultConfig()
-	return NewState(2*time.Minute, 50000, 75000, 75000)
+	return NewState(2*time.Minute, 50000, 75000, 75000, 75000)
 }
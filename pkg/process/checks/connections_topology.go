@@ -0,0 +1,228 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/corechecks"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+)
+
+const (
+	connectionsTopologyCheckID = "process_connections_topology"
+	processType                = "process"
+	endpointType               = "endpoint"
+)
+
+// ConnectionsTopologyCollector turns the connections of the ConnectionsCheck into process components and the
+// directional relations from the processes to the processes or remote endpoints they connect to
+type ConnectionsTopologyCollector struct {
+	corechecks.CheckTopologyCollector
+}
+
+// privateNetworks are the address ranges that are reused across networks: the private, shared (carrier-grade NAT),
+// link-local and unique local ranges
+var privateNetworks = parseNetworks("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "169.254.0.0/16",
+	"fc00::/7", "fe80::/10")
+
+// listenAddr is an address a process accepts connections on, either an ip on the host or a port of a container
+type listenAddr struct {
+	ip          string
+	containerID string
+	port        int32
+	connType    model.ConnectionType
+}
+
+// processKey identifies a process, pids are reused so the create time is part of the identity
+type processKey struct {
+	pid        int32
+	createTime int64
+}
+
+// MakeConnectionsTopologyCollector returns a new instance of ConnectionsTopologyCollector
+func MakeConnectionsTopologyCollector() *ConnectionsTopologyCollector {
+	return &ConnectionsTopologyCollector{
+		corechecks.MakeCheckProcessTopologyCollector(connectionsTopologyCheckID),
+	}
+}
+
+// BuildTopology produces the process topology derived from the connections, ctrIDForPID and nameForPID contain the
+// container and name of the processes that are known to the process and container checks. The networkID scopes the
+// endpoints with a private address, it is empty when the network of the host is unknown.
+func (ctc *ConnectionsTopologyCollector) BuildTopology(hostname, networkID string, conns []*model.Connection, ctrIDForPID map[int32]string,
+	nameForPID map[int32]string) error {
+	sender := batcher.GetBatcher()
+	if sender == nil {
+		return errors.New("no batcher instance available, skipping BuildTopology")
+	}
+
+	components, relations := ctc.buildTopology(hostname, networkID, conns, ctrIDForPID, nameForPID)
+	for _, component := range components {
+		sender.SubmitComponent(ctc.CheckID, ctc.TopologyInstance, *component)
+	}
+	for _, relation := range relations {
+		sender.SubmitRelation(ctc.CheckID, ctc.TopologyInstance, *relation)
+	}
+	sender.SubmitComplete(ctc.CheckID)
+
+	return nil
+}
+
+// buildTopology relates the client side of every outgoing connection to the server side. The server is the process
+// that accepts the connection on this host, either on the remote address itself or, when LocalResolver resolved the
+// remote address to a local container, on the same port within that container. Connections to servers that are not
+// on this host relate to an endpoint component for the remote address.
+func (ctc *ConnectionsTopologyCollector) buildTopology(hostname, networkID string, conns []*model.Connection, ctrIDForPID map[int32]string,
+	nameForPID map[int32]string) ([]*topology.Component, []*topology.Relation) {
+	listeners := make(map[listenAddr]*model.Connection)
+	for _, conn := range conns {
+		if conn.Direction != model.ConnectionDirection_incoming || conn.Laddr == nil {
+			continue
+		}
+		listeners[listenAddr{ip: conn.Laddr.Ip, port: conn.Laddr.Port, connType: conn.Type}] = conn
+		if containerID, found := ctrIDForPID[conn.Pid]; found {
+			listeners[listenAddr{containerID: containerID, port: conn.Laddr.Port, connType: conn.Type}] = conn
+		}
+	}
+
+	components := make([]*topology.Component, 0)
+	relations := make([]*topology.Relation, 0)
+	reported := make(map[string]bool)
+	addComponent := func(component *topology.Component) {
+		if !reported[component.ExternalID] {
+			reported[component.ExternalID] = true
+			components = append(components, component)
+		}
+	}
+	addRelation := func(relation *topology.Relation) {
+		if !reported[relation.ExternalID] {
+			reported[relation.ExternalID] = true
+			relations = append(relations, relation)
+		}
+	}
+
+	for _, conn := range conns {
+		if conn.Direction != model.ConnectionDirection_outgoing || conn.Raddr == nil {
+			continue
+		}
+
+		client := processToComponent(hostname, processKey{conn.Pid, conn.PidCreateTime}, ctrIDForPID[conn.Pid], nameForPID[conn.Pid])
+
+		server, found := listeners[listenAddr{ip: conn.Raddr.Ip, port: conn.Raddr.Port, connType: conn.Type}]
+		if !found && conn.Raddr.ContainerId != "" {
+			server, found = listeners[listenAddr{containerID: conn.Raddr.ContainerId, port: conn.Raddr.Port, connType: conn.Type}]
+		}
+
+		var target *topology.Component
+		switch {
+		case found && server.Pid == conn.Pid:
+			// a process connecting to itself does not add to the service map
+			continue
+		case found:
+			target = processToComponent(hostname, processKey{server.Pid, server.PidCreateTime}, ctrIDForPID[server.Pid], nameForPID[server.Pid])
+		case isLocalAddress(conn.Raddr.Ip):
+			// the server is on this host but unknown, an endpoint for it would collide with the same address on other hosts
+			continue
+		default:
+			target = endpointToComponent(hostname, networkID, conn.Raddr, conn.Type)
+		}
+
+		addComponent(client)
+		addComponent(target)
+		addRelation(createConnectionRelation(client.ExternalID, target.ExternalID, conn.Type))
+	}
+
+	return components, relations
+}
+
+// processToComponent produces a topology.Component for a process on the host
+func processToComponent(hostname string, key processKey, containerID, name string) *topology.Component {
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%d:%d", processType, hostname, key.pid, key.createTime),
+		Type:       topology.Type{Name: processType},
+		Data: topology.Data{
+			"pid":        key.pid,
+			"createTime": key.createTime,
+			"host":       hostname,
+		},
+	}
+	component.Data.PutNonEmpty("name", name)
+	component.Data.PutNonEmpty("containerId", containerID)
+	return component
+}
+
+// endpointToComponent produces a topology.Component for a remote address that is not on the host. A private address
+// only identifies an endpoint within its network, so it is scoped by the network id, or by the host when the network is
+// unknown, to keep the same address in different networks apart.
+func endpointToComponent(hostname, networkID string, addr *model.Addr, connType model.ConnectionType) *topology.Component {
+	component := &topology.Component{
+		ExternalID: fmt.Sprintf("urn:%s:/%s:%d", endpointType, addr.Ip, addr.Port),
+		Type:       topology.Type{Name: endpointType},
+		Data: topology.Data{
+			"ip":       addr.Ip,
+			"port":     addr.Port,
+			"protocol": connType.String(),
+		},
+	}
+
+	if isPrivateAddress(addr.Ip) {
+		if networkID != "" {
+			component.ExternalID = fmt.Sprintf("urn:%s:/%s:%s:%d", endpointType, networkID, addr.Ip, addr.Port)
+			component.Data["networkId"] = networkID
+		} else {
+			component.ExternalID = fmt.Sprintf("urn:%s:/%s:%s:%d", endpointType, hostname, addr.Ip, addr.Port)
+			component.Data["host"] = hostname
+		}
+	}
+
+	return component
+}
+
+// createConnectionRelation creates a directional topology.Relation from the client to the server of a connection
+func createConnectionRelation(clientExternalID, serverExternalID string, connType model.ConnectionType) *topology.Relation {
+	return &topology.Relation{
+		ExternalID: fmt.Sprintf("%s->%s", clientExternalID, serverExternalID),
+		SourceID:   clientExternalID,
+		TargetID:   serverExternalID,
+		Type:       topology.Type{Name: "connects_to"},
+		Data: topology.Data{
+			"protocol": connType.String(),
+		},
+	}
+}
+
+// isLocalAddress returns whether the ip is a loopback or unspecified address, these never identify another host
+func isLocalAddress(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && (parsed.IsLoopback() || parsed.IsUnspecified())
+}
+
+// isPrivateAddress returns whether the ip is in one of the privateNetworks
+func isPrivateAddress(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetworks parses the CIDR notations of address ranges
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
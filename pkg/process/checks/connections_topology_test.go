@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"testing"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/StackVista/stackstate-agent/pkg/batcher"
+	"github.com/StackVista/stackstate-agent/pkg/collector/check"
+	"github.com/StackVista/stackstate-agent/pkg/health"
+	"github.com/StackVista/stackstate-agent/pkg/topology"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeDirectedConnection(pid int32, createTime int64, direction model.ConnectionDirection, laddr, raddr *model.Addr) *model.Connection {
+	return &model.Connection{
+		Pid:           pid,
+		PidCreateTime: createTime,
+		Direction:     direction,
+		Type:          model.ConnectionType_tcp,
+		Laddr:         laddr,
+		Raddr:         raddr,
+	}
+}
+
+func TestMakeConnectionsTopologyCollector(t *testing.T) {
+	ctc := MakeConnectionsTopologyCollector()
+	assert.Equal(t, check.ID("process_connections_topology"), ctc.CheckID)
+	assert.Equal(t, topology.Instance{Type: "process", URL: "agents"}, ctc.TopologyInstance)
+}
+
+func TestConnectionsTopologyCollector_BuildTopology(t *testing.T) {
+	conns := []*model.Connection{
+		// the web server (1) accepts a connection from the load balancer (4) on the host ip
+		makeDirectedConnection(1, 100, model.ConnectionDirection_incoming,
+			&model.Addr{Ip: "10.0.0.1", Port: 8080}, &model.Addr{Ip: "10.0.0.1", Port: 50000}),
+		makeDirectedConnection(4, 400, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "10.0.0.1", Port: 50000}, &model.Addr{Ip: "10.0.0.1", Port: 8080}),
+		// the web server (1) connects to the database (2) in a container, the remote address is resolved to the
+		// container while the database accepts the connection on the container ip
+		makeDirectedConnection(1, 100, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "10.0.0.1", Port: 50001}, &model.Addr{Ip: "10.0.0.1", Port: 5432, ContainerId: "db"}),
+		makeDirectedConnection(2, 200, model.ConnectionDirection_incoming,
+			&model.Addr{Ip: "172.17.0.2", Port: 5432}, &model.Addr{Ip: "172.17.0.1", Port: 50001}),
+		// a second connection between the same processes results in the same relation
+		makeDirectedConnection(1, 100, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "10.0.0.1", Port: 50002}, &model.Addr{Ip: "10.0.0.1", Port: 5432, ContainerId: "db"}),
+		// the web server (1) connects to a remote api
+		makeDirectedConnection(1, 100, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "10.0.0.1", Port: 50003}, &model.Addr{Ip: "93.184.216.34", Port: 443}),
+		// connections to unknown local servers and to the process itself are left out
+		makeDirectedConnection(3, 300, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "127.0.0.1", Port: 50004}, &model.Addr{Ip: "127.0.0.1", Port: 6379}),
+		makeDirectedConnection(1, 100, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "10.0.0.1", Port: 50005}, &model.Addr{Ip: "10.0.0.1", Port: 8080}),
+		// the web server (1) connects to a cache on another host in the private network
+		makeDirectedConnection(1, 100, model.ConnectionDirection_outgoing,
+			&model.Addr{Ip: "10.0.0.1", Port: 50006}, &model.Addr{Ip: "10.0.1.5", Port: 6379}),
+	}
+	ctrIDForPID := map[int32]string{2: "db"}
+	nameForPID := map[int32]string{1: "nginx", 2: "postgres"}
+
+	mockBatcher := batcher.NewMockBatcher()
+
+	ctc := MakeConnectionsTopologyCollector()
+	err := ctc.BuildTopology("test-hostname", "test-network", conns, ctrIDForPID, nameForPID)
+	assert.NoError(t, err)
+
+	webID := "urn:process:/test-hostname:1:100"
+	dbID := "urn:process:/test-hostname:2:200"
+	lbID := "urn:process:/test-hostname:4:400"
+	apiID := "urn:endpoint:/93.184.216.34:443"
+	cacheID := "urn:endpoint:/test-network:10.0.1.5:6379"
+	relation := func(source, target string) topology.Relation {
+		return *createConnectionRelation(source, target, model.ConnectionType_tcp)
+	}
+
+	expectedTopology := batcher.CheckInstanceBatchStates(map[check.ID]batcher.CheckInstanceBatchState{
+		"process_connections_topology": {
+			Health: make(map[string]health.Health),
+			Topology: &topology.Topology{
+				StartSnapshot: false,
+				StopSnapshot:  false,
+				Instance:      topology.Instance{Type: "process", URL: "agents"},
+				Components: []topology.Component{
+					{
+						ExternalID: lbID,
+						Type:       topology.Type{Name: "process"},
+						Data:       topology.Data{"pid": int32(4), "createTime": int64(400), "host": "test-hostname"},
+					},
+					{
+						ExternalID: webID,
+						Type:       topology.Type{Name: "process"},
+						Data:       topology.Data{"pid": int32(1), "createTime": int64(100), "host": "test-hostname", "name": "nginx"},
+					},
+					{
+						ExternalID: dbID,
+						Type:       topology.Type{Name: "process"},
+						Data: topology.Data{
+							"pid":         int32(2),
+							"createTime":  int64(200),
+							"host":        "test-hostname",
+							"name":        "postgres",
+							"containerId": "db",
+						},
+					},
+					{
+						ExternalID: apiID,
+						Type:       topology.Type{Name: "endpoint"},
+						Data:       topology.Data{"ip": "93.184.216.34", "port": int32(443), "protocol": "tcp"},
+					},
+					{
+						ExternalID: cacheID,
+						Type:       topology.Type{Name: "endpoint"},
+						Data:       topology.Data{"ip": "10.0.1.5", "port": int32(6379), "protocol": "tcp", "networkId": "test-network"},
+					},
+				},
+				Relations: []topology.Relation{
+					relation(lbID, webID),
+					relation(webID, dbID),
+					relation(webID, apiID),
+					relation(webID, cacheID),
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, expectedTopology, mockBatcher.CollectedTopology.Flush())
+}
+
+func TestEndpointToComponent(t *testing.T) {
+	for _, tc := range []struct {
+		testCase   string
+		networkID  string
+		ip         string
+		expectedID string
+	}{
+		{"public address", "test-network", "93.184.216.34", "urn:endpoint:/93.184.216.34:443"},
+		{"private address in a known network", "test-network", "192.168.1.10", "urn:endpoint:/test-network:192.168.1.10:443"},
+		{"private address in an unknown network", "", "172.16.4.2", "urn:endpoint:/test-hostname:172.16.4.2:443"},
+		{"shared address", "", "100.64.0.7", "urn:endpoint:/test-hostname:100.64.0.7:443"},
+		{"link-local address", "", "169.254.169.254", "urn:endpoint:/test-hostname:169.254.169.254:443"},
+		{"unique local address", "", "fd00::1", "urn:endpoint:/test-hostname:fd00::1:443"},
+		{"global ipv6 address", "", "2606:2800:220:1::1", "urn:endpoint:/2606:2800:220:1::1:443"},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			component := endpointToComponent("test-hostname", tc.networkID, &model.Addr{Ip: tc.ip, Port: 443}, model.ConnectionType_tcp)
+			assert.Equal(t, tc.expectedID, component.ExternalID)
+		})
+	}
+}
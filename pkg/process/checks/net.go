@@ -8,6 +8,7 @@ import (
 
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/StackVista/stackstate-agent/pkg/ebpf"
+	"github.com/StackVista/stackstate-agent/pkg/network/encoding"
 	"github.com/StackVista/stackstate-agent/pkg/process/config"
 	"github.com/StackVista/stackstate-agent/pkg/process/dockerproxy"
 	"github.com/StackVista/stackstate-agent/pkg/process/net"
@@ -34,6 +35,9 @@ type ConnectionsCheck struct {
 	networkID              string
 	notInitializedLogLimit *procutil.LogLimit
 	lastTelemetry          *model.CollectorConnectionsTelemetry
+	// sts
+	// topologyCollector produces the process topology derived from the connections, nil when it is disabled
+	topologyCollector *ConnectionsTopologyCollector
 }
 
 // Init initializes a ConnectionsCheck instance.
@@ -53,6 +57,11 @@ func (c *ConnectionsCheck) Init(cfg *config.AgentConfig, _ *model.SystemInfo) {
 	}
 	c.networkID = networkID
 
+	// sts
+	if cfg.EnableConnectionsTopology {
+		c.topologyCollector = MakeConnectionsTopologyCollector()
+	}
+
 	// Run the check one time on init to register the client on the system probe
 	_, _ = c.Run(cfg, 0)
 }
@@ -71,7 +80,7 @@ func (c *ConnectionsCheck) RealTime() bool { return false }
 func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 	start := time.Now()
 
-	conns, err := c.getConnections()
+	conns, extension, err := c.getConnections()
 	if err != nil {
 		// If the tracer is not initialized, or still not initialized, then we want to exit without error'ing
 		if err == ebpf.ErrNotImplemented || err == ErrTracerStillNotInitialized {
@@ -80,6 +89,9 @@ func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.
 		return nil, err
 	}
 
+	// sts - the extended stats are kept per connection, the docker-proxy filter drops connections
+	extensions := connectionExtensions(conns.Conns, extension)
+
 	// Filter out (in-place) connection data associated with docker-proxy
 	dockerproxy.NewFilter().Filter(conns)
 	// Resolve the Raddr side of connections for local containers
@@ -87,17 +99,28 @@ func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.
 
 	tel := c.diffTelemetry(conns.Telemetry)
 
+	enrichedConns := c.enrichConnections(conns.Conns)
+
+	// sts
+	if c.topologyCollector != nil {
+		pids := connectionPIDs(enrichedConns)
+		err = c.topologyCollector.BuildTopology(cfg.HostName, c.networkID, enrichedConns, getCtrIDsByPIDs(pids), Process.namesForPIDs(pids))
+		if err != nil {
+			log.Warnf("could not produce the process topology of the connections: %s", err)
+		}
+	}
+
 	log.Debugf("collected connections in %s", time.Since(start))
-	return batchConnections(cfg, groupID, c.enrichConnections(conns.Conns), conns.Dns, c.networkID, tel), nil
+	return batchConnections(cfg, groupID, enrichedConns, extensions, conns.Dns, c.networkID, tel), nil
 }
 
-func (c *ConnectionsCheck) getConnections() (*model.Connections, error) {
+func (c *ConnectionsCheck) getConnections() (*model.Connections, *encoding.ConnectionsExtension, error) {
 	tu, err := net.GetRemoteSystemProbeUtil()
 	if err != nil {
 		if c.notInitializedLogLimit.ShouldLog() {
 			log.Warnf("could not initialize system-probe connection: %v (will only log every 10 minutes)", err)
 		}
-		return nil, ErrTracerStillNotInitialized
+		return nil, nil, ErrTracerStillNotInitialized
 	}
 	return tu.GetConnections(c.tracerClientID)
 }
@@ -153,10 +176,12 @@ func (c *ConnectionsCheck) saveTelemetry(tel *model.ConnectionsTelemetry) {
 }
 
 // Connections are split up into a chunks of a configured size conns per message to limit the message size on intake.
+// sts - a batch that has extended connection stats is sent as a CollectorConnections.
 func batchConnections(
 	cfg *config.AgentConfig,
 	groupID int32,
 	cxs []*model.Connection,
+	extensions map[*model.Connection]*encoding.ConnectionExtension,
 	dns map[string]*model.DNSEntry,
 	networkID string,
 	telemetry *model.CollectorConnectionsTelemetry,
@@ -194,7 +219,13 @@ func batchConnections(
 		if len(batches) == 0 {
 			cc.Telemetry = telemetry
 		}
-		batches = append(batches, cc)
+
+		// sts
+		if extension := batchExtension(batchConns, extensions); extension != nil {
+			batches = append(batches, &CollectorConnections{CollectorConnections: cc, Extension: extension})
+		} else {
+			batches = append(batches, cc)
+		}
 
 		cxs = cxs[batchSize:]
 	}
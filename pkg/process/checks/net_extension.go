@@ -0,0 +1,75 @@
+package checks
+
+import (
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/StackVista/stackstate-agent/pkg/network/encoding"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	"github.com/gogo/protobuf/proto"
+)
+
+// CollectorConnections is a batch of connections that carries the extended stats of the connections, the stats that
+// model.CollectorConnections has no fields for. The encoded extension is appended to the encoded
+// model.CollectorConnections, the same way the system probe appends it to model.Connections, so the intake decodes the
+// extension from the message body and decoders that only know model.CollectorConnections skip it.
+type CollectorConnections struct {
+	*model.CollectorConnections
+	// Extension extends the connection at the same index of CollectorConnections.Connections
+	Extension *encoding.ConnectionsExtension `json:"extension,omitempty"`
+}
+
+// Marshal encodes the connections followed by the extension, it is used by proto.Marshal
+func (m *CollectorConnections) Marshal() ([]byte, error) {
+	blob, err := m.CollectorConnections.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	extensionBlob, err := proto.Marshal(m.Extension)
+	if err != nil {
+		return nil, err
+	}
+	return append(blob, extensionBlob...), nil
+}
+
+// Size returns the size of the encoded connections and extension
+func (m *CollectorConnections) Size() int {
+	return m.CollectorConnections.Size() + proto.Size(m.Extension)
+}
+
+// connectionExtensions returns the extension of each connection, the extension is looked up by connection because the
+// docker-proxy filter drops connections from the payload. It returns nil when there is no extension or when the
+// extension does not match the connections.
+func connectionExtensions(conns []*model.Connection, extension *encoding.ConnectionsExtension) map[*model.Connection]*encoding.ConnectionExtension {
+	if extension == nil {
+		return nil
+	}
+	if len(extension.Conns) != len(conns) {
+		log.Warnf("dropping the extended connection stats, got the stats of %d connections for %d connections", len(extension.Conns), len(conns))
+		return nil
+	}
+
+	extensions := make(map[*model.Connection]*encoding.ConnectionExtension, len(conns))
+	for i, conn := range conns {
+		extensions[conn] = extension.Conns[i]
+	}
+	return extensions
+}
+
+// batchExtension returns the extension of a batch of connections, nil when none of the connections has an extension
+func batchExtension(batchConns []*model.Connection, extensions map[*model.Connection]*encoding.ConnectionExtension) *encoding.ConnectionsExtension {
+	extended := false
+	batchExtensions := make([]*encoding.ConnectionExtension, len(batchConns))
+	for i, conn := range batchConns {
+		if extension, ok := extensions[conn]; ok && extension != nil {
+			batchExtensions[i] = extension
+			extended = true
+		} else {
+			batchExtensions[i] = &encoding.ConnectionExtension{}
+		}
+	}
+
+	if !extended {
+		return nil
+	}
+	return &encoding.ConnectionsExtension{Conns: batchExtensions}
+}
@@ -0,0 +1,131 @@
+// +build linux
+
+package checks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/StackVista/stackstate-agent/pkg/network"
+	"github.com/StackVista/stackstate-agent/pkg/network/encoding"
+	"github.com/StackVista/stackstate-agent/pkg/process/config"
+	processnet "github.com/StackVista/stackstate-agent/pkg/process/net"
+	procutil "github.com/StackVista/stackstate-agent/pkg/process/util"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNetworkConnectionsExtension follows the extended connection stats from the system probe to the message body
+// that is sent to the intake
+func TestNetworkConnectionsExtension(t *testing.T) {
+	conns := &network.Connections{
+		Conns: []network.ConnectionStats{
+			{Pid: 1, Source: procutil.AddressFromString("10.1.1.1"), Dest: procutil.AddressFromString("10.2.2.2"), SPort: 1000, DPort: 9000},
+			{
+				Pid: 2, Source: procutil.AddressFromString("10.1.1.1"), Dest: procutil.AddressFromString("10.3.3.3"), SPort: 1001, DPort: 80,
+				ConnectionsOpened: 3,
+				FailedConnects:    1,
+				ResetsReceived:    2,
+				HTTPStatsByPath: map[string]network.HTTPStats{
+					"/api": {RequestCount: 4, StatusClassCounts: [5]uint32{0, 3, 0, 0, 1}},
+				},
+			},
+			{Pid: 3, Source: procutil.AddressFromString("10.1.1.1"), Dest: procutil.AddressFromString("10.4.4.4"), SPort: 1002, DPort: 53, ConnectionsClosed: 1},
+		},
+	}
+	marshaler := encoding.GetMarshaler(encoding.ContentTypeProtobuf)
+	blob, err := marshaler.Marshal(conns)
+	require.NoError(t, err)
+
+	// serve the encoded connections on the system probe socket
+	dir, err := ioutil.TempDir("", "sts-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "sysprobe.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {})
+	mux.HandleFunc("/connections", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-type", marshaler.ContentType())
+		_, _ = w.Write(blob)
+	})
+	go http.Serve(listener, mux) //nolint:errcheck
+
+	processnet.SetSystemProbePath(socketPath)
+	check := &ConnectionsCheck{tracerClientID: "1", notInitializedLogLimit: procutil.NewLogLimit(1, time.Minute)}
+	payload, extension, err := check.getConnections()
+	require.NoError(t, err)
+	require.Len(t, payload.Conns, 3)
+	require.NotNil(t, extension)
+
+	// the first connection is dropped, e.g. by the docker-proxy filter, after the extensions are looked up
+	extensions := connectionExtensions(payload.Conns, extension)
+	payload.Conns = payload.Conns[1:]
+
+	Process.lastCtrIDForPID = map[int32]string{}
+	for _, conn := range payload.Conns {
+		Process.lastCtrIDForPID[conn.Pid] = fmt.Sprintf("%d", conn.Pid)
+	}
+	Process.lastRun = time.Now()
+	cfg := config.NewDefaultAgentConfig(false)
+	cfg.MaxConnsPerMessage = 1
+
+	batches := batchConnections(cfg, 0, payload.Conns, extensions, payload.Dns, "nid", nil)
+	require.Len(t, batches, 2)
+
+	for i, batch := range batches {
+		require.IsType(t, &CollectorConnections{}, batch)
+
+		body, err := proto.Marshal(batch)
+		require.NoError(t, err)
+
+		// the intake decodes the connections and the extension from the same body
+		decoded := new(model.CollectorConnections)
+		require.NoError(t, proto.Unmarshal(body, decoded))
+		require.Len(t, decoded.Connections, 1)
+		assert.Equal(t, payload.Conns[i].Pid, decoded.Connections[0].Pid)
+		assert.Equal(t, "nid", decoded.NetworkId)
+		assert.Equal(t, len(body), batch.Size())
+
+		decodedExtension, err := encoding.GetUnmarshaler(encoding.ContentTypeProtobuf).UnmarshalExtension(body)
+		require.NoError(t, err)
+		require.Len(t, decodedExtension.Conns, 1)
+
+		switch decoded.Connections[0].Pid {
+		case 2:
+			assert.Equal(t, uint32(3), decodedExtension.Conns[0].ConnectionsOpened)
+			assert.Equal(t, uint32(1), decodedExtension.Conns[0].FailedConnects)
+			assert.Equal(t, uint32(2), decodedExtension.Conns[0].ResetsReceived)
+			require.Len(t, decodedExtension.Conns[0].HTTPStatsByPath, 1)
+			assert.Equal(t, "/api", decodedExtension.Conns[0].HTTPStatsByPath[0].PathPrefix)
+			assert.Equal(t, uint32(4), decodedExtension.Conns[0].HTTPStatsByPath[0].RequestCount)
+		case 3:
+			assert.Equal(t, uint32(1), decodedExtension.Conns[0].ConnectionsClosed)
+		default:
+			t.Errorf("unexpected connection of pid %d", decoded.Connections[0].Pid)
+		}
+	}
+}
+
+func TestNetworkConnectionBatchingWithoutExtension(t *testing.T) {
+	p := []*model.Connection{makeConnection(1), makeConnection(2)}
+	cfg := config.NewDefaultAgentConfig(false)
+	cfg.MaxConnsPerMessage = 1
+
+	extensions := connectionExtensions(p, &encoding.ConnectionsExtension{Conns: []*encoding.ConnectionExtension{{}}})
+	assert.Nil(t, extensions, "the extension does not match the connections")
+
+	for _, batch := range batchConnections(cfg, 0, p, extensions, map[string]*model.DNSEntry{}, "nid", nil) {
+		assert.IsType(t, &model.CollectorConnections{}, batch)
+	}
+}
@@ -74,7 +74,7 @@ func TestNetworkConnectionBatching(t *testing.T) {
 	} {
 		cfg.MaxConnsPerMessage = tc.maxSize
 		tm := &model.CollectorConnectionsTelemetry{}
-		chunks := batchConnections(cfg, 0, tc.cur, map[string]*model.DNSEntry{}, "nid", tm)
+		chunks := batchConnections(cfg, 0, tc.cur, nil, map[string]*model.DNSEntry{}, "nid", tm)
 
 		assert.Len(t, chunks, tc.expectedChunks, "len %d", i)
 		total := 0
@@ -124,7 +124,7 @@ func TestNetworkConnectionBatchingWithDNS(t *testing.T) {
 	cfg := config.NewDefaultAgentConfig(false)
 	cfg.MaxConnsPerMessage = 1
 
-	chunks := batchConnections(cfg, 0, p, dns, "nid", nil)
+	chunks := batchConnections(cfg, 0, p, nil, dns, "nid", nil)
 
 	assert.Len(t, chunks, 4)
 	total := 0
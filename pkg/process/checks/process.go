@@ -362,3 +362,18 @@ func (p *ProcessCheck) createTimesforPIDs(pids []int32) map[int32]int64 {
 	}
 	return createTimeForPID
 }
+
+// sts
+// namesForPIDs returns the names of the processes with the given pids that were seen in the last run
+func (p *ProcessCheck) namesForPIDs(pids []int32) map[int32]string {
+	p.Lock()
+	defer p.Unlock()
+
+	nameForPID := make(map[int32]string)
+	for _, pid := range pids {
+		if p, ok := p.lastProcs[pid]; ok {
+			nameForPID[pid] = p.Name
+		}
+	}
+	return nameForPID
+}
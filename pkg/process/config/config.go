@@ -94,8 +94,22 @@ type AgentConfig struct {
 	OffsetGuessThreshold           uint64
 
 	// DNS stats configuration
-	CollectDNSStats bool
-	DNSTimeout      time.Duration
+	CollectDNSStats      bool
+	DNSTimeout           time.Duration
+	MaxDNSDomainsTracked int
+
+	// sts - HTTP monitoring configuration
+	EnableHTTPMonitoring bool
+
+	// sts - Collect the failed connects, resets and zero window time of the TCP connections
+	CollectTCPFailures bool
+
+	// sts - Collapse the connections of a process to the same service
+	AggregateConnections bool
+
+	// sts
+	// Derive process topology from the connections and submit it through the batcher
+	EnableConnectionsTopology bool
 
 	// Orchestrator collection configuration
 	OrchestrationCollectionEnabled bool
@@ -206,6 +220,7 @@ func NewDefaultAgentConfig(canAccessContainers bool) *AgentConfig {
 		SystemProbeLogFile:    defaultSystemProbeFilePath,
 		MaxTrackedConnections: defaultMaxTrackedConnections,
 		EnableConntrack:       true,
+		CollectTCPFailures:    true,
 		ClosedChannelSize:     500,
 		ConntrackMaxStateSize: defaultMaxTrackedConnections * 2,
 		ConntrackRateLimit:    500,
@@ -406,6 +421,7 @@ func loadEnvVariables() {
 		{"DD_STRIP_PROCESS_ARGS", "process_config.strip_proc_arguments"},
 		{"DD_PROCESS_AGENT_URL", "process_config.process_dd_url"},
 		{"DD_ORCHESTRATOR_URL", "process_config.orchestrator_dd_url"},
+		{"DD_PROCESS_AGENT_CONNECTIONS_TOPOLOGY_ENABLED", "process_config.connections_topology_enabled"},
 		{"DD_HOSTNAME", "hostname"},
 		{"DD_DOGSTATSD_PORT", "dogstatsd_port"},
 		{"DD_BIND_HOST", "bind_host"},
@@ -47,11 +47,18 @@ func SysProbeConfigFromConfig(cfg *AgentConfig) *ebpf.Config {
 
 	tracerConfig.CollectLocalDNS = cfg.CollectLocalDNS
 	tracerConfig.CollectDNSStats = cfg.CollectDNSStats
+	tracerConfig.EnableHTTPMonitoring = cfg.EnableHTTPMonitoring
+	tracerConfig.AggregateConnections = cfg.AggregateConnections
+	tracerConfig.CollectTCPFailures = cfg.CollectTCPFailures
 
 	if to := cfg.DNSTimeout; to > 0 {
 		tracerConfig.DNSTimeout = cfg.DNSTimeout
 	}
 
+	if mdt := cfg.MaxDNSDomainsTracked; mdt > 0 {
+		tracerConfig.MaxDNSDomainsTracked = cfg.MaxDNSDomainsTracked
+	}
+
 	tracerConfig.MaxTrackedConnections = cfg.MaxTrackedConnections
 	tracerConfig.ProcRoot = util.GetProcRoot()
 	tracerConfig.BPFDebug = cfg.SysProbeBPFDebug
@@ -50,6 +50,14 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 	if config.Datadog.IsSet(key(spNS, "dns_timeout_in_s")) {
 		a.DNSTimeout = config.Datadog.GetDuration(key(spNS, "dns_timeout_in_s")) * time.Second
 	}
+	a.EnableHTTPMonitoring = config.Datadog.GetBool(key(spNS, "enable_http_monitoring"))
+	a.AggregateConnections = config.Datadog.GetBool(key(spNS, "aggregate_connections"))
+	if config.Datadog.IsSet(key(spNS, "collect_tcp_failures")) {
+		a.CollectTCPFailures = config.Datadog.GetBool(key(spNS, "collect_tcp_failures"))
+	}
+	if config.Datadog.IsSet(key(spNS, "max_dns_domains_tracked")) {
+		a.MaxDNSDomainsTracked = config.Datadog.GetInt(key(spNS, "max_dns_domains_tracked"))
+	}
 
 	if config.Datadog.GetBool(key(spNS, "enabled")) {
 		a.EnabledChecks = append(a.EnabledChecks, "connections")
@@ -344,6 +352,12 @@ func (a *AgentConfig) LoadProcessYamlConfig(path string) error {
 		}
 	}
 
+	// sts
+	// Derive process components and the relations between them from the connections
+	if k := key(ns, "connections_topology_enabled"); config.Datadog.IsSet(k) {
+		a.EnableConnectionsTopology = config.Datadog.GetBool(k)
+	}
+
 	// Used to override container source auto-detection
 	// and to enable multiple collector sources if needed.
 	// "docker", "ecs_fargate", "kubelet", "kubelet docker", etc.
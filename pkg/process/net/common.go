@@ -79,33 +79,44 @@ func GetRemoteSystemProbeUtil() (*RemoteSysProbeUtil, error) {
 	return globalUtil, nil
 }
 
-// GetConnections returns a set of active network connections, retrieved from the system probe service
-func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections, error) {
+// GetConnections returns a set of active network connections, retrieved from the system probe service. The extension
+// holds the stats of the connections that model.Connections has no fields for, it is nil when the system probe sent none.
+func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections, *encoding.ConnectionsExtension, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", connectionsURL, clientID), nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	req.Header.Set("Accept", contentTypeProtobuf)
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	} else if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("conn request failed: Probe Path %s, url: %s, status code: %d", r.path, connectionsURL, resp.StatusCode)
+		return nil, nil, fmt.Errorf("conn request failed: Probe Path %s, url: %s, status code: %d", r.path, connectionsURL, resp.StatusCode)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	contentType := resp.Header.Get("Content-type")
-	conns, err := encoding.GetUnmarshaler(contentType).Unmarshal(body)
+	unmarshaler := encoding.GetUnmarshaler(contentType)
+	conns, err := unmarshaler.Unmarshal(body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// sts - the extended stats are decoded from the same body, the model.Connections decoder skips them
+	extension, err := unmarshaler.UnmarshalExtension(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(extension.Conns) == 0 {
+		extension = nil
 	}
 
-	return conns, nil
+	return conns, extension, nil
 }
 
 // GetStats returns the expvar stats of the system probe
@@ -5,6 +5,7 @@ package net
 import (
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/StackVista/stackstate-agent/pkg/ebpf"
+	"github.com/StackVista/stackstate-agent/pkg/network/encoding"
 )
 
 // RemoteSysProbeUtil is not supported
@@ -26,8 +27,8 @@ func GetRemoteSystemProbeUtil() (*RemoteSysProbeUtil, error) {
 }
 
 // GetConnections is not supported
-func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections, error) {
-	return nil, ebpf.ErrNotImplemented
+func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections, *encoding.ConnectionsExtension, error) {
+	return nil, nil, ebpf.ErrNotImplemented
 }
 
 // GetStats is not supported
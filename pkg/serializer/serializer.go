@@ -105,6 +105,12 @@ type AgentV1Serializer interface {
 	SendJSONToV1Intake(data interface{}) error
 }
 
+// AgentV1DeliverySerializer is a serializer for agent v1 data that can wait until the data reached the intake
+type AgentV1DeliverySerializer interface {
+	AgentV1Serializer
+	SendJSONToV1IntakeAndWait(data interface{}) error
+}
+
 // AgentV1MockSerializer is a mock implementation of agent v1 serializer. USed for testing
 type AgentV1MockSerializer struct {
 	sendJSONToV1IntakeMessages chan interface{}
@@ -123,6 +129,11 @@ func (serializer AgentV1MockSerializer) SendJSONToV1Intake(data interface{}) err
 	return nil
 }
 
+// SendJSONToV1IntakeAndWait publishes v1 agent data, the mock delivers the data once it is published
+func (serializer AgentV1MockSerializer) SendJSONToV1IntakeAndWait(data interface{}) error {
+	return serializer.SendJSONToV1Intake(data)
+}
+
 // GetJSONToV1IntakeMessage gets message from the mock
 func (serializer AgentV1MockSerializer) GetJSONToV1IntakeMessage() interface{} {
 	select {
@@ -408,3 +419,25 @@ func (s *Serializer) SendJSONToV1Intake(data interface{}) error {
 	log.Debugf("Sent intake payload, content: %v", apiKeyRegExp.ReplaceAllString(string(payload), apiKeyReplacement))
 	return nil
 }
+
+// SendJSONToV1IntakeAndWait serializes a payload and sends it to the forwarder like SendJSONToV1Intake, but waits
+// until the payload reached the intake. The payload is not retried by the forwarder, an error is returned when it
+// could not be delivered.
+func (s *Serializer) SendJSONToV1IntakeAndWait(data interface{}) error {
+	if !s.enableJSONToV1Intake {
+		log.Debug("JSON to V1 intake endpoint payloads are disabled: dropping it")
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("could not serialize v1 payload: %s", err)
+	}
+	if err := s.Forwarder.SubmitV1IntakeAndWait(forwarder.Payloads{&payload}, jsonExtraHeaders); err != nil {
+		return err
+	}
+
+	log.Infof("Delivered intake payload, size: %d bytes.", len(payload))
+	log.Debugf("Delivered intake payload, content: %v", apiKeyRegExp.ReplaceAllString(string(payload), apiKeyReplacement))
+	return nil
+}
@@ -405,6 +405,24 @@ func TestSendJSONToV1Intake(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestSendJSONToV1IntakeAndWait(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	payload := []byte("\"test\"")
+	payloads, _ := mkPayloads(payload, false)
+	f.On("SubmitV1IntakeAndWait", payloads, jsonExtraHeaders).Return(nil).Times(1)
+
+	s := NewSerializer(f)
+
+	err := s.SendJSONToV1IntakeAndWait("test")
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+
+	f.On("SubmitV1IntakeAndWait", payloads, jsonExtraHeaders).Return(fmt.Errorf("some error")).Times(1)
+	err = s.SendJSONToV1IntakeAndWait("test")
+	require.NotNil(t, err)
+	f.AssertExpectations(t)
+}
+
 func TestSendWithDisabledKind(t *testing.T) {
 	mockConfig := config.Mock()
 
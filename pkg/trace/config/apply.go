@@ -380,6 +380,16 @@ func readInterpreterConfigYaml() *interpreterconfig.Config {
 		if ini.ServiceIdentifiers != nil {
 			conf.ServiceIdentifiers = ini.ServiceIdentifiers
 		}
+		conf.Interpreters = ini.Interpreters
+		if ini.ExternalServices.HostRules != nil {
+			conf.ExternalServices.HostRules = ini.ExternalServices.HostRules
+		}
+		if ini.ExternalServices.Allow != nil {
+			conf.ExternalServices.Allow = ini.ExternalServices.Allow
+		}
+		if ini.ExternalServices.Deny != nil {
+			conf.ExternalServices.Deny = ini.ExternalServices.Deny
+		}
 	}
 
 	return conf
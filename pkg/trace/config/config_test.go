@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/StackVista/stackstate-agent/pkg/config"
+	interpreterconfig "github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -218,6 +219,30 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(o.RemoveStackTraces)
 	assert.True(c.Obfuscation.Redis.Enabled)
 	assert.True(c.Obfuscation.Memcached.Enabled)
+
+	assert.Equal(&interpreterconfig.Config{
+		ServiceIdentifiers: []string{"db.instance", "db.name"},
+		Interpreters: []interpreterconfig.RuleInterpreterConfig{
+			{
+				Name: "envoy",
+				Kind: interpreterconfig.SourceInterpreterKind,
+				Rules: []interpreterconfig.InterpreterRuleConfig{
+					{
+						Match:              map[string]string{"upstream_cluster": "^outbound"},
+						ServiceName:        "${upstream_cluster}",
+						ServiceType:        "envoy",
+						ServiceInstanceURN: "urn:service-instance:/${upstream_cluster}:/${node_id}",
+					},
+				},
+			},
+		},
+		ExternalServices: interpreterconfig.ExternalServicesConfig{
+			HostRules: []interpreterconfig.HostRuleConfig{
+				{Match: "^[^.]+\\.execute-api\\.(.+)$", Replace: "execute-api.$1"},
+			},
+			Deny: []string{"\\.internal$"},
+		},
+	}, c.InterpreterConfig)
 }
 
 func TestUndocumentedYamlConfig(t *testing.T) {
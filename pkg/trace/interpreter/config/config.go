@@ -1,14 +1,73 @@
 package config
 
+// The kinds of rule interpreters, they determine how spans are routed to the interpreter
+const (
+	// TypeInterpreterKind registers the interpreter for spans with a matching span type
+	TypeInterpreterKind = "type"
+	// SourceInterpreterKind registers the interpreter for spans with a matching "source" meta
+	SourceInterpreterKind = "source"
+)
+
 // Config holds the configuration that allows the span interpreter
 // to interpret and enrich various span types.
 type Config struct {
-	ServiceIdentifiers []string `mapstructure:"service_identifiers"`
+	ServiceIdentifiers []string                `mapstructure:"service_identifiers"`
+	Interpreters       []RuleInterpreterConfig `mapstructure:"interpreters"`
+	ExternalServices   ExternalServicesConfig  `mapstructure:"external_services"`
+}
+
+// ExternalServicesConfig determines which hosts called by http client spans are external services and how their hosts
+// are normalized. Allow and Deny hold regular expressions matched against the normalized host, a denied host is never
+// an external service and when Allow is not empty only the allowed hosts are
+type ExternalServicesConfig struct {
+	HostRules []HostRuleConfig `mapstructure:"host_rules"`
+	Allow     []string         `mapstructure:"allow"`
+	Deny      []string         `mapstructure:"deny"`
+}
+
+// HostRuleConfig rewrites a host matching the Match regular expression to Replace, in which $1 refers to the first
+// submatch. For example the rule {Match: "^[^.]+\\.execute-api\\.(.+)$", Replace: "execute-api.$1"} maps all api
+// gateway hosts of a region to a single external service
+type HostRuleConfig struct {
+	Match   string `mapstructure:"match"`
+	Replace string `mapstructure:"replace"`
+}
+
+// RuleInterpreterConfig declares a span interpreter that applies the first of its rules matching a span
+type RuleInterpreterConfig struct {
+	// Name is the span type or span source the interpreter is registered for
+	Name string `mapstructure:"name"`
+	// Kind is either TypeInterpreterKind or SourceInterpreterKind
+	Kind  string                  `mapstructure:"kind"`
+	Rules []InterpreterRuleConfig `mapstructure:"rules"`
+}
+
+// InterpreterRuleConfig maps span meta to the service fields of a span. Match holds regular expressions that meta
+// values must match for the rule to apply. The other fields are templates in which ${key} is replaced by the value of
+// the span meta key, the rule does not apply when a referenced key is absent. Empty templates are not applied
+type InterpreterRuleConfig struct {
+	Match              map[string]string `mapstructure:"match"`
+	ServiceName        string            `mapstructure:"service_name"`
+	ServiceURN         string            `mapstructure:"service_urn"`
+	ServiceType        string            `mapstructure:"service_type"`
+	ServiceInstanceURN string            `mapstructure:"service_instance_urn"`
 }
 
 // DefaultInterpreterConfig creates the default config
 func DefaultInterpreterConfig() *Config {
 	return &Config{
 		ServiceIdentifiers: []string{"db.instance"},
+		ExternalServices: ExternalServicesConfig{
+			Deny: []string{
+				// loopback
+				"^localhost$", "^127\\.", "^::1$",
+				// private ranges 10/8, 172.16/12, 192.168/16 and fc00::/7
+				"^10\\.", "^172\\.(1[6-9]|2[0-9]|3[01])\\.", "^192\\.168\\.", "^f[cd][0-9a-f]{2}:",
+				// link-local ranges 169.254/16 and fe80::/10
+				"^169\\.254\\.", "^fe[89ab][0-9a-f]:",
+				// single-label hosts resolved through search domains, and internal domains
+				"^[^.:]+$", "\\.internal$", "\\.svc(\\.cluster\\.local)?$",
+			},
+		},
 	}
 }
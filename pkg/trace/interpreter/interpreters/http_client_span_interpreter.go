@@ -0,0 +1,163 @@
+package interpreters
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+)
+
+// HTTPClientSpanInterpreter sets up the http client span interpreter
+type HTTPClientSpanInterpreter struct {
+	interpreter
+	process   *ProcessSpanInterpreter
+	hostRules []hostRule
+	allow     []*regexp.Regexp
+	deny      []*regexp.Regexp
+}
+
+// HTTPClientSpanInterpreterName is the name used for matching this interpreter
+const HTTPClientSpanInterpreterName = "http"
+
+// ExternalServiceTypeName returns the external service type
+const ExternalServiceTypeName = "external-service"
+
+// ClientSpanKind is the kind of spans that make outgoing calls
+const ClientSpanKind = "client"
+
+// hostRule is the compiled form of a config.HostRuleConfig
+type hostRule struct {
+	match   *regexp.Regexp
+	replace string
+}
+
+// MakeHTTPClientSpanInterpreter creates an instance of the http client span interpreter. Invalid host rules and
+// allow or deny expressions are skipped
+func MakeHTTPClientSpanInterpreter(config *config.Config) *HTTPClientSpanInterpreter {
+	in := &HTTPClientSpanInterpreter{
+		interpreter: interpreter{Config: config},
+		process:     MakeProcessSpanInterpreter(config),
+	}
+
+	for _, rule := range config.ExternalServices.HostRules {
+		match, err := regexp.Compile(rule.Match)
+		if err != nil {
+			log.Warnf("Skipping external service host rule %q: %s", rule.Match, err)
+			continue
+		}
+		in.hostRules = append(in.hostRules, hostRule{match: match, replace: rule.Replace})
+	}
+	in.allow = compileHostExpressions("allow", config.ExternalServices.Allow)
+	in.deny = compileHostExpressions("deny", config.ExternalServices.Deny)
+
+	return in
+}
+
+func compileHostExpressions(list string, expressions []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			log.Warnf("Skipping external service %s expression %q: %s", list, expression, err)
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// CreateExternalServiceURN creates the urn identifier for external service components
+func (in *HTTPClientSpanInterpreter) CreateExternalServiceURN(host, port string) string {
+	return fmt.Sprintf("urn:%s:/%s:%s", ExternalServiceTypeName, host, port)
+}
+
+// Interpret performs the interpretation for the HTTPClientSpanInterpreter, client spans calling an external service
+// are tagged with the peer they call
+func (in *HTTPClientSpanInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	// no meta, add a empty map
+	if span.Meta == nil {
+		span.Meta = map[string]string{}
+	}
+
+	in.process.Interpret(span)
+
+	if span.Kind != ClientSpanKind {
+		return span.Span
+	}
+	rawURL, found := span.Meta["http.url"]
+	if !found {
+		return span.Span
+	}
+	host, port, ok := in.peer(rawURL)
+	if !ok || !in.isExternal(host) {
+		return span.Span
+	}
+
+	span.Meta["span.peerHostname"] = host
+	span.Meta["span.peerPort"] = port
+	span.Meta["span.peerServiceType"] = ExternalServiceTypeName
+	span.Meta["span.peerServiceURN"] = in.CreateExternalServiceURN(host, port)
+
+	return span.Span
+}
+
+// peer returns the normalized host and the port of the url, the port defaults to the one of the url scheme
+func (in *HTTPClientSpanInterpreter) peer(rawURL string) (string, string, bool) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", false
+	}
+
+	host := in.normalizeHost(parsedURL.Hostname())
+	if host == "" {
+		return "", "", false
+	}
+
+	port := parsedURL.Port()
+	if port == "" {
+		switch strings.ToLower(parsedURL.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return "", "", false
+		}
+	}
+
+	return host, port, true
+}
+
+// normalizeHost lower cases the host and rewrites it with the first matching host rule
+func (in *HTTPClientSpanInterpreter) normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, rule := range in.hostRules {
+		if rule.match.MatchString(host) {
+			return rule.match.ReplaceAllString(host, rule.replace)
+		}
+	}
+	return host
+}
+
+// isExternal checks the host against the deny list and, when it is not empty, the allow list
+func (in *HTTPClientSpanInterpreter) isExternal(host string) bool {
+	for _, re := range in.deny {
+		if re.MatchString(host) {
+			return false
+		}
+	}
+	if len(in.allow) == 0 {
+		return true
+	}
+	for _, re := range in.allow {
+		if re.MatchString(host) {
+			return true
+		}
+	}
+	return false
+}
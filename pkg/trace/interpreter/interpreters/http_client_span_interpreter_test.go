@@ -0,0 +1,160 @@
+package interpreters
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClientSpanInterpreter(t *testing.T) {
+	interpreterConfig := config.DefaultInterpreterConfig()
+	interpreterConfig.ExternalServices.HostRules = []config.HostRuleConfig{
+		{Match: "^[^.]+\\.execute-api\\.(.+)$", Replace: "execute-api.$1"},
+		{Match: "(", Replace: "invalid"},
+	}
+	interpreterConfig.ExternalServices.Deny = append(interpreterConfig.ExternalServices.Deny, "\\.internal\\.example\\.com$")
+	httpClientInterpreter := MakeHTTPClientSpanInterpreter(interpreterConfig)
+
+	for _, tc := range []struct {
+		testCase string
+		kind     string
+		url      string
+		expected map[string]string
+	}{
+		{
+			testCase: "Should tag a client span with the external service of the url",
+			kind:     "client",
+			url:      "https://API.Stripe.com./v1/charges",
+			expected: map[string]string{
+				"span.peerHostname":    "api.stripe.com",
+				"span.peerPort":        "443",
+				"span.peerServiceType": "external-service",
+				"span.peerServiceURN":  "urn:external-service:/api.stripe.com:443",
+			},
+		},
+		{
+			testCase: "Should use the explicit port of the url",
+			kind:     "client",
+			url:      "http://maps.example.org:8080/geocode",
+			expected: map[string]string{
+				"span.peerHostname":    "maps.example.org",
+				"span.peerPort":        "8080",
+				"span.peerServiceType": "external-service",
+				"span.peerServiceURN":  "urn:external-service:/maps.example.org:8080",
+			},
+		},
+		{
+			testCase: "Should normalize the host with the first matching host rule",
+			kind:     "client",
+			url:      "https://a1b2c3.execute-api.eu-west-1.amazonaws.com/prod/orders",
+			expected: map[string]string{
+				"span.peerHostname":    "execute-api.eu-west-1.amazonaws.com",
+				"span.peerPort":        "443",
+				"span.peerServiceType": "external-service",
+				"span.peerServiceURN":  "urn:external-service:/execute-api.eu-west-1.amazonaws.com:443",
+			},
+		},
+		{
+			testCase: "Should not tag a call to a denied host",
+			kind:     "client",
+			url:      "http://billing.internal.example.com/invoices",
+		},
+		{
+			testCase: "Should not tag a call to a kubernetes service with the default deny list",
+			kind:     "client",
+			url:      "http://billing.shop.svc.cluster.local:8080/invoices",
+		},
+		{
+			testCase: "Should not tag a server span",
+			kind:     "server",
+			url:      "https://api.stripe.com/v1/charges",
+		},
+		{
+			testCase: "Should not tag a client span without url",
+			kind:     "client",
+		},
+		{
+			testCase: "Should not tag a url with an unknown default port",
+			kind:     "client",
+			url:      "ftp://files.example.org/export.csv",
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			meta := map[string]string{"span.serviceName": "checkout"}
+			expected := map[string]string{
+				"span.serviceName":        "checkout",
+				"span.serviceType":        "service",
+				"span.serviceInstanceURN": "urn:service-instance:/checkout:/hostname:10:1586441095",
+			}
+			if tc.url != "" {
+				meta["http.url"] = tc.url
+				expected["http.url"] = tc.url
+			}
+			for key, value := range tc.expected {
+				expected[key] = value
+			}
+
+			span := model.SpanWithMeta{
+				Span: &pb.Span{Service: "checkout", Type: "http", Meta: meta},
+				SpanMetadata: &model.SpanMetadata{
+					CreateTime: 1586441095,
+					Hostname:   "hostname",
+					PID:        10,
+					Type:       "http",
+					Kind:       tc.kind,
+				},
+			}
+			actual := httpClientInterpreter.Interpret(&span)
+			assert.EqualValues(t, expected, actual.Meta)
+		})
+	}
+}
+
+func TestHTTPClientSpanInterpreterAllowList(t *testing.T) {
+	interpreterConfig := config.DefaultInterpreterConfig()
+	interpreterConfig.ExternalServices.Allow = []string{"\\.stripe\\.com$"}
+	httpClientInterpreter := MakeHTTPClientSpanInterpreter(interpreterConfig)
+
+	for _, tc := range []struct {
+		host     string
+		external bool
+	}{
+		{host: "api.stripe.com", external: true},
+		{host: "api.github.com", external: false},
+		{host: "localhost", external: false},
+	} {
+		assert.Equal(t, tc.external, httpClientInterpreter.isExternal(tc.host), tc.host)
+	}
+}
+
+func TestHTTPClientSpanInterpreterDefaultDenyList(t *testing.T) {
+	httpClientInterpreter := MakeHTTPClientSpanInterpreter(config.DefaultInterpreterConfig())
+
+	for _, tc := range []struct {
+		host     string
+		external bool
+	}{
+		{host: "api.stripe.com", external: true},
+		{host: "8.8.8.8", external: true},
+		{host: "172.32.0.1", external: true},
+		{host: "2001:db8::1", external: true},
+		{host: "localhost", external: false},
+		{host: "127.0.0.1", external: false},
+		{host: "::1", external: false},
+		{host: "10.1.2.3", external: false},
+		{host: "172.16.0.1", external: false},
+		{host: "172.31.255.255", external: false},
+		{host: "192.168.1.1", external: false},
+		{host: "fd12:3456::1", external: false},
+		{host: "169.254.169.254", external: false},
+		{host: "fe80::1", external: false},
+		{host: "reviews", external: false},
+		{host: "metadata.google.internal", external: false},
+		{host: "reviews.default.svc.cluster.local", external: false},
+	} {
+		assert.Equal(t, tc.external, httpClientInterpreter.isExternal(tc.host), tc.host)
+	}
+}
@@ -0,0 +1,58 @@
+package interpreters
+
+import (
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+)
+
+// KafkaSpanInterpreter sets up the kafka span interpreter
+type KafkaSpanInterpreter struct {
+	messagingInterpreter
+}
+
+// KafkaSpanInterpreterName is the name used for matching this interpreter
+const KafkaSpanInterpreterName = "kafka"
+
+// MakeKafkaSpanInterpreter creates an instance of the kafka span interpreter
+func MakeKafkaSpanInterpreter(config *config.Config) *KafkaSpanInterpreter {
+	return &KafkaSpanInterpreter{makeMessagingInterpreter(config)}
+}
+
+// Interpret performs the interpretation for the KafkaSpanInterpreter
+func (in *KafkaSpanInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	// no meta, add a empty map
+	if span.Meta == nil {
+		span.Meta = map[string]string{}
+	}
+
+	destination, found := in.destination(span)
+	return in.interpretDestination(span, destination, found)
+}
+
+// destination finds the kafka topic of the span, the role falls back to the produce or consume span name
+func (in *KafkaSpanInterpreter) destination(span *model.SpanWithMeta) (*messagingDestination, bool) {
+	topic, found := firstMeta(span.Span, "kafka.topic", "messaging.kafka.topic")
+	if !found {
+		if system := span.Meta["messaging.system"]; system != KafkaSpanInterpreterName {
+			return nil, false
+		}
+		if topic, found = firstMeta(span.Span, "messaging.destination", "messaging.destination.name"); !found {
+			return nil, false
+		}
+	}
+
+	role := messagingRole(span)
+	if role == "" {
+		name := strings.ToLower(span.Name)
+		if strings.Contains(name, "produce") {
+			role = ProducerRole
+		} else if strings.Contains(name, "consume") {
+			role = ConsumerRole
+		}
+	}
+
+	return &messagingDestination{System: KafkaSpanInterpreterName, Type: TopicTypeName, Name: topic, Role: role}, true
+}
@@ -0,0 +1,65 @@
+package interpreters
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaSpanInterpreter(t *testing.T) {
+	kafkaInterpreter := MakeKafkaSpanInterpreter(config.DefaultInterpreterConfig())
+	producedSpan := messagingSpan("kafka", "client", map[string]string{"kafka.topic": "orders"})
+	producedSpan.Name = "kafka.produce"
+
+	for _, tc := range []struct {
+		testCase string
+		span     *model.SpanWithMeta
+		expected map[string]string
+	}{
+		{
+			testCase: "Should tag a producer span with the topic",
+			span:     messagingSpan("kafka", "producer", map[string]string{"kafka.topic": "orders"}),
+			expected: expectedMessagingMeta(map[string]string{"kafka.topic": "orders"}, map[string]string{
+				"span.messagingSystem":          "kafka",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "topic",
+				"span.messagingDestinationName": "orders",
+				"span.messagingDestinationURN":  "urn:topic:/kafka:orders",
+			}),
+		},
+		{
+			testCase: "Should tag a consumer span with the topic of the messaging destination",
+			span:     messagingSpan("kafka", "consumer", map[string]string{"messaging.system": "kafka", "messaging.destination": "orders"}),
+			expected: expectedMessagingMeta(map[string]string{"messaging.system": "kafka", "messaging.destination": "orders"}, map[string]string{
+				"span.messagingSystem":          "kafka",
+				"span.messagingRole":            "consumer",
+				"span.messagingDestinationType": "topic",
+				"span.messagingDestinationName": "orders",
+				"span.messagingDestinationURN":  "urn:topic:/kafka:orders",
+			}),
+		},
+		{
+			testCase: "Should derive the role from the span name if the kind is not producer or consumer",
+			span:     producedSpan,
+			expected: expectedMessagingMeta(map[string]string{"kafka.topic": "orders"}, map[string]string{
+				"span.messagingSystem":          "kafka",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "topic",
+				"span.messagingDestinationName": "orders",
+				"span.messagingDestinationURN":  "urn:topic:/kafka:orders",
+			}),
+		},
+		{
+			testCase: "Should not tag a span without topic",
+			span:     messagingSpan("kafka", "producer", nil),
+			expected: expectedMessagingMeta(nil, nil),
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			actual := kafkaInterpreter.Interpret(tc.span)
+			assert.EqualValues(t, tc.expected, actual.Meta)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package interpreters
+
+import (
+	"fmt"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+)
+
+// MessagingSpanInterpreterName is the name used for matching this interpreter
+const MessagingSpanInterpreterName = "queue"
+
+// The types of messaging destinations
+const (
+	TopicTypeName    = "topic"
+	QueueTypeName    = "queue"
+	ExchangeTypeName = "exchange"
+)
+
+// The roles of spans that send or receive messages
+const (
+	ProducerRole = "producer"
+	ConsumerRole = "consumer"
+)
+
+// messagingDestination is the topic, queue or exchange a messaging span sends to or receives from
+type messagingDestination struct {
+	System string
+	Type   string
+	Name   string
+	Role   string
+}
+
+// messagingSystemInterpreter finds the destination of the spans of a specific messaging system
+type messagingSystemInterpreter interface {
+	destination(span *model.SpanWithMeta) (*messagingDestination, bool)
+}
+
+// messagingInterpreter interprets the span as a process span and tags it with its messaging destination
+type messagingInterpreter struct {
+	interpreter
+	process *ProcessSpanInterpreter
+}
+
+func makeMessagingInterpreter(config *config.Config) messagingInterpreter {
+	return messagingInterpreter{interpreter: interpreter{Config: config}, process: MakeProcessSpanInterpreter(config)}
+}
+
+// CreateMessagingDestinationURN creates the urn identifier for topic, queue and exchange components
+func (in *messagingInterpreter) CreateMessagingDestinationURN(destinationType, system, name string) string {
+	return fmt.Sprintf("urn:%s:/%s:%s", destinationType, system, name)
+}
+
+// interpretDestination interprets the span with the process interpreter and tags it with the destination, when the
+// role of the span is unknown the destination is not tagged
+func (in *messagingInterpreter) interpretDestination(span *model.SpanWithMeta, destination *messagingDestination, found bool) *pb.Span {
+	in.process.Interpret(span)
+
+	if !found || destination.Role == "" {
+		return span.Span
+	}
+
+	span.Meta["span.messagingSystem"] = destination.System
+	span.Meta["span.messagingRole"] = destination.Role
+	span.Meta["span.messagingDestinationType"] = destination.Type
+	span.Meta["span.messagingDestinationName"] = destination.Name
+	span.Meta["span.messagingDestinationURN"] = in.CreateMessagingDestinationURN(destination.Type, destination.System, destination.Name)
+
+	return span.Span
+}
+
+// messagingRole determines the role from the span kind, producer and consumer kinds are used as is
+func messagingRole(span *model.SpanWithMeta) string {
+	switch span.Kind {
+	case ProducerRole, ConsumerRole:
+		return span.Kind
+	default:
+		return ""
+	}
+}
+
+// firstMeta returns the value of the first of the meta keys present on the span
+func firstMeta(span *pb.Span, keys ...string) (string, bool) {
+	for _, key := range keys {
+		if value, found := span.Meta[key]; found && value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// MessagingSpanInterpreter interprets the generic queue spans, it delegates to the interpreter of the messaging system
+// that recognizes the span
+type MessagingSpanInterpreter struct {
+	messagingInterpreter
+	systems []messagingSystemInterpreter
+}
+
+// MakeMessagingSpanInterpreter creates an instance of the messaging span interpreter
+func MakeMessagingSpanInterpreter(config *config.Config) *MessagingSpanInterpreter {
+	return &MessagingSpanInterpreter{
+		messagingInterpreter: makeMessagingInterpreter(config),
+		systems: []messagingSystemInterpreter{
+			MakeKafkaSpanInterpreter(config),
+			MakeRabbitMQSpanInterpreter(config),
+			MakeSQSSpanInterpreter(config),
+		},
+	}
+}
+
+// Interpret performs the interpretation for the MessagingSpanInterpreter
+func (in *MessagingSpanInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	// no meta, add a empty map
+	if span.Meta == nil {
+		span.Meta = map[string]string{}
+	}
+
+	for _, system := range in.systems {
+		if destination, found := system.destination(span); found {
+			return in.interpretDestination(span, destination, found)
+		}
+	}
+	return in.interpretDestination(span, nil, false)
+}
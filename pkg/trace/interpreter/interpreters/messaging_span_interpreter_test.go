@@ -0,0 +1,97 @@
+package interpreters
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+// messagingSpan creates a span of the given type and kind that was already interpreted by the default interpreter
+func messagingSpan(spanType, kind string, meta map[string]string) *model.SpanWithMeta {
+	spanMeta := map[string]string{"span.serviceName": "span-service"}
+	for key, value := range meta {
+		spanMeta[key] = value
+	}
+	return &model.SpanWithMeta{
+		Span: &pb.Span{Name: "span-name", Service: "span-service", Type: spanType, Meta: spanMeta},
+		SpanMetadata: &model.SpanMetadata{
+			CreateTime: 1586441095,
+			Hostname:   "hostname",
+			PID:        10,
+			Type:       spanType,
+			Kind:       kind,
+		},
+	}
+}
+
+// expectedMessagingMeta adds the meta set by the process interpreter and the messaging destination to the span meta
+func expectedMessagingMeta(meta map[string]string, destination map[string]string) map[string]string {
+	expected := map[string]string{
+		"span.serviceName":        "span-service",
+		"span.serviceType":        "service",
+		"span.serviceInstanceURN": "urn:service-instance:/span-service:/hostname:10:1586441095",
+	}
+	for key, value := range meta {
+		expected[key] = value
+	}
+	for key, value := range destination {
+		expected[key] = value
+	}
+	return expected
+}
+
+func TestMessagingSpanInterpreter(t *testing.T) {
+	messagingInterpreter := MakeMessagingSpanInterpreter(config.DefaultInterpreterConfig())
+	for _, tc := range []struct {
+		testCase string
+		span     *model.SpanWithMeta
+		expected map[string]string
+	}{
+		{
+			testCase: "Should delegate to the kafka interpreter for spans with a kafka topic",
+			span:     messagingSpan("queue", "consumer", map[string]string{"kafka.topic": "orders"}),
+			expected: expectedMessagingMeta(map[string]string{"kafka.topic": "orders"}, map[string]string{
+				"span.messagingSystem":          "kafka",
+				"span.messagingRole":            "consumer",
+				"span.messagingDestinationType": "topic",
+				"span.messagingDestinationName": "orders",
+				"span.messagingDestinationURN":  "urn:topic:/kafka:orders",
+			}),
+		},
+		{
+			testCase: "Should delegate to the rabbitmq interpreter for amqp spans",
+			span:     messagingSpan("queue", "producer", map[string]string{"amqp.exchange": "events"}),
+			expected: expectedMessagingMeta(map[string]string{"amqp.exchange": "events"}, map[string]string{
+				"span.messagingSystem":          "rabbitmq",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "exchange",
+				"span.messagingDestinationName": "events",
+				"span.messagingDestinationURN":  "urn:exchange:/rabbitmq:events",
+			}),
+		},
+		{
+			testCase: "Should delegate to the sqs interpreter for sqs spans",
+			span:     messagingSpan("queue", "client", map[string]string{"aws.service": "SQS", "aws.operation": "SendMessage", "aws.queue.name": "payments"}),
+			expected: expectedMessagingMeta(map[string]string{"aws.service": "SQS", "aws.operation": "SendMessage", "aws.queue.name": "payments"}, map[string]string{
+				"span.messagingSystem":          "sqs",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "queue",
+				"span.messagingDestinationName": "payments",
+				"span.messagingDestinationURN":  "urn:queue:/sqs:payments",
+			}),
+		},
+		{
+			testCase: "Should only interpret the process of unknown messaging spans",
+			span:     messagingSpan("queue", "producer", map[string]string{"component": "nats"}),
+			expected: expectedMessagingMeta(map[string]string{"component": "nats"}, nil),
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			actual := messagingInterpreter.Interpret(tc.span)
+			assert.EqualValues(t, tc.expected, actual.Meta)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package interpreters
+
+import (
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+)
+
+// RabbitMQSpanInterpreter sets up the rabbitmq span interpreter
+type RabbitMQSpanInterpreter struct {
+	messagingInterpreter
+}
+
+// RabbitMQSpanInterpreterName is the name used for matching this interpreter
+const RabbitMQSpanInterpreterName = "rabbitmq"
+
+// MakeRabbitMQSpanInterpreter creates an instance of the rabbitmq span interpreter
+func MakeRabbitMQSpanInterpreter(config *config.Config) *RabbitMQSpanInterpreter {
+	return &RabbitMQSpanInterpreter{makeMessagingInterpreter(config)}
+}
+
+// Interpret performs the interpretation for the RabbitMQSpanInterpreter
+func (in *RabbitMQSpanInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	// no meta, add a empty map
+	if span.Meta == nil {
+		span.Meta = map[string]string{}
+	}
+
+	destination, found := in.destination(span)
+	return in.interpretDestination(span, destination, found)
+}
+
+// destination finds the exchange a producer publishes to, or the queue a consumer receives from. Messages published
+// to the default exchange are routed to the queue named by the routing key, so that queue is the destination instead
+func (in *RabbitMQSpanInterpreter) destination(span *model.SpanWithMeta) (*messagingDestination, bool) {
+	_, isAMQP := firstMeta(span.Span, "amqp.command", "amqp.exchange", "amqp.queue", "amqp.routing_key")
+	if !isAMQP && span.Meta["messaging.system"] != RabbitMQSpanInterpreterName {
+		return nil, false
+	}
+
+	role := messagingRole(span)
+	if role == "" {
+		switch span.Meta["amqp.command"] {
+		case "basic.publish":
+			role = ProducerRole
+		case "basic.deliver", "basic.get", "basic.consume":
+			role = ConsumerRole
+		}
+	}
+
+	if role == ConsumerRole {
+		if queue, found := firstMeta(span.Span, "amqp.queue", "messaging.destination", "messaging.destination.name"); found {
+			return &messagingDestination{System: RabbitMQSpanInterpreterName, Type: QueueTypeName, Name: queue, Role: role}, true
+		}
+		return nil, false
+	}
+
+	if exchange, found := firstMeta(span.Span, "amqp.exchange", "messaging.destination", "messaging.destination.name"); found {
+		return &messagingDestination{System: RabbitMQSpanInterpreterName, Type: ExchangeTypeName, Name: exchange, Role: role}, true
+	}
+	if routingKey, found := firstMeta(span.Span, "amqp.routing_key", "messaging.rabbitmq.routing_key"); found {
+		return &messagingDestination{System: RabbitMQSpanInterpreterName, Type: QueueTypeName, Name: routingKey, Role: role}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,62 @@
+package interpreters
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRabbitMQSpanInterpreter(t *testing.T) {
+	rabbitMQInterpreter := MakeRabbitMQSpanInterpreter(config.DefaultInterpreterConfig())
+	for _, tc := range []struct {
+		testCase string
+		span     *model.SpanWithMeta
+		expected map[string]string
+	}{
+		{
+			testCase: "Should tag a publish span with the exchange",
+			span:     messagingSpan("rabbitmq", "client", map[string]string{"amqp.command": "basic.publish", "amqp.exchange": "events", "amqp.routing_key": "order.created"}),
+			expected: expectedMessagingMeta(map[string]string{"amqp.command": "basic.publish", "amqp.exchange": "events", "amqp.routing_key": "order.created"}, map[string]string{
+				"span.messagingSystem":          "rabbitmq",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "exchange",
+				"span.messagingDestinationName": "events",
+				"span.messagingDestinationURN":  "urn:exchange:/rabbitmq:events",
+			}),
+		},
+		{
+			testCase: "Should tag a publish span on the default exchange with the queue of the routing key",
+			span:     messagingSpan("rabbitmq", "producer", map[string]string{"amqp.exchange": "", "amqp.routing_key": "invoices"}),
+			expected: expectedMessagingMeta(map[string]string{"amqp.exchange": "", "amqp.routing_key": "invoices"}, map[string]string{
+				"span.messagingSystem":          "rabbitmq",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "queue",
+				"span.messagingDestinationName": "invoices",
+				"span.messagingDestinationURN":  "urn:queue:/rabbitmq:invoices",
+			}),
+		},
+		{
+			testCase: "Should tag a deliver span with the queue",
+			span:     messagingSpan("rabbitmq", "server", map[string]string{"amqp.command": "basic.deliver", "amqp.exchange": "events", "amqp.queue": "billing"}),
+			expected: expectedMessagingMeta(map[string]string{"amqp.command": "basic.deliver", "amqp.exchange": "events", "amqp.queue": "billing"}, map[string]string{
+				"span.messagingSystem":          "rabbitmq",
+				"span.messagingRole":            "consumer",
+				"span.messagingDestinationType": "queue",
+				"span.messagingDestinationName": "billing",
+				"span.messagingDestinationURN":  "urn:queue:/rabbitmq:billing",
+			}),
+		},
+		{
+			testCase: "Should not tag a span of which the role is unknown",
+			span:     messagingSpan("rabbitmq", "client", map[string]string{"amqp.command": "queue.declare", "amqp.queue": "billing"}),
+			expected: expectedMessagingMeta(map[string]string{"amqp.command": "queue.declare", "amqp.queue": "billing"}, nil),
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			actual := rabbitMQInterpreter.Interpret(tc.span)
+			assert.EqualValues(t, tc.expected, actual.Meta)
+		})
+	}
+}
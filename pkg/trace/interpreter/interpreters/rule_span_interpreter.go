@@ -0,0 +1,198 @@
+package interpreters
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+)
+
+var templateKeyRegexp = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+type interpreterRule struct {
+	match              map[string]*regexp.Regexp
+	serviceName        string
+	serviceURN         string
+	serviceType        string
+	serviceInstanceURN string
+}
+
+// ruleInterpreter applies the first rule of a rule interpreter config that matches a span
+type ruleInterpreter struct {
+	interpreter
+	rules []interpreterRule
+}
+
+// RuleTypeSpanInterpreter is a rule interpreter for spans of a given type
+type RuleTypeSpanInterpreter struct {
+	ruleInterpreter
+	fallback TypeInterpreter
+}
+
+// RuleSourceSpanInterpreter is a rule interpreter for spans of a given source
+type RuleSourceSpanInterpreter struct {
+	ruleInterpreter
+	fallback SourceInterpreter
+}
+
+// MakeRuleTypeSpanInterpreter creates a type interpreter from a rule interpreter config, spans that match no rule are
+// interpreted by the fallback interpreter when it is not nil
+func MakeRuleTypeSpanInterpreter(config *config.Config, ruleConfig config.RuleInterpreterConfig, fallback TypeInterpreter) (*RuleTypeSpanInterpreter, error) {
+	in, err := makeRuleInterpreter(config, ruleConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleTypeSpanInterpreter{ruleInterpreter: in, fallback: fallback}, nil
+}
+
+// MakeRuleSourceSpanInterpreter creates a source interpreter from a rule interpreter config, spans that match no rule
+// are interpreted by the fallback interpreter when it is not nil
+func MakeRuleSourceSpanInterpreter(config *config.Config, ruleConfig config.RuleInterpreterConfig, fallback SourceInterpreter) (*RuleSourceSpanInterpreter, error) {
+	in, err := makeRuleInterpreter(config, ruleConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleSourceSpanInterpreter{ruleInterpreter: in, fallback: fallback}, nil
+}
+
+func makeRuleInterpreter(conf *config.Config, ruleConfig config.RuleInterpreterConfig) (ruleInterpreter, error) {
+	if ruleConfig.Name == "" {
+		return ruleInterpreter{}, fmt.Errorf("span interpreter has no name")
+	}
+	if len(ruleConfig.Rules) == 0 {
+		return ruleInterpreter{}, fmt.Errorf("span interpreter %s has no rules", ruleConfig.Name)
+	}
+
+	rules := make([]interpreterRule, 0, len(ruleConfig.Rules))
+	for i, ruleConf := range ruleConfig.Rules {
+		rule := interpreterRule{
+			match:              make(map[string]*regexp.Regexp, len(ruleConf.Match)),
+			serviceName:        ruleConf.ServiceName,
+			serviceURN:         ruleConf.ServiceURN,
+			serviceType:        ruleConf.ServiceType,
+			serviceInstanceURN: ruleConf.ServiceInstanceURN,
+		}
+		for key, pattern := range ruleConf.Match {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return ruleInterpreter{}, fmt.Errorf("span interpreter %s rule %d: match %q: %s", ruleConfig.Name, i, key, err)
+			}
+			rule.match[key] = re
+		}
+		rules = append(rules, rule)
+	}
+
+	return ruleInterpreter{interpreter: interpreter{Config: conf}, rules: rules}, nil
+}
+
+// Interpret performs the interpretation for the RuleTypeSpanInterpreter
+func (in *RuleTypeSpanInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	if !in.interpretSpan(span.Span) && in.fallback != nil {
+		return in.fallback.Interpret(span)
+	}
+	return span.Span
+}
+
+// Interpret performs the interpretation for the RuleSourceSpanInterpreter
+func (in *RuleSourceSpanInterpreter) Interpret(spans []*pb.Span) []*pb.Span {
+	interpreted := make([]*pb.Span, 0, len(spans))
+	unmatched := make([]*pb.Span, 0)
+	for _, span := range spans {
+		if in.interpretSpan(span) || in.fallback == nil {
+			interpreted = append(interpreted, span)
+		} else {
+			unmatched = append(unmatched, span)
+		}
+	}
+
+	if len(unmatched) > 0 {
+		interpreted = append(interpreted, in.fallback.Interpret(unmatched)...)
+	}
+	return interpreted
+}
+
+// interpretSpan applies the first matching rule, a span that matches no rule is left untouched. Returns whether a rule
+// matched the span
+func (in *ruleInterpreter) interpretSpan(span *pb.Span) bool {
+	// no meta, add a empty map
+	if span.Meta == nil {
+		span.Meta = map[string]string{}
+	}
+
+	for _, rule := range in.rules {
+		if values, ok := rule.apply(span); ok {
+			for key, value := range values {
+				span.Meta[key] = value
+			}
+			if _, found := values["span.serviceName"]; found && rule.serviceURN == "" {
+				span.Meta["span.serviceURN"] = in.CreateServiceURN(values["span.serviceName"])
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// apply returns the meta values the rule sets on the span, or false if the rule does not match the span
+func (rule interpreterRule) apply(span *pb.Span) (map[string]string, bool) {
+	for key, re := range rule.match {
+		value, found := lookupSpanValue(span, key)
+		if !found || !re.MatchString(value) {
+			return nil, false
+		}
+	}
+
+	values := make(map[string]string, 4)
+	for key, template := range map[string]string{
+		"span.serviceName":        rule.serviceName,
+		"span.serviceURN":         rule.serviceURN,
+		"span.serviceType":        rule.serviceType,
+		"span.serviceInstanceURN": rule.serviceInstanceURN,
+	} {
+		if template == "" {
+			continue
+		}
+		value, ok := expandTemplate(span, template)
+		if !ok {
+			return nil, false
+		}
+		values[key] = value
+	}
+	return values, true
+}
+
+// expandTemplate replaces the ${key} references in the template, returns false if a key can't be found
+func expandTemplate(span *pb.Span, template string) (string, bool) {
+	complete := true
+	expanded := templateKeyRegexp.ReplaceAllStringFunc(template, func(reference string) string {
+		value, found := lookupSpanValue(span, templateKeyRegexp.FindStringSubmatch(reference)[1])
+		if !found {
+			complete = false
+		}
+		return value
+	})
+	return expanded, complete
+}
+
+// lookupSpanValue looks up a span meta key, span.service, span.name, span.resource and span.type fall back to the
+// fields of the span when they are not in the meta
+func lookupSpanValue(span *pb.Span, key string) (string, bool) {
+	if value, found := span.Meta[key]; found {
+		return value, true
+	}
+
+	switch key {
+	case "span.service":
+		return span.Service, true
+	case "span.name":
+		return span.Name, true
+	case "span.resource":
+		return span.Resource, true
+	case "span.type":
+		return span.Type, true
+	}
+	return "", false
+}
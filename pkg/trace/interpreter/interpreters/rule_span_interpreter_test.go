@@ -0,0 +1,196 @@
+package interpreters
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeRuleSpanInterpreterErrors(t *testing.T) {
+	for _, tc := range []struct {
+		testCase   string
+		ruleConfig config.RuleInterpreterConfig
+		expected   string
+	}{
+		{
+			testCase:   "Should fail without a name",
+			ruleConfig: config.RuleInterpreterConfig{Rules: []config.InterpreterRuleConfig{{ServiceType: "envoy"}}},
+			expected:   "span interpreter has no name",
+		},
+		{
+			testCase:   "Should fail without rules",
+			ruleConfig: config.RuleInterpreterConfig{Name: "envoy"},
+			expected:   "span interpreter envoy has no rules",
+		},
+		{
+			testCase: "Should fail on an invalid match expression",
+			ruleConfig: config.RuleInterpreterConfig{
+				Name:  "envoy",
+				Rules: []config.InterpreterRuleConfig{{Match: map[string]string{"component": "("}}},
+			},
+			expected: "span interpreter envoy rule 0: match \"component\": error parsing regexp: missing closing ): `(`",
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			_, err := MakeRuleTypeSpanInterpreter(config.DefaultInterpreterConfig(), tc.ruleConfig, nil)
+			assert.EqualError(t, err, tc.expected)
+			_, err = MakeRuleSourceSpanInterpreter(config.DefaultInterpreterConfig(), tc.ruleConfig, nil)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestRuleTypeSpanInterpreter(t *testing.T) {
+	ruleInterpreter, err := MakeRuleTypeSpanInterpreter(config.DefaultInterpreterConfig(), config.RuleInterpreterConfig{
+		Name: "envoy",
+		Kind: config.TypeInterpreterKind,
+		Rules: []config.InterpreterRuleConfig{
+			{
+				Match:              map[string]string{"upstream_cluster": "^outbound\\|"},
+				ServiceName:        "${upstream_cluster.name}",
+				ServiceType:        "envoy",
+				ServiceInstanceURN: "urn:service-instance:/${upstream_cluster.name}:/${span.hostname}",
+			},
+			{
+				Match:       map[string]string{"component": "^proxy$"},
+				ServiceURN:  "urn:service:/envoy/${span.service}",
+				ServiceType: "envoy",
+			},
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		testCase string
+		meta     map[string]string
+		expected map[string]string
+	}{
+		{
+			testCase: "Should apply the first matching rule",
+			meta: map[string]string{
+				"upstream_cluster":      "outbound|8080||reviews",
+				"upstream_cluster.name": "reviews",
+				"component":             "proxy",
+				"span.hostname":         "hostname",
+			},
+			expected: map[string]string{
+				"upstream_cluster":        "outbound|8080||reviews",
+				"upstream_cluster.name":   "reviews",
+				"component":               "proxy",
+				"span.hostname":           "hostname",
+				"span.serviceName":        "reviews",
+				"span.serviceURN":         "urn:service:/reviews",
+				"span.serviceType":        "envoy",
+				"span.serviceInstanceURN": "urn:service-instance:/reviews:/hostname",
+			},
+		},
+		{
+			testCase: "Should skip a rule that references a missing meta key",
+			meta: map[string]string{
+				"upstream_cluster": "outbound|8080||reviews",
+				"component":        "proxy",
+			},
+			expected: map[string]string{
+				"upstream_cluster": "outbound|8080||reviews",
+				"component":        "proxy",
+				"span.serviceURN":  "urn:service:/envoy/productpage",
+				"span.serviceType": "envoy",
+			},
+		},
+		{
+			testCase: "Should leave the span untouched when no rule matches",
+			meta: map[string]string{
+				"upstream_cluster": "inbound|9080||",
+				"component":        "router",
+			},
+			expected: map[string]string{
+				"upstream_cluster": "inbound|9080||",
+				"component":        "router",
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			span := model.SpanWithMeta{
+				Span:         &pb.Span{Service: "productpage", Meta: tc.meta},
+				SpanMetadata: &model.SpanMetadata{Type: "envoy"},
+			}
+			actual := ruleInterpreter.Interpret(&span)
+			assert.EqualValues(t, tc.expected, actual.Meta)
+		})
+	}
+}
+
+func TestRuleSourceSpanInterpreter(t *testing.T) {
+	ruleInterpreter, err := MakeRuleSourceSpanInterpreter(config.DefaultInterpreterConfig(), config.RuleInterpreterConfig{
+		Name: "nginx-ingress",
+		Kind: config.SourceInterpreterKind,
+		Rules: []config.InterpreterRuleConfig{
+			{
+				ServiceName: "${ingress.name}:${span.resource}",
+				ServiceType: "nginx",
+			},
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	spans := []*pb.Span{
+		{Resource: "GET /", Meta: map[string]string{"ingress.name": "shop"}},
+		{Resource: "GET /"},
+	}
+	actual := ruleInterpreter.Interpret(spans)
+
+	assert.EqualValues(t, []*pb.Span{
+		{
+			Resource: "GET /",
+			Meta: map[string]string{
+				"ingress.name":     "shop",
+				"span.serviceName": "shop:GET /",
+				"span.serviceURN":  "urn:service:/shop:GET /",
+				"span.serviceType": "nginx",
+			},
+		},
+		{Resource: "GET /", Meta: map[string]string{}},
+	}, actual)
+}
+
+type fallbackTypeInterpreter struct{}
+
+func (fallbackTypeInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	span.Meta["span.serviceType"] = "fallback"
+	return span.Span
+}
+
+type fallbackSourceInterpreter struct{}
+
+func (fallbackSourceInterpreter) Interpret(spans []*pb.Span) []*pb.Span {
+	for _, span := range spans {
+		span.Meta["span.serviceType"] = "fallback"
+	}
+	return spans
+}
+
+func TestRuleSpanInterpreterFallback(t *testing.T) {
+	ruleConfig := config.RuleInterpreterConfig{
+		Name:  "sql",
+		Rules: []config.InterpreterRuleConfig{{Match: map[string]string{"db.type": "^cassandra$"}, ServiceType: "cassandra"}},
+	}
+	typeInterpreter, err := MakeRuleTypeSpanInterpreter(config.DefaultInterpreterConfig(), ruleConfig, fallbackTypeInterpreter{})
+	assert.NoError(t, err)
+	sourceInterpreter, err := MakeRuleSourceSpanInterpreter(config.DefaultInterpreterConfig(), ruleConfig, fallbackSourceInterpreter{})
+	assert.NoError(t, err)
+
+	matched := &pb.Span{Meta: map[string]string{"db.type": "cassandra"}}
+	unmatched := &pb.Span{Meta: map[string]string{"db.type": "postgresql"}}
+	assert.Equal(t, "cassandra", typeInterpreter.Interpret(&model.SpanWithMeta{Span: matched}).Meta["span.serviceType"])
+	assert.Equal(t, "fallback", typeInterpreter.Interpret(&model.SpanWithMeta{Span: unmatched}).Meta["span.serviceType"])
+
+	matched = &pb.Span{Meta: map[string]string{"db.type": "cassandra"}}
+	unmatched = &pb.Span{Meta: map[string]string{"db.type": "postgresql"}}
+	actual := sourceInterpreter.Interpret([]*pb.Span{unmatched, matched})
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "cassandra", matched.Meta["span.serviceType"])
+	assert.Equal(t, "fallback", unmatched.Meta["span.serviceType"])
+}
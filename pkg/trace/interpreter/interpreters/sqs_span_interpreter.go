@@ -0,0 +1,70 @@
+package interpreters
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+)
+
+// SQSSpanInterpreter sets up the sqs span interpreter
+type SQSSpanInterpreter struct {
+	messagingInterpreter
+}
+
+// SQSSpanInterpreterName is the name used for matching this interpreter
+const SQSSpanInterpreterName = "sqs"
+
+// MakeSQSSpanInterpreter creates an instance of the sqs span interpreter
+func MakeSQSSpanInterpreter(config *config.Config) *SQSSpanInterpreter {
+	return &SQSSpanInterpreter{makeMessagingInterpreter(config)}
+}
+
+// Interpret performs the interpretation for the SQSSpanInterpreter
+func (in *SQSSpanInterpreter) Interpret(span *model.SpanWithMeta) *pb.Span {
+	// no meta, add a empty map
+	if span.Meta == nil {
+		span.Meta = map[string]string{}
+	}
+
+	destination, found := in.destination(span)
+	return in.interpretDestination(span, destination, found)
+}
+
+// destination finds the sqs queue of the span, from its name or else the last segment of its url. The role falls back
+// to the aws operation
+func (in *SQSSpanInterpreter) destination(span *model.SpanWithMeta) (*messagingDestination, bool) {
+	service := strings.ToLower(span.Meta["aws.service"])
+	if service != SQSSpanInterpreterName && service != "amazonsqs" && span.Meta["messaging.system"] != "aws_sqs" {
+		return nil, false
+	}
+
+	queue, found := firstMeta(span.Span, "aws.queue.name", "aws.sqs.queue_name", "queuename", "messaging.destination", "messaging.destination.name")
+	if !found {
+		queueURL, found := firstMeta(span.Span, "aws.queue.url", "aws.sqs.queue_url", "messaging.url")
+		if !found {
+			return nil, false
+		}
+		parsedURL, err := url.Parse(queueURL)
+		if err != nil || path.Base(parsedURL.Path) == "/" || path.Base(parsedURL.Path) == "." {
+			return nil, false
+		}
+		queue = path.Base(parsedURL.Path)
+	}
+
+	role := messagingRole(span)
+	if role == "" {
+		operation, _ := firstMeta(span.Span, "aws.operation", "aws_operation")
+		switch operation {
+		case "SendMessage", "SendMessageBatch":
+			role = ProducerRole
+		case "ReceiveMessage":
+			role = ConsumerRole
+		}
+	}
+
+	return &messagingDestination{System: SQSSpanInterpreterName, Type: QueueTypeName, Name: queue, Role: role}, true
+}
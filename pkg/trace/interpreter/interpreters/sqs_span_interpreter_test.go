@@ -0,0 +1,56 @@
+package interpreters
+
+import (
+	"testing"
+
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQSSpanInterpreter(t *testing.T) {
+	sqsInterpreter := MakeSQSSpanInterpreter(config.DefaultInterpreterConfig())
+	for _, tc := range []struct {
+		testCase string
+		span     *model.SpanWithMeta
+		expected map[string]string
+	}{
+		{
+			testCase: "Should tag a send message span with the queue name",
+			span:     messagingSpan("sqs", "client", map[string]string{"aws.service": "sqs", "aws.operation": "SendMessageBatch", "aws.sqs.queue_name": "payments"}),
+			expected: expectedMessagingMeta(map[string]string{"aws.service": "sqs", "aws.operation": "SendMessageBatch", "aws.sqs.queue_name": "payments"}, map[string]string{
+				"span.messagingSystem":          "sqs",
+				"span.messagingRole":            "producer",
+				"span.messagingDestinationType": "queue",
+				"span.messagingDestinationName": "payments",
+				"span.messagingDestinationURN":  "urn:queue:/sqs:payments",
+			}),
+		},
+		{
+			testCase: "Should tag a receive message span with the queue from the queue url",
+			span:     messagingSpan("sqs", "client", map[string]string{"aws.service": "AmazonSQS", "aws.operation": "ReceiveMessage", "aws.queue.url": "https://sqs.eu-west-1.amazonaws.com/123456789012/payments"}),
+			expected: expectedMessagingMeta(map[string]string{"aws.service": "AmazonSQS", "aws.operation": "ReceiveMessage", "aws.queue.url": "https://sqs.eu-west-1.amazonaws.com/123456789012/payments"}, map[string]string{
+				"span.messagingSystem":          "sqs",
+				"span.messagingRole":            "consumer",
+				"span.messagingDestinationType": "queue",
+				"span.messagingDestinationName": "payments",
+				"span.messagingDestinationURN":  "urn:queue:/sqs:payments",
+			}),
+		},
+		{
+			testCase: "Should not tag a span of another aws service",
+			span:     messagingSpan("sqs", "client", map[string]string{"aws.service": "s3", "aws.operation": "PutObject"}),
+			expected: expectedMessagingMeta(map[string]string{"aws.service": "s3", "aws.operation": "PutObject"}, nil),
+		},
+		{
+			testCase: "Should not tag a span without queue",
+			span:     messagingSpan("sqs", "client", map[string]string{"aws.service": "sqs", "aws.operation": "ListQueues"}),
+			expected: expectedMessagingMeta(map[string]string{"aws.service": "sqs", "aws.operation": "ListQueues"}, nil),
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			actual := sqsInterpreter.Interpret(tc.span)
+			assert.EqualValues(t, tc.expected, actual.Meta)
+		})
+	}
+}
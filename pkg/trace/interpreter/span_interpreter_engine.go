@@ -6,6 +6,7 @@ import (
 	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/interpreters"
 	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/model"
 	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
 	"github.com/golang/protobuf/proto"
 )
 
@@ -33,12 +34,49 @@ func NewSpanInterpreterEngine(agentConfig *config.AgentConfig) *SpanInterpreterE
 	typeIns := make(map[string]interpreters.TypeInterpreter, 0)
 	typeIns[interpreters.ProcessSpanInterpreterName] = interpreters.MakeProcessSpanInterpreter(interpreterConf)
 	typeIns[interpreters.SQLSpanInterpreterName] = interpreters.MakeSQLSpanInterpreter(interpreterConf)
+	typeIns[interpreters.MessagingSpanInterpreterName] = interpreters.MakeMessagingSpanInterpreter(interpreterConf)
+	typeIns[interpreters.KafkaSpanInterpreterName] = interpreters.MakeKafkaSpanInterpreter(interpreterConf)
+	typeIns[interpreters.RabbitMQSpanInterpreterName] = interpreters.MakeRabbitMQSpanInterpreter(interpreterConf)
+	typeIns[interpreters.SQSSpanInterpreterName] = interpreters.MakeSQSSpanInterpreter(interpreterConf)
+	typeIns[interpreters.HTTPClientSpanInterpreterName] = interpreters.MakeHTTPClientSpanInterpreter(interpreterConf)
 	sourceIns := make(map[string]interpreters.SourceInterpreter, 0)
 	sourceIns[interpreters.TraefikSpanInterpreterSpan] = interpreters.MakeTraefikInterpreter(interpreterConf)
+	registerRuleInterpreters(interpreterConf, typeIns, sourceIns)
 
 	return MakeSpanInterpreterEngine(interpreterConf, typeIns, sourceIns)
 }
 
+// registerRuleInterpreters adds the rule interpreters of the config, they take precedence over the interpreters
+// registered for the same name. Spans that match no rule fall back to the interpreter that was registered for the name
+// before, or to the process interpreter for types without interpreter. Invalid interpreters are skipped
+func registerRuleInterpreters(interpreterConf *interpreterConfig.Config, typeIns map[string]interpreters.TypeInterpreter, sourceIns map[string]interpreters.SourceInterpreter) {
+	for _, ruleConf := range interpreterConf.Interpreters {
+		switch ruleConf.Kind {
+		case interpreterConfig.TypeInterpreterKind:
+			fallback, found := typeIns[ruleConf.Name]
+			if !found {
+				fallback = typeIns[interpreters.ProcessSpanInterpreterName]
+			}
+			in, err := interpreters.MakeRuleTypeSpanInterpreter(interpreterConf, ruleConf, fallback)
+			if err != nil {
+				log.Warnf("Skipping span interpreter: %s", err)
+				continue
+			}
+			typeIns[ruleConf.Name] = in
+		case interpreterConfig.SourceInterpreterKind:
+			in, err := interpreters.MakeRuleSourceSpanInterpreter(interpreterConf, ruleConf, sourceIns[ruleConf.Name])
+			if err != nil {
+				log.Warnf("Skipping span interpreter: %s", err)
+				continue
+			}
+			sourceIns[ruleConf.Name] = in
+		default:
+			log.Warnf("Skipping span interpreter %s: unknown kind %q, expected %q or %q", ruleConf.Name, ruleConf.Kind,
+				interpreterConfig.TypeInterpreterKind, interpreterConfig.SourceInterpreterKind)
+		}
+	}
+}
+
 // Interpret interprets the trace using the configured SpanInterpreterEngine
 func (se *SpanInterpreterEngine) Interpret(origTrace pb.Trace) pb.Trace {
 
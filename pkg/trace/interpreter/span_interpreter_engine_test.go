@@ -2,6 +2,8 @@ package interpreter
 
 import (
 	"github.com/StackVista/stackstate-agent/pkg/trace/config"
+	interpreterConfig "github.com/StackVista/stackstate-agent/pkg/trace/interpreter/config"
+	"github.com/StackVista/stackstate-agent/pkg/trace/interpreter/interpreters"
 	"github.com/StackVista/stackstate-agent/pkg/trace/pb"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -56,6 +58,73 @@ func TestSpanInterpreterEngine(t *testing.T) {
 				},
 			},
 		},
+		{
+			testCase: "Should run the kafka span interpreter if we have metadata and the type is 'kafka'",
+			span: pb.Span{
+				Service: "order-service",
+				Type:    "kafka",
+				Meta: map[string]string{
+					"span.starttime": "1586441095", //Thursday, 9 April 2020 14:04:55
+					"span.hostname":  "hostname",
+					"span.pid":       "10",
+					"span.kind":      "producer",
+					"kafka.topic":    "orders",
+				},
+			},
+			expected: pb.Span{
+				Service: "order-service",
+				Type:    "kafka",
+				Meta: map[string]string{
+					"span.serviceName":              "order-service",
+					"span.starttime":                "1586441095", //Thursday, 9 April 2020 14:04:55
+					"span.hostname":                 "hostname",
+					"span.pid":                      "10",
+					"span.kind":                     "producer",
+					"kafka.topic":                   "orders",
+					"span.serviceType":              "service",
+					"span.serviceURN":               "urn:service:/order-service",
+					"span.serviceInstanceURN":       "urn:service-instance:/order-service:/hostname:10:1586441095",
+					"span.messagingSystem":          "kafka",
+					"span.messagingRole":            "producer",
+					"span.messagingDestinationType": "topic",
+					"span.messagingDestinationName": "orders",
+					"span.messagingDestinationURN":  "urn:topic:/kafka:orders",
+				},
+			},
+		},
+		{
+			testCase: "Should run the http client span interpreter if we have metadata and the type is 'http'",
+			span: pb.Span{
+				Service: "checkout",
+				Type:    "http",
+				Meta: map[string]string{
+					"span.starttime": "1586441095", //Thursday, 9 April 2020 14:04:55
+					"span.hostname":  "hostname",
+					"span.pid":       "10",
+					"span.kind":      "client",
+					"http.url":       "https://api.stripe.com/v1/charges",
+				},
+			},
+			expected: pb.Span{
+				Service: "checkout",
+				Type:    "http",
+				Meta: map[string]string{
+					"span.serviceName":        "checkout",
+					"span.starttime":          "1586441095", //Thursday, 9 April 2020 14:04:55
+					"span.hostname":           "hostname",
+					"span.pid":                "10",
+					"span.kind":               "client",
+					"http.url":                "https://api.stripe.com/v1/charges",
+					"span.serviceType":        "service",
+					"span.serviceURN":         "urn:service:/checkout",
+					"span.serviceInstanceURN": "urn:service-instance:/checkout:/hostname:10:1586441095",
+					"span.peerHostname":       "api.stripe.com",
+					"span.peerPort":           "443",
+					"span.peerServiceType":    "external-service",
+					"span.peerServiceURN":     "urn:external-service:/api.stripe.com:443",
+				},
+			},
+		},
 		{
 			testCase: "Should run the process span interpreter if we have metadata and the type is 'web'",
 			span: pb.Span{
@@ -185,3 +254,171 @@ func TestSpanInterpreterEngine(t *testing.T) {
 		})
 	}
 }
+
+func TestSpanInterpreterEngineRuleInterpreters(t *testing.T) {
+	agentConfig := config.New()
+	agentConfig.InterpreterConfig.Interpreters = []interpreterConfig.RuleInterpreterConfig{
+		{
+			Name: "grpc",
+			Kind: interpreterConfig.TypeInterpreterKind,
+			Rules: []interpreterConfig.InterpreterRuleConfig{
+				{
+					Match:       map[string]string{"rpc.service": ".+"},
+					ServiceName: "${rpc.service}",
+					ServiceType: "grpc",
+				},
+			},
+		},
+		{
+			Name: "nginx-ingress",
+			Kind: interpreterConfig.SourceInterpreterKind,
+			Rules: []interpreterConfig.InterpreterRuleConfig{
+				{
+					ServiceName:        "${ingress.name}",
+					ServiceType:        "nginx",
+					ServiceInstanceURN: "urn:service-instance:/${ingress.name}:/${span.hostname}",
+				},
+			},
+		},
+		// replaces the built-in sql interpreter, which still interprets the spans that match no rule
+		{
+			Name: "sql",
+			Kind: interpreterConfig.TypeInterpreterKind,
+			Rules: []interpreterConfig.InterpreterRuleConfig{
+				{
+					Match:       map[string]string{"db.type": "^cassandra$"},
+					ServiceName: "cassandra:${db.instance}",
+					ServiceType: "cassandra",
+				},
+			},
+		},
+		// invalid interpreters are skipped
+		{Name: "no-rules", Kind: interpreterConfig.TypeInterpreterKind},
+		{Name: "wrong-kind", Kind: "other", Rules: []interpreterConfig.InterpreterRuleConfig{{ServiceType: "other"}}},
+	}
+	sie := NewSpanInterpreterEngine(agentConfig)
+
+	assert.IsType(t, &interpreters.RuleTypeSpanInterpreter{}, sie.TypeInterpreters["grpc"])
+	assert.IsType(t, &interpreters.RuleSourceSpanInterpreter{}, sie.SourceInterpreters["nginx-ingress"])
+	assert.NotContains(t, sie.TypeInterpreters, "no-rules")
+	assert.NotContains(t, sie.TypeInterpreters, "wrong-kind")
+	assert.NotContains(t, sie.SourceInterpreters, "wrong-kind")
+
+	for _, tc := range []struct {
+		testCase string
+		span     pb.Span
+		expected pb.Span
+	}{
+		{
+			testCase: "Should run the configured type interpreter if the type matches",
+			span: pb.Span{
+				Service: "checkout",
+				Type:    "grpc",
+				Meta: map[string]string{
+					"span.hostname": "hostname",
+					"span.pid":      "10",
+					"span.kind":     "server",
+					"rpc.service":   "CheckoutService",
+				},
+			},
+			expected: pb.Span{
+				Service: "checkout",
+				Type:    "grpc",
+				Meta: map[string]string{
+					"span.hostname":    "hostname",
+					"span.pid":         "10",
+					"span.kind":        "server",
+					"rpc.service":      "CheckoutService",
+					"span.serviceName": "CheckoutService",
+					"span.serviceURN":  "urn:service:/CheckoutService",
+					"span.serviceType": "grpc",
+				},
+			},
+		},
+		{
+			testCase: "Should run the configured type interpreter that replaces a built-in interpreter",
+			span: pb.Span{
+				Service: "Cassandra",
+				Type:    "sql",
+				Meta: map[string]string{
+					"span.hostname": "hostname",
+					"span.pid":      "10",
+					"span.kind":     "client",
+					"db.type":       "cassandra",
+					"db.instance":   "Instance",
+				},
+			},
+			expected: pb.Span{
+				Service: "Cassandra",
+				Type:    "sql",
+				Meta: map[string]string{
+					"span.hostname":    "hostname",
+					"span.pid":         "10",
+					"span.kind":        "client",
+					"db.type":          "cassandra",
+					"db.instance":      "Instance",
+					"span.serviceName": "cassandra:Instance",
+					"span.serviceURN":  "urn:service:/cassandra:Instance",
+					"span.serviceType": "cassandra",
+				},
+			},
+		},
+		{
+			testCase: "Should fall back to the replaced built-in interpreter if no rule matches",
+			span: pb.Span{
+				Service: "Postgresql",
+				Type:    "sql",
+				Meta: map[string]string{
+					"span.hostname": "hostname",
+					"span.pid":      "10",
+					"span.kind":     "client",
+					"db.type":       "postgresql",
+					"db.instance":   "Instance",
+				},
+			},
+			expected: pb.Span{
+				Service: "Postgresql",
+				Type:    "sql",
+				Meta: map[string]string{
+					"span.hostname":    "hostname",
+					"span.pid":         "10",
+					"span.kind":        "client",
+					"db.type":          "postgresql",
+					"db.instance":      "Instance",
+					"span.serviceName": "Postgresql:Instance",
+					"span.serviceURN":  "urn:service:/Postgresql:Instance",
+					"span.serviceType": "postgresql",
+				},
+			},
+		},
+		{
+			testCase: "Should run the configured source interpreter if the meta source matches",
+			span: pb.Span{
+				Service: "nginx",
+				Meta: map[string]string{
+					"source":        "nginx-ingress",
+					"span.hostname": "hostname",
+					"ingress.name":  "shop",
+				},
+			},
+			expected: pb.Span{
+				Service: "nginx",
+				Meta: map[string]string{
+					"source":                  "nginx-ingress",
+					"span.hostname":           "hostname",
+					"ingress.name":            "shop",
+					"span.serviceName":        "shop",
+					"span.serviceURN":         "urn:service:/shop",
+					"span.serviceType":        "nginx",
+					"span.serviceInstanceURN": "urn:service-instance:/shop:/hostname",
+				},
+			},
+		},
+	} {
+		t.Run(tc.testCase, func(t *testing.T) {
+			trace := []*pb.Span{&tc.span}
+			actual := sie.Interpret(trace)
+			assert.EqualValues(t, tc.expected, *actual[0])
+		})
+	}
+}
@@ -44,6 +44,7 @@ type ContainerdItf interface {
 	Namespace() string
 	TaskMetrics(ctn containerd.Container) (*types.Metric, error)
 	TaskPids(ctn containerd.Container) ([]containerd.ProcessInfo, error)
+	TaskStatus(ctn containerd.Container) (containerd.Status, error)
 }
 
 // ContainerdUtil is the util used to interact with the Containerd api.
@@ -200,3 +201,17 @@ func (c *ContainerdUtil) TaskPids(ctn containerd.Container) ([]containerd.Proces
 
 	return t.Pids(ctxNamespace)
 }
+
+// TaskStatus interfaces with the containerd api to get the status of the task of a container
+func (c *ContainerdUtil) TaskStatus(ctn containerd.Container) (containerd.Status, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.queryTimeout)
+	defer cancel()
+	ctxNamespace := namespaces.WithNamespace(ctx, c.namespace)
+
+	t, errTask := ctn.Task(ctxNamespace, nil)
+	if errTask != nil {
+		return containerd.Status{}, errTask
+	}
+
+	return t.Status(ctxNamespace)
+}
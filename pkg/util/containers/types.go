@@ -79,6 +79,9 @@ type Container struct {
 	StartedAt   int64
 
 	Mounts []types.MountPoint
+	// sts
+	Labels     map[string]string
+	NetworkIDs []string
 
 	metrics.ContainerMetrics
 	Limits  metrics.ContainerLimits
@@ -158,4 +161,11 @@ type SwarmTask struct {
 	ContainerSpec   *swarm.ContainerSpec   `json:",omitempty"`
 	ContainerStatus *swarm.ContainerStatus `json:",omitempty"`
 	DesiredState    swarm.TaskState        `json:",omitempty"`
+	// the error and time of the last status change of the task
+	Error     string    `json:",omitempty"`
+	Timestamp time.Time `json:",omitempty"`
+	// the node the task is scheduled on and its state
+	NodeID           string                 `json:",omitempty"`
+	NodeState        swarm.NodeState        `json:",omitempty"`
+	NodeAvailability swarm.NodeAvailability `json:",omitempty"`
 }
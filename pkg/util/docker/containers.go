@@ -14,6 +14,7 @@ import (
 	"io"
 	"net"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -244,6 +245,8 @@ func (d *DockerUtil) dockerContainers(cfg *ContainerListConfig) ([]*containers.C
 			Excluded:    excluded,
 			Health:      parseContainerHealth(c.Status),
 			Mounts:      c.Mounts,
+			Labels:      c.Labels,
+			NetworkIDs:  containerNetworkIDs(c.NetworkSettings),
 			AddressList: d.parseContainerNetworkAddresses(c.ID, c.Ports, c.NetworkSettings, c.Names[0]),
 		}
 
@@ -265,6 +268,21 @@ func (d *DockerUtil) dockerContainers(cfg *ContainerListConfig) ([]*containers.C
 	return ret, nil
 }
 
+// containerNetworkIDs returns the ids of the networks the container is attached to
+func containerNetworkIDs(netSettings *types.SummaryNetworkSettings) []string {
+	if netSettings == nil {
+		return nil
+	}
+	networkIDs := make([]string, 0, len(netSettings.Networks))
+	for _, network := range netSettings.Networks {
+		if network != nil && network.NetworkID != "" {
+			networkIDs = append(networkIDs, network.NetworkID)
+		}
+	}
+	sort.Strings(networkIDs)
+	return networkIDs
+}
+
 // Parse the health out of a container status. The format is either:
 //  - 'Up 5 seconds (health: starting)'
 //  - 'Up 18 hours (unhealthy)'
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 
 	"github.com/StackVista/stackstate-agent/pkg/config"
@@ -131,6 +132,28 @@ func (d *DockerUtil) CountVolumes() (int, int, error) {
 	return len(attachedVolumes.Volumes), len(danglingVolumes.Volumes), nil
 }
 
+// ListNetworks returns all the docker networks together with the containers attached to them on this host.
+func (d *DockerUtil) ListNetworks() ([]types.NetworkResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.queryTimeout)
+	defer cancel()
+	networks, err := d.cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list docker networks: %s", err)
+	}
+	return networks, nil
+}
+
+// ListVolumes returns all the docker volumes.
+func (d *DockerUtil) ListVolumes() ([]*types.Volume, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.queryTimeout)
+	defer cancel()
+	volumes, err := d.cli.VolumeList(ctx, filters.NewArgs())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list docker volumes: %s", err)
+	}
+	return volumes.Volumes, nil
+}
+
 // RawContainerList wraps around the docker client's ContainerList method.
 // Value validation and error handling are the caller's responsibility.
 func (d *DockerUtil) RawContainerList(options types.ContainerListOptions) ([]types.Container, error) {
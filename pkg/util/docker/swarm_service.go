@@ -37,7 +37,7 @@ func dockerSwarmServices(ctx context.Context, client SwarmServiceAPIClient) ([]*
 		return nil, fmt.Errorf("error listing swarm services: %s", err)
 	}
 
-	activeNodes, err := getActiveNodes(ctx, client)
+	nodes, err := getNodes(ctx, client)
 	if err != nil {
 		log.Errorf("Error getting active nodes: %s", err)
 		return nil, err
@@ -75,7 +75,8 @@ func dockerSwarmServices(ctx context.Context, client SwarmServiceAPIClient) ([]*
 					desired++
 				}
 			}
-			if _, nodeActive := activeNodes[task.NodeID]; nodeActive && task.Status.State == swarm.TaskStateRunning {
+			node, nodeFound := nodes[task.NodeID]
+			if nodeFound && node.Status.State == swarm.NodeStateReady && task.Status.State == swarm.TaskStateRunning {
 				log.Debugf("Task having service ID %s is running", task.ServiceID)
 				running++
 			}
@@ -86,6 +87,13 @@ func dockerSwarmServices(ctx context.Context, client SwarmServiceAPIClient) ([]*
 				ContainerSpec:   task.Spec.ContainerSpec,
 				ContainerStatus: task.Status.ContainerStatus,
 				DesiredState:    task.Status.State,
+				Error:           task.Status.Err,
+				Timestamp:       task.Status.Timestamp,
+				NodeID:          task.NodeID,
+			}
+			if nodeFound {
+				taskComponent.NodeState = node.Status.State
+				taskComponent.NodeAvailability = node.Spec.Availability
 			}
 			log.Debugf("Creating a task %s for service %s", task.Name, s.Spec.Name)
 			tasksComponents = append(tasksComponents, taskComponent)
@@ -116,16 +124,15 @@ func dockerSwarmServices(ctx context.Context, client SwarmServiceAPIClient) ([]*
 	return ret, nil
 }
 
-func getActiveNodes(ctx context.Context, client SwarmServiceAPIClient) (map[string]bool, error) {
-	nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+// getNodes returns all the nodes of the swarm cluster by their ID
+func getNodes(ctx context.Context, client SwarmServiceAPIClient) (map[string]swarm.Node, error) {
+	nodeList, err := client.NodeList(ctx, types.NodeListOptions{})
 	if err != nil {
 		return nil, err
 	}
-	activeNodes := make(map[string]bool)
-	for _, n := range nodes {
-		if n.Status.State == swarm.NodeStateReady {
-			activeNodes[n.ID] = true
-		}
+	nodes := make(map[string]swarm.Node, len(nodeList))
+	for _, n := range nodeList {
+		nodes[n.ID] = n
 	}
-	return activeNodes, nil
+	return nodes, nil
 }
@@ -111,6 +111,8 @@ var (
 					Image: "stackstate/stackstate-agent-2-test:stac-12057-swarm-topology@sha256:1d463af3e8c407e08bff9f6127e4959d5286a25018ec5269bfad5324815eb367",
 				},
 				DesiredState: swarm.TaskStateRunning,
+				NodeID:       "NodeStateReady",
+				NodeState:    swarm.NodeStateReady,
 			},
 		},
 		DesiredTasks: 1,
@@ -118,7 +120,7 @@ var (
 	}
 )
 
-func TestDockerUtil_getActiveNodes(t *testing.T) {
+func TestDockerUtil_getNodes(t *testing.T) {
 
 	mockSwarmServiceClient := &mockSwarmServiceAPIClient{
 		nodeList: func() ([]swarm.Node, error) {
@@ -152,13 +154,20 @@ func TestDockerUtil_getActiveNodes(t *testing.T) {
 		},
 	}
 
-	nodeMap, err := getActiveNodes(nil, mockSwarmServiceClient)
+	nodeMap, err := getNodes(nil, mockSwarmServiceClient)
 	assert.NoError(t, err)
 
-	expectedNodeMap := map[string]bool{
-		"Node-NodeStateReady": true,
+	expectedNodeStates := map[string]swarm.NodeState{
+		"Node-NodeStateDown":         swarm.NodeStateDown,
+		"Node-NodeStateUnknown":      swarm.NodeStateUnknown,
+		"Node-NodeStateReady":        swarm.NodeStateReady,
+		"Node-NodeStateDisconnected": swarm.NodeStateDisconnected,
 	}
-	assert.EqualValues(t, expectedNodeMap, nodeMap)
+	nodeStates := make(map[string]swarm.NodeState, len(nodeMap))
+	for id, node := range nodeMap {
+		nodeStates[id] = node.Status.State
+	}
+	assert.EqualValues(t, expectedNodeStates, nodeStates)
 }
 
 func TestDockerUtil_dockerSwarmServices(t *testing.T) {
@@ -19,6 +19,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -73,6 +74,10 @@ type APIClient struct {
 	// the corresponding MutatingWebhookConfiguration object.
 	WebhookConfigInformerFactory informers.SharedInformerFactory
 
+	// DynamicInformerFactory gives access to informers for custom resources.
+	// This informer can be used by the topology check to watch the custom resources it collects.
+	DynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory
+
 	// WPAClient gives access to WPA API
 	WPAClient wpa_client.Interface
 	// WPAInformerFactory gives access to informers for Watermark Pod Autoscalers.
@@ -229,6 +234,16 @@ func getInformerFactory() (informers.SharedInformerFactory, error) {
 	return informers.NewSharedInformerFactory(client, resyncPeriodSeconds*time.Second), nil
 }
 
+func getDynamicInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
+	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
+	client, err := getKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
+	if err != nil {
+		log.Errorf("Could not get apiserver dynamic client: %v", err)
+		return nil, err
+	}
+	return dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriodSeconds*time.Second), nil
+}
+
 func getInformerFactoryWithOption(options informers.SharedInformerOption) (informers.SharedInformerFactory, error) {
 	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
 	client, err := getKubeClient(0) // No timeout for the Informers, to allow long watch.
@@ -247,7 +262,8 @@ func (c *APIClient) connect() error {
 		return err
 	}
 
-	if config.Datadog.GetBool("admission_controller.enabled") || config.Datadog.GetBool("compliance_config.enabled") {
+	if config.Datadog.GetBool("admission_controller.enabled") || config.Datadog.GetBool("compliance_config.enabled") ||
+		config.Datadog.GetBool("collect_kubernetes_topology") {
 		c.DynamicCl, err = getKubeDynamicClient(time.Duration(c.timeoutSeconds) * time.Second)
 		if err != nil {
 			log.Infof("Could not get apiserver dynamic client: %v", err)
@@ -261,6 +277,12 @@ func (c *APIClient) connect() error {
 		return err
 	}
 
+	if config.Datadog.GetBool("collect_kubernetes_topology") {
+		if c.DynamicInformerFactory, err = getDynamicInformerFactory(); err != nil {
+			return err
+		}
+	}
+
 	if config.Datadog.GetBool("orchestrator_explorer.enabled") {
 		tweakListOptions := func(options *metav1.ListOptions) {
 			options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", "").String()
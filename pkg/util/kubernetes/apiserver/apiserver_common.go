@@ -9,10 +9,17 @@ package apiserver
 
 import (
 	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2B1 "k8s.io/api/autoscaling/v2beta1"
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1B "k8s.io/api/batch/v1beta1"
 	coreV1 "k8s.io/api/core/v1"
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
 	extensionsV1B "k8s.io/api/extensions/v1beta1"
+	networkingV1 "k8s.io/api/networking/v1"
+	policyV1B1 "k8s.io/api/policy/v1beta1"
+	storageV1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type APICollectorClient interface {
@@ -23,6 +30,7 @@ type APICollectorClient interface {
 	GetJobs() ([]batchV1.Job, error)
 	GetCronJobs() ([]batchV1B.CronJob, error)
 	GetEndpoints() ([]coreV1.Endpoints, error)
+	GetEndpointSlices() ([]discoveryV1A1.EndpointSlice, error)
 	GetNodes() ([]coreV1.Node, error)
 	GetPods() ([]coreV1.Pod, error)
 	GetServices() ([]coreV1.Service, error)
@@ -32,4 +40,9 @@ type APICollectorClient interface {
 	GetNamespaces() ([]coreV1.Namespace, error)
 	GetPersistentVolumes() ([]coreV1.PersistentVolume, error)
 	GetPersistentVolumeClaims() ([]coreV1.PersistentVolumeClaim, error)
+	GetHorizontalPodAutoscalers() ([]autoscalingV2B1.HorizontalPodAutoscaler, error)
+	GetNetworkPolicies() ([]networkingV1.NetworkPolicy, error)
+	GetPodDisruptionBudgets() ([]policyV1B1.PodDisruptionBudget, error)
+	GetStorageClasses() ([]storageV1.StorageClass, error)
+	GetCustomResources(gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error)
 }
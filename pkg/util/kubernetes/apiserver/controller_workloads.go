@@ -9,6 +9,7 @@ package apiserver
 
 import (
 	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2B1 "k8s.io/api/autoscaling/v2beta1"
 	batchV1 "k8s.io/api/batch/v1"
 	batchV1B "k8s.io/api/batch/v1beta1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -73,3 +74,19 @@ func (c *APIClient) GetCronJobs() ([]batchV1B.CronJob, error) {
 
 	return cjList.Items, nil
 }
+
+// GetHorizontalPodAutoscalers() retrieves all the HorizontalPodAutoscalers in the Kubernetes / OpenShift cluster across all namespaces.
+// The HorizontalPodAutoscalers of the preferred served version are converted to v2beta1 HorizontalPodAutoscalers.
+func (c *APIClient) GetHorizontalPodAutoscalers() ([]autoscalingV2B1.HorizontalPodAutoscaler, error) {
+	gvr, err := optionalGroupVersionResource(c.Cl.Discovery(), horizontalPodAutoscalersGroupResource, horizontalPodAutoscalerVersions)
+	if err != nil {
+		return []autoscalingV2B1.HorizontalPodAutoscaler{}, err
+	}
+
+	items, err := c.GetCustomResources(gvr)
+	if err != nil {
+		return []autoscalingV2B1.HorizontalPodAutoscaler{}, optionalResourceError(gvr.Resource, err)
+	}
+
+	return horizontalPodAutoscalersFromUnstructured(items)
+}
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GetCustomResources() retrieves all the custom resources of the GroupVersionResource in the Kubernetes / OpenShift cluster across all namespaces.
+func (c *APIClient) GetCustomResources(gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	if c.DynamicCl == nil {
+		return []unstructured.Unstructured{}, fmt.Errorf("no dynamic client to retrieve the custom resources %s", gvr.String())
+	}
+
+	crList, err := c.DynamicCl.Resource(gvr).List(metaV1.ListOptions{})
+	if err != nil {
+		return []unstructured.Unstructured{}, err
+	}
+
+	return crList.Items, nil
+}
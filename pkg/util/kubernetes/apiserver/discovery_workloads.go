@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"errors"
+
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// ErrEndpointSlicesNotServed is returned when the API server does not serve any version of the EndpointSlices, the
+// Endpoints have to be used instead
+var ErrEndpointSlicesNotServed = errors.New("the EndpointSlices are not served by the API server")
+
+// endpointSliceVersions are the versions of the discovery.k8s.io EndpointSlices, in order of preference. They share
+// the fields of the v1alpha1 EndpointSlice that are collected
+var endpointSliceVersions = []string{"v1", "v1beta1", "v1alpha1"}
+
+// GetEndpointSlices() retrieves all the EndpointSlices in the Kubernetes / OpenShift cluster across all namespaces.
+// The EndpointSlices of the preferred served version are converted to v1alpha1 EndpointSlices.
+func (c *APIClient) GetEndpointSlices() ([]discoveryV1A1.EndpointSlice, error) {
+	gvr, err := endpointSliceGroupVersionResource(c.Cl.Discovery())
+	if err != nil {
+		return []discoveryV1A1.EndpointSlice{}, err
+	}
+
+	items, err := c.GetCustomResources(gvr)
+	if err != nil {
+		return []discoveryV1A1.EndpointSlice{}, err
+	}
+
+	return endpointSlicesFromUnstructured(items)
+}
+
+// endpointSliceGroupVersionResource returns the preferred version of the EndpointSlices that is served by the API
+// server, or ErrEndpointSlicesNotServed when none of the versions is served
+func endpointSliceGroupVersionResource(discoveryClient discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
+	groupResource := schema.GroupResource{Group: discoveryV1A1.GroupName, Resource: "endpointslices"}
+	gvr, found, err := servedGroupVersionResource(discoveryClient, groupResource, endpointSliceVersions)
+	if err != nil {
+		return gvr, err
+	}
+	if !found {
+		return gvr, ErrEndpointSlicesNotServed
+	}
+	return gvr, nil
+}
+
+// endpointSlicesFromUnstructured converts the EndpointSlices of any version to v1alpha1 EndpointSlices, the fields
+// that v1alpha1 does not know are dropped
+func endpointSlicesFromUnstructured(items []unstructured.Unstructured) ([]discoveryV1A1.EndpointSlice, error) {
+	endpointSlices := make([]discoveryV1A1.EndpointSlice, 0, len(items))
+	for _, item := range items {
+		var endpointSlice discoveryV1A1.EndpointSlice
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &endpointSlice); err != nil {
+			return []discoveryV1A1.EndpointSlice{}, err
+		}
+		endpointSlices = append(endpointSlices, endpointSlice)
+	}
+
+	return endpointSlices, nil
+}
@@ -0,0 +1,603 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/StackVista/stackstate-agent/pkg/util/log"
+	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2B1 "k8s.io/api/autoscaling/v2beta1"
+	batchV1 "k8s.io/api/batch/v1"
+	batchV1B "k8s.io/api/batch/v1beta1"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
+	extensionsV1B "k8s.io/api/extensions/v1beta1"
+	networkingV1 "k8s.io/api/networking/v1"
+	policyV1B1 "k8s.io/api/policy/v1beta1"
+	storageV1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	appsListers "k8s.io/client-go/listers/apps/v1"
+	batchListers "k8s.io/client-go/listers/batch/v1"
+	batchV1BListers "k8s.io/client-go/listers/batch/v1beta1"
+	coreListers "k8s.io/client-go/listers/core/v1"
+	extensionsListers "k8s.io/client-go/listers/extensions/v1beta1"
+	networkingListers "k8s.io/client-go/listers/networking/v1"
+	storageListers "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	daemonSetsInformer               InformerName = "daemonsets"
+	replicaSetsInformer              InformerName = "replicasets"
+	deploymentsInformer              InformerName = "deployments"
+	statefulSetsInformer             InformerName = "statefulsets"
+	jobsInformer                     InformerName = "jobs"
+	cronJobsInformer                 InformerName = "cronjobs"
+	nodesInformer                    InformerName = "nodes"
+	ingressesInformer                InformerName = "ingresses"
+	configMapsInformer               InformerName = "configmaps"
+	namespacesInformer               InformerName = "namespaces"
+	persistentVolumesInformer        InformerName = "persistentvolumes"
+	persistentVolumeClaimsInformer   InformerName = "persistentvolumeclaims"
+	horizontalPodAutoscalersInformer InformerName = "horizontalpodautoscalers"
+	networkPoliciesInformer          InformerName = "networkpolicies"
+	podDisruptionBudgetsInformer     InformerName = "poddisruptionbudgets"
+	storageClassesInformer           InformerName = "storageclasses"
+)
+
+// InformerCollectorClient implements the APICollectorClient with the listers of shared informers. The resources are
+// read from the local informer cache, which is kept up to date by watching the API server, instead of being listed on
+// the API server for every call. The informers of custom resources are only registered once the custom resource is
+// collected, as the custom resources are not known up front. The Secrets are cached without their data, see
+// SecretDataDigestAnnotation.
+//
+// The HorizontalPodAutoscalers, NetworkPolicies, PodDisruptionBudgets and StorageClasses are optional, their informers are
+// synced separately and they are not collected when they are not served by the API server or their informer does not
+// sync, e.g. because the ClusterRole of the agent does not include them. The HorizontalPodAutoscalers and
+// PodDisruptionBudgets are collected with the dynamic informers of their preferred served version.
+type InformerCollectorClient struct {
+	factory           informers.SharedInformerFactory
+	informers         map[InformerName]cache.SharedInformer
+	optionalInformers map[InformerName]cache.SharedInformer
+
+	dynamicFactory      dynamicinformer.DynamicSharedInformerFactory
+	customResources     map[schema.GroupVersionResource]informers.GenericInformer
+	customResourcesLock sync.Mutex
+	handlers            []cache.ResourceEventHandler
+	stopCh              <-chan struct{}
+
+	// the served versions of the EndpointSlices, HorizontalPodAutoscalers and PodDisruptionBudgets are discovered once
+	discoveryClient                  discovery.DiscoveryInterface
+	endpointSlicesResource           *discoveredResource
+	horizontalPodAutoscalersResource *discoveredResource
+	podDisruptionBudgetsResource     *discoveredResource
+
+	daemonSets             appsListers.DaemonSetLister
+	replicaSets            appsListers.ReplicaSetLister
+	deployments            appsListers.DeploymentLister
+	statefulSets           appsListers.StatefulSetLister
+	jobs                   batchListers.JobLister
+	cronJobs               batchV1BListers.CronJobLister
+	endpoints              coreListers.EndpointsLister
+	nodes                  coreListers.NodeLister
+	pods                   coreListers.PodLister
+	services               coreListers.ServiceLister
+	ingresses              extensionsListers.IngressLister
+	configMaps             coreListers.ConfigMapLister
+	secrets                coreListers.SecretLister
+	namespaces             coreListers.NamespaceLister
+	persistentVolumes      coreListers.PersistentVolumeLister
+	persistentVolumeClaims coreListers.PersistentVolumeClaimLister
+	networkPolicies        networkingListers.NetworkPolicyLister
+	storageClasses         storageListers.StorageClassLister
+}
+
+// NewInformerCollectorClient registers the informers of all the resources of the APICollectorClient with the factory.
+// The informers only fill their cache once the client is started. Custom resources, EndpointSlices,
+// HorizontalPodAutoscalers and PodDisruptionBudgets can not be collected when the dynamicFactory is nil.
+func NewInformerCollectorClient(factory informers.SharedInformerFactory, dynamicFactory dynamicinformer.DynamicSharedInformerFactory, discoveryClient discovery.DiscoveryInterface) *InformerCollectorClient {
+	c := &InformerCollectorClient{
+		factory:         factory,
+		dynamicFactory:  dynamicFactory,
+		discoveryClient: discoveryClient,
+		customResources: map[schema.GroupVersionResource]informers.GenericInformer{},
+		endpointSlicesResource: &discoveredResource{
+			discover:  endpointSliceGroupVersionResource,
+			permanent: func(err error) bool { return err == ErrEndpointSlicesNotServed },
+		},
+		horizontalPodAutoscalersResource: &discoveredResource{
+			discover: func(discoveryClient discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
+				return optionalGroupVersionResource(discoveryClient, horizontalPodAutoscalersGroupResource, horizontalPodAutoscalerVersions)
+			},
+			permanent: isResourceNotCollected,
+		},
+		podDisruptionBudgetsResource: &discoveredResource{
+			discover: func(discoveryClient discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
+				return optionalGroupVersionResource(discoveryClient, podDisruptionBudgetsGroupResource, podDisruptionBudgetVersions)
+			},
+			permanent: isResourceNotCollected,
+		},
+		daemonSets:             factory.Apps().V1().DaemonSets().Lister(),
+		replicaSets:            factory.Apps().V1().ReplicaSets().Lister(),
+		deployments:            factory.Apps().V1().Deployments().Lister(),
+		statefulSets:           factory.Apps().V1().StatefulSets().Lister(),
+		jobs:                   factory.Batch().V1().Jobs().Lister(),
+		cronJobs:               factory.Batch().V1beta1().CronJobs().Lister(),
+		endpoints:              factory.Core().V1().Endpoints().Lister(),
+		nodes:                  factory.Core().V1().Nodes().Lister(),
+		pods:                   factory.Core().V1().Pods().Lister(),
+		services:               factory.Core().V1().Services().Lister(),
+		ingresses:              factory.Extensions().V1beta1().Ingresses().Lister(),
+		configMaps:             factory.Core().V1().ConfigMaps().Lister(),
+		secrets:                coreListers.NewSecretLister(factory.InformerFor(&coreV1.Secret{}, newSecretInformer).GetIndexer()),
+		namespaces:             factory.Core().V1().Namespaces().Lister(),
+		persistentVolumes:      factory.Core().V1().PersistentVolumes().Lister(),
+		persistentVolumeClaims: factory.Core().V1().PersistentVolumeClaims().Lister(),
+		networkPolicies:        factory.Networking().V1().NetworkPolicies().Lister(),
+		storageClasses:         factory.Storage().V1().StorageClasses().Lister(),
+	}
+
+	c.informers = map[InformerName]cache.SharedInformer{
+		daemonSetsInformer:             factory.Apps().V1().DaemonSets().Informer(),
+		replicaSetsInformer:            factory.Apps().V1().ReplicaSets().Informer(),
+		deploymentsInformer:            factory.Apps().V1().Deployments().Informer(),
+		statefulSetsInformer:           factory.Apps().V1().StatefulSets().Informer(),
+		jobsInformer:                   factory.Batch().V1().Jobs().Informer(),
+		cronJobsInformer:               factory.Batch().V1beta1().CronJobs().Informer(),
+		endpointsInformer:              factory.Core().V1().Endpoints().Informer(),
+		nodesInformer:                  factory.Core().V1().Nodes().Informer(),
+		PodsInformer:                   factory.Core().V1().Pods().Informer(),
+		servicesInformer:               factory.Core().V1().Services().Informer(),
+		ingressesInformer:              factory.Extensions().V1beta1().Ingresses().Informer(),
+		configMapsInformer:             factory.Core().V1().ConfigMaps().Informer(),
+		SecretsInformer:                factory.InformerFor(&coreV1.Secret{}, newSecretInformer),
+		namespacesInformer:             factory.Core().V1().Namespaces().Informer(),
+		persistentVolumesInformer:      factory.Core().V1().PersistentVolumes().Informer(),
+		persistentVolumeClaimsInformer: factory.Core().V1().PersistentVolumeClaims().Informer(),
+	}
+
+	c.optionalInformers = map[InformerName]cache.SharedInformer{
+		networkPoliciesInformer: factory.Networking().V1().NetworkPolicies().Informer(),
+		storageClassesInformer:  factory.Storage().V1().StorageClasses().Informer(),
+	}
+
+	return c
+}
+
+// AddEventHandler adds the handler to the informers of all resources, it is notified of every change in the cache.
+func (c *InformerCollectorClient) AddEventHandler(handler cache.ResourceEventHandler) {
+	c.customResourcesLock.Lock()
+	defer c.customResourcesLock.Unlock()
+
+	for _, informer := range c.informers {
+		informer.AddEventHandler(handler)
+	}
+	for _, informer := range c.optionalInformers {
+		informer.AddEventHandler(handler)
+	}
+	for _, informer := range c.customResources {
+		informer.Informer().AddEventHandler(handler)
+	}
+	c.handlers = append(c.handlers, handler)
+}
+
+// Start starts the informers that are not running yet and blocks until their caches are synced or the
+// cache_sync_timeout exceeded. An optional informer that does not sync does not fail the start, its resources are not
+// collected until it is synced.
+func (c *InformerCollectorClient) Start(stopCh <-chan struct{}) error {
+	c.customResourcesLock.Lock()
+	c.stopCh = stopCh
+	c.customResourcesLock.Unlock()
+
+	c.factory.Start(stopCh)
+
+	var wg sync.WaitGroup
+	for name, informer := range c.optionalInformers {
+		wg.Add(1)
+		go func(name InformerName, informer cache.SharedInformer) {
+			defer wg.Done()
+			if err := SyncInformers(map[InformerName]cache.SharedInformer{name: informer}); err != nil {
+				_ = log.Warnf("The %s are not collected until their informer is synced: %s", name, err)
+			}
+		}(name, informer)
+	}
+	err := SyncInformers(c.informers)
+	wg.Wait()
+
+	return err
+}
+
+// optionalInformerSynced returns an error that wraps ErrResourceNotCollected when the optional informer is not synced
+func (c *InformerCollectorClient) optionalInformerSynced(name InformerName) error {
+	if !c.optionalInformers[name].HasSynced() {
+		return notCollectedError(string(name), errors.New("the informer is not synced"))
+	}
+	return nil
+}
+
+// GetDaemonSets returns the DaemonSets of the informer cache
+func (c *InformerCollectorClient) GetDaemonSets() ([]appsV1.DaemonSet, error) {
+	daemonSets, err := c.daemonSets.List(labels.Everything())
+	if err != nil {
+		return []appsV1.DaemonSet{}, err
+	}
+
+	items := make([]appsV1.DaemonSet, 0, len(daemonSets))
+	for _, daemonSet := range daemonSets {
+		items = append(items, *daemonSet)
+	}
+	return items, nil
+}
+
+// GetReplicaSets returns the ReplicaSets of the informer cache
+func (c *InformerCollectorClient) GetReplicaSets() ([]appsV1.ReplicaSet, error) {
+	replicaSets, err := c.replicaSets.List(labels.Everything())
+	if err != nil {
+		return []appsV1.ReplicaSet{}, err
+	}
+
+	items := make([]appsV1.ReplicaSet, 0, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		items = append(items, *replicaSet)
+	}
+	return items, nil
+}
+
+// GetDeployments returns the Deployments of the informer cache
+func (c *InformerCollectorClient) GetDeployments() ([]appsV1.Deployment, error) {
+	deployments, err := c.deployments.List(labels.Everything())
+	if err != nil {
+		return []appsV1.Deployment{}, err
+	}
+
+	items := make([]appsV1.Deployment, 0, len(deployments))
+	for _, deployment := range deployments {
+		items = append(items, *deployment)
+	}
+	return items, nil
+}
+
+// GetStatefulSets returns the StatefulSets of the informer cache
+func (c *InformerCollectorClient) GetStatefulSets() ([]appsV1.StatefulSet, error) {
+	statefulSets, err := c.statefulSets.List(labels.Everything())
+	if err != nil {
+		return []appsV1.StatefulSet{}, err
+	}
+
+	items := make([]appsV1.StatefulSet, 0, len(statefulSets))
+	for _, statefulSet := range statefulSets {
+		items = append(items, *statefulSet)
+	}
+	return items, nil
+}
+
+// GetJobs returns the Jobs of the informer cache
+func (c *InformerCollectorClient) GetJobs() ([]batchV1.Job, error) {
+	jobs, err := c.jobs.List(labels.Everything())
+	if err != nil {
+		return []batchV1.Job{}, err
+	}
+
+	items := make([]batchV1.Job, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, *job)
+	}
+	return items, nil
+}
+
+// GetCronJobs returns the CronJobs of the informer cache
+func (c *InformerCollectorClient) GetCronJobs() ([]batchV1B.CronJob, error) {
+	cronJobs, err := c.cronJobs.List(labels.Everything())
+	if err != nil {
+		return []batchV1B.CronJob{}, err
+	}
+
+	items := make([]batchV1B.CronJob, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		items = append(items, *cronJob)
+	}
+	return items, nil
+}
+
+// GetEndpoints returns the Endpoints of the informer cache
+func (c *InformerCollectorClient) GetEndpoints() ([]coreV1.Endpoints, error) {
+	endpoints, err := c.endpoints.List(labels.Everything())
+	if err != nil {
+		return []coreV1.Endpoints{}, err
+	}
+
+	items := make([]coreV1.Endpoints, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		items = append(items, *endpoint)
+	}
+	return items, nil
+}
+
+// GetEndpointSlices returns the EndpointSlices of the informer cache, converted to v1alpha1 EndpointSlices. The served
+// version of the EndpointSlices is discovered on the first call, ErrEndpointSlicesNotServed is returned when the API
+// server does not serve the EndpointSlices.
+func (c *InformerCollectorClient) GetEndpointSlices() ([]discoveryV1A1.EndpointSlice, error) {
+	if c.discoveryClient == nil {
+		return []discoveryV1A1.EndpointSlice{}, ErrEndpointSlicesNotServed
+	}
+	gvr, err := c.endpointSlicesResource.groupVersionResource(c.discoveryClient)
+	if err != nil {
+		return []discoveryV1A1.EndpointSlice{}, err
+	}
+
+	items, err := c.GetCustomResources(gvr)
+	if err != nil {
+		return []discoveryV1A1.EndpointSlice{}, err
+	}
+
+	return endpointSlicesFromUnstructured(items)
+}
+
+// GetNodes returns the Nodes of the informer cache
+func (c *InformerCollectorClient) GetNodes() ([]coreV1.Node, error) {
+	nodes, err := c.nodes.List(labels.Everything())
+	if err != nil {
+		return []coreV1.Node{}, err
+	}
+
+	items := make([]coreV1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		items = append(items, *node)
+	}
+	return items, nil
+}
+
+// GetPods returns the Pods of the informer cache
+func (c *InformerCollectorClient) GetPods() ([]coreV1.Pod, error) {
+	pods, err := c.pods.List(labels.Everything())
+	if err != nil {
+		return []coreV1.Pod{}, err
+	}
+
+	items := make([]coreV1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		items = append(items, *pod)
+	}
+	return items, nil
+}
+
+// GetServices returns the Services of the informer cache
+func (c *InformerCollectorClient) GetServices() ([]coreV1.Service, error) {
+	services, err := c.services.List(labels.Everything())
+	if err != nil {
+		return []coreV1.Service{}, err
+	}
+
+	items := make([]coreV1.Service, 0, len(services))
+	for _, service := range services {
+		items = append(items, *service)
+	}
+	return items, nil
+}
+
+// GetIngresses returns the Ingresses of the informer cache
+func (c *InformerCollectorClient) GetIngresses() ([]extensionsV1B.Ingress, error) {
+	ingresses, err := c.ingresses.List(labels.Everything())
+	if err != nil {
+		return []extensionsV1B.Ingress{}, err
+	}
+
+	items := make([]extensionsV1B.Ingress, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		items = append(items, *ingress)
+	}
+	return items, nil
+}
+
+// GetConfigMaps returns the ConfigMaps of the informer cache
+func (c *InformerCollectorClient) GetConfigMaps() ([]coreV1.ConfigMap, error) {
+	configMaps, err := c.configMaps.List(labels.Everything())
+	if err != nil {
+		return []coreV1.ConfigMap{}, err
+	}
+
+	items := make([]coreV1.ConfigMap, 0, len(configMaps))
+	for _, configMap := range configMaps {
+		items = append(items, *configMap)
+	}
+	return items, nil
+}
+
+// GetSecrets returns the Secrets of the informer cache
+func (c *InformerCollectorClient) GetSecrets() ([]coreV1.Secret, error) {
+	secrets, err := c.secrets.List(labels.Everything())
+	if err != nil {
+		return []coreV1.Secret{}, err
+	}
+
+	items := make([]coreV1.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		items = append(items, *secret)
+	}
+	return items, nil
+}
+
+// GetNamespaces returns the Namespaces of the informer cache
+func (c *InformerCollectorClient) GetNamespaces() ([]coreV1.Namespace, error) {
+	namespaces, err := c.namespaces.List(labels.Everything())
+	if err != nil {
+		return []coreV1.Namespace{}, err
+	}
+
+	items := make([]coreV1.Namespace, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		items = append(items, *namespace)
+	}
+	return items, nil
+}
+
+// GetPersistentVolumes returns the PersistentVolumes of the informer cache
+func (c *InformerCollectorClient) GetPersistentVolumes() ([]coreV1.PersistentVolume, error) {
+	persistentVolumes, err := c.persistentVolumes.List(labels.Everything())
+	if err != nil {
+		return []coreV1.PersistentVolume{}, err
+	}
+
+	items := make([]coreV1.PersistentVolume, 0, len(persistentVolumes))
+	for _, persistentVolume := range persistentVolumes {
+		items = append(items, *persistentVolume)
+	}
+	return items, nil
+}
+
+// GetPersistentVolumeClaims returns the PersistentVolumeClaims of the informer cache
+func (c *InformerCollectorClient) GetPersistentVolumeClaims() ([]coreV1.PersistentVolumeClaim, error) {
+	persistentVolumeClaims, err := c.persistentVolumeClaims.List(labels.Everything())
+	if err != nil {
+		return []coreV1.PersistentVolumeClaim{}, err
+	}
+
+	items := make([]coreV1.PersistentVolumeClaim, 0, len(persistentVolumeClaims))
+	for _, persistentVolumeClaim := range persistentVolumeClaims {
+		items = append(items, *persistentVolumeClaim)
+	}
+	return items, nil
+}
+
+// GetHorizontalPodAutoscalers returns the HorizontalPodAutoscalers of the informer cache of their preferred served
+// version, converted to v2beta1 HorizontalPodAutoscalers
+func (c *InformerCollectorClient) GetHorizontalPodAutoscalers() ([]autoscalingV2B1.HorizontalPodAutoscaler, error) {
+	items, err := c.getOptionalResources(c.horizontalPodAutoscalersResource, horizontalPodAutoscalersGroupResource)
+	if err != nil {
+		return []autoscalingV2B1.HorizontalPodAutoscaler{}, err
+	}
+
+	return horizontalPodAutoscalersFromUnstructured(items)
+}
+
+// GetNetworkPolicies returns the NetworkPolicies of the informer cache
+func (c *InformerCollectorClient) GetNetworkPolicies() ([]networkingV1.NetworkPolicy, error) {
+	if err := c.optionalInformerSynced(networkPoliciesInformer); err != nil {
+		return []networkingV1.NetworkPolicy{}, err
+	}
+
+	networkPolicies, err := c.networkPolicies.List(labels.Everything())
+	if err != nil {
+		return []networkingV1.NetworkPolicy{}, err
+	}
+
+	items := make([]networkingV1.NetworkPolicy, 0, len(networkPolicies))
+	for _, networkPolicy := range networkPolicies {
+		items = append(items, *networkPolicy)
+	}
+	return items, nil
+}
+
+// GetPodDisruptionBudgets returns the PodDisruptionBudgets of the informer cache of their preferred served version,
+// converted to v1beta1 PodDisruptionBudgets
+func (c *InformerCollectorClient) GetPodDisruptionBudgets() ([]policyV1B1.PodDisruptionBudget, error) {
+	items, err := c.getOptionalResources(c.podDisruptionBudgetsResource, podDisruptionBudgetsGroupResource)
+	if err != nil {
+		return []policyV1B1.PodDisruptionBudget{}, err
+	}
+
+	return podDisruptionBudgetsFromUnstructured(items)
+}
+
+// GetStorageClasses returns the StorageClasses of the informer cache
+func (c *InformerCollectorClient) GetStorageClasses() ([]storageV1.StorageClass, error) {
+	if err := c.optionalInformerSynced(storageClassesInformer); err != nil {
+		return []storageV1.StorageClass{}, err
+	}
+
+	storageClasses, err := c.storageClasses.List(labels.Everything())
+	if err != nil {
+		return []storageV1.StorageClass{}, err
+	}
+
+	items := make([]storageV1.StorageClass, 0, len(storageClasses))
+	for _, storageClass := range storageClasses {
+		items = append(items, *storageClass)
+	}
+	return items, nil
+}
+
+// getOptionalResources returns the optional resources of the dynamic informer of their discovered version, an error
+// that wraps ErrResourceNotCollected is returned when they are not served or their informer does not sync
+func (c *InformerCollectorClient) getOptionalResources(resource *discoveredResource, groupResource schema.GroupResource) ([]unstructured.Unstructured, error) {
+	if c.discoveryClient == nil {
+		return []unstructured.Unstructured{}, notCollectedError(groupResource.String(), errors.New("no discovery client"))
+	}
+	gvr, err := resource.groupVersionResource(c.discoveryClient)
+	if err != nil {
+		return []unstructured.Unstructured{}, err
+	}
+
+	items, err := c.GetCustomResources(gvr)
+	if err != nil {
+		return []unstructured.Unstructured{}, notCollectedError(groupResource.String(), err)
+	}
+	return items, nil
+}
+
+// GetCustomResources returns the custom resources of the GroupVersionResource of the informer cache. The informer of the
+// custom resource is started and synced the first time it is collected.
+func (c *InformerCollectorClient) GetCustomResources(gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	informer, err := c.customResourceInformer(gvr)
+	if err != nil {
+		return []unstructured.Unstructured{}, err
+	}
+
+	customResources, err := informer.Lister().List(labels.Everything())
+	if err != nil {
+		return []unstructured.Unstructured{}, err
+	}
+
+	items := make([]unstructured.Unstructured, 0, len(customResources))
+	for _, customResource := range customResources {
+		if item, ok := customResource.(*unstructured.Unstructured); ok {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+// customResourceInformer returns the synced informer of the GroupVersionResource, it is registered with the event
+// handlers of the client and started when it does not exist yet
+func (c *InformerCollectorClient) customResourceInformer(gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	c.customResourcesLock.Lock()
+	defer c.customResourcesLock.Unlock()
+
+	if c.dynamicFactory == nil {
+		return nil, fmt.Errorf("no dynamic informer factory to collect the custom resources %s", gvr.String())
+	}
+	if c.stopCh == nil {
+		return nil, fmt.Errorf("the informers are not started, can not collect the custom resources %s", gvr.String())
+	}
+
+	informer, found := c.customResources[gvr]
+	if !found {
+		informer = c.dynamicFactory.ForResource(gvr)
+		for _, handler := range c.handlers {
+			informer.Informer().AddEventHandler(handler)
+		}
+		c.customResources[gvr] = informer
+		c.dynamicFactory.Start(c.stopCh)
+		if err := SyncInformers(map[InformerName]cache.SharedInformer{InformerName(gvr.String()): informer.Informer()}); err != nil {
+			return nil, err
+		}
+	} else if !informer.Informer().HasSynced() {
+		// a custom resource that is not served by the API server does not sync, it is not waited for again
+		return nil, fmt.Errorf("the informer of the custom resources %s is not synced", gvr.String())
+	}
+
+	return informer, nil
+}
@@ -0,0 +1,300 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsV1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1A1 "k8s.io/api/discovery/v1alpha1"
+	storageV1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryFake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clientTesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestInformerCollectorClient(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&coreV1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}},
+		&coreV1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "kube-system"}},
+		&coreV1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&appsV1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deployment-1", Namespace: "default"}},
+	)
+	collectorClient := NewInformerCollectorClient(informers.NewSharedInformerFactory(client, 0), nil, client.Discovery())
+
+	added := make(chan string, 10)
+	collectorClient.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*coreV1.Pod); ok {
+				added <- pod.Name
+			}
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	require.NoError(t, collectorClient.Start(stopCh))
+
+	pods, err := collectorClient.GetPods()
+	require.NoError(t, err)
+	podNames := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		podNames = append(podNames, pod.Name)
+	}
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2"}, podNames)
+
+	nodes, err := collectorClient.GetNodes()
+	require.NoError(t, err)
+	assert.Len(t, nodes, 1)
+
+	deployments, err := collectorClient.GetDeployments()
+	require.NoError(t, err)
+	assert.Len(t, deployments, 1)
+
+	services, err := collectorClient.GetServices()
+	require.NoError(t, err)
+	assert.Empty(t, services)
+
+	// changes are picked up by the cache without listing the resources again
+	_, err = client.CoreV1().Pods("default").Create(&coreV1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-3", Namespace: "default"}})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		pods, err := collectorClient.GetPods()
+		return err == nil && len(pods) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	addedPods := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		addedPods = append(addedPods, <-added)
+	}
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2", "pod-3"}, addedPods)
+}
+
+func TestInformerCollectorClientCustomResources(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	certificate := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		}}
+	}
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(runtime.NewScheme(), certificate("certificate-1"))
+	collectorClient := NewInformerCollectorClient(
+		informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0),
+		dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0),
+		nil,
+	)
+
+	_, err := collectorClient.GetCustomResources(gvr)
+	assert.Error(t, err, "custom resources can not be collected before the client is started")
+
+	added := make(chan string, 10)
+	collectorClient.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if customResource, ok := obj.(*unstructured.Unstructured); ok {
+				added <- customResource.GetName()
+			}
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	require.NoError(t, collectorClient.Start(stopCh))
+
+	certificates, err := collectorClient.GetCustomResources(gvr)
+	require.NoError(t, err)
+	require.Len(t, certificates, 1)
+	assert.Equal(t, "certificate-1", certificates[0].GetName())
+	assert.Equal(t, "certificate-1", <-added)
+
+	_, err = dynamicClient.Resource(gvr).Namespace("default").Create(certificate("certificate-2"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		certificates, err := collectorClient.GetCustomResources(gvr)
+		return err == nil && len(certificates) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "certificate-2", <-added)
+
+	_, err = NewInformerCollectorClient(informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0), nil, nil).GetCustomResources(gvr)
+	assert.Error(t, err, "custom resources can not be collected without a dynamic informer factory")
+}
+
+func TestInformerCollectorClientEndpointSlices(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*discoveryFake.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "discovery.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "endpointslices", Kind: "EndpointSlice", Namespaced: true}},
+		},
+	}
+	// a v1 EndpointSlice, the fields v1alpha1 does not know are dropped
+	endpointSlice := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":  "discovery.k8s.io/v1",
+		"kind":        "EndpointSlice",
+		"metadata":    map[string]interface{}{"name": "web-abcde", "namespace": "default", "labels": map[string]interface{}{"kubernetes.io/service-name": "web"}},
+		"addressType": "IPv6",
+		"endpoints": []interface{}{
+			map[string]interface{}{
+				"addresses":  []interface{}{"fd00::1"},
+				"conditions": map[string]interface{}{"ready": true},
+				"hostname":   "web-0",
+				"nodeName":   "node-1",
+				"targetRef":  map[string]interface{}{"kind": "Pod", "name": "web-0", "namespace": "default"},
+			},
+		},
+		"ports": []interface{}{map[string]interface{}{"name": "http", "port": int64(8080), "protocol": "TCP"}},
+	}}
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(runtime.NewScheme(), endpointSlice)
+	collectorClient := NewInformerCollectorClient(
+		informers.NewSharedInformerFactory(client, 0),
+		dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0),
+		client.Discovery(),
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	require.NoError(t, collectorClient.Start(stopCh))
+
+	endpointSlices, err := collectorClient.GetEndpointSlices()
+	require.NoError(t, err)
+	require.Len(t, endpointSlices, 1)
+	assert.Equal(t, "web", endpointSlices[0].Labels["kubernetes.io/service-name"])
+	assert.Equal(t, discoveryV1A1.AddressType("IPv6"), *endpointSlices[0].AddressType)
+	require.Len(t, endpointSlices[0].Endpoints, 1)
+	assert.Equal(t, []string{"fd00::1"}, endpointSlices[0].Endpoints[0].Addresses)
+	assert.True(t, *endpointSlices[0].Endpoints[0].Conditions.Ready)
+	assert.Equal(t, "web-0", *endpointSlices[0].Endpoints[0].Hostname)
+	assert.Equal(t, "web-0", endpointSlices[0].Endpoints[0].TargetRef.Name)
+	require.Len(t, endpointSlices[0].Ports, 1)
+	assert.Equal(t, int32(8080), *endpointSlices[0].Ports[0].Port)
+
+	_, err = NewInformerCollectorClient(informers.NewSharedInformerFactory(client, 0), nil, nil).GetEndpointSlices()
+	assert.Equal(t, ErrEndpointSlicesNotServed, err)
+}
+
+func TestInformerCollectorClientDropsSecretData(t *testing.T) {
+	data := map[string][]byte{"password": []byte("secret"), "user": []byte("admin")}
+	client := fake.NewSimpleClientset(
+		&coreV1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret-1", Namespace: "default"}, Data: data},
+	)
+	collectorClient := NewInformerCollectorClient(informers.NewSharedInformerFactory(client, 0), nil, client.Discovery())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	require.NoError(t, collectorClient.Start(stopCh))
+
+	secrets, err := collectorClient.GetSecrets()
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Nil(t, secrets[0].Data)
+	assert.Equal(t, SecretDataDigest(data), secrets[0].Annotations[SecretDataDigestAnnotation])
+
+	// watched secrets are cached without their data as well
+	_, err = client.CoreV1().Secrets("default").Create(&coreV1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret-2", Namespace: "default"}, Data: data})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		secrets, err := collectorClient.GetSecrets()
+		return err == nil && len(secrets) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	secrets, err = collectorClient.GetSecrets()
+	require.NoError(t, err)
+	for _, secret := range secrets {
+		assert.Nil(t, secret.Data)
+		assert.Equal(t, SecretDataDigest(data), secret.Annotations[SecretDataDigestAnnotation])
+	}
+}
+
+func TestInformerCollectorClientOptionalResources(t *testing.T) {
+	defer func(timeout time.Duration) { syncTimeout = timeout }(syncTimeout)
+	syncTimeout = 100 * time.Millisecond
+
+	client := fake.NewSimpleClientset(&storageV1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})
+	// the agent is not allowed to list the NetworkPolicies, their informer does not sync
+	client.PrependReactor("list", "networkpolicies", func(action clientTesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "networking.k8s.io", Resource: "networkpolicies"}, "", errors.New("forbidden"))
+	})
+	// autoscaling/v2 is preferred, the PodDisruptionBudgets are not served
+	client.Discovery().(*discoveryFake.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "autoscaling/v2",
+			APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true}},
+		},
+		{
+			GroupVersion: "autoscaling/v2beta1",
+			APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true}},
+		},
+		{GroupVersion: "policy/v1"},
+		{
+			GroupVersion: "policy/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "podsecuritypolicies", Kind: "PodSecurityPolicy"}},
+		},
+	}
+	hpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{"kind": "Deployment", "name": "web"},
+			"minReplicas":    int64(1),
+			"maxReplicas":    int64(5),
+			"metrics": []interface{}{
+				map[string]interface{}{
+					"type": "Resource",
+					"resource": map[string]interface{}{
+						"name":   "cpu",
+						"target": map[string]interface{}{"type": "Utilization", "averageUtilization": int64(80)},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"currentReplicas": int64(2), "desiredReplicas": int64(3)},
+	}}
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(runtime.NewScheme(), hpa)
+	collectorClient := NewInformerCollectorClient(
+		informers.NewSharedInformerFactory(client, 0),
+		dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0),
+		client.Discovery(),
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	require.NoError(t, collectorClient.Start(stopCh), "optional informers that do not sync do not fail the start")
+
+	_, err := collectorClient.GetNetworkPolicies()
+	assert.True(t, errors.Is(err, ErrResourceNotCollected))
+
+	storageClasses, err := collectorClient.GetStorageClasses()
+	require.NoError(t, err)
+	assert.Len(t, storageClasses, 1)
+
+	hpas, err := collectorClient.GetHorizontalPodAutoscalers()
+	require.NoError(t, err)
+	require.Len(t, hpas, 1)
+	assert.Equal(t, "web", hpas[0].Name)
+	assert.Equal(t, "web", hpas[0].Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(5), hpas[0].Spec.MaxReplicas)
+	assert.Equal(t, int32(3), hpas[0].Status.DesiredReplicas)
+
+	_, err = collectorClient.GetPodDisruptionBudgets()
+	assert.True(t, errors.Is(err, ErrResourceNotCollected))
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	autoscalingV2B1 "k8s.io/api/autoscaling/v2beta1"
+	policyV1B1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// ErrResourceNotCollected is wrapped by the errors of the optional resources, the HorizontalPodAutoscalers,
+// NetworkPolicies, PodDisruptionBudgets and StorageClasses, when they are not served by the API server or the agent
+// is not allowed to list them. The collectors of the optional resources are skipped on this error.
+var ErrResourceNotCollected = errors.New("the resource is not collected")
+
+// horizontalPodAutoscalerVersions are the versions of the autoscaling HorizontalPodAutoscalers, in order of preference.
+// They share the fields of the v2beta1 HorizontalPodAutoscaler that are collected, apart from the metrics.
+var horizontalPodAutoscalerVersions = []string{"v2", "v2beta2", "v2beta1"}
+
+// podDisruptionBudgetVersions are the versions of the policy PodDisruptionBudgets, in order of preference. They share
+// the fields of the v1beta1 PodDisruptionBudget.
+var podDisruptionBudgetVersions = []string{"v1", "v1beta1"}
+
+var (
+	horizontalPodAutoscalersGroupResource = schema.GroupResource{Group: autoscalingV2B1.GroupName, Resource: string(horizontalPodAutoscalersInformer)}
+	podDisruptionBudgetsGroupResource     = schema.GroupResource{Group: policyV1B1.GroupName, Resource: string(podDisruptionBudgetsInformer)}
+)
+
+// notCollectedError wraps ErrResourceNotCollected with the reason that the resource is not collected
+func notCollectedError(resource string, reason error) error {
+	return fmt.Errorf("%w: the %s can not be collected: %s", ErrResourceNotCollected, resource, reason)
+}
+
+// optionalResourceError returns the error of listing an optional resource, a resource that is not found or that the
+// agent is forbidden to list is not collected
+func optionalResourceError(resource string, err error) error {
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return notCollectedError(resource, err)
+	}
+	return err
+}
+
+// servedGroupVersionResource returns the preferred version of the resource that is served by the API server, found is
+// false when none of the versions is served
+func servedGroupVersionResource(discoveryClient discovery.DiscoveryInterface, groupResource schema.GroupResource, versions []string) (gvr schema.GroupVersionResource, found bool, err error) {
+	for _, version := range versions {
+		gv := schema.GroupVersion{Group: groupResource.Group, Version: version}
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return schema.GroupVersionResource{}, false, err
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == groupResource.Resource {
+				return gv.WithResource(resource.Name), true, nil
+			}
+		}
+	}
+
+	return schema.GroupVersionResource{}, false, nil
+}
+
+// optionalGroupVersionResource returns the preferred served version of an optional resource, an error that wraps
+// ErrResourceNotCollected is returned when none of the versions is served
+func optionalGroupVersionResource(discoveryClient discovery.DiscoveryInterface, groupResource schema.GroupResource, versions []string) (schema.GroupVersionResource, error) {
+	gvr, found, err := servedGroupVersionResource(discoveryClient, groupResource, versions)
+	if err != nil {
+		return gvr, err
+	}
+	if !found {
+		return gvr, notCollectedError(groupResource.String(), errors.New("none of the versions is served by the API server"))
+	}
+	return gvr, nil
+}
+
+// discoveredResource caches the served version of a resource, it is discovered once. A failed discovery is retried on
+// the next call.
+type discoveredResource struct {
+	discover func(discovery.DiscoveryInterface) (schema.GroupVersionResource, error)
+	// permanent tells whether the error of the discovery is kept, e.g. because the resource is not served
+	permanent func(error) bool
+
+	lock       sync.Mutex
+	discovered bool
+	gvr        schema.GroupVersionResource
+	err        error
+}
+
+// groupVersionResource returns the discovered version of the resource
+func (r *discoveredResource) groupVersionResource(discoveryClient discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.discovered {
+		gvr, err := r.discover(discoveryClient)
+		if err != nil && !r.permanent(err) {
+			return gvr, err
+		}
+		r.gvr, r.err, r.discovered = gvr, err, true
+	}
+
+	return r.gvr, r.err
+}
+
+// horizontalPodAutoscalersFromUnstructured converts the HorizontalPodAutoscalers of any version to v2beta1
+// HorizontalPodAutoscalers. The metrics are dropped, their fields differ between the versions.
+func horizontalPodAutoscalersFromUnstructured(items []unstructured.Unstructured) ([]autoscalingV2B1.HorizontalPodAutoscaler, error) {
+	hpas := make([]autoscalingV2B1.HorizontalPodAutoscaler, 0, len(items))
+	for _, item := range items {
+		object := item.DeepCopy().Object
+		unstructured.RemoveNestedField(object, "spec", "metrics")
+		unstructured.RemoveNestedField(object, "status", "currentMetrics")
+
+		var hpa autoscalingV2B1.HorizontalPodAutoscaler
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, &hpa); err != nil {
+			return []autoscalingV2B1.HorizontalPodAutoscaler{}, err
+		}
+		hpas = append(hpas, hpa)
+	}
+
+	return hpas, nil
+}
+
+// podDisruptionBudgetsFromUnstructured converts the PodDisruptionBudgets of any version to v1beta1 PodDisruptionBudgets
+func podDisruptionBudgetsFromUnstructured(items []unstructured.Unstructured) ([]policyV1B1.PodDisruptionBudget, error) {
+	pdbs := make([]policyV1B1.PodDisruptionBudget, 0, len(items))
+	for _, item := range items {
+		var pdb policyV1B1.PodDisruptionBudget
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pdb); err != nil {
+			return []policyV1B1.PodDisruptionBudget{}, err
+		}
+		pdbs = append(pdbs, pdb)
+	}
+
+	return pdbs, nil
+}
+
+// isResourceNotCollected tells whether the error wraps ErrResourceNotCollected
+func isResourceNotCollected(err error) bool {
+	return errors.Is(err, ErrResourceNotCollected)
+}
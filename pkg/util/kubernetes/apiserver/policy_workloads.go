@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	networkingV1 "k8s.io/api/networking/v1"
+	policyV1B1 "k8s.io/api/policy/v1beta1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetNetworkPolicies() retrieves all the NetworkPolicies in the Kubernetes / OpenShift cluster across all namespaces.
+func (c *APIClient) GetNetworkPolicies() ([]networkingV1.NetworkPolicy, error) {
+	npList, err := c.Cl.NetworkingV1().NetworkPolicies(metaV1.NamespaceAll).List(metaV1.ListOptions{})
+	if err != nil {
+		return []networkingV1.NetworkPolicy{}, optionalResourceError(string(networkPoliciesInformer), err)
+	}
+
+	return npList.Items, nil
+}
+
+// GetPodDisruptionBudgets() retrieves all the PodDisruptionBudgets in the Kubernetes / OpenShift cluster across all namespaces.
+// The PodDisruptionBudgets of the preferred served version are converted to v1beta1 PodDisruptionBudgets.
+func (c *APIClient) GetPodDisruptionBudgets() ([]policyV1B1.PodDisruptionBudget, error) {
+	gvr, err := optionalGroupVersionResource(c.Cl.Discovery(), podDisruptionBudgetsGroupResource, podDisruptionBudgetVersions)
+	if err != nil {
+		return []policyV1B1.PodDisruptionBudget{}, err
+	}
+
+	items, err := c.GetCustomResources(gvr)
+	if err != nil {
+		return []policyV1B1.PodDisruptionBudget{}, optionalResourceError(gvr.Resource, err)
+	}
+
+	return podDisruptionBudgetsFromUnstructured(items)
+}
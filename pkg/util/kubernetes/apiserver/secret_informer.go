@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SecretDataDigestAnnotation is set on the Secrets of the informer cache of the InformerCollectorClient. The data of
+// the secrets is dropped before they are cached, the annotation holds the SecretDataDigest of the dropped data.
+const SecretDataDigestAnnotation = "stackstate.com/secret-data-digest"
+
+// SecretDataDigest returns the sha256 digest of the secret data, the keys are sorted so that the digest is stable
+func SecretDataDigest(data map[string][]byte) string {
+	hash := sha256.New()
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// writing to a hash never returns an error
+		_, _ = hash.Write([]byte(key))
+		_, _ = hash.Write(data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// newSecretInformer returns a Secrets informer that replaces the data of the listed and watched secrets with the
+// SecretDataDigestAnnotation, so that the secret data is not kept in the informer cache
+func newSecretInformer(client kubernetes.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				secrets, err := client.CoreV1().Secrets(metav1.NamespaceAll).List(options)
+				if err != nil {
+					return nil, err
+				}
+				for i := range secrets.Items {
+					dropSecretData(&secrets.Items[i])
+				}
+				return secrets, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				w, err := client.CoreV1().Secrets(metav1.NamespaceAll).Watch(options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+					if secret, ok := event.Object.(*coreV1.Secret); ok {
+						dropSecretData(secret)
+					}
+					return event, true
+				}), nil
+			},
+		},
+		&coreV1.Secret{},
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
+
+// dropSecretData replaces the data of the secret with the SecretDataDigestAnnotation
+func dropSecretData(secret *coreV1.Secret) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[SecretDataDigestAnnotation] = SecretDataDigest(secret.Data)
+	secret.Data = nil
+	secret.StringData = nil
+}
@@ -9,6 +9,7 @@ package apiserver
 
 import (
 	coreV1 "k8s.io/api/core/v1"
+	storageV1 "k8s.io/api/storage/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -61,3 +62,13 @@ func (c *APIClient) GetPersistentVolumeClaims() ([]coreV1.PersistentVolumeClaim,
 
 	return pvList.Items, nil
 }
+
+// GetStorageClasses() retrieves all the StorageClasses in the Kubernetes / OpenShift cluster.
+func (c *APIClient) GetStorageClasses() ([]storageV1.StorageClass, error) {
+	scList, err := c.Cl.StorageV1().StorageClasses().List(metaV1.ListOptions{})
+	if err != nil {
+		return []storageV1.StorageClass{}, optionalResourceError(string(storageClassesInformer), err)
+	}
+
+	return scList.Items, nil
+}